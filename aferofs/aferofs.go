@@ -0,0 +1,28 @@
+// Package aferofs adapts afero file systems to dsstore.WriteFS,
+// so stores can be written, walked and cleaned in afero file systems.
+package aferofs
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/spf13/afero"
+	"github.com/strongo/dsstore"
+)
+
+// FS is dsstore.WriteFS of afero file system
+type FS struct {
+	afero.IOFS
+}
+
+var _ dsstore.WriteFS = FS{}
+
+// New returns dsstore.WriteFS of afero file system
+func New(fsys afero.Fs) FS {
+	return FS{afero.NewIOFS(fsys)}
+}
+
+// OpenFile opens the file for writing
+func (f FS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	return f.Fs.OpenFile(name, flag, perm)
+}
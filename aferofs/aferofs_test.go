@@ -0,0 +1,41 @@
+package aferofs
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/strongo/dsstore"
+)
+
+func TestFS(t *testing.T) {
+	mem := afero.NewMemMapFs()
+	if err := mem.MkdirAll("a/b", 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	fsys := New(mem)
+	r := dsstore.Record{FileName: "file", Type: "long", Data: []byte{0, 0, 0, 1}}
+	r.SetCode("Iloc")
+	s := &dsstore.Store{Records: []dsstore.Record{r}}
+	for _, name := range []string{"a/.DS_Store", "a/b/.DS_Store"} {
+		if err := s.WriteFSWithOptions(fsys, name, 0o644, dsstore.WriteOptions{Atomic: true}); err != nil {
+			t.Fatalf("WriteFS failed: %v", err)
+		}
+	}
+	var found []string
+	if err := dsstore.WalkFS(fsys, ".", func(path string, s *dsstore.Store, err error) error {
+		if err != nil || len(s.Records) != 1 {
+			t.Errorf("unexpected store of %s: %v", path, err)
+		}
+		found = append(found, path)
+		return nil
+	}); err != nil || len(found) != 2 {
+		t.Errorf("expected 2 stores, got %v: %v", found, err)
+	}
+	summary, err := dsstore.CleanFS(fsys, "a", dsstore.CleanOptions{})
+	if err != nil || summary.Files != 2 {
+		t.Errorf("expected 2 cleaned files, got %+v: %v", summary, err)
+	}
+	if exists, _ := afero.Exists(mem, "a/.DS_Store"); exists {
+		t.Error("expected store to be removed")
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/strongo/dsstore/aferofs
+
+go 1.25.5
+
+replace github.com/strongo/dsstore => ../
+
+require (
+	github.com/spf13/afero v1.11.0
+	github.com/strongo/dsstore v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.38.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,96 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tags of extra fields of alias records
+const (
+	aliasTagFolderName  = 0  // name of the parent folder
+	aliasTagCarbonPath  = 2  // "Volume:folder:file"
+	aliasTagUnicodeName = 14 // file name in UTF-16
+	aliasTagUnicodeVol  = 15 // volume name in UTF-16
+	aliasTagPOSIXPath   = 18 // path inside of the volume
+	aliasTagMountPoint  = 19 // path of the mounted volume
+	aliasTagEnd         = 0xffff
+)
+
+// encodeAlias encodes version 2 alias record of the file with the absolute path inside of the volume,
+// like "/.background/bg.png", as "backgroundImageAlias" of icon view settings.
+// Catalog node IDs and dates are unknown before the volume is created, so Finder resolves
+// the alias by the volume name and the path.
+func encodeAlias(volume, file string) []byte {
+	file = path.Join("/", file)
+	dir, name := path.Split(file)
+	b := &bytes.Buffer{}
+	b.Write(make([]byte, 4))                         // application info
+	b.Write(make([]byte, 2))                         // record size, set below
+	_ = binary.Write(b, binary.BigEndian, uint16(2)) // version
+	b.Write(make([]byte, 2))                         // kind is file
+	b.Write(pascalString(carbonName(volume), 27))
+	b.Write(make([]byte, 4)) // volume creation date
+	b.WriteString("H+")      // file system type
+	b.Write(make([]byte, 2)) // fixed disk
+	b.Write(make([]byte, 4)) // parent directory ID
+	b.Write(pascalString(carbonName(name), 63))
+	b.Write(make([]byte, 4+4+4+4))                          // file ID, creation date, creator and type codes
+	_ = binary.Write(b, binary.BigEndian, [2]int16{-1, -1}) // unknown levels from and to the root
+	b.Write(make([]byte, 4+2+10))                           // volume attributes, file system ID and reserved bytes
+	if folder := path.Base(dir); folder != "/" {
+		aliasTag(b, aliasTagFolderName, []byte(carbonName(folder)))
+	}
+	carbonPath := []string{carbonName(volume)}
+	for _, element := range strings.Split(file[1:], "/") {
+		carbonPath = append(carbonPath, carbonName(element))
+	}
+	aliasTag(b, aliasTagCarbonPath, []byte(strings.Join(carbonPath, ":")))
+	aliasTag(b, aliasTagUnicodeName, unicodeName(name))
+	aliasTag(b, aliasTagUnicodeVol, unicodeName(volume))
+	aliasTag(b, aliasTagPOSIXPath, []byte(file))
+	aliasTag(b, aliasTagMountPoint, []byte(path.Join("/Volumes", volume)))
+	_ = binary.Write(b, binary.BigEndian, [2]uint16{aliasTagEnd, 0})
+	data := b.Bytes()
+	binary.BigEndian.PutUint16(data[4:], uint16(len(data)))
+	return data
+}
+
+// aliasTag writes extra field of alias record padded to even length
+func aliasTag(b *bytes.Buffer, tag uint16, data []byte) {
+	_ = binary.Write(b, binary.BigEndian, [2]uint16{tag, uint16(len(data))})
+	b.Write(data)
+	if len(data)%2 != 0 {
+		b.WriteByte(0)
+	}
+}
+
+// carbonName returns the name with ":" replaced by "/", like Carbon paths show it
+func carbonName(name string) string {
+	return strings.ReplaceAll(name, ":", "/")
+}
+
+// pascalString returns the string truncated to max bytes with the length byte in front, padded to max+1 bytes
+func pascalString(s string, max int) []byte {
+	if len(s) > max {
+		s = s[:max]
+	}
+	b := make([]byte, max+1)
+	b[0] = byte(len(s))
+	copy(b[1:], s)
+	return b
+}
+
+// unicodeName returns the name decomposed like HFS+ stores it as count of UTF-16 characters and the characters
+func unicodeName(name string) []byte {
+	units := utf16.Encode([]rune(norm.NFD.String(name)))
+	b := binary.BigEndian.AppendUint16(nil, uint16(len(units)))
+	for _, u := range units {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+package dsstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// arrangements are AppleScript arrangements of "arrangeBy" values of icon view settings
+var arrangements = map[string]string{
+	"none":         "not arranged",
+	"grid":         "snap to grid",
+	"name":         "arranged by name",
+	"kind":         "arranged by kind",
+	"size":         "arranged by size",
+	"label":        "arranged by label",
+	"dateModified": "arranged by modification date",
+	"dateCreated":  "arranged by creation date",
+}
+
+// AppleScript returns Finder script applying settings of the store to the folder,
+// see FolderSettings.AppleScript
+func (s *Store) AppleScript(folder string) (string, error) {
+	settings, err := s.FolderSettings()
+	if err != nil {
+		return "", err
+	}
+	return settings.AppleScript(folder), nil
+}
+
+// AppleScript returns Finder script applying the settings to the folder with POSIX path,
+// like "/Volumes/App". The script is run by osascript when writing .DS_Store directly isn't possible:
+// Finder opens the folder, sets view options and icon positions and writes .DS_Store itself.
+// Background pictures are not set, because their aliases reference files of other volumes.
+func (settings FolderSettings) AppleScript(folder string) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString("\t")
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\n")
+	}
+	b.WriteString("tell application \"Finder\"\n")
+	line("set theFolder to (POSIX file %s as alias)", appleScriptString(folder))
+	line("open theFolder")
+	line("set theWindow to container window of theFolder")
+	switch settings.View {
+	case "":
+	case "gallery view":
+		// gallery view replaced cover flow, Finder scripting knows it as flow view
+		line("set current view of theWindow to flow view")
+	default:
+		line("set current view of theWindow to %s", settings.View)
+	}
+	if w := settings.Window; w != nil {
+		line("set toolbar visible of theWindow to %t", w.ShowToolbar)
+		line("set statusbar visible of theWindow to %t", w.ShowStatusBar)
+		line("set pathbar visible of theWindow to %t", w.ShowPathbar)
+		if !w.Bounds.Empty() {
+			line("set bounds of theWindow to {%d, %d, %d, %d}", w.Bounds.Min.X, w.Bounds.Min.Y, w.Bounds.Max.X, w.Bounds.Max.Y)
+		}
+	}
+	if v := settings.IconView; v != nil {
+		line("set theOptions to icon view options of theWindow")
+		if arrangement, ok := arrangements[v.ArrangeBy]; ok {
+			line("set arrangement of theOptions to %s", arrangement)
+		}
+		if v.IconSize > 0 {
+			line("set icon size of theOptions to %d", int(v.IconSize))
+		}
+		if v.TextSize > 0 {
+			line("set text size of theOptions to %d", int(v.TextSize))
+		}
+		if v.LabelOnBottom {
+			line("set label position of theOptions to bottom")
+		} else {
+			line("set label position of theOptions to right")
+		}
+		line("set shows item info of theOptions to %t", v.ShowItemInfo)
+		line("set shows icon preview of theOptions to %t", v.ShowIconPreview)
+		if v.BackgroundType == 1 {
+			c := v.BackgroundColor
+			line("set background color of theOptions to {%d, %d, %d}", colorComponent(c[0]), colorComponent(c[1]), colorComponent(c[2]))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(settings.Positions)) {
+		p := settings.Positions[name]
+		line("set position of item %s of theFolder to {%d, %d}", appleScriptString(name), p.X, p.Y)
+	}
+	line("update theFolder without registering applications")
+	line("close theWindow")
+	b.WriteString("end tell\n")
+	return b.String()
+}
+
+// appleScriptString returns quoted AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// colorComponent converts color component from 0-1 range to AppleScript 0-65535 range
+func colorComponent(c float64) int {
+	return int(min(max(c, 0), 1)*65535 + 0.5)
+}
@@ -0,0 +1,77 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"image"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFolderSettings(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	settings, err := s.FolderSettings()
+	if err != nil {
+		t.Fatalf("FolderSettings failed: %v", err)
+	}
+	if settings.Window == nil || settings.Window.Bounds != image.Rect(200, 458, 560, 680) || settings.Window.ShowToolbar {
+		t.Errorf("unexpected window settings %+v", settings.Window)
+	}
+	if v := settings.IconView; v == nil || v.IconSize != 48 || v.TextSize != 12 || v.ArrangeBy != "none" ||
+		!v.LabelOnBottom || v.BackgroundColor != [3]float64{1, 1, 1} {
+		t.Errorf("unexpected icon view settings %+v", settings.IconView)
+	}
+	if len(settings.Positions) != 2 {
+		t.Errorf("unexpected positions %v", settings.Positions)
+	}
+
+	broken := Record{FileName: ".", Type: "blob", DataLen: 4, Data: []byte("junk")}
+	broken.SetCode("icvp")
+	s.Records = append(s.Records, broken)
+	if _, err = s.FolderSettings(); err == nil {
+		t.Error("expected error for broken icvp record")
+	}
+}
+
+func TestAppleScript(t *testing.T) {
+	style := Record{FileName: ".", Type: "type", Data: []byte("icnv")}
+	style.SetCode("vstl")
+	loc := Record{FileName: `My "App".app`, Type: "blob", DataLen: 16, Data: make([]byte, 16)}
+	loc.SetCode("Iloc")
+	binary.BigEndian.PutUint32(loc.Data, 140)
+	binary.BigEndian.PutUint32(loc.Data[4:], 120)
+	s := &Store{Records: []Record{style, loc}}
+	script, err := s.AppleScript("/Volumes/App")
+	if err != nil {
+		t.Fatalf("AppleScript failed: %v", err)
+	}
+	for _, line := range []string{
+		`set theFolder to (POSIX file "/Volumes/App" as alias)`,
+		`set current view of theWindow to icon view`,
+		`set position of item "My \"App\".app" of theFolder to {140, 120}`,
+	} {
+		if !strings.Contains(script, "\t"+line+"\n") {
+			t.Errorf("expected %q in script:\n%s", line, script)
+		}
+	}
+
+	settings := FolderSettings{
+		Window:   &WindowSettings{Bounds: image.Rect(10, 20, 110, 220)},
+		IconView: &IconViewSettings{IconSize: 128, ArrangeBy: "grid", BackgroundType: 1, BackgroundColor: [3]float64{1, 0.5, 0}},
+	}
+	script = settings.AppleScript(`C:\dir`)
+	for _, line := range []string{
+		`set theFolder to (POSIX file "C:\\dir" as alias)`,
+		`set bounds of theWindow to {10, 20, 110, 220}`,
+		`set arrangement of theOptions to snap to grid`,
+		`set icon size of theOptions to 128`,
+		`set background color of theOptions to {65535, 32768, 0}`,
+	} {
+		if !strings.Contains(script, "\t"+line+"\n") {
+			t.Errorf("expected %q in script:\n%s", line, script)
+		}
+	}
+}
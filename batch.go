@@ -0,0 +1,64 @@
+package dsstore
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Result of processing of one .DS_Store file by ProcessAll
+type Result struct {
+	Path string
+	Err  error // error of reading or processing, nil on success
+}
+
+// ProcessAll reads .DS_Store files in parallel and calls fn for each read store.
+// Not more than workers files are read and processed at the same time, so memory usage is bounded.
+// Zero or negative workers means runtime.GOMAXPROCS(0). fn is called concurrently,
+// the store is not used after fn returns. Results are in the order of paths.
+func ProcessAll(paths []string, workers int, fn func(path string, s *Store) error) []Result {
+	return ProcessAllContext(context.Background(), paths, workers, ReadOptions{}, fn)
+}
+
+// ProcessAllWithOptions is ProcessAll reading files using options
+func ProcessAllWithOptions(paths []string, workers int, opts ReadOptions, fn func(path string, s *Store) error) []Result {
+	return ProcessAllContext(context.Background(), paths, workers, opts, fn)
+}
+
+// ProcessAllContext is ProcessAll reading files using options until the context is done.
+// Files which are not processed because of the done context have the context error in results.
+func ProcessAllContext(ctx context.Context, paths []string, workers int, opts ReadOptions, fn func(path string, s *Store) error) []Result {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := make([]Result, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := ctx.Err()
+				if err == nil {
+					err = processFile(ctx, paths[i], opts, fn)
+				}
+				results[i] = Result{Path: paths[i], Err: err}
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func processFile(ctx context.Context, path string, opts ReadOptions, fn func(path string, s *Store) error) error {
+	var s Store
+	if err := s.readFile(ctx, path, opts); err != nil {
+		return err
+	}
+	return fn(path, &s)
+}
@@ -0,0 +1,50 @@
+package dsstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProcessAll(t *testing.T) {
+	testdata := filepath.Join(".", "testdata", "00.DS_Store")
+	missing := filepath.Join(t.TempDir(), "missing.DS_Store")
+	paths := []string{testdata, missing, testdata, testdata}
+	errFailed := errors.New("failed")
+
+	var records atomic.Int64
+	results := ProcessAll(paths, 2, func(path string, s *Store) error {
+		records.Add(int64(len(s.Records)))
+		if len(s.Records) == 0 {
+			return errFailed
+		}
+		return nil
+	})
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	for i, r := range results {
+		if r.Path != paths[i] {
+			t.Errorf("expected result %d for %q, got %q", i, paths[i], r.Path)
+		}
+		if (r.Err != nil) != (r.Path == missing) {
+			t.Errorf("unexpected error of %q: %v", r.Path, r.Err)
+		}
+	}
+	if !errors.Is(results[1].Err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", results[1].Err)
+	}
+	if records.Load() != 3*6 {
+		t.Errorf("expected %d records, got %d", 3*6, records.Load())
+	}
+
+	results = ProcessAll([]string{testdata}, 0, func(string, *Store) error { return errFailed })
+	if !errors.Is(results[0].Err, errFailed) {
+		t.Errorf("expected error of fn, got %v", results[0].Err)
+	}
+	if len(ProcessAll(nil, 4, nil)) != 0 {
+		t.Error("expected no results")
+	}
+}
@@ -0,0 +1,40 @@
+// Package billyfs adapts go-billy file systems to dsstore.WriteFS,
+// so stores can be written, walked and cleaned in billy file systems.
+package billyfs
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/helper/iofs"
+	"github.com/strongo/dsstore"
+)
+
+// FS is dsstore.WriteFS of billy file system
+type FS struct {
+	fs.FS
+	fsys billy.Filesystem
+}
+
+var _ dsstore.WriteFS = FS{}
+
+// New returns dsstore.WriteFS of billy file system
+func New(fsys billy.Filesystem) FS {
+	return FS{FS: iofs.New(fsys), fsys: fsys}
+}
+
+// OpenFile opens the file for writing
+func (f FS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	return f.fsys.OpenFile(name, flag, perm)
+}
+
+// Remove removes the file
+func (f FS) Remove(name string) error {
+	return f.fsys.Remove(name)
+}
+
+// Rename renames the file
+func (f FS) Rename(oldName, newName string) error {
+	return f.fsys.Rename(oldName, newName)
+}
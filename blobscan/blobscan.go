@@ -0,0 +1,99 @@
+// Package blobscan finds .DS_Store files in object storage buckets, fetches and reads them.
+// Buckets are accessed by BlobLister and BlobFetcher implementations, S3 implementation
+// is built with the s3 build tag.
+package blobscan
+
+import (
+	"context"
+	"io"
+	"path"
+	"runtime"
+	"sync"
+
+	"github.com/strongo/dsstore"
+)
+
+// BlobLister lists keys of objects of a bucket
+type BlobLister interface {
+	// List calls fn for keys starting with the prefix, listing stops when fn returns an error
+	List(ctx context.Context, prefix string, fn func(key string) error) error
+}
+
+// BlobFetcher fetches content of objects of a bucket
+type BlobFetcher interface {
+	Fetch(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Options are options of scanning
+type Options struct {
+	// Prefix limits scanning to keys starting with it
+	Prefix string
+	// Workers is count of objects fetched at the same time, runtime.GOMAXPROCS(0) by default
+	Workers int
+	// ReadOptions are options of reading fetched stores, they limit size of objects too
+	ReadOptions dsstore.ReadOptions
+}
+
+// Found is a .DS_Store object found in a bucket
+type Found struct {
+	Key   string
+	Store *dsstore.Store // read store, nil when nothing of the object can be read
+	Err   error          // error of fetching or reading the object
+}
+
+// Scan lists keys of the bucket, fetches and reads objects named .DS_Store.
+// Found objects are in the listing order, errors of objects are stored in them.
+// The returned error is an error of listing or of the context.
+func Scan(ctx context.Context, lister BlobLister, fetcher BlobFetcher, opts Options) ([]Found, error) {
+	var keys []string
+	err := lister.List(ctx, opts.Prefix, func(key string) error {
+		if path.Base(key) == dsstore.StoreFileName {
+			keys = append(keys, key)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	found := make([]Found, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(keys)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				found[i] = fetch(ctx, fetcher, keys[i], opts.ReadOptions)
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return found, ctx.Err()
+}
+
+// fetch fetches and reads the object
+func fetch(ctx context.Context, fetcher BlobFetcher, key string, opts dsstore.ReadOptions) Found {
+	if err := ctx.Err(); err != nil {
+		return Found{Key: key, Err: err}
+	}
+	r, err := fetcher.Fetch(ctx, key)
+	if err != nil {
+		return Found{Key: key, Err: err}
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	s := &dsstore.Store{}
+	if err = s.ReadContextWithOptions(ctx, r, opts); err != nil && len(s.Records) == 0 {
+		return Found{Key: key, Err: err}
+	}
+	return Found{Key: key, Store: s, Err: err}
+}
@@ -0,0 +1,103 @@
+package blobscan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func storeData(t *testing.T, names ...string) []byte {
+	var s dsstore.Store
+	for _, name := range names {
+		r := dsstore.Record{FileName: name, Type: "long", Data: []byte{0, 0, 0, 1}}
+		r.SetCode("Iloc")
+		s.Records = append(s.Records, r)
+	}
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// memBucket is in-memory bucket
+type memBucket map[string][]byte
+
+func (b memBucket) List(_ context.Context, prefix string, fn func(key string) error) error {
+	for _, key := range slices.Sorted(maps.Keys(b)) {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b memBucket) Fetch(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := b[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// missingBucket lists keys which can't be fetched
+type missingBucket struct {
+	memBucket
+}
+
+func (missingBucket) Fetch(context.Context, string) (io.ReadCloser, error) {
+	return nil, fs.ErrNotExist
+}
+
+func TestScan(t *testing.T) {
+	bucket := memBucket{
+		"site/.DS_Store":        storeData(t, "index.html"),
+		"site/index.html":       []byte("<html>"),
+		"site/img/.DS_Store":    storeData(t, "a.png", "b.png"),
+		"site/broken/.DS_Store": []byte("broken"),
+		"other/.DS_Store":       storeData(t, "x"),
+		"site/x.DS_Store":       []byte("not a store"),
+	}
+	found, err := Scan(context.Background(), bucket, bucket, Options{Prefix: "site/", Workers: 2})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	var keys []string
+	for _, f := range found {
+		keys = append(keys, f.Key)
+	}
+	if !slices.Equal(keys, []string{"site/.DS_Store", "site/broken/.DS_Store", "site/img/.DS_Store"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if found[0].Err != nil || len(found[0].Store.Records) != 1 {
+		t.Errorf("unexpected store of %s: %v", found[0].Key, found[0].Err)
+	}
+	if found[1].Err == nil || found[1].Store != nil {
+		t.Errorf("expected error for broken store, got %v", found[1].Store)
+	}
+	if found[2].Err != nil || len(found[2].Store.Records) != 2 {
+		t.Errorf("unexpected store of %s: %v", found[2].Key, found[2].Err)
+	}
+
+	found, err = Scan(context.Background(), bucket, missingBucket{bucket}, Options{Prefix: "other/"})
+	if err != nil || len(found) != 1 || !errors.Is(found[0].Err, fs.ErrNotExist) {
+		t.Errorf("expected fetch error, got %+v: %v", found, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = Scan(ctx, bucket, bucket, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build s3
+
+package blobscan
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// S3Bucket lists and fetches objects of a public S3 bucket by anonymous requests of S3 REST API,
+// so buckets leaking .DS_Store files are scanned without credentials
+type S3Bucket struct {
+	// Endpoint is URL of the bucket, like "https://bucket.s3.amazonaws.com"
+	// or "https://s3.eu-west-1.amazonaws.com/bucket" for path-style requests
+	Endpoint string
+	Client   *http.Client // HTTP client, http.DefaultClient by default
+}
+
+var (
+	_ BlobLister  = S3Bucket{}
+	_ BlobFetcher = S3Bucket{}
+)
+
+// listBucketResult is response of ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List lists keys of the bucket by ListObjectsV2 requests
+func (b S3Bucket) List(ctx context.Context, prefix string, fn func(key string) error) error {
+	var token string
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var result listBucketResult
+		err := b.get(ctx, strings.TrimSuffix(b.Endpoint, "/")+"/?"+query.Encode(), func(body io.Reader) error {
+			return xml.NewDecoder(body).Decode(&result)
+		})
+		if err != nil {
+			return err
+		}
+		for _, object := range result.Contents {
+			if err = fn(object.Key); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Fetch fetches content of the object
+func (b S3Bucket) Fetch(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.do(ctx, b.objectURL(key))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// objectURL returns URL of the object with escaped key
+func (b S3Bucket) objectURL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimSuffix(b.Endpoint, "/") + "/" + strings.Join(segments, "/")
+}
+
+// get requests the URL and reads the response body by read
+func (b S3Bucket) get(ctx context.Context, rawURL string, read func(body io.Reader) error) error {
+	resp, err := b.do(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return read(resp.Body)
+}
+
+// do requests the URL, responses with status other than 200 OK are errors
+func (b S3Bucket) do(ctx context.Context, rawURL string) (*http.Response, error) {
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	return resp, nil
+}
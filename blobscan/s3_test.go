@@ -0,0 +1,44 @@
+//go:build s3
+
+package blobscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestS3Bucket(t *testing.T) {
+	data := storeData(t, "a b.txt")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/bucket/" && r.URL.Query().Get("continuation-token") == "":
+			if r.URL.Query().Get("prefix") != "dir" {
+				t.Errorf("unexpected prefix %q", r.URL.Query().Get("prefix"))
+			}
+			_, _ = w.Write([]byte(`<ListBucketResult><Contents><Key>dir/a b.txt</Key></Contents>` +
+				`<IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`))
+		case r.URL.Path == "/bucket/":
+			_, _ = w.Write([]byte(`<ListBucketResult><Contents><Key>dir/sub dir/.DS_Store</Key></Contents>` +
+				`<IsTruncated>false</IsTruncated></ListBucketResult>`))
+		case r.URL.Path == "/bucket/dir/sub dir/.DS_Store":
+			_, _ = w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	bucket := S3Bucket{Endpoint: server.URL + "/bucket/"}
+	found, err := Scan(context.Background(), bucket, bucket, Options{Prefix: "dir"})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Key != "dir/sub dir/.DS_Store" || found[0].Err != nil ||
+		len(found[0].Store.Records) != 1 {
+		t.Errorf("unexpected found objects %+v", found)
+	}
+	if _, err = bucket.Fetch(context.Background(), "missing"); err == nil {
+		t.Error("expected error for missing object")
+	}
+}
@@ -0,0 +1,203 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Alias is decoded version 2 alias record, like "pict" records and "backgroundImageAlias" of "icvp" records
+type Alias struct {
+	Volume        string    // volume name
+	VolumeCreated time.Time // creation time of the volume, zero when unknown
+	Name          string    // file name
+	Created       time.Time // creation time of the file, zero when unknown
+	Path          string    // POSIX path inside of the volume, empty when unknown
+	CarbonPath    string    // "Volume:folder:file", empty when unknown
+	MountPoint    string    // path of the mounted volume, empty when unknown
+}
+
+// DecodeAlias decodes version 2 alias record, the inverse of encoding of IconViewSettings.SetBackground
+func DecodeAlias(data []byte) (Alias, error) {
+	const headerSize = 150
+	if len(data) < headerSize || binary.BigEndian.Uint16(data[6:]) != 2 {
+		return Alias{}, errors.New("not a version 2 alias record")
+	}
+	a := Alias{
+		Volume:        carbonName(pascalText(data[10:38])),
+		VolumeCreated: macTime(binary.BigEndian.Uint32(data[38:])),
+		Name:          carbonName(pascalText(data[50:114])),
+		Created:       macTime(binary.BigEndian.Uint32(data[118:])),
+	}
+	for b := data[headerSize:]; len(b) >= 4; {
+		tag, size := binary.BigEndian.Uint16(b), int(binary.BigEndian.Uint16(b[2:]))
+		if tag == aliasTagEnd {
+			break
+		}
+		if len(b) < 4+size {
+			return a, fmt.Errorf("alias tag %d exceeds the record", tag)
+		}
+		value := b[4 : 4+size]
+		switch tag {
+		case aliasTagCarbonPath:
+			a.CarbonPath = string(value)
+		case aliasTagUnicodeName:
+			a.Name = unicodeText(value)
+		case aliasTagUnicodeVol:
+			a.Volume = unicodeText(value)
+		case aliasTagPOSIXPath:
+			a.Path = string(value)
+		case aliasTagMountPoint:
+			a.MountPoint = string(value)
+		}
+		b = b[4+size+size%2:]
+	}
+	return a, nil
+}
+
+// pascalText returns text of the string with the length byte in front
+func pascalText(b []byte) string {
+	return string(b[1 : 1+min(int(b[0]), len(b)-1)])
+}
+
+// unicodeText returns text of the count of UTF-16 characters and the characters
+func unicodeText(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	units := make([]uint16, min(int(binary.BigEndian.Uint16(b)), (len(b)-2)/2))
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2+2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// macTime returns time of seconds since 1904, zero seconds are unknown time
+func macTime(seconds uint32) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return epoch1904.Add(time.Duration(seconds) * time.Second)
+}
+
+// Bookmark is decoded bookmark data of NSURL, like "pBBk" records
+type Bookmark struct {
+	Path          string    // absolute path of the file
+	Volume        string    // volume name
+	VolumePath    string    // path of the mounted volume
+	VolumeUUID    string    // UUID of the volume
+	VolumeCreated time.Time // creation time of the volume, zero when unknown
+	Created       time.Time // creation time of the file, zero when unknown
+}
+
+// Keys of bookmark items
+const (
+	bookmarkPath          = 0x1004
+	bookmarkCreated       = 0x1040
+	bookmarkVolumePath    = 0x2002
+	bookmarkVolumeCreated = 0x2013
+	bookmarkVolumeName    = 0x2010
+	bookmarkVolumeUUID    = 0x2011
+)
+
+// bookmarkEpoch is the start of dates of bookmarks
+var bookmarkEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DecodeBookmark decodes bookmark data: little endian header with "book" magic,
+// items referenced by tables of contents, dates are big endian seconds since 2001
+func DecodeBookmark(data []byte) (Bookmark, error) {
+	if len(data) < 52 || !bytes.HasPrefix(data, []byte("book")) {
+		return Bookmark{}, errors.New("not a bookmark")
+	}
+	start := int(binary.LittleEndian.Uint32(data[12:]))
+	if start < 16 || start+4 > len(data) {
+		return Bookmark{}, errors.New("bookmark header is invalid")
+	}
+	body := data[start:]
+	items := make(map[uint32]uint32)
+	visited := make(map[uint32]bool)
+	for toc := binary.LittleEndian.Uint32(body); toc != 0 && !visited[toc]; {
+		visited[toc] = true
+		if uint64(toc)+20 > uint64(len(body)) {
+			return Bookmark{}, errors.New("bookmark table of contents exceeds data")
+		}
+		count := binary.LittleEndian.Uint32(body[toc+16:])
+		if uint64(toc)+20+12*uint64(count) > uint64(len(body)) {
+			return Bookmark{}, errors.New("bookmark table of contents exceeds data")
+		}
+		for i := range count {
+			entry := body[toc+20+12*i:]
+			items[binary.LittleEndian.Uint32(entry)] = binary.LittleEndian.Uint32(entry[4:])
+		}
+		toc = binary.LittleEndian.Uint32(body[toc+12:])
+	}
+	var b Bookmark
+	if offset, ok := items[bookmarkPath]; ok {
+		for _, element := range bookmarkArray(body, offset) {
+			text, _ := bookmarkItem(body, element).(string)
+			b.Path += "/" + text
+		}
+	}
+	b.VolumePath, _ = bookmarkValue(body, items, bookmarkVolumePath).(string)
+	b.Volume, _ = bookmarkValue(body, items, bookmarkVolumeName).(string)
+	b.VolumeUUID, _ = bookmarkValue(body, items, bookmarkVolumeUUID).(string)
+	b.Created, _ = bookmarkValue(body, items, bookmarkCreated).(time.Time)
+	b.VolumeCreated, _ = bookmarkValue(body, items, bookmarkVolumeCreated).(time.Time)
+	return b, nil
+}
+
+// bookmarkValue returns decoded item of the key, nil when it is absent
+func bookmarkValue(body []byte, items map[uint32]uint32, key uint32) any {
+	offset, ok := items[key]
+	if !ok {
+		return nil
+	}
+	return bookmarkItem(body, offset)
+}
+
+// bookmarkItem decodes string, URL, date or UUID item at the offset, nil for other types
+func bookmarkItem(body []byte, offset uint32) any {
+	value, typ := bookmarkData(body, offset)
+	switch typ {
+	case 0x0101, 0x0901: // string, URL
+		return string(value)
+	case 0x0400: // date
+		if len(value) == 8 {
+			seconds := math.Float64frombits(binary.BigEndian.Uint64(value))
+			return bookmarkEpoch.Add(time.Duration(seconds * float64(time.Second)))
+		}
+	case 0x0201: // data, volume UUID is text
+		return strings.TrimRight(string(value), "\x00")
+	}
+	return nil
+}
+
+// bookmarkArray returns offsets of elements of array item at the offset
+func bookmarkArray(body []byte, offset uint32) []uint32 {
+	value, typ := bookmarkData(body, offset)
+	if typ != 0x0601 {
+		return nil
+	}
+	elements := make([]uint32, len(value)/4)
+	for i := range elements {
+		elements[i] = binary.LittleEndian.Uint32(value[4*i:])
+	}
+	return elements
+}
+
+// bookmarkData returns data and type of item at the offset, nil data when it exceeds the bookmark
+func bookmarkData(body []byte, offset uint32) ([]byte, uint32) {
+	if uint64(offset)+8 > uint64(len(body)) {
+		return nil, 0
+	}
+	size := binary.LittleEndian.Uint32(body[offset:])
+	if uint64(offset)+8+uint64(size) > uint64(len(body)) {
+		return nil, 0
+	}
+	return body[offset+8 : offset+8+size], binary.LittleEndian.Uint32(body[offset+4:])
+}
@@ -0,0 +1,45 @@
+package dsstore
+
+import (
+	"testing"
+)
+
+func TestDecodeAlias(t *testing.T) {
+	a, err := DecodeAlias(encodeAlias("My App", ".background/bg:1.png"))
+	if err != nil {
+		t.Fatalf("DecodeAlias failed: %v", err)
+	}
+	want := Alias{Volume: "My App", Name: "bg:1.png", Path: "/.background/bg:1.png",
+		CarbonPath: "My App:.background:bg/1.png", MountPoint: "/Volumes/My App"}
+	if a != want {
+		t.Errorf("want %+v, got %+v", want, a)
+	}
+	if _, err = DecodeAlias([]byte("book")); err == nil {
+		t.Error("want error for data which isn't alias")
+	}
+}
+
+func TestDecodeBookmark(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	var b Bookmark
+	var err error
+	for _, r := range s.Records {
+		if r.Code() == "pBBk" {
+			b, err = DecodeBookmark(r.Data)
+		}
+	}
+	if err != nil {
+		t.Fatalf("DecodeBookmark failed: %v", err)
+	}
+	if b.Path != "/Users/gwend/Library/Mobile Documents/com~apple~CloudDocs/Getscreen/Background_Black.png" ||
+		b.Volume != "Macintosh HD" || b.VolumePath != "/" || b.VolumeUUID != "81DED881-7FF2-4FBB-A8F8-DC8A4BC4C10E" ||
+		b.Created.Year() != 2019 || b.VolumeCreated.Year() != 2020 {
+		t.Errorf("unexpected bookmark %+v", b)
+	}
+	if _, err = DecodeBookmark(encodeAlias("App", "a")); err == nil {
+		t.Error("want error for data which isn't bookmark")
+	}
+}
@@ -0,0 +1,79 @@
+package btree
+
+// Build splits sorted records into nodes fitting into pageSize, size returns size of the encoded record.
+// Nodes have at most fanout records, 0 limits nodes only by pageSize. The last node of a level
+// exceeds the limits by the last record when the record can't be a separator without an empty node.
+// A record which doesn't fit into a node goes to the parent level as a separator
+// between the node and the next one. Nodes are built bottom-up, so children go
+// before their parents and the root node is the last one. Block index of the n-th
+// node is returned by index. It returns nodes and count of levels of internal nodes.
+func Build[R any](records []R, size func(R) int, pageSize, fanout int, index func(n int) uint32) ([]Node[R], int) {
+	var nodes []Node[R]
+	children := []uint32(nil) // children before records of the level, nil for leaves
+	var rightmost uint32      // the rightmost child of the level
+	levels := 0
+	for {
+		var parentRecords []R
+		var parentChildren []uint32
+		node := Node[R]{}
+		nodeSize := 8
+		// closeNode adds the node to the level, separator goes to the parent level
+		closeNode := func(separator R, nodeRightmost uint32) {
+			node.Rightmost = nodeRightmost
+			nodes = append(nodes, node)
+			parentRecords = append(parentRecords, separator)
+			parentChildren = append(parentChildren, index(len(nodes)-1))
+			node, nodeSize = Node[R]{}, 8
+		}
+		// addRecord adds the record with the child before it to the node
+		addRecord := func(r R, entrySize int, child uint32) {
+			node.Records = append(node.Records, r)
+			if children != nil {
+				node.Children = append(node.Children, child)
+			}
+			nodeSize += entrySize
+		}
+		for i, r := range records {
+			entrySize := size(r)
+			var child uint32
+			if children != nil {
+				entrySize += 4
+				child = children[i]
+			}
+			if len(node.Records) == 0 || nodeSize+entrySize <= pageSize && (fanout <= 0 || len(node.Records) < fanout) {
+				addRecord(r, entrySize, child)
+				continue
+			}
+			if i == len(records)-1 && len(node.Records) == 1 {
+				// the last record as a separator would start an empty rightmost node,
+				// so it goes to the node exceeding the limits
+				addRecord(r, entrySize, child)
+				continue
+			}
+			if i == len(records)-1 {
+				// the last record can't be a separator, so the last record of the node
+				// becomes the separator and the last record goes to the next node
+				last := len(node.Records) - 1
+				separator := node.Records[last]
+				var separatorChild uint32
+				node.Records = node.Records[:last]
+				if children != nil {
+					separatorChild = node.Children[last]
+					node.Children = node.Children[:last]
+				}
+				closeNode(separator, separatorChild)
+				addRecord(r, entrySize, child)
+				continue
+			}
+			closeNode(r, child)
+		}
+		node.Rightmost = rightmost
+		nodes = append(nodes, node)
+		if len(parentRecords) == 0 {
+			return nodes, levels
+		}
+		levels++
+		records, children = parentRecords, parentChildren
+		rightmost = index(len(nodes) - 1)
+	}
+}
@@ -0,0 +1,104 @@
+package btree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	var records [][]byte
+	for i := 0; i < 100; i++ {
+		records = append(records, bytes.Repeat([]byte{byte(i)}, 10))
+	}
+	size := func(r []byte) int { return len(r) }
+	nodes, levels := Build(records, size, 64, 0, func(n int) uint32 { return uint32(n + 2) })
+	if levels < 2 {
+		t.Errorf("expected at least 2 levels of internal nodes, got %d", levels)
+	}
+	// walk the tree in-order from the root, the last node
+	var walked [][]byte
+	var walk func(index uint32)
+	walk = func(index uint32) {
+		node := nodes[index-2]
+		if node.Size(size) > 64 {
+			t.Errorf("node %d of size %d doesn't fit into page", index, node.Size(size))
+		}
+		if len(node.Records) == 0 {
+			t.Errorf("node %d is empty", index)
+		}
+		for i, r := range node.Records {
+			if node.Children != nil {
+				walk(node.Children[i])
+			}
+			walked = append(walked, r)
+		}
+		if node.Rightmost != 0 {
+			walk(node.Rightmost)
+		}
+	}
+	walk(uint32(len(nodes) + 1))
+	if len(walked) != len(records) {
+		t.Fatalf("expected %d records, got %d", len(records), len(walked))
+	}
+	for i := range records {
+		if !bytes.Equal(records[i], walked[i]) {
+			t.Errorf("record %d is out of order", i)
+		}
+	}
+
+	nodes, levels = Build(nil, size, 64, 0, func(n int) uint32 { return uint32(n + 2) })
+	if len(nodes) != 1 || levels != 0 || len(nodes[0].Records) != 0 {
+		t.Errorf("expected one empty leaf, got %d nodes and %d levels", len(nodes), levels)
+	}
+}
+
+func TestBuildFanout(t *testing.T) {
+	var records [][]byte
+	for i := 0; i < 100; i++ {
+		records = append(records, []byte{byte(i)})
+	}
+	nodes, levels := Build(records, func(r []byte) int { return len(r) }, 4096, 3, func(n int) uint32 { return uint32(n + 2) })
+	if levels < 3 {
+		t.Errorf("expected at least 3 levels of internal nodes, got %d", levels)
+	}
+	count := 0
+	for i, node := range nodes {
+		if len(node.Records) > 3 {
+			t.Errorf("node %d has %d records, expected at most 3", i, len(node.Records))
+		}
+		count += len(node.Records)
+	}
+	if count != len(records) {
+		t.Errorf("expected %d records, got %d", len(records), count)
+	}
+}
+
+func TestBuildExactMultiple(t *testing.T) {
+	for fanout := 1; fanout <= 4; fanout++ {
+		for n := fanout; n <= 10*fanout; n += fanout {
+			records := make([][]byte, n)
+			for i := range records {
+				records[i] = []byte{byte(i)}
+			}
+			nodes, _ := Build(records, func(r []byte) int { return len(r) }, 4096, fanout, func(n int) uint32 { return uint32(n + 2) })
+			count := 0
+			for i, node := range nodes {
+				if len(node.Records) == 0 {
+					t.Errorf("fanout %d, %d records: node %d is empty", fanout, n, i)
+				}
+				count += len(node.Records)
+			}
+			if count != n {
+				t.Errorf("fanout %d: expected %d records, got %d", fanout, n, count)
+			}
+		}
+	}
+	// records which don't fit into a page by two
+	records := [][]byte{bytes.Repeat([]byte{1}, 3000), bytes.Repeat([]byte{2}, 3000)}
+	nodes, _ := Build(records, func(r []byte) int { return len(r) }, 4096, 0, func(n int) uint32 { return uint32(n + 2) })
+	for i, node := range nodes {
+		if len(node.Records) == 0 {
+			t.Errorf("node %d of big records is empty", i)
+		}
+	}
+}
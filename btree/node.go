@@ -0,0 +1,131 @@
+// Package btree implements on-disk B-trees like the DSDB tree of .DS_Store: nodes are stored
+// in blocks addressed by indexes, records are ordered by a key comparator and encoded by a record codec.
+// A node has the block index of its rightmost child (0 for leaf nodes) and the count of records,
+// internal nodes have the block index of a child before every record. All integers are big-endian.
+package btree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrMalformed is returned for trees with cycles, too deep trees and nodes which can't be decoded
+var ErrMalformed = errors.New("malformed B-tree")
+
+// Codec encodes and decodes records of nodes
+type Codec[R any] interface {
+	// Append appends encoded record to b
+	Append(b []byte, r R) []byte
+	// Decode reads the record from the node data
+	Decode(b *bytes.Buffer) (R, error)
+}
+
+// Node of B-tree
+type Node[R any] struct {
+	Rightmost uint32   // block index of the rightmost child, 0 for leaf node
+	Children  []uint32 // block indexes of children before records, nil for leaf node
+	Records   []R      // sorted records
+}
+
+// Leaf reports whether the node has no children
+func (n *Node[R]) Leaf() bool {
+	return n.Rightmost == 0
+}
+
+// Append appends encoded node to b, records are encoded by appendRecord
+func (n *Node[R]) Append(b []byte, appendRecord func(b []byte, r R) []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, n.Rightmost)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(n.Records)))
+	for i, r := range n.Records {
+		if n.Children != nil {
+			b = binary.BigEndian.AppendUint32(b, n.Children[i])
+		}
+		b = appendRecord(b, r)
+	}
+	return b
+}
+
+// Size returns size of the encoded node, size returns size of the encoded record
+func (n *Node[R]) Size(size func(R) int) int {
+	total := 8 + 4*len(n.Children)
+	for _, r := range n.Records {
+		total += size(r)
+	}
+	return total
+}
+
+// ReadHeader reads the block index of the rightmost child and the count of records of the node
+func ReadHeader(b *bytes.Buffer) (rightmost, count uint32, err error) {
+	if b.Len() < 8 {
+		b.Next(b.Len())
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return binary.BigEndian.Uint32(b.Next(4)), binary.BigEndian.Uint32(b.Next(4)), nil
+}
+
+// ReadChild reads the block index of the child before the next record of internal node
+func ReadChild(b *bytes.Buffer) (uint32, error) {
+	if b.Len() < 4 {
+		b.Next(b.Len())
+		return 0, io.ErrUnexpectedEOF
+	}
+	return binary.BigEndian.Uint32(b.Next(4)), nil
+}
+
+// ReadNode decodes the node, records are decoded by decode
+func ReadNode[R any](b *bytes.Buffer, decode func(b *bytes.Buffer) (R, error)) (*Node[R], error) {
+	rightmost, count, err := ReadHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	// every record has at least 4 bytes, so hostile counts don't allocate
+	if uint64(count)*4 > uint64(b.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	n := &Node[R]{Rightmost: rightmost, Records: make([]R, 0, count)}
+	if rightmost != 0 {
+		n.Children = make([]uint32, 0, count)
+	}
+	for range count {
+		if rightmost != 0 {
+			child, err := ReadChild(b)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+		r, err := decode(b)
+		if err != nil {
+			return nil, err
+		}
+		n.Records = append(n.Records, r)
+	}
+	return n, nil
+}
+
+// child returns block index of the child at position i, the rightmost child is at position len(Records)
+func (n *Node[R]) child(i int) uint32 {
+	if i < len(n.Children) {
+		return n.Children[i]
+	}
+	return n.Rightmost
+}
+
+// kids returns block indexes of all children of internal node with the rightmost one at the end
+func (n *Node[R]) kids() []uint32 {
+	if n.Leaf() {
+		return nil
+	}
+	return append(append(make([]uint32, 0, len(n.Children)+1), n.Children...), n.Rightmost)
+}
+
+// setKids sets children of internal node by the list with the rightmost one at the end
+func (n *Node[R]) setKids(kids []uint32) {
+	if len(kids) == 0 {
+		n.Children, n.Rightmost = nil, 0
+		return
+	}
+	n.Children, n.Rightmost = kids[:len(kids)-1:len(kids)-1], kids[len(kids)-1]
+}
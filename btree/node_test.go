@@ -0,0 +1,54 @@
+package btree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+// stringCodec encodes strings with big-endian length
+type stringCodec struct{}
+
+func (stringCodec) Append(b []byte, r string) []byte {
+	return append(binary.BigEndian.AppendUint32(b, uint32(len(r))), r...)
+}
+
+func (stringCodec) Decode(b *bytes.Buffer) (string, error) {
+	if b.Len() < 4 {
+		return "", io.ErrUnexpectedEOF
+	}
+	n := int(binary.BigEndian.Uint32(b.Next(4)))
+	if n > b.Len() {
+		return "", io.ErrUnexpectedEOF
+	}
+	return string(b.Next(n)), nil
+}
+
+func TestNode(t *testing.T) {
+	codec := stringCodec{}
+	for _, n := range []*Node[string]{
+		{Records: []string{"a", "bc"}},
+		{Rightmost: 4, Children: []uint32{2, 3}, Records: []string{"a", "bc"}},
+	} {
+		data := n.Append(nil, codec.Append)
+		if size := n.Size(func(r string) int { return 4 + len(r) }); size != len(data) {
+			t.Errorf("expected size %d, got %d", len(data), size)
+		}
+		read, err := ReadNode(bytes.NewBuffer(data), codec.Decode)
+		if err != nil {
+			t.Fatalf("ReadNode failed: %v", err)
+		}
+		if read.Leaf() != n.Leaf() || read.Rightmost != n.Rightmost || !slices.Equal(read.Children, n.Children) ||
+			!slices.Equal(read.Records, n.Records) {
+			t.Errorf("expected %+v, got %+v", n, read)
+		}
+		for size := range len(data) {
+			if _, err = ReadNode(bytes.NewBuffer(data[:size]), codec.Decode); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("expected ErrUnexpectedEOF for %d bytes, got %v", size, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package btree
+
+import "github.com/strongo/dsstore/buddy"
+
+// FilePager stores nodes in blocks of the buddy container
+type FilePager struct {
+	File *buddy.File
+}
+
+// ReadBlock returns data of the block
+func (p FilePager) ReadBlock(index uint32) ([]byte, error) {
+	return p.File.Block(index)
+}
+
+// WriteBlock writes data to the block, the rest of the block is zeroed
+func (p FilePager) WriteBlock(index uint32, data []byte) error {
+	block, err := p.File.Block(index)
+	if err != nil {
+		return err
+	}
+	if len(data) > len(block) {
+		if err = p.File.Resize(index, uint32(len(data))); err != nil {
+			return err
+		}
+		block, _ = p.File.Block(index)
+	}
+	copy(block, data)
+	clear(block[len(data):])
+	return nil
+}
+
+// AllocBlock allocates block for size bytes
+func (p FilePager) AllocBlock(size int) (uint32, error) {
+	return p.File.Alloc(uint32(size))
+}
+
+// FreeBlock frees the block
+func (p FilePager) FreeBlock(index uint32) error {
+	return p.File.Free(index)
+}
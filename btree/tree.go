@@ -0,0 +1,387 @@
+package btree
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// Pager stores nodes in blocks by indexes
+type Pager interface {
+	// ReadBlock returns data of the block
+	ReadBlock(index uint32) ([]byte, error)
+	// WriteBlock writes data to the block, the block is reallocated keeping its index when data doesn't fit
+	WriteBlock(index uint32, data []byte) error
+	// AllocBlock allocates block for size bytes and returns its index
+	AllocBlock(size int) (uint32, error)
+	// FreeBlock frees the block
+	FreeBlock(index uint32) error
+}
+
+// Tree is B-tree stored by pager, it is updated in place: only nodes on the path
+// to the changed record and their split or merged siblings are written.
+type Tree[R any] struct {
+	Root     uint32           // block index of the root node
+	Levels   int              // count of levels of internal nodes, 0 when all records are in the root
+	Count    int              // count of records
+	Nodes    int              // count of nodes
+	PageSize int              // nodes bigger than the page size are split
+	Compare  func(a, b R) int // compares keys of records
+	Codec    Codec[R]
+	Pager    Pager
+}
+
+// Create creates the tree with empty root node
+func Create[R any](pager Pager, codec Codec[R], compare func(a, b R) int, pageSize int) (*Tree[R], error) {
+	t := &Tree[R]{PageSize: pageSize, Compare: compare, Codec: codec, Pager: pager}
+	root, err := t.alloc(&Node[R]{})
+	if err != nil {
+		return nil, err
+	}
+	t.Root = root
+	return t, nil
+}
+
+// split of a node: the separator and block index of the new node following the separator
+type split[R any] struct {
+	ok        bool
+	separator R
+	right     uint32
+}
+
+// node reads and decodes the node at depth, depth of the root is 1
+func (t *Tree[R]) node(index uint32, depth int) (*Node[R], error) {
+	if depth > t.Levels+1 {
+		return nil, fmt.Errorf("%w: depth is more than %d", ErrMalformed, t.Levels+1)
+	}
+	data, err := t.Pager.ReadBlock(index)
+	if err != nil {
+		return nil, err
+	}
+	n, err := ReadNode(bytes.NewBuffer(data), t.Codec.Decode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: node %d: %w", ErrMalformed, index, err)
+	}
+	return n, nil
+}
+
+// encode returns encoded node
+func (t *Tree[R]) encode(n *Node[R]) []byte {
+	return n.Append(nil, t.Codec.Append)
+}
+
+// alloc allocates block of the new node and writes it
+func (t *Tree[R]) alloc(n *Node[R]) (uint32, error) {
+	data := t.encode(n)
+	index, err := t.Pager.AllocBlock(max(len(data), t.PageSize))
+	if err != nil {
+		return 0, err
+	}
+	t.Nodes++
+	return index, t.Pager.WriteBlock(index, data)
+}
+
+// free frees block of the node
+func (t *Tree[R]) free(index uint32) error {
+	t.Nodes--
+	return t.Pager.FreeBlock(index)
+}
+
+// store writes the node. Node bigger than the page size is split in halves by size:
+// the lower half stays in the block and the upper half goes to the new block.
+func (t *Tree[R]) store(index uint32, n *Node[R]) (split[R], error) {
+	data := t.encode(n)
+	if len(data) <= t.PageSize || len(n.Records) < 3 {
+		return split[R]{}, t.Pager.WriteBlock(index, data)
+	}
+	// the record in the middle by size becomes the separator, both halves keep at least one record
+	m, size := 1, 8
+	for i, r := range n.Records {
+		size += len(t.Codec.Append(nil, r))
+		if n.Children != nil {
+			size += 4
+		}
+		if size >= len(data)/2 {
+			m = i
+			break
+		}
+	}
+	m = min(max(m, 1), len(n.Records)-2)
+	s := split[R]{ok: true, separator: n.Records[m]}
+	right := &Node[R]{Records: slices.Clone(n.Records[m+1:])}
+	if kids := n.kids(); kids != nil {
+		right.setKids(slices.Clone(kids[m+1:]))
+		n.setKids(kids[:m+1])
+	}
+	n.Records = n.Records[:m]
+	var err error
+	if s.right, err = t.alloc(right); err != nil {
+		return s, err
+	}
+	return s, t.Pager.WriteBlock(index, t.encode(n))
+}
+
+// insertSplit adds the separator and the new child of split child at position i
+func (n *Node[R]) insertSplit(i int, s split[R]) {
+	kids := n.kids()
+	n.Records = slices.Insert(n.Records, i, s.separator)
+	n.setKids(slices.Insert(kids, i+1, s.right))
+}
+
+// search returns position of the key in the node and whether the record with the key is found
+func (t *Tree[R]) search(n *Node[R], key R) (int, bool) {
+	return slices.BinarySearchFunc(n.Records, key, t.Compare)
+}
+
+// Get returns the record with the same key
+func (t *Tree[R]) Get(key R) (R, bool, error) {
+	index := t.Root
+	for depth := 1; ; depth++ {
+		n, err := t.node(index, depth)
+		if err != nil {
+			var zero R
+			return zero, false, err
+		}
+		i, found := t.search(n, key)
+		if found {
+			return n.Records[i], true, nil
+		}
+		if n.Leaf() {
+			var zero R
+			return zero, false, nil
+		}
+		index = n.child(i)
+	}
+}
+
+// Walk calls fn for records in-order until fn returns an error
+func (t *Tree[R]) Walk(fn func(r R) error) error {
+	return t.walk(t.Root, 1, fn)
+}
+
+func (t *Tree[R]) walk(index uint32, depth int, fn func(r R) error) error {
+	n, err := t.node(index, depth)
+	if err != nil {
+		return err
+	}
+	for i, r := range n.Records {
+		if !n.Leaf() {
+			if err = t.walk(n.Children[i], depth+1, fn); err != nil {
+				return err
+			}
+		}
+		if err = fn(r); err != nil {
+			return err
+		}
+	}
+	if !n.Leaf() {
+		return t.walk(n.Rightmost, depth+1, fn)
+	}
+	return nil
+}
+
+// Insert adds the record or replaces the record with the same key.
+// Nodes which don't fit into the page size anymore are split, so the tree can grow by a level.
+func (t *Tree[R]) Insert(r R) error {
+	s, replaced, err := t.insert(t.Root, 1, r)
+	if err != nil {
+		return err
+	}
+	if !replaced {
+		t.Count++
+	}
+	return t.grow(s)
+}
+
+func (t *Tree[R]) insert(index uint32, depth int, r R) (split[R], bool, error) {
+	n, err := t.node(index, depth)
+	if err != nil {
+		return split[R]{}, false, err
+	}
+	i, found := t.search(n, r)
+	if found {
+		n.Records[i] = r
+		s, err := t.store(index, n)
+		return s, true, err
+	}
+	if n.Leaf() {
+		n.Records = slices.Insert(n.Records, i, r)
+		s, err := t.store(index, n)
+		return s, false, err
+	}
+	s, replaced, err := t.insert(n.child(i), depth+1, r)
+	if err != nil || !s.ok {
+		return split[R]{}, replaced, err
+	}
+	n.insertSplit(i, s)
+	s, err = t.store(index, n)
+	return s, replaced, err
+}
+
+// grow adds the new root node when the root node is split
+func (t *Tree[R]) grow(s split[R]) error {
+	if !s.ok {
+		return nil
+	}
+	root, err := t.alloc(&Node[R]{Rightmost: s.right, Children: []uint32{t.Root}, Records: []R{s.separator}})
+	if err != nil {
+		return err
+	}
+	t.Root = root
+	t.Levels++
+	return nil
+}
+
+// Delete removes the record with the key and reports whether it was found.
+// Underfull nodes are merged with their siblings when they fit together into the page size,
+// empty nodes borrow a record from their siblings, so the tree can shrink by a level.
+func (t *Tree[R]) Delete(key R) (bool, error) {
+	deleted, s, err := t.delete(t.Root, 1, key)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	t.Count--
+	if err = t.grow(s); err != nil {
+		return true, err
+	}
+	// internal root node without records is replaced by its only child
+	root, err := t.node(t.Root, 1)
+	if err != nil {
+		return true, err
+	}
+	if !root.Leaf() && len(root.Records) == 0 {
+		old := t.Root
+		t.Root = root.Rightmost
+		t.Levels--
+		return true, t.free(old)
+	}
+	return true, nil
+}
+
+func (t *Tree[R]) delete(index uint32, depth int, key R) (bool, split[R], error) {
+	n, err := t.node(index, depth)
+	if err != nil {
+		return false, split[R]{}, err
+	}
+	i, found := t.search(n, key)
+	if n.Leaf() {
+		if !found {
+			return false, split[R]{}, nil
+		}
+		n.Records = slices.Delete(n.Records, i, i+1)
+		s, err := t.store(index, n)
+		return true, s, err
+	}
+	if found {
+		// the record is replaced by the largest record of the subtree before it, which is deleted instead
+		if n.Records[i], err = t.last(n.child(i), depth+1); err != nil {
+			return false, split[R]{}, err
+		}
+		key = n.Records[i]
+	}
+	deleted, s, err := t.delete(n.child(i), depth+1, key)
+	if err != nil || !deleted {
+		return deleted, split[R]{}, err
+	}
+	if s.ok {
+		n.insertSplit(i, s)
+	} else if err = t.rebalance(n, i, depth); err != nil {
+		return true, split[R]{}, err
+	}
+	s, err = t.store(index, n)
+	return true, s, err
+}
+
+// last returns the largest record of the subtree
+func (t *Tree[R]) last(index uint32, depth int) (R, error) {
+	for ; ; depth++ {
+		n, err := t.node(index, depth)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		if n.Leaf() {
+			if len(n.Records) == 0 {
+				var zero R
+				return zero, fmt.Errorf("%w: node %d is empty", ErrMalformed, index)
+			}
+			return n.Records[len(n.Records)-1], nil
+		}
+		index = n.Rightmost
+	}
+}
+
+// rebalance merges the child at position i of the node with its sibling when the child is underfull
+// and they fit together, or the empty child borrows a record through the node from its sibling
+func (t *Tree[R]) rebalance(n *Node[R], i, depth int) error {
+	kids := n.kids()
+	child, err := t.node(kids[i], depth+1)
+	if err != nil {
+		return err
+	}
+	if len(child.Records) > 0 && len(t.encode(child)) >= t.PageSize/4 {
+		return nil
+	}
+	// the sibling after the child, the rightmost child uses the sibling before it
+	l := min(i, len(kids)-2)
+	if l < 0 {
+		return nil
+	}
+	left, right := child, child
+	if l == i {
+		right, err = t.node(kids[l+1], depth+1)
+	} else {
+		left, err = t.node(kids[l], depth+1)
+	}
+	if err != nil {
+		return err
+	}
+	merged := &Node[R]{Records: slices.Concat(left.Records, []R{n.Records[l]}, right.Records)}
+	if !left.Leaf() {
+		merged.setKids(slices.Concat(left.kids(), right.kids()))
+	}
+	if len(t.encode(merged)) <= t.PageSize {
+		// the merged node replaces the right one
+		if err = t.Pager.WriteBlock(kids[l+1], t.encode(merged)); err != nil {
+			return err
+		}
+		freed := kids[l]
+		n.Records = slices.Delete(n.Records, l, l+1)
+		n.setKids(slices.Delete(kids, l, l+1))
+		return t.free(freed)
+	}
+	if len(child.Records) > 0 {
+		return nil
+	}
+	if l == i {
+		// rotate the first record of the right sibling through the node
+		child.Records = []R{n.Records[l]}
+		n.Records[l] = right.Records[0]
+		right.Records = slices.Delete(right.Records, 0, 1)
+		if !child.Leaf() {
+			rightKids := right.kids()
+			child.setKids(append(child.kids(), rightKids[0]))
+			right.setKids(rightKids[1:])
+		}
+	} else {
+		// rotate the last record of the left sibling through the node
+		child.Records = []R{n.Records[l]}
+		last := len(left.Records) - 1
+		n.Records[l] = left.Records[last]
+		left.Records = left.Records[:last]
+		if !child.Leaf() {
+			leftKids := left.kids()
+			child.setKids(append([]uint32{leftKids[last+1]}, child.kids()...))
+			left.setKids(leftKids[:last+1])
+		}
+	}
+	for _, pair := range []struct {
+		index uint32
+		node  *Node[R]
+	}{{kids[l], left}, {kids[l+1], right}} {
+		if err = t.Pager.WriteBlock(pair.index, t.encode(pair.node)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package btree
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore/buddy"
+)
+
+// memPager keeps blocks in memory
+type memPager struct {
+	blocks map[uint32][]byte
+	next   uint32
+}
+
+func (p *memPager) ReadBlock(index uint32) ([]byte, error) {
+	data, ok := p.blocks[index]
+	if !ok {
+		return nil, fmt.Errorf("block %d is not allocated", index)
+	}
+	return data, nil
+}
+
+func (p *memPager) WriteBlock(index uint32, data []byte) error {
+	if _, ok := p.blocks[index]; !ok {
+		return fmt.Errorf("block %d is not allocated", index)
+	}
+	p.blocks[index] = bytes.Clone(data)
+	return nil
+}
+
+func (p *memPager) AllocBlock(int) (uint32, error) {
+	p.next++
+	p.blocks[p.next] = nil
+	return p.next, nil
+}
+
+func (p *memPager) FreeBlock(index uint32) error {
+	delete(p.blocks, index)
+	return nil
+}
+
+// checkTree checks that the tree has the records, leaves are at the same depth,
+// nodes fit into the page size and only the root node can be empty
+func checkTree(t *testing.T, tree *Tree[string], records []string, blocks int) {
+	t.Helper()
+	var walked []string
+	if err := tree.Walk(func(r string) error {
+		walked = append(walked, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if !slices.Equal(walked, records) || tree.Count != len(records) {
+		t.Fatalf("expected %d records, got %d with count %d", len(records), len(walked), tree.Count)
+	}
+	if tree.Nodes != blocks {
+		t.Errorf("expected %d nodes, got %d blocks", tree.Nodes, blocks)
+	}
+	var check func(index uint32, depth int)
+	check = func(index uint32, depth int) {
+		n, err := tree.node(index, depth)
+		if err != nil {
+			t.Fatalf("node %d: %v", index, err)
+		}
+		if size := len(tree.encode(n)); size > tree.PageSize && len(n.Records) > 2 {
+			t.Errorf("node %d of size %d doesn't fit into page", index, size)
+		}
+		if len(n.Records) == 0 && index != tree.Root {
+			t.Errorf("node %d is empty", index)
+		}
+		if n.Leaf() {
+			if depth != tree.Levels+1 {
+				t.Errorf("leaf %d is at depth %d, expected %d", index, depth, tree.Levels+1)
+			}
+			return
+		}
+		for _, child := range n.kids() {
+			check(child, depth+1)
+		}
+	}
+	check(tree.Root, 1)
+}
+
+func TestTree(t *testing.T) {
+	pager := &memPager{blocks: map[uint32][]byte{}}
+	tree, err := Create(pager, Codec[string](stringCodec{}), strings.Compare, 128)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	rnd := rand.New(rand.NewPCG(1, 2))
+	var records []string
+	for i := range 1000 {
+		records = append(records, fmt.Sprintf("record %04d %s", i, strings.Repeat("x", rnd.IntN(20))))
+	}
+	for _, i := range rnd.Perm(len(records)) {
+		if err = tree.Insert(records[i]); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	checkTree(t, tree, records, len(pager.blocks))
+	if tree.Levels < 2 {
+		t.Errorf("expected at least 2 levels of internal nodes, got %d", tree.Levels)
+	}
+
+	// records with the same key are replaced
+	compare := func(a, b string) int {
+		return strings.Compare(a[:len("record 0000")], b[:len("record 0000")])
+	}
+	tree.Compare = compare
+	records[500] = "record 0500 " + strings.Repeat("y", 50)
+	if err = tree.Insert(records[500]); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	r, ok, err := tree.Get("record 0500")
+	if err != nil || !ok || r != records[500] {
+		t.Errorf("expected replaced record, got %q, %v, %v", r, ok, err)
+	}
+	checkTree(t, tree, records, len(pager.blocks))
+
+	for n, i := range rnd.Perm(len(records)) {
+		ok, err := tree.Delete(records[i])
+		if err != nil || !ok {
+			t.Fatalf("Delete of %q failed: %v, %v", records[i], ok, err)
+		}
+		records[i] = ""
+		if n%100 == 0 {
+			checkTree(t, tree, slices.DeleteFunc(slices.Clone(records), func(r string) bool { return r == "" }), len(pager.blocks))
+		}
+	}
+	if ok, err = tree.Delete("record 0001"); ok || err != nil {
+		t.Errorf("expected missing record, got %v, %v", ok, err)
+	}
+	checkTree(t, tree, nil, len(pager.blocks))
+	if tree.Levels != 0 || tree.Nodes != 1 {
+		t.Errorf("expected empty root, got %d levels and %d nodes", tree.Levels, tree.Nodes)
+	}
+}
+
+func TestTreeFilePager(t *testing.T) {
+	f := buddy.New()
+	tree, err := Create(FilePager{f}, Codec[string](stringCodec{}), strings.Compare, 256)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	var records []string
+	for i := range 300 {
+		records = append(records, fmt.Sprintf("record %03d", i))
+		if err = tree.Insert(records[i]); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	f.Directory["tree"] = tree.Root
+	var buf bytes.Buffer
+	if err = f.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	read, err := buddy.Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	reopened := &Tree[string]{Root: read.Directory["tree"], Levels: tree.Levels, Count: tree.Count, Nodes: tree.Nodes,
+		PageSize: 256, Compare: strings.Compare, Codec: stringCodec{}, Pager: FilePager{read}}
+	checkTree(t, reopened, records, read.Blocks()-1)
+}
@@ -0,0 +1,186 @@
+package btree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// frame is a node on the traversal stack
+type frame struct {
+	node      uint32        // block index of the node
+	depth     int           // depth of the node, root has depth 1
+	block     *bytes.Buffer // data of the node, nil until loaded
+	rightmost uint32        // rightmost child of internal node, 0 for leaf node
+	remaining uint32        // count of records left to read
+	childDone bool          // child before the next record is traversed
+}
+
+// Walker traverses B-tree without recursion, records are decoded as nodes are read.
+// Records are returned in-order: records of a child go before the record following
+// the child pointer in its parent, records of the rightmost child go last.
+// So records are returned in the same order as they are sorted in B-tree.
+type Walker[R any] struct {
+	// Fetch returns data of the node, nil data without error skips the node
+	Fetch func(node uint32, depth int) (*bytes.Buffer, error)
+	// Decode decodes the next record of the node data
+	Decode func(b *bytes.Buffer) (R, error)
+	// Done is called with the rest of node data after all records of the node are read, it can be nil
+	Done func(node uint32, b *bytes.Buffer)
+	// MaxDepth limits depth of the tree, 0 is no limit
+	MaxDepth int
+
+	stack   []frame
+	visited map[uint32]bool
+}
+
+// NewWalker creates walker of the tree with the root node
+func NewWalker[R any](root uint32, fetch func(node uint32, depth int) (*bytes.Buffer, error), decode func(b *bytes.Buffer) (R, error)) *Walker[R] {
+	return &Walker[R]{Fetch: fetch, Decode: decode, stack: []frame{{node: root, depth: 1}}, visited: make(map[uint32]bool)}
+}
+
+// More reports whether there are nodes left to traverse
+func (w *Walker[R]) More() bool {
+	return len(w.stack) > 0
+}
+
+// Node returns block index of the node the next Step works on
+func (w *Walker[R]) Node() uint32 {
+	if len(w.stack) == 0 {
+		return 0
+	}
+	return w.stack[len(w.stack)-1].node
+}
+
+// push adds node to the traversal stack
+func (w *Walker[R]) push(node uint32, depth int) {
+	w.stack = append(w.stack, frame{node: node, depth: depth})
+}
+
+// pop removes the top node from the traversal stack
+func (w *Walker[R]) pop() {
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+// load reads the node data and the node header. It returns false if the node is skipped.
+func (w *Walker[R]) load(f *frame) (bool, error) {
+	// protect from cycles and too deep trees
+	if w.visited[f.node] {
+		return false, fmt.Errorf("%w: node %d is referenced twice", ErrMalformed, f.node)
+	}
+	w.visited[f.node] = true
+	if w.MaxDepth > 0 && f.depth > w.MaxDepth {
+		return false, fmt.Errorf("%w: depth is more than %d", ErrMalformed, w.MaxDepth)
+	}
+	block, err := w.Fetch(f.node, f.depth)
+	if err != nil || block == nil {
+		return false, err
+	}
+	if f.rightmost, f.remaining, err = ReadHeader(block); err != nil {
+		return false, err
+	}
+	f.block = block
+	return true, nil
+}
+
+// Step makes one traversal step on the node returned by Node, it returns true when the record is read.
+// The node is left on error, so traversal can continue with other nodes.
+func (w *Walker[R]) Step() (R, bool, error) {
+	var r R
+	f := &w.stack[len(w.stack)-1]
+	if f.block == nil {
+		if loaded, err := w.load(f); err != nil || !loaded {
+			w.pop()
+			return r, false, err
+		}
+	}
+	if f.remaining == 0 {
+		if w.Done != nil {
+			w.Done(f.node, f.block)
+		}
+		rightmost, depth := f.rightmost, f.depth
+		w.pop()
+		if rightmost > 0 {
+			w.push(rightmost, depth+1)
+		}
+		return r, false, nil
+	}
+	if f.rightmost > 0 && !f.childDone {
+		// internal node: traverse child before the record
+		child, err := ReadChild(f.block)
+		if err != nil {
+			w.pop()
+			return r, false, err
+		}
+		f.childDone = true
+		w.push(child, f.depth+1)
+		return r, false, nil
+	}
+	r, err := w.Decode(f.block)
+	if err != nil {
+		w.pop()
+		return r, false, err
+	}
+	f.remaining--
+	f.childDone = false
+	return r, true, nil
+}
+
+// Next returns the next record in-order, false is returned when all records are read
+func (w *Walker[R]) Next() (R, bool, error) {
+	for w.More() {
+		r, ok, err := w.Step()
+		if err != nil || ok {
+			return r, ok, err
+		}
+	}
+	var r R
+	return r, false, nil
+}
+
+// Search finds the record walking the tree from the root using comparisons, so only nodes
+// on the path to the record are read. compare compares the searched key with the record.
+// maxDepth limits depth of the tree, 0 is no limit. Nil data of fetch ends the search.
+func Search[R any](root uint32, fetch func(node uint32) (*bytes.Buffer, error), decode func(b *bytes.Buffer) (R, error),
+	compare func(r R) int, maxDepth int) (R, bool, error) {
+	var zero R
+	node := root
+	for depth := 1; ; depth++ {
+		if maxDepth > 0 && depth > maxDepth {
+			return zero, false, fmt.Errorf("%w: depth is more than %d", ErrMalformed, maxDepth)
+		}
+		block, err := fetch(node)
+		if err != nil || block == nil {
+			return zero, false, err
+		}
+		rightmost, count, err := ReadHeader(block)
+		if err != nil {
+			return zero, false, err
+		}
+		next := rightmost
+		for range count {
+			var child uint32
+			if rightmost > 0 {
+				if child, err = ReadChild(block); err != nil {
+					return zero, false, err
+				}
+			}
+			r, err := decode(block)
+			if err != nil {
+				return zero, false, err
+			}
+			c := compare(r)
+			if c == 0 {
+				return r, true, nil
+			}
+			if c < 0 {
+				next = child
+				break
+			}
+		}
+		if next == 0 {
+			// leaf node doesn't have the key
+			return zero, false, nil
+		}
+		node = next
+	}
+}
@@ -0,0 +1,94 @@
+package btree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// builtTree returns blocks of the tree of the records built by Build and index of the root node
+func builtTree(records []string, pageSize int) (map[uint32][]byte, uint32) {
+	codec := stringCodec{}
+	nodes, _ := Build(records, func(r string) int { return 4 + len(r) }, pageSize, 0, func(n int) uint32 { return uint32(n + 1) })
+	blocks := make(map[uint32][]byte)
+	for i, n := range nodes {
+		blocks[uint32(i+1)] = n.Append(nil, codec.Append)
+	}
+	return blocks, uint32(len(nodes))
+}
+
+func TestWalker(t *testing.T) {
+	var records []string
+	for i := range 200 {
+		records = append(records, fmt.Sprintf("record %03d", i))
+	}
+	blocks, root := builtTree(records, 64)
+	var done []uint32
+	w := NewWalker(root, func(node uint32, depth int) (*bytes.Buffer, error) {
+		return bytes.NewBuffer(blocks[node]), nil
+	}, stringCodec{}.Decode)
+	w.Done = func(node uint32, b *bytes.Buffer) {
+		done = append(done, node)
+	}
+	var walked []string
+	for {
+		r, ok, err := w.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if !ok {
+			break
+		}
+		walked = append(walked, r)
+	}
+	if !slices.Equal(walked, records) {
+		t.Errorf("expected records in-order, got %v", walked)
+	}
+	if len(done) != len(blocks) {
+		t.Errorf("expected %d done nodes, got %d", len(blocks), len(done))
+	}
+
+	// cycle
+	blocks[root] = (&Node[string]{Rightmost: root}).Append(nil, stringCodec{}.Append)
+	w = NewWalker(root, func(node uint32, depth int) (*bytes.Buffer, error) {
+		return bytes.NewBuffer(blocks[node]), nil
+	}, stringCodec{}.Decode)
+	if _, _, err := w.Next(); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var records []string
+	for i := range 200 {
+		records = append(records, fmt.Sprintf("record %03d", 2*i))
+	}
+	blocks, root := builtTree(records, 64)
+	for i := range 400 {
+		key := fmt.Sprintf("record %03d", i)
+		nodes := 0
+		r, ok, err := Search(root, func(node uint32) (*bytes.Buffer, error) {
+			nodes++
+			return bytes.NewBuffer(blocks[node]), nil
+		}, stringCodec{}.Decode, func(r string) int {
+			return strings.Compare(key, r)
+		}, 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if ok != (i%2 == 0) || ok && r != key {
+			t.Errorf("unexpected result of search of %q: %q, %v", key, r, ok)
+		}
+		if nodes > 5 {
+			t.Errorf("expected only nodes on the path to be read, got %d", nodes)
+		}
+	}
+	if _, _, err := Search(root, func(node uint32) (*bytes.Buffer, error) {
+		return bytes.NewBuffer(blocks[node]), nil
+	}, stringCodec{}.Decode, func(r string) int { return 1 }, 1); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed for too deep tree, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package buddy reads and writes Bud1 containers, the files of blocks managed by power of 2
+// buddy allocator which .DS_Store is stored in. The container has a header pointing to the root
+// (bookkeeping) block with addresses of blocks, the directory of named blocks and free lists.
+// Blocks are addressed by offset | width, where the block size is 1 << width and offsets
+// are counted after 4 bytes of the file prefix.
+package buddy
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// MinBlockWidth is power of 2 of the smallest block allocated by Allocator
+const MinBlockWidth = 5
+
+// Offset returns offset of the block by its address
+func Offset(addr uint32) uint32 {
+	return addr &^ 0x1f
+}
+
+// Size returns size of the block by its address
+func Size(addr uint32) uint32 {
+	return uint32(1) << (addr & 0x1f)
+}
+
+// Allocator is power of 2 buddy allocator of blocks like the one used by Finder.
+// Free lists hold sorted offsets of free blocks by power of 2 of block size.
+type Allocator struct {
+	FreeLists [32][]uint32
+}
+
+// NewAllocator creates allocator of the empty container. The first 32 bytes are used by the header.
+func NewAllocator() *Allocator {
+	a := &Allocator{}
+	a.FreeLists[31] = []uint32{0}
+	if _, err := a.Alloc(32); err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// AllocatorFrom creates allocator with copies of free lists, like the ones read from a container
+func AllocatorFrom(freeLists [32][]uint32) *Allocator {
+	a := &Allocator{}
+	for i, list := range freeLists {
+		a.FreeLists[i] = slices.Clone(list)
+		slices.Sort(a.FreeLists[i])
+	}
+	return a
+}
+
+// blockWidth returns power of 2 of the block needed for size bytes
+func blockWidth(size uint32) int {
+	if size <= 1<<MinBlockWidth {
+		return MinBlockWidth
+	}
+	return bits.Len32(size - 1)
+}
+
+// Alloc allocates block for size bytes and returns its address.
+// The smallest free block is split into buddies until it fits size.
+func (a *Allocator) Alloc(size uint32) (uint32, error) {
+	width := blockWidth(size)
+	w := width
+	for w < 32 && len(a.FreeLists[w]) == 0 {
+		w++
+	}
+	if w >= 32 {
+		return 0, fmt.Errorf("no free space for block of size %d", size)
+	}
+	offset := a.FreeLists[w][0]
+	a.FreeLists[w] = a.FreeLists[w][1:]
+	// split block and free the upper buddies
+	for w > width {
+		w--
+		a.insert(w, offset+1<<w)
+	}
+	return offset | uint32(width), nil
+}
+
+// Free frees block by address and coalesces it with free buddies
+func (a *Allocator) Free(addr uint32) {
+	offset, width := Offset(addr), int(addr&0x1f)
+	for width < 31 {
+		buddy := offset ^ 1<<width
+		i, found := slices.BinarySearch(a.FreeLists[width], buddy)
+		if !found {
+			break
+		}
+		a.FreeLists[width] = slices.Delete(a.FreeLists[width], i, i+1)
+		offset &^= 1 << width
+		width++
+	}
+	a.insert(width, offset)
+}
+
+func (a *Allocator) insert(width int, offset uint32) {
+	i, _ := slices.BinarySearch(a.FreeLists[width], offset)
+	a.FreeLists[width] = slices.Insert(a.FreeLists[width], i, offset)
+}
@@ -0,0 +1,69 @@
+package buddy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a := NewAllocator()
+	// header is allocated, all other space is free
+	for i := 5; i < 31; i++ {
+		if !slices.Equal(a.FreeLists[i], []uint32{1 << i}) {
+			t.Errorf("expected free list %d to be [%#x], got %#x", i, 1<<i, a.FreeLists[i])
+		}
+	}
+
+	addr, err := a.Alloc(100)
+	if err != nil {
+		t.Fatalf("Alloc failed: %v", err)
+	}
+	if addr != 0x80|7 {
+		t.Errorf("expected address %#x, got %#x", 0x80|7, addr)
+	}
+	addr2, err := a.Alloc(1)
+	if err != nil {
+		t.Fatalf("Alloc failed: %v", err)
+	}
+	if addr2 != 0x20|5 {
+		t.Errorf("expected address %#x, got %#x", 0x20|5, addr2)
+	}
+	// split block: 0x100 is taken by splitting 0x100-0x200
+	addr3, err := a.Alloc(128)
+	if err != nil {
+		t.Fatalf("Alloc failed: %v", err)
+	}
+	if addr3 != 0x100|7 || !slices.Equal(a.FreeLists[7], []uint32{0x180}) {
+		t.Errorf("expected address %#x with free buddy 0x180, got %#x and %#x", 0x100|7, addr3, a.FreeLists[7])
+	}
+	if Offset(addr3) != 0x100 || Size(addr3) != 128 {
+		t.Errorf("expected block at 0x100 of size 128, got %#x of size %d", Offset(addr3), Size(addr3))
+	}
+
+	// freeing coalesces buddies back to the initial state
+	for _, addr := range []uint32{addr3, addr2, addr} {
+		a.Free(addr)
+	}
+	initial := NewAllocator()
+	for i := range a.FreeLists {
+		if !slices.Equal(a.FreeLists[i], initial.FreeLists[i]) {
+			t.Errorf("free list %d: expected %#x, got %#x", i, initial.FreeLists[i], a.FreeLists[i])
+		}
+	}
+
+	if _, err = a.Alloc(1 << 31); err == nil {
+		t.Error("expected error for block bigger than free space")
+	}
+}
+
+func TestAllocatorFrom(t *testing.T) {
+	var freeLists [32][]uint32
+	freeLists[5] = []uint32{0x60, 0x20}
+	a := AllocatorFrom(freeLists)
+	if !slices.Equal(a.FreeLists[5], []uint32{0x20, 0x60}) {
+		t.Errorf("expected sorted free list, got %#x", a.FreeLists[5])
+	}
+	if freeLists[5][0] != 0x60 {
+		t.Error("expected free lists to be copied")
+	}
+}
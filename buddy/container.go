@@ -0,0 +1,197 @@
+package buddy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Magic numbers of the header
+const (
+	Magic1 uint32 = 0x1
+	Magic2 uint32 = 0x42756431 // "Bud1"
+)
+
+// HeaderSize is size of the header including 4 bytes of the file prefix
+const HeaderSize = 36
+
+// ErrTooManyBlocks is returned by ReadOffsets for tables with more blocks than allowed
+var ErrTooManyBlocks = errors.New("too many blocks")
+
+// Header of the container
+type Header struct {
+	RootOffset uint32 // offset of the root block
+	RootSize   uint32 // size of the root block, it may be less than its block
+	Extra      []byte // unknown data, 16 bytes
+}
+
+// ReadHeader decodes the header at the start of the container data
+func ReadHeader(data []byte) (Header, error) {
+	if len(data) < HeaderSize {
+		return Header{}, errors.New("invalid file header")
+	}
+	if binary.BigEndian.Uint32(data) != Magic1 {
+		return Header{}, errors.New("invalid first magic")
+	}
+	if binary.BigEndian.Uint32(data[4:]) != Magic2 {
+		return Header{}, errors.New("invalid second magic")
+	}
+	offset1, size, offset2 := binary.BigEndian.Uint32(data[8:]), binary.BigEndian.Uint32(data[12:]), binary.BigEndian.Uint32(data[16:])
+	if offset1 != offset2 {
+		return Header{}, errors.New("invalid header offset")
+	}
+	return Header{RootOffset: offset1, RootSize: size, Extra: bytes.Clone(data[20:HeaderSize])}, nil
+}
+
+// Append appends encoded header to b. Extra is padded or truncated to 16 bytes.
+func (h Header) Append(b []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, Magic1)
+	b = binary.BigEndian.AppendUint32(b, Magic2)
+	// the root block offset is written twice
+	b = binary.BigEndian.AppendUint32(b, h.RootOffset)
+	b = binary.BigEndian.AppendUint32(b, h.RootSize)
+	b = binary.BigEndian.AppendUint32(b, h.RootOffset)
+	var extra [16]byte
+	copy(extra[:], h.Extra)
+	return append(b, extra[:]...)
+}
+
+// Root is the bookkeeping block of the container
+type Root struct {
+	Offsets   []uint32          // addresses of blocks by index, zero for unused index
+	Directory map[string]uint32 // block indexes by name, like "DSDB"
+	FreeLists [32][]uint32      // offsets of free blocks by power of 2 of block size
+	Extra     []byte            // unknown data after free lists
+}
+
+// ReadRoot decodes the root block. maxBlocks limits count of blocks, 0 is no limit.
+func ReadRoot(data []byte, maxBlocks int) (*Root, error) {
+	b := bytes.NewBuffer(data)
+	table, count, err := ReadOffsets(b, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+	r := &Root{Offsets: table[:count]}
+	if r.Directory, err = ReadDirectory(b); err != nil {
+		return nil, err
+	}
+	if r.FreeLists, err = ReadFreeLists(b); err != nil {
+		return nil, err
+	}
+	r.Extra = bytes.Clone(b.Bytes())
+	return r, nil
+}
+
+// readUint32 reads big-endian uint32 like binary.Read does, but without allocations
+func readUint32(b *bytes.Buffer) (uint32, error) {
+	if b.Len() < 4 {
+		if b.Len() == 0 {
+			return 0, io.EOF
+		}
+		b.Next(b.Len())
+		return 0, io.ErrUnexpectedEOF
+	}
+	return binary.BigEndian.Uint32(b.Next(4)), nil
+}
+
+// ReadOffsets reads the table of block addresses. The table is padded with zeros to a multiple
+// of 256 entries, the whole table is returned with count of its used entries.
+// maxBlocks limits the count, 0 is no limit.
+func ReadOffsets(b *bytes.Buffer, maxBlocks int) ([]uint32, int, error) {
+	count, err := readUint32(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if maxBlocks > 0 && uint64(count) > uint64(maxBlocks) {
+		return nil, 0, fmt.Errorf("%w: %d blocks, maximum is %d", ErrTooManyBlocks, count, maxBlocks)
+	}
+	// unknown 4 bytes
+	if _, err = readUint32(b); err != nil {
+		return nil, 0, err
+	}
+	size := (int(count) + 255) / 256 * 256
+	if 4*size > b.Len() {
+		// fail without allocation of the table for hostile counts
+		b.Next(b.Len())
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	table := make([]uint32, size)
+	for i := range table {
+		table[i] = binary.BigEndian.Uint32(b.Next(4))
+	}
+	return table, int(count), nil
+}
+
+// ReadDirectory reads block indexes by names
+func ReadDirectory(b *bytes.Buffer) (map[string]uint32, error) {
+	count, err := readUint32(b)
+	if err != nil {
+		return nil, err
+	}
+	directory := make(map[string]uint32)
+	for ; count > 0; count-- {
+		length, err := b.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if int(length) > b.Len() {
+			b.Next(b.Len())
+			return nil, io.ErrUnexpectedEOF
+		}
+		name := string(b.Next(int(length)))
+		index, err := readUint32(b)
+		if err != nil {
+			return nil, err
+		}
+		directory[name] = index
+	}
+	return directory, nil
+}
+
+// ReadFreeLists reads offsets of free blocks by power of 2 of block size
+func ReadFreeLists(b *bytes.Buffer) ([32][]uint32, error) {
+	var freeLists [32][]uint32
+	for i := range freeLists {
+		count, err := readUint32(b)
+		if err != nil {
+			return freeLists, err
+		}
+		for ; count > 0; count-- {
+			offset, err := readUint32(b)
+			if err != nil {
+				return freeLists, err
+			}
+			freeLists[i] = append(freeLists[i], offset)
+		}
+	}
+	return freeLists, nil
+}
+
+// Append appends encoded root block to b. The table of offsets is padded to a multiple of 256 entries,
+// directory entries are sorted by names.
+func (r *Root) Append(b []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(r.Offsets)))
+	// unknown 4 bytes
+	b = binary.BigEndian.AppendUint32(b, 0)
+	for _, addr := range r.Offsets {
+		b = binary.BigEndian.AppendUint32(b, addr)
+	}
+	b = append(b, make([]byte, 4*((256-len(r.Offsets)%256)%256))...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(r.Directory)))
+	for _, name := range slices.Sorted(maps.Keys(r.Directory)) {
+		b = append(b, byte(len(name)))
+		b = append(b, name...)
+		b = binary.BigEndian.AppendUint32(b, r.Directory[name])
+	}
+	for _, list := range r.FreeLists {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(list)))
+		for _, offset := range list {
+			b = binary.BigEndian.AppendUint32(b, offset)
+		}
+	}
+	return append(b, r.Extra...)
+}
@@ -0,0 +1,65 @@
+package buddy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	h := Header{RootOffset: 0x800, RootSize: 0x80c, Extra: []byte{1, 2, 3}}
+	data := h.Append(nil)
+	if len(data) != HeaderSize || string(data[4:8]) != "Bud1" {
+		t.Fatalf("unexpected header %x", data)
+	}
+	read, err := ReadHeader(data)
+	if err != nil {
+		t.Fatalf("ReadHeader failed: %v", err)
+	}
+	if read.RootOffset != h.RootOffset || read.RootSize != h.RootSize || !bytes.Equal(read.Extra, append([]byte{1, 2, 3}, make([]byte, 13)...)) {
+		t.Errorf("unexpected header %+v", read)
+	}
+
+	for name, corrupt := range map[string]func(b []byte) []byte{
+		"invalid file header":   func(b []byte) []byte { return b[:20] },
+		"invalid first magic":   func(b []byte) []byte { b[3] = 2; return b },
+		"invalid second magic":  func(b []byte) []byte { b[4] = 'b'; return b },
+		"invalid header offset": func(b []byte) []byte { b[16] = 1; return b },
+	} {
+		if _, err = ReadHeader(corrupt(slices.Clone(data))); err == nil || err.Error() != name {
+			t.Errorf("expected %q error, got %v", name, err)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	root := &Root{Offsets: []uint32{0x100b, 0x45, 0x200c}, Directory: map[string]uint32{"DSDB": 1, "Test": 2}, Extra: []byte{9}}
+	for i := uint32(0); i < 40; i++ {
+		root.FreeLists[10] = append(root.FreeLists[10], 0x10000+i*1024)
+	}
+	data := root.Append(nil)
+	// offsets are padded to 256 entries, directory entries have length byte
+	if expected := 8 + 256*4 + 4 + 2*(1+4+4) + 32*4 + 40*4 + 1; len(data) != expected {
+		t.Errorf("expected %d bytes, got %d", expected, len(data))
+	}
+	read, err := ReadRoot(data, 0)
+	if err != nil {
+		t.Fatalf("ReadRoot failed: %v", err)
+	}
+	if !slices.Equal(read.Offsets, root.Offsets) || !maps.Equal(read.Directory, root.Directory) ||
+		!slices.Equal(read.FreeLists[10], root.FreeLists[10]) || !bytes.Equal(read.Extra, root.Extra) {
+		t.Errorf("expected %+v, got %+v", root, read)
+	}
+
+	if _, err = ReadRoot(data, 2); !errors.Is(err, ErrTooManyBlocks) {
+		t.Errorf("expected ErrTooManyBlocks, got %v", err)
+	}
+	for _, size := range []int{0, 6, 100, 8 + 256*4 + 6, len(data) - 10} {
+		if _, err = ReadRoot(data[:size], 0); !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected EOF error for %d bytes, got %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package buddy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// File is a container in memory with blocks allocated by Allocator.
+// The root block is one of the blocks, it is reallocated and encoded by Write.
+type File struct {
+	HeaderExtra []byte            // unknown data of the header
+	RootExtra   []byte            // unknown data of the root block
+	Directory   map[string]uint32 // block indexes by name, like "DSDB"
+
+	offsets   []uint32   // addresses of blocks by index
+	root      int        // index of the root block
+	allocator *Allocator // allocator of blocks
+	data      []byte     // container data, blocks are addressed after 4 bytes of the prefix
+}
+
+// New creates empty container, block 0 is its root block
+func New() *File {
+	return &File{Directory: map[string]uint32{}, offsets: []uint32{0}, allocator: NewAllocator(), data: make([]byte, HeaderSize)}
+}
+
+// Read decodes the container, the data is copied
+func Read(data []byte) (*File, error) {
+	h, err := ReadHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(h.RootOffset)+4+uint64(h.RootSize) > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid root block: offset %d with size %d exceeds file size %d", h.RootOffset, h.RootSize, len(data))
+	}
+	root, err := ReadRoot(data[4+h.RootOffset:4+h.RootOffset+h.RootSize], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root block: %w", err)
+	}
+	f := &File{HeaderExtra: h.Extra, RootExtra: root.Extra, Directory: root.Directory, offsets: root.Offsets,
+		allocator: AllocatorFrom(root.FreeLists), data: slices.Clone(data)}
+	f.root = slices.IndexFunc(f.offsets, func(addr uint32) bool {
+		return addr != 0 && Offset(addr) == h.RootOffset
+	})
+	if f.root < 0 {
+		return nil, errors.New("root block is not in the table of offsets")
+	}
+	for i, addr := range f.offsets {
+		if uint64(Offset(addr))+4+uint64(Size(addr)) > uint64(len(data)) {
+			return nil, fmt.Errorf("block %d at offset %d with size %d exceeds file size %d", i, Offset(addr), Size(addr), len(data))
+		}
+	}
+	return f, nil
+}
+
+// Blocks returns count of block indexes including unused ones
+func (f *File) Blocks() int {
+	return len(f.offsets)
+}
+
+// Address returns address of the block, zero for unused index
+func (f *File) Address(index uint32) uint32 {
+	if int(index) >= len(f.offsets) {
+		return 0
+	}
+	return f.offsets[index]
+}
+
+// Block returns data of the block, it is valid until the next Alloc or Write
+func (f *File) Block(index uint32) ([]byte, error) {
+	addr := f.Address(index)
+	if addr == 0 {
+		return nil, fmt.Errorf("block %d is not allocated", index)
+	}
+	start := 4 + Offset(addr)
+	return f.data[start : start+Size(addr) : start+Size(addr)], nil
+}
+
+// Alloc allocates zeroed block for size bytes and returns its index. Unused indexes are reused.
+func (f *File) Alloc(size uint32) (uint32, error) {
+	addr, err := f.allocator.Alloc(size)
+	if err != nil {
+		return 0, err
+	}
+	f.place(addr)
+	index := slices.Index(f.offsets, 0)
+	if index < 0 || index == f.root {
+		index = len(f.offsets)
+		f.offsets = append(f.offsets, addr)
+	} else {
+		f.offsets[index] = addr
+	}
+	return uint32(index), nil
+}
+
+// place grows the container data up to the end of the block and zeroes the block
+func (f *File) place(addr uint32) {
+	end := int(4 + Offset(addr) + Size(addr))
+	if end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+	clear(f.data[end-int(Size(addr)) : end])
+}
+
+// Resize reallocates the block for size bytes keeping its index and data, data which doesn't fit is dropped
+func (f *File) Resize(index uint32, size uint32) error {
+	if int(index) == f.root {
+		return errors.New("root block can't be resized")
+	}
+	addr := f.Address(index)
+	if addr == 0 {
+		return fmt.Errorf("block %d is not allocated", index)
+	}
+	resized, err := f.allocator.Alloc(size)
+	if err != nil {
+		return err
+	}
+	f.place(resized)
+	copy(f.data[4+Offset(resized):4+Offset(resized)+Size(resized)], f.data[4+Offset(addr):4+Offset(addr)+Size(addr)])
+	f.allocator.Free(addr)
+	f.offsets[index] = resized
+	return nil
+}
+
+// Free frees the block, its index becomes unused
+func (f *File) Free(index uint32) error {
+	if int(index) == f.root {
+		return errors.New("root block can't be freed")
+	}
+	addr := f.Address(index)
+	if addr == 0 {
+		return fmt.Errorf("block %d is not allocated", index)
+	}
+	f.allocator.Free(addr)
+	f.offsets[index] = 0
+	// trailing unused indexes are dropped
+	for len(f.offsets) > f.root+1 && f.offsets[len(f.offsets)-1] == 0 {
+		f.offsets = f.offsets[:len(f.offsets)-1]
+	}
+	return nil
+}
+
+// Write encodes the root block and the header and writes the container.
+// The root block contains offsets and free lists, so it is reallocated until it fits its block.
+func (f *File) Write(w io.Writer) error {
+	root := Root{Offsets: f.offsets, Directory: f.Directory, Extra: f.RootExtra}
+	var block []byte
+	for {
+		root.FreeLists = f.allocator.FreeLists
+		block = root.Append(block[:0])
+		addr := f.offsets[f.root]
+		if addr != 0 && uint32(len(block)) <= Size(addr) {
+			break
+		}
+		if addr != 0 {
+			f.allocator.Free(addr)
+		}
+		addr, err := f.allocator.Alloc(uint32(len(block)))
+		if err != nil {
+			return err
+		}
+		f.place(addr)
+		f.offsets[f.root] = addr
+	}
+	addr := f.offsets[f.root]
+	copy(f.data[4+Offset(addr):], block)
+	header := Header{RootOffset: Offset(addr), RootSize: uint32(len(block)), Extra: f.HeaderExtra}
+	copy(f.data, header.Append(nil))
+	// data after the last allocated block is not written
+	end := uint32(32)
+	for _, addr := range f.offsets {
+		if addr != 0 {
+			end = max(end, Offset(addr)+Size(addr))
+		}
+	}
+	_, err := w.Write(f.data[:4+end])
+	return err
+}
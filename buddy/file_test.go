@@ -0,0 +1,95 @@
+package buddy
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	f := New()
+	f.Directory["DSDB"] = 1
+	f.HeaderExtra = []byte{1}
+	index, err := f.Alloc(100)
+	if err != nil {
+		t.Fatalf("Alloc failed: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("expected block 1, got %d", index)
+	}
+	block, _ := f.Block(index)
+	copy(block, "data of block 1")
+	tmp, _ := f.Alloc(5000)
+	if err = f.Free(tmp); err != nil {
+		t.Fatalf("Free failed: %v", err)
+	}
+	if _, err = f.Block(tmp); err == nil {
+		t.Error("expected error for freed block")
+	}
+	if err = f.Free(0); err == nil {
+		t.Error("expected error for freeing root block")
+	}
+
+	var buf bytes.Buffer
+	if err = f.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	read, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if read.Blocks() != 2 || read.Directory["DSDB"] != 1 || read.HeaderExtra[0] != 1 {
+		t.Errorf("unexpected container: %d blocks, directory %v", read.Blocks(), read.Directory)
+	}
+	block, err = read.Block(1)
+	if err != nil || !bytes.HasPrefix(block, []byte("data of block 1")) {
+		t.Errorf("unexpected block %q: %v", block, err)
+	}
+	// freed index is reused
+	if index, _ = read.Alloc(10); index != 2 {
+		t.Errorf("expected block 2, got %d", index)
+	}
+	// resized block keeps its index and data
+	if err = read.Resize(1, 5000); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	if block, _ = read.Block(1); len(block) != 8192 || !bytes.HasPrefix(block, []byte("data of block 1")) {
+		t.Errorf("unexpected resized block of %d bytes", len(block))
+	}
+	if err = read.Free(1); err != nil {
+		t.Fatalf("Free failed: %v", err)
+	}
+	if index, _ = read.Alloc(10); index != 1 {
+		t.Errorf("expected reused block 1, got %d", index)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	data, err := os.ReadFile("../testdata/00.DS_Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Read(data)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if _, ok := f.Directory["DSDB"]; !ok {
+		t.Errorf("expected DSDB block, got %v", f.Directory)
+	}
+	// the container is written with the same blocks
+	var buf bytes.Buffer
+	if err = f.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	read, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	for i := range f.Blocks() {
+		a, _ := f.Block(uint32(i))
+		b, _ := read.Block(uint32(i))
+		if f.Address(uint32(i)) != read.Address(uint32(i)) || !bytes.Equal(a, b) {
+			t.Errorf("block %d differs", i)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Records returns records of the settings, the inverse of Store.FolderSettings:
+// "vstl", "bwsp" and "icvp" records of the folder itself and "Iloc" records of icon positions.
+// Settings which are nil or empty have no records.
+func (settings FolderSettings) Records() ([]Record, error) {
+	var records []Record
+	if settings.View != "" {
+		style := ""
+		for code, view := range viewStyles {
+			if view == settings.View {
+				style = code
+			}
+		}
+		if style == "" {
+			return nil, fmt.Errorf("unknown view style %q", settings.View)
+		}
+		r := Record{FileName: ".", Type: "type", Data: []byte(style)}
+		r.SetCode("vstl")
+		records = append(records, r)
+	}
+	if w := settings.Window; w != nil {
+		r, err := plistRecord("bwsp", map[string]any{
+			"WindowBounds": fmt.Sprintf("{{%d, %d}, {%d, %d}}", w.Bounds.Min.X, w.Bounds.Min.Y, w.Bounds.Dx(), w.Bounds.Dy()),
+			"ShowToolbar":  w.ShowToolbar, "ShowStatusBar": w.ShowStatusBar,
+			"ShowSidebar": w.ShowSidebar, "ContainerShowSidebar": w.ShowSidebar,
+			"ShowPathbar": w.ShowPathbar, "ShowTabView": false,
+		})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	if v := settings.IconView; v != nil {
+		dict := map[string]any{
+			"viewOptionsVersion": 1, "gridOffsetX": 0.0, "gridOffsetY": 0.0,
+			"iconSize": v.IconSize, "textSize": v.TextSize, "gridSpacing": v.GridSpacing,
+			"arrangeBy": v.ArrangeBy, "labelOnBottom": v.LabelOnBottom,
+			"showItemInfo": v.ShowItemInfo, "showIconPreview": v.ShowIconPreview,
+			"backgroundType":     v.BackgroundType,
+			"backgroundColorRed": v.BackgroundColor[0], "backgroundColorGreen": v.BackgroundColor[1],
+			"backgroundColorBlue": v.BackgroundColor[2],
+		}
+		if v.BackgroundImage != nil {
+			dict["backgroundImageAlias"] = v.BackgroundImage
+		}
+		r, err := plistRecord("icvp", dict)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	for _, name := range slices.Sorted(maps.Keys(settings.Positions)) {
+		p := settings.Positions[name]
+		data := binary.BigEndian.AppendUint32(nil, uint32(int32(p.X)))
+		data = binary.BigEndian.AppendUint32(data, uint32(int32(p.Y)))
+		// index of the icon in the window is unknown
+		data = append(data, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0)
+		r := Record{FileName: name, Type: "blob", DataLen: uint32(len(data)), Data: data}
+		r.SetCode("Iloc")
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// plistRecord returns blob record of the folder itself with the property list dictionary
+func plistRecord(code string, dict map[string]any) (Record, error) {
+	data, err := encodePlist(dict)
+	if err != nil {
+		return Record{}, fmt.Errorf("%s record: %w", code, err)
+	}
+	r := Record{FileName: ".", Type: "blob", DataLen: uint32(len(data)), Data: data}
+	r.SetCode(code)
+	return r, nil
+}
+
+// SetFolderSettings replaces records of the settings which aren't nil or empty by records of them, see FolderSettings.Records.
+// Records of other files and other settings are kept, so layout of a folder can be changed partially.
+func (s *Store) SetFolderSettings(settings FolderSettings) error {
+	records, err := settings.Records()
+	if err != nil {
+		return err
+	}
+	replaced := make(map[[2]string]bool, len(records))
+	for _, r := range records {
+		replaced[[2]string{nameKey(r.FileName), r.Code()}] = true
+	}
+	s.Records = slices.DeleteFunc(s.Records, func(r Record) bool {
+		return replaced[[2]string{nameKey(r.FileName), r.Code()}]
+	})
+	s.Records = append(s.Records, records...)
+	s.mods++
+	return nil
+}
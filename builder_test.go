@@ -0,0 +1,43 @@
+package dsstore
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFolderSettingsRecords(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	settings, err := s.FolderSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.View = "list view"
+	built := &Store{}
+	if err = built.SetFolderSettings(settings); err != nil {
+		t.Fatalf("SetFolderSettings failed: %v", err)
+	}
+	got, err := built.FolderSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, settings) {
+		t.Errorf("settings differ after building:\n%+v\n%+v", got, settings)
+	}
+
+	// partial settings replace only their records
+	if err = s.SetFolderSettings(FolderSettings{Positions: map[string]image.Point{"APPLICATIONS": {1, 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = s.FolderSettings(); got.Positions["APPLICATIONS"] != image.Pt(1, 2) || got.IconView == nil ||
+		len(got.Positions) != 2 || len(s.Records) != 6 {
+		t.Errorf("unexpected settings %+v of %d records", got, len(s.Records))
+	}
+	if _, err = (FolderSettings{View: "tree view"}).Records(); err == nil {
+		t.Error("expected error for unknown view")
+	}
+}
@@ -0,0 +1,76 @@
+package dsstore
+
+import (
+	"bytes"
+	"io"
+)
+
+// DefaultCarveSize is the maximal size of a carved store when ReadOptions.MaxFileSize is not set
+const DefaultCarveSize int64 = 4 << 20 // 4 MiB
+
+// carveChunk is size of data scanned for the signature at once
+const carveChunk = 1 << 20
+
+// signature is the start of .DS_Store file: prefix and magic of the header
+var signature = []byte("\x00\x00\x00\x01Bud1")
+
+// CarvedStore is .DS_Store found in raw data
+type CarvedStore struct {
+	Offset int64  // offset of the store in the data
+	Size   int64  // size of the store in the data, truncated store ends at the end of the data
+	Store  *Store // records read in best-effort mode
+	Err    error  // error of reading, the store is partially read
+}
+
+// Carve scans raw data of the given size, like disk images or memory dumps, for .DS_Store signatures
+// and reads a store at every found signature. Stores are returned in order of their offsets,
+// signatures without readable stores are skipped.
+func Carve(r io.ReaderAt, size int64) ([]CarvedStore, error) {
+	return CarveWithOptions(r, size, ReadOptions{})
+}
+
+// CarveWithOptions is Carve reading stores using options.
+// Not more than ReadOptions.MaxFileSize bytes are read for every store, DefaultCarveSize by default.
+func CarveWithOptions(r io.ReaderAt, size int64, opts ReadOptions) ([]CarvedStore, error) {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultCarveSize
+	}
+	opts.BestEffort = true
+	var stores []CarvedStore
+	buf := make([]byte, carveChunk+len(signature)-1)
+	for start := int64(0); start < size; start += carveChunk {
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), size-start)], start)
+		if err != nil && err != io.EOF {
+			return stores, err
+		}
+		// signatures starting in the overlap are found in the next chunk
+		for data, pos := buf[:n], 0; ; pos++ {
+			i := bytes.Index(data[pos:], signature)
+			if i < 0 || pos+i >= carveChunk {
+				break
+			}
+			pos += i
+			if store, ok := carveStore(r, start+int64(pos), size, opts); ok {
+				stores = append(stores, store)
+			}
+		}
+	}
+	return stores, nil
+}
+
+// carveStore reads store at the offset, it returns false when nothing can be read
+func carveStore(r io.ReaderAt, offset, size int64, opts ReadOptions) (CarvedStore, bool) {
+	window := min(size-offset, opts.MaxFileSize)
+	s := &Store{}
+	err := s.ReadWithOptions(io.NewSectionReader(r, offset, window), opts)
+	if err != nil && !s.readable() {
+		return CarvedStore{}, false
+	}
+	// data after the allocated region doesn't belong to the store
+	carved := CarvedStore{Offset: offset, Size: window - int64(len(s.trailing)), Store: s, Err: err}
+	if s.truncErr != nil {
+		carved.Size = s.truncErr.Offset
+	}
+	s.trailing = nil
+	return carved, true
+}
@@ -0,0 +1,56 @@
+package dsstore
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCarve(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	rnd := rand.New(rand.NewPCG(1, 2))
+	data := make([]byte, 3*carveChunk)
+	for i := range data {
+		data[i] = byte(rnd.IntN(256))
+	}
+	offsets := []int64{100, carveChunk - 3, 2 * carveChunk}
+	for _, offset := range offsets {
+		copy(data[offset:], fileData)
+	}
+	// false signature
+	copy(data[50000:], signature)
+	// truncated store at the end, its root block is kept
+	s := &Store{}
+	s.Records = append(s.Records, Record{FileName: "a", Type: "blob", DataLen: 3000, Data: make([]byte, 3000)})
+	buf := new(bytes.Buffer)
+	if err = s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	truncated := int64(len(data)) - 3000
+	data = append(data[:truncated], buf.Bytes()[:6000]...)
+
+	stores, err := Carve(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Carve failed: %v", err)
+	}
+	if len(stores) != 4 {
+		t.Fatalf("expected 4 stores, got %d", len(stores))
+	}
+	for i, offset := range offsets {
+		c := stores[i]
+		if c.Offset != offset || c.Size != int64(len(fileData)) || c.Err != nil || len(c.Store.Records) != 6 {
+			t.Errorf("unexpected store %d at %d of size %d: %v", i, c.Offset, c.Size, c.Err)
+		}
+		if len(c.Store.Trailing()) != 0 {
+			t.Errorf("expected no trailing data of store %d", i)
+		}
+	}
+	if c := stores[3]; c.Offset != truncated || c.Size != 6000 || c.Err == nil {
+		t.Errorf("unexpected truncated store at %d of size %d: %v", c.Offset, c.Size, c.Err)
+	}
+}
@@ -0,0 +1,175 @@
+package dsstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CleanOptions are options of Clean
+type CleanOptions struct {
+	// DryRun only reports files which would be cleaned
+	DryRun bool
+	// Scrub removes records for which it returns true and rewrites the file instead of deleting it.
+	// Files are deleted when Scrub is nil.
+	Scrub func(r Record) bool
+	// Policy removes records it denies like Scrub, store patterns of its rules are matched by paths relative to the root.
+	// Both Scrub and Policy remove records when they are set.
+	Policy *Policy
+	// Include are glob patterns of paths relative to the root, like "Projects/*/.DS_Store".
+	// A pattern matches the path or its trailing part after a slash, like "dist/.DS_Store".
+	// All files are included when Include is empty.
+	Include []string
+	// Exclude are glob patterns of paths which are not cleaned, they are matched like Include
+	Exclude []string
+	// MinAge skips files modified less than MinAge ago, so stores currently used by Finder stay
+	MinAge time.Duration
+}
+
+// CleanSummary is result of Clean
+type CleanSummary struct {
+	Files int      // number of deleted or scrubbed files
+	Bytes int64    // reclaimed bytes
+	Paths []string // paths of deleted or scrubbed files
+}
+
+// Clean deletes or scrubs .DS_Store files in the file tree rooted at root.
+// Only regular files named .DS_Store are cleaned, symbolic links are not followed.
+// Files failed to be cleaned don't stop cleaning, their errors are joined into the returned error.
+func Clean(root string, opts CleanOptions) (CleanSummary, error) {
+	return CleanContext(context.Background(), root, opts)
+}
+
+// CleanContext is Clean stopping when the context is done
+func CleanContext(ctx context.Context, root string, opts CleanOptions) (CleanSummary, error) {
+	return clean(opts, cleanTarget{
+		walk: func(fn WalkFunc) error {
+			return WalkContext(ctx, root, ReadOptions{BestEffort: true}, fn)
+		},
+		stat: os.Lstat,
+		rel: func(name string) (string, error) {
+			rel, err := filepath.Rel(root, name)
+			return filepath.ToSlash(rel), err
+		},
+		remove: os.Remove,
+		write: func(name string, s *Store, perm fs.FileMode) error {
+			return s.WriteFileWithOptions(name, perm, WriteOptions{Atomic: true, PreserveMetadata: true})
+		},
+	})
+}
+
+// cleanTarget provides files of the cleaned tree
+type cleanTarget struct {
+	walk   func(fn WalkFunc) error
+	stat   func(name string) (fs.FileInfo, error) // stat doesn't follow symbolic links
+	rel    func(name string) (string, error)      // rel returns slash-separated path relative to the root
+	remove func(name string) error
+	write  func(name string, s *Store, perm fs.FileMode) error
+}
+
+func clean(opts CleanOptions, target cleanTarget) (CleanSummary, error) {
+	for _, pattern := range slices.Concat(opts.Include, opts.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return CleanSummary{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	var summary CleanSummary
+	var errs []error
+	now := time.Now()
+	err := target.walk(func(filePath string, s *Store, err error) error {
+		info, statErr := target.stat(filePath)
+		if statErr != nil || !info.Mode().IsRegular() {
+			// directory can't be read
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		rel, relErr := target.rel(filePath)
+		if relErr != nil || !opts.included(rel) || opts.MinAge > 0 && now.Sub(info.ModTime()) < opts.MinAge {
+			return nil
+		}
+		var reclaimed int64
+		if opts.Scrub == nil && opts.Policy == nil {
+			reclaimed = info.Size()
+			err = nil
+			if !opts.DryRun {
+				err = target.remove(filePath)
+			}
+		} else {
+			reclaimed, err = scrubFile(filePath, rel, s, err, info, opts, target)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filePath, err))
+			return nil
+		}
+		if reclaimed >= 0 {
+			summary.Files++
+			summary.Bytes += reclaimed
+			summary.Paths = append(summary.Paths, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return summary, errors.Join(errs...)
+}
+
+// scrubFile removes records from the read store and writes it back.
+// It returns reclaimed bytes, negative value means that there are no records to remove.
+func scrubFile(filePath, rel string, s *Store, readErr error, info fs.FileInfo, opts CleanOptions, target cleanTarget) (int64, error) {
+	if readErr != nil {
+		// damaged file is not rewritten, because skipped records would be lost
+		return 0, readErr
+	}
+	records := make([]Record, 0, len(s.Records))
+	for _, r := range s.Records {
+		if (opts.Scrub == nil || !opts.Scrub(r)) && (opts.Policy == nil || opts.Policy.Allowed(rel, r)) {
+			records = append(records, r)
+		}
+	}
+	if len(records) == len(s.Records) {
+		return -1, nil
+	}
+	s.Records = records
+	size := int64(s.EncodedSize())
+	if !opts.DryRun {
+		if err := target.write(filePath, s, info.Mode().Perm()); err != nil {
+			return 0, err
+		}
+	}
+	return max(info.Size()-size, 0), nil
+}
+
+// included reports whether the relative slash-separated path is cleaned
+func (o CleanOptions) included(rel string) bool {
+	if len(o.Include) > 0 && !matchAny(o.Include, rel) {
+		return false
+	}
+	return !matchAny(o.Exclude, rel)
+}
+
+// matchAny reports whether any of patterns matches the path or its trailing part after a slash
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		for suffix := name; ; {
+			if ok, _ := path.Match(pattern, suffix); ok {
+				return true
+			}
+			i := strings.IndexByte(suffix, '/')
+			if i < 0 {
+				break
+			}
+			suffix = suffix[i+1:]
+		}
+	}
+	return false
+}
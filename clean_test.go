@@ -0,0 +1,125 @@
+package dsstore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// existing returns slash-separated relative paths of .DS_Store files in the directory
+func existing(t *testing.T, dir string) []string {
+	var paths []string
+	err := Walk(dir, func(path string, s *Store, err error) error {
+		rel, _ := filepath.Rel(dir, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	return paths
+}
+
+func TestClean(t *testing.T) {
+	dir := t.TempDir()
+	files := walkTestFiles(t)
+	writeTree(t, dir, files)
+	all := []string{".DS_Store", "a/.DS_Store", "a/b/.DS_Store", "c/.DS_Store"}
+
+	summary, err := Clean(dir, CleanOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if summary.Files != 4 || len(summary.Paths) != 4 {
+		t.Errorf("expected 4 files, got %+v", summary)
+	}
+	var size int64
+	for _, name := range all {
+		size += int64(len(files[name]))
+	}
+	if summary.Bytes != size {
+		t.Errorf("expected %d bytes, got %d", size, summary.Bytes)
+	}
+	if got := existing(t, dir); !slices.Equal(got, all) {
+		t.Errorf("dry run removed files, got %v", got)
+	}
+
+	summary, err = Clean(dir, CleanOptions{Include: []string{"a/*"}, Exclude: []string{"b/.DS_Store"}})
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if summary.Files != 1 || summary.Paths[0] != filepath.Join(dir, "a", ".DS_Store") {
+		t.Errorf("expected a/.DS_Store to be cleaned, got %+v", summary)
+	}
+	if got := existing(t, dir); !slices.Equal(got, []string{".DS_Store", "a/b/.DS_Store", "c/.DS_Store"}) {
+		t.Errorf("unexpected files %v", got)
+	}
+
+	// fresh files are kept
+	old := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(filepath.Join(dir, "c", ".DS_Store"), old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if summary, err = Clean(dir, CleanOptions{MinAge: time.Hour}); err != nil || summary.Files != 1 {
+		t.Errorf("expected 1 old file to be cleaned, got %+v: %v", summary, err)
+	}
+	if got := existing(t, dir); !slices.Equal(got, []string{".DS_Store", "a/b/.DS_Store"}) {
+		t.Errorf("unexpected files %v", got)
+	}
+
+	if _, err = Clean(dir, CleanOptions{Include: []string{"["}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if _, err = Clean(filepath.Join(dir, "missing"), CleanOptions{}); err == nil {
+		t.Error("expected error for missing root")
+	}
+}
+
+func TestCleanScrub(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, walkTestFiles(t))
+	scrub := func(r Record) bool {
+		return r.FileName != "."
+	}
+	summary, err := Clean(dir, CleanOptions{Scrub: scrub})
+	// damaged files are not rewritten
+	if err == nil {
+		t.Error("expected errors of damaged files")
+	}
+	if summary.Files != 2 {
+		t.Errorf("expected 2 scrubbed files, got %+v", summary)
+	}
+	var s Store
+	if err = s.ReadFile(filepath.Join(dir, "a", ".DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, r := range s.Records {
+		if r.FileName != "." {
+			t.Errorf("expected record of %q to be removed", r.FileName)
+		}
+	}
+	// nothing to scrub anymore
+	if summary, _ = Clean(dir, CleanOptions{Scrub: scrub}); summary.Files != 0 {
+		t.Errorf("expected no scrubbed files, got %+v", summary)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	for _, test := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{".DS_Store", ".DS_Store", true},
+		{".DS_Store", "a/b/.DS_Store", true},
+		{"b/.DS_Store", "a/b/.DS_Store", true},
+		{"a/*", "a/b/.DS_Store", false},
+		{"a/*/.DS_Store", "a/b/.DS_Store", true},
+		{"c/.DS_Store", "a/b/.DS_Store", false},
+	} {
+		if match := matchAny([]string{test.pattern}, test.name); match != test.match {
+			t.Errorf("%q matching %q: expected %v", test.pattern, test.name, test.match)
+		}
+	}
+}
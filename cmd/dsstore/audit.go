@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/strongo/dsstore"
+)
+
+func runAudit(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	// fidelity keeps the file data, so names of deleted files are found in free blocks
+	s := &dsstore.Store{}
+	if err := s.ReadFileWithOptions(flags.Arg(0), dsstore.ReadOptions{Fidelity: true}); err != nil {
+		return err
+	}
+	report := dsstore.Analyze(s)
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	_, err := fmt.Fprint(stdout, report.Summary())
+	return err
+}
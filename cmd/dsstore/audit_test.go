@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "audit", testStore)
+	if code != 0 {
+		t.Fatalf("audit failed: %s", stderr)
+	}
+	if !strings.HasPrefix(stdout, "2 file names are listed\n") || !strings.Contains(stdout, "user names: gwend\n") {
+		t.Errorf("unexpected summary:\n%s", stdout)
+	}
+	code, stdout, stderr = runCmd(t, "audit", "--json", testStore)
+	if code != 0 {
+		t.Fatalf("audit --json failed: %s", stderr)
+	}
+	var report struct {
+		Files   []string `json:"files"`
+		Volumes []string `json:"volumes"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || len(report.Files) != 2 || len(report.Volumes) != 1 {
+		t.Errorf("unexpected report %s: %v", stdout, err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// carvedFile is an entry of the manifest of carved stores
+type carvedFile struct {
+	Offset  int64  `json:"offset"`
+	Size    int64  `json:"size"`
+	File    string `json:"file,omitempty"` // name of the extracted file in the output directory
+	SHA256  string `json:"sha256"`         // hash of the extracted data
+	Records int    `json:"records"`
+	Error   string `json:"error,omitempty"` // error of reading, the store is partially read
+}
+
+// carvedManifest is the name of the manifest in the output directory
+const carvedManifest = "manifest.json"
+
+func runCarve(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	outDir := flags.String("out-dir", "", "extract found stores and manifest.json with their offsets to the directory")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	stores, err := dsstore.Carve(f, info.Size())
+	if err != nil {
+		return err
+	}
+	if *outDir != "" {
+		if err = os.MkdirAll(*outDir, 0o755); err != nil {
+			return err
+		}
+	}
+	manifest := make([]carvedFile, 0, len(stores))
+	for _, carved := range stores {
+		data := make([]byte, carved.Size)
+		if _, err = f.ReadAt(data, carved.Offset); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		entry := carvedFile{Offset: carved.Offset, Size: carved.Size, SHA256: hex.EncodeToString(sum[:]),
+			Records: len(carved.Store.Records)}
+		if carved.Err != nil {
+			entry.Error = carved.Err.Error()
+		}
+		if *outDir != "" {
+			entry.File = fmt.Sprintf("%012x.DS_Store", carved.Offset)
+			if err = os.WriteFile(filepath.Join(*outDir, entry.File), data, 0o644); err != nil {
+				return err
+			}
+		}
+		manifest = append(manifest, entry)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "OFFSET\tSIZE\tRECORDS\tERROR")
+	for _, entry := range manifest {
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%d\t%s\n", entry.Offset, entry.Size, entry.Records, entry.Error)
+	}
+	if err = w.Flush(); err != nil || *outDir == "" {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(*outDir, carvedManifest), append(data, '\n'), 0o644)
+}
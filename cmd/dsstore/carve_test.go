@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCarve(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	image := filepath.Join(dir, "image.dd")
+	raw := append(append(bytes.Repeat([]byte{0xaa}, 1000), data...), bytes.Repeat([]byte{0}, 500)...)
+	if err = os.WriteFile(image, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "carved")
+	code, stdout, stderr := runCmd(t, "carve", "--out-dir", outDir, image)
+	if code != 0 || !strings.Contains(stdout, "\n1000 ") {
+		t.Fatalf("carve failed with %d: %s%s", code, stdout, stderr)
+	}
+	manifestData, err := os.ReadFile(filepath.Join(outDir, carvedManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest []carvedFile
+	if err = json.Unmarshal(manifestData, &manifest); err != nil || len(manifest) != 1 || manifest[0].Records != 6 {
+		t.Fatalf("unexpected manifest %s", manifestData)
+	}
+	if code, stdout, _ = runCmd(t, "ls", filepath.Join(outDir, manifest[0].File)); code != 0 || !strings.Contains(stdout, "Applications") {
+		t.Errorf("unexpected extracted store %q", stdout)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// parseAge parses duration like "36h" or count of days like "7d"
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseUint(days, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+func runClean(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	recursive := flags.Bool("recursive", false, "clean .DS_Store files of subdirectories too")
+	dryRun := flags.Bool("dry-run", false, "only list files which would be deleted")
+	check := flags.Bool("check", false, "only list files like --dry-run and exit with code 1 if any is found, for CI")
+	olderThan := flags.String("older-than", "", "skip files modified less than the age ago, like 36h or 7d")
+	policy := flags.String("policy", "", "YAML policy file, records it denies are removed instead of deleting files")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	opts := dsstore.CleanOptions{DryRun: *dryRun || *check}
+	if *policy != "" {
+		var err error
+		if opts.Policy, err = dsstore.LoadPolicy(*policy); err != nil {
+			return err
+		}
+	}
+	if *olderThan != "" {
+		var err error
+		if opts.MinAge, err = parseAge(*olderThan); err != nil {
+			return err
+		}
+	}
+	root := flags.Arg(0)
+	if !*recursive {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			root = filepath.Join(root, dsstore.StoreFileName)
+		}
+		if _, err = os.Lstat(root); errors.Is(err, fs.ErrNotExist) {
+			return printCleanSummary(stdout, root, dsstore.CleanSummary{}, opts)
+		}
+	}
+	summary, err := dsstore.Clean(root, opts)
+	if printErr := printCleanSummary(stdout, root, summary, opts); printErr != nil {
+		return printErr
+	}
+	if err != nil {
+		return err
+	}
+	if *check && summary.Files > 0 {
+		return exitError{code: 1, msg: fmt.Sprintf("found %d .DS_Store files", summary.Files)}
+	}
+	return nil
+}
+
+// printCleanSummary prints cleaned files and the total
+func printCleanSummary(w io.Writer, root string, summary dsstore.CleanSummary, opts dsstore.CleanOptions) error {
+	action := "deleted"
+	if opts.DryRun {
+		action = "found"
+	} else if opts.Policy != nil {
+		action = "scrubbed"
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, p := range summary.Paths {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", action, p)
+	}
+	_, _ = fmt.Fprintf(tw, "total\t%d files, %d bytes in %s\n", summary.Files, summary.Bytes, root)
+	return tw.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	for s, expected := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour, "0d": 0} {
+		if d, err := parseAge(s); err != nil || d != expected {
+			t.Errorf("%s: expected %v, got %v: %v", s, expected, d, err)
+		}
+	}
+	for _, s := range []string{"d", "-1h", "1w", "-1d"} {
+		if _, err := parseAge(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".DS_Store", "a/.DS_Store", "a/b/.DS_Store"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, stderr := runCmd(t, "clean", "--check", "--recursive", dir)
+	if code != 1 || strings.Count(stdout, "found") != 3 || !strings.Contains(stderr, "found 3 .DS_Store files") {
+		t.Errorf("unexpected check %d:\n%s%s", code, stdout, stderr)
+	}
+	if code, stdout, _ = runCmd(t, "clean", "--recursive", "--older-than", "1d", dir); code != 0 || !strings.Contains(stdout, "total  0 files") {
+		t.Errorf("expected no old files, got %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ = runCmd(t, "clean", filepath.Join(dir, "a")); code != 0 || strings.Count(stdout, "deleted") != 1 {
+		t.Errorf("unexpected clean %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ = runCmd(t, "clean", filepath.Join(dir, "a")); code != 0 || !strings.Contains(stdout, "total  0 files") {
+		t.Errorf("expected nothing to clean, got %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ = runCmd(t, "clean", "--recursive", dir); code != 0 || strings.Count(stdout, "deleted") != 2 {
+		t.Errorf("unexpected clean %d:\n%s", code, stdout)
+	}
+	if code, _, _ = runCmd(t, "clean", "--check", "--recursive", dir); code != 0 {
+		t.Errorf("expected clean tree, got %d", code)
+	}
+	if code, _, _ = runCmd(t, "clean", "--older-than", "x", dir); code != 1 {
+		t.Errorf("expected invalid age, got %d", code)
+	}
+}
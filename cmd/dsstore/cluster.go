@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/strongo/dsstore"
+)
+
+func runCluster(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print clusters as JSON")
+	threshold := flags.Float64("threshold", 0.9, "minimal similarity of records of near-identical stores from 0 to 1")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	stores := make(map[string]*dsstore.Store)
+	results := dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, func(path string, s *dsstore.Store) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stores[path] = s
+		return nil
+	})
+	clusters := dsstore.Cluster(stores, *threshold)
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(clusters); err != nil {
+			return err
+		}
+	} else {
+		for i, cluster := range clusters {
+			_, _ = fmt.Fprintf(stdout, "cluster %d: %d stores\n", i+1, len(cluster))
+			for _, path := range cluster {
+				_, _ = fmt.Fprintf(stdout, "  %s  %s\n", stores[path].Fingerprint()[:12], path)
+			}
+		}
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	return nil
+}
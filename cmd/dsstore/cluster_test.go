@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestCluster(t *testing.T) {
+	root := t.TempDir()
+	stores := map[string][]dsstore.Record{
+		"a": {dsstore.TextRecord("x", "cmmt", "1"), dsstore.TextRecord("y", "cmmt", "2")},
+		"b": {dsstore.TextRecord("y", "cmmt", "2"), dsstore.TextRecord("x", "cmmt", "1")},
+		"c": {dsstore.TextRecord("z", "cmmt", "3")},
+	}
+	for dir, records := range stores {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		s := &dsstore.Store{Records: records}
+		if err := s.WriteFile(filepath.Join(root, dir, ".DS_Store"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, stderr := runCmd(t, "cluster", root)
+	if code != 0 {
+		t.Fatalf("cluster failed: %s", stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "cluster 1: 2 stores" || !strings.HasSuffix(lines[2], filepath.Join("b", ".DS_Store")) ||
+		lines[3] != "cluster 2: 1 stores" {
+		t.Errorf("unexpected clusters:\n%s", stdout)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/strongo/dsstore"
+	"gopkg.in/yaml.v3"
+)
+
+// storeSignature starts binary .DS_Store files
+var storeSignature = []byte("\x00\x00\x00\x01Bud1")
+
+// convertFormats are formats of convert by file extensions, other extensions are binary .DS_Store
+var convertFormats = map[string]string{".json": "json", ".yaml": "yaml", ".yml": "yaml", ".plist": "plist", ".csv": "csv"}
+
+// formatOf returns format of the file by its extension
+func formatOf(name string) string {
+	if format, ok := convertFormats[strings.ToLower(filepath.Ext(name))]; ok {
+		return format
+	}
+	return "binary"
+}
+
+// decodeStore decodes the store of the format, binary stores are detected by the signature
+func decodeStore(data []byte, format string) (*dsstore.Store, error) {
+	s := &dsstore.Store{}
+	if bytes.HasPrefix(data, storeSignature) {
+		format = "binary"
+	}
+	switch format {
+	case "binary":
+		return s, s.Read(bytes.NewReader(data))
+	case "yaml":
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+		return decodeJSONStore(data)
+	case "json":
+		return decodeJSONStore(data)
+	case "plist":
+		return s, s.UnmarshalPlist(data)
+	case "csv":
+		rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			if i == 0 && strings.Join(row, ",") == "name,code,type,value,data" {
+				continue
+			}
+			if len(row) != 5 {
+				return nil, fmt.Errorf("line %d: want 5 columns, got %d", i+1, len(row))
+			}
+			r, err := csvRecord(row)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			s.Records = append(s.Records, r)
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// decodeJSONStore decodes JSON of the store or of the evidence wrapping it
+func decodeJSONStore(data []byte) (*dsstore.Store, error) {
+	var e dsstore.Evidence
+	if err := json.Unmarshal(data, &e); err == nil && e.SHA256 != "" {
+		if e.Store == nil {
+			return nil, errors.New("evidence has no store")
+		}
+		return e.Store, nil
+	}
+	s := &dsstore.Store{}
+	return s, json.Unmarshal(data, s)
+}
+
+// csvRecord returns record of the row of name, code, type, value and data in hex, data has precedence over value
+func csvRecord(row []string) (dsstore.Record, error) {
+	name, code, typ, value, data := row[0], row[1], row[2], row[3], row[4]
+	if data == "" {
+		return parseRecord(name, code, typ, value)
+	}
+	// the record is decoded like JSON records, so both have the same checks
+	fields, err := json.Marshal(map[string]string{"name": name, "code": code, "type": typ, "data": data})
+	if err != nil {
+		return dsstore.Record{}, err
+	}
+	var r dsstore.Record
+	err = json.Unmarshal(fields, &r)
+	return r, err
+}
+
+// encodeStore encodes the store in the format
+func encodeStore(s *dsstore.Store, format string) ([]byte, error) {
+	switch format {
+	case "binary":
+		var buf bytes.Buffer
+		err := s.Write(&buf)
+		return buf.Bytes(), err
+	case "json", "yaml":
+		return encodeJSON(s, format)
+	case "plist":
+		return s.MarshalPlist()
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"name", "code", "type", "value", "data"})
+		for _, r := range s.Records {
+			// value is informational and blobs have only data unless they have registered codec
+			value := ""
+			_, registered := dsstore.LookupCodec(r.Code())
+			if v, err := r.Value(); err == nil && (r.Type != "blob" || registered) {
+				if text, ok := v.(string); ok {
+					value = text
+				} else {
+					value = formatValue(r)
+				}
+			}
+			_ = w.Write([]string{r.FileName, r.Code(), r.Type, value, hex.EncodeToString(r.Data)})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// encodeJSON encodes the value as indented JSON or as YAML of the same structure
+func encodeJSON(v any, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil || format == "json" {
+		return append(data, '\n'), err
+	}
+	var tree any
+	if err = json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(tree)
+}
+
+func runConvert(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	from := flags.String("from", "", "input format: binary, json, yaml, plist or csv, by extension by default")
+	to := flags.String("to", "", "output format: binary, json, yaml, plist or csv, by extension by default")
+	evidence := flags.Bool("evidence", false, "wrap JSON or YAML of binary store with source, SHA-256, acquisition time and tool version")
+	if err := parse(flags, args, 2, 2); err != nil {
+		return err
+	}
+	in, out := flags.Arg(0), flags.Arg(1)
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	if *evidence {
+		return convertEvidence(in, out, data, cmp.Or(*to, formatOf(out)))
+	}
+	s, err := decodeStore(data, cmp.Or(*from, formatOf(in)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	if data, err = encodeStore(s, cmp.Or(*to, formatOf(out))); err != nil {
+		return fmt.Errorf("%s: %w", out, err)
+	}
+	return os.WriteFile(out, data, 0o644)
+}
+
+// convertEvidence writes the binary store read from the input as evidence with chain-of-custody metadata
+func convertEvidence(in, out string, data []byte, format string) error {
+	if format != "json" && format != "yaml" {
+		return fmt.Errorf("evidence is written only as JSON or YAML, not %s", format)
+	}
+	if !bytes.HasPrefix(data, storeSignature) {
+		return fmt.Errorf("%s: evidence is acquired only from binary stores", in)
+	}
+	e, err := dsstore.NewEvidence(in, data, dsstore.ReadOptions{})
+	if err != nil {
+		return err
+	}
+	if data, err = encodeJSON(e, format); err != nil {
+		return fmt.Errorf("%s: %w", out, err)
+	}
+	return os.WriteFile(out, data, 0o644)
+}
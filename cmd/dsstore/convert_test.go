@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	_, want, _ := runCmd(t, "dump", "--hex", testStore)
+	in := testStore
+	for _, name := range []string{"records.json", "records.yaml", "records.plist", "records.csv", "converted.DS_Store"} {
+		out := filepath.Join(dir, name)
+		if code, _, stderr := runCmd(t, "convert", in, out); code != 0 {
+			t.Fatalf("convert %s failed: %s", name, stderr)
+		}
+		in = out
+	}
+	if _, got, _ := runCmd(t, "dump", "--hex", in); got != want {
+		t.Errorf("records changed by conversion:\n%s\nwant:\n%s", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "records.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "name,code,type,value,data\n") || !strings.Contains(string(data), "Applications,Iloc,blob,,") {
+		t.Errorf("unexpected CSV:\n%s", data)
+	}
+}
+
+func TestConvertValues(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.csv"), filepath.Join(dir, "out.json")
+	csv := "a,cmmt,ustr,\"hello, world\",\nb,ICVO,long,-7,\n"
+	if err := os.WriteFile(in, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runCmd(t, "convert", in, "--to", "binary", out); code != 0 {
+		t.Fatalf("convert failed: %s", stderr)
+	}
+	if code, stdout, _ := runCmd(t, "get", out, "a", "cmmt"); code != 0 || stdout != "hello, world\n" {
+		t.Errorf("unexpected comment %q", stdout)
+	}
+	if code, _, _ := runCmd(t, "convert", in, "--from", "xml", out); code != 1 {
+		t.Errorf("want exit code 1 for unknown format, got %d", code)
+	}
+}
+
+func TestConvertEvidence(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "evidence.json")
+	if code, _, stderr := runCmd(t, "convert", "--evidence", testStore, out); code != 0 {
+		t.Fatalf("convert failed: %s", stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"source": "` + testStore + `"`, `"sha256": "`, `"tool": "github.com/strongo/dsstore`, `"records": [`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("no %s in evidence:\n%s", expected, data)
+		}
+	}
+	_, want, _ := runCmd(t, "dump", "--hex", testStore)
+	if code, _, stderr := runCmd(t, "convert", out, filepath.Join(dir, "converted.DS_Store")); code != 0 {
+		t.Fatalf("convert of evidence failed: %s", stderr)
+	}
+	if _, got, _ := runCmd(t, "dump", "--hex", filepath.Join(dir, "converted.DS_Store")); got != want {
+		t.Errorf("records of evidence changed:\n%s\nwant:\n%s", got, want)
+	}
+	if code, _, _ := runCmd(t, "convert", "--evidence", testStore, filepath.Join(dir, "evidence.csv")); code == 0 {
+		t.Error("evidence is written as CSV")
+	}
+}
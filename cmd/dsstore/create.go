@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/strongo/dsstore"
+	"gopkg.in/yaml.v3"
+)
+
+// readSpec reads JSON or YAML specification, YAML is detected by .yaml or .yml extension
+func readSpec(name string) (dsstore.Spec, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return dsstore.Spec{}, err
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		var v any
+		if err = yaml.Unmarshal(data, &v); err != nil {
+			return dsstore.Spec{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return dsstore.Spec{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	spec, err := dsstore.ParseSpec(data)
+	if err != nil {
+		return spec, fmt.Errorf("%s: %w", name, err)
+	}
+	return spec, nil
+}
+
+func runCreate(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	specFile := flags.String("spec", "", "JSON or YAML specification of the layout, or appdmg JSON")
+	out := flags.String("out", dsstore.StoreFileName, "output file")
+	vars := make(map[string]string)
+	flags.Func("var", "template variable of the specification like Version=2.0, can be repeated", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid variable %q, expected name=value", s)
+		}
+		vars[name] = value
+		return nil
+	})
+	if err := parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+	if *specFile == "" {
+		return errUsage
+	}
+	spec, err := readSpec(*specFile)
+	if err != nil {
+		return err
+	}
+	if spec, err = spec.Expand(vars); err != nil {
+		return fmt.Errorf("%s: %w", *specFile, err)
+	}
+	s, err := spec.Build()
+	if err != nil {
+		return err
+	}
+	return saveStore(*out, s, 0o644)
+}
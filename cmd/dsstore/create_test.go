@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "layout.yaml")
+	yaml := `volume: App
+window: {x: 100, y: 100, width: 540, height: 380}
+iconSize: 128
+background: "#ffffff"
+icons:
+  - {name: App.app, x: 140, y: 180}
+  - {name: Applications, x: 400, y: 180}
+`
+	if err := os.WriteFile(spec, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, ".DS_Store")
+	if code, _, stderr := runCmd(t, "create", "--spec", spec, "--out", out); code != 0 {
+		t.Fatalf("create failed: %s", stderr)
+	}
+	if code, stdout, _ := runCmd(t, "get", out, "Applications", "Iloc"); code != 0 || !strings.HasPrefix(stdout, "00000190000000b4") {
+		t.Errorf("unexpected icon location %q", stdout)
+	}
+	if code, stdout, _ := runCmd(t, "get", out, ".", "icvp"); code != 0 || !strings.Contains(stdout, "iconSize: 128") {
+		t.Errorf("unexpected icon view %q", stdout)
+	}
+
+	// variables of templates are resolved
+	if err := os.WriteFile(spec, []byte(`icons: [{name: "App {{.Version}}.app", x: 140, y: 180}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runCmd(t, "create", "--spec", spec, "--var", "Version=2.0", "--out", out); code != 0 {
+		t.Fatalf("create failed: %s", stderr)
+	}
+	if code, _, _ := runCmd(t, "get", out, "App 2.0.app", "Iloc"); code != 0 {
+		t.Error("expected icon location of expanded name")
+	}
+	if code, _, stderr := runCmd(t, "create", "--spec", spec, "--out", out); code != 1 || !strings.Contains(stderr, "Version") {
+		t.Errorf("expected error for undefined variable, got %d: %s", code, stderr)
+	}
+
+	if err := os.WriteFile(spec, []byte("icons: [{name: a/b}]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runCmd(t, "create", "--spec", spec, "--out", out); code != 1 || !strings.Contains(stderr, "a/b") {
+		t.Errorf("expected error for invalid icon name, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCmd(t, "create", "--out", out); code != 2 {
+		t.Errorf("expected usage error without spec, got %d", code)
+	}
+}
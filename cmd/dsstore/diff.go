@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// diffChange is JSON of a change
+type diffChange struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+	Type string `json:"type"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+func runDiff(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print changes as JSON")
+	if err := parse(flags, args, 2, 2); err != nil {
+		return err
+	}
+	old, err := readStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	s, err := readStore(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes := dsstore.Diff(old, s)
+	if *asJSON {
+		result := make([]diffChange, len(changes))
+		for i, c := range changes {
+			r := c.New
+			if c.Kind == dsstore.ChangeRemoved {
+				r = c.Old
+			}
+			result[i] = diffChange{Kind: c.Kind.String(), Name: r.FileName, Code: r.Code(), Type: r.Type}
+			if c.Kind != dsstore.ChangeAdded {
+				result[i].Old = jsonValue(c.Old)
+			}
+			if c.Kind != dsstore.ChangeRemoved {
+				result[i].New = jsonValue(c.New)
+			}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(result); err != nil {
+			return err
+		}
+	} else if err = printDiff(stdout, changes); err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		// like diff(1), exit code tells that stores differ
+		return exitError{code: 1}
+	}
+	return nil
+}
+
+// printDiff prints changes as lines prefixed by +, - and ~. Changes of property list dictionaries
+// are printed by keys.
+func printDiff(w io.Writer, changes []dsstore.Change) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range changes {
+		switch c.Kind {
+		case dsstore.ChangeAdded:
+			_, _ = fmt.Fprintf(tw, "+\t%s\t%s\t%s\t%s\n", c.New.FileName, c.New.Code(), c.New.Type, formatValue(c.New))
+		case dsstore.ChangeRemoved:
+			_, _ = fmt.Fprintf(tw, "-\t%s\t%s\t%s\t%s\n", c.Old.FileName, c.Old.Code(), c.Old.Type, formatValue(c.Old))
+		default:
+			prefix := fmt.Sprintf("~\t%s\t%s\t%s\t", c.New.FileName, c.New.Code(), c.New.Type)
+			oldValue, oldErr := c.Old.Value()
+			newValue, newErr := c.New.Value()
+			oldDict, oldOk := oldValue.(map[string]any)
+			newDict, newOk := newValue.(map[string]any)
+			if oldErr != nil || newErr != nil || !oldOk || !newOk {
+				_, _ = fmt.Fprintf(tw, "%s%s -> %s\n", prefix, formatValue(c.Old), formatValue(c.New))
+				continue
+			}
+			keys := slices.Collect(maps.Keys(oldDict))
+			for k := range newDict {
+				if _, ok := oldDict[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				o, inOld := oldDict[k]
+				n, inNew := newDict[k]
+				switch {
+				case !inOld:
+					_, _ = fmt.Fprintf(tw, "%s%s: + %s\n", prefix, k, formatAny(n))
+				case !inNew:
+					_, _ = fmt.Fprintf(tw, "%s%s: - %s\n", prefix, k, formatAny(o))
+				case !reflect.DeepEqual(o, n):
+					_, _ = fmt.Fprintf(tw, "%s%s: %s -> %s\n", prefix, k, formatAny(o), formatAny(n))
+				}
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.DS_Store")
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(changed, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, stdout, _ := runCmd(t, "diff", testStore, changed); code != 0 || stdout != "" {
+		t.Errorf("expected no changes, got %d:\n%s", code, stdout)
+	}
+	_, icvp, _ := runCmd(t, "get", "--hex", testStore, ".", "icvp")
+	// iconSize 48.0 -> 64.0
+	icvp = strings.Replace(strings.TrimSpace(icvp), "234048000000000000", "234050000000000000", 1)
+	for _, args := range [][]string{
+		{"rm", changed, "Applications"},
+		{"set", changed, "New.app", "Iloc", "blob", "0000008c00000078ffffffffffff0000"},
+		{"set", changed, ".", "vSrn", "long", "2"},
+		{"set", changed, ".", "icvp", "blob", icvp},
+	} {
+		if code, _, stderr := runCmd(t, args...); code != 0 {
+			t.Fatalf("%v failed: %s", args, stderr)
+		}
+	}
+	code, stdout, _ := runCmd(t, "diff", testStore, changed)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 1 || len(lines) != 4 || !strings.HasSuffix(lines[0], "iconSize: 48 -> 64") || !strings.HasSuffix(lines[1], "1 -> 2") ||
+		!strings.HasPrefix(lines[2], "-  Applications") || !strings.HasPrefix(lines[3], "+  New.app") {
+		t.Errorf("unexpected diff %d:\n%s", code, stdout)
+	}
+	code, stdout, _ = runCmd(t, "diff", "--json", testStore, changed)
+	var changes []diffChange
+	if err = json.Unmarshal([]byte(stdout), &changes); err != nil || code != 1 || len(changes) != 4 ||
+		changes[1].Kind != "modified" || changes[1].Old != float64(1) || changes[1].New != float64(2) {
+		t.Errorf("unexpected JSON diff %d: %v\n%s", code, err, stdout)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// dumpRecord is JSON of a record
+type dumpRecord struct {
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+	Data  string `json:"data,omitempty"` // raw data in hex with --hex
+}
+
+func runDump(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print records as JSON")
+	asHex := flags.Bool("hex", false, "print raw data of records in hex")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s, err := readStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		records := make([]dumpRecord, len(s.Records))
+		for i, r := range s.Records {
+			records[i] = dumpRecord{Name: r.FileName, Code: r.Code(), Type: r.Type, Value: jsonValue(r)}
+			if *asHex {
+				records[i].Data = hex.EncodeToString(r.Data)
+			}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tCODE\tTYPE\tVALUE")
+	for _, r := range s.Records {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.FileName, r.Code(), r.Type, formatValue(r))
+		if *asHex {
+			if err = w.Flush(); err != nil {
+				return err
+			}
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(hex.Dump(r.Data), "\n"), "\n") {
+				_, _ = fmt.Fprintf(stdout, "    %s", line)
+			}
+			_, _ = fmt.Fprintln(stdout)
+		}
+	}
+	return w.Flush()
+}
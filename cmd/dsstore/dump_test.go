@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	code, stdout, _ := runCmd(t, "dump", testStore)
+	if code != 0 || !strings.Contains(stdout, `WindowBounds: "{{200, 458}, {360, 222}}"`) ||
+		!strings.Contains(stdout, "Applications      Iloc  blob  0000010c00000040ffffffffffff0000") {
+		t.Errorf("unexpected dump %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ = runCmd(t, "dump", "--hex", testStore); code != 0 || !strings.Contains(stdout, "|bplist00........|") {
+		t.Errorf("unexpected hex dump %d:\n%s", code, stdout)
+	}
+	code, stdout, _ = runCmd(t, "dump", "--json", "--hex", testStore)
+	var records []dumpRecord
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil || code != 0 || len(records) != 6 {
+		t.Fatalf("unexpected JSON dump %d: %v\n%s", code, err, stdout)
+	}
+	if r := records[3]; r.Code != "vSrn" || r.Value != float64(1) || r.Data != "00000001" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
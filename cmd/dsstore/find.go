@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// foundStore is a .DS_Store file found by find
+type foundStore struct {
+	Path      string   `json:"path"`
+	Size      int64    `json:"size"`
+	Records   int      `json:"records"`
+	Anomalies []string `json:"anomalies,omitempty"` // "error", "invalid", "trailing data" or "unknown codes"
+	Error     string   `json:"error,omitempty"`
+}
+
+// findStores returns paths of .DS_Store files of the tree and errors of directories which can't be read
+func findStores(root string) ([]string, []foundStore) {
+	var paths []string
+	var failed []foundStore
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			failed = append(failed, foundStore{Path: path, Anomalies: []string{"error"}, Error: err.Error()})
+		case d.Name() == dsstore.StoreFileName && d.Type().IsRegular():
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, failed
+}
+
+// storePaths returns paths of store files of the arguments, .DS_Store files of directories are found recursively
+func storePaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		found, _ := findStores(arg)
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// inspectStore returns description of the read store
+func inspectStore(path string, s *dsstore.Store) foundStore {
+	found := foundStore{Path: path, Records: len(s.Records)}
+	if info, err := os.Stat(path); err == nil {
+		found.Size = info.Size()
+	}
+	if s.Validate() != nil {
+		found.Anomalies = append(found.Anomalies, "invalid")
+	}
+	if len(s.Trailing()) > 0 {
+		found.Anomalies = append(found.Anomalies, "trailing data")
+	}
+	for _, r := range s.Records {
+		if !dsstore.IsKnownCode(r.Code()) {
+			found.Anomalies = append(found.Anomalies, "unknown codes")
+			break
+		}
+	}
+	return found
+}
+
+func runFind(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	stats := flags.Bool("stats", false, "print summary of all stores")
+	asJSON := flags.Bool("json", false, "print stores as JSON")
+	workers := flags.Int("workers", 0, "count of files read in parallel, GOMAXPROCS by default")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	paths, stores := findStores(flags.Arg(0))
+	report := dsstore.NewReport()
+	var mu sync.Mutex
+	results := dsstore.ProcessAllContext(context.Background(), paths, *workers, dsstore.ReadOptions{},
+		func(path string, s *dsstore.Store) error {
+			found := inspectStore(path, s)
+			mu.Lock()
+			stores = append(stores, found)
+			mu.Unlock()
+			return report.Add(path, s, nil)
+		})
+	report.AddResults(results)
+	for _, result := range results {
+		if result.Err != nil {
+			stores = append(stores, foundStore{Path: result.Path, Anomalies: []string{"error"}, Error: result.Err.Error()})
+		}
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i].Path < stores[j].Path })
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if *stats {
+			return encoder.Encode(struct {
+				Stores []foundStore    `json:"stores"`
+				Stats  *dsstore.Report `json:"stats"`
+			}{stores, report})
+		}
+		return encoder.Encode(stores)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PATH\tRECORDS\tSIZE\tANOMALIES")
+	for _, found := range stores {
+		anomalies := strings.Join(found.Anomalies, ", ")
+		if found.Error != "" {
+			anomalies += ": " + found.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", found.Path, found.Records, found.Size, anomalies)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if *stats {
+		_, err := fmt.Fprintf(stdout, "\n%d stores, %d failed, %d records, %d files\n",
+			report.Stores, report.Failed, report.Records, report.Files)
+		return err
+	}
+	return nil
+}
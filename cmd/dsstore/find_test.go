@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	for name, content := range map[string][]byte{"a": data, "b/c": append(data, "garbage"...), "d": []byte("broken")} {
+		dir := filepath.Join(root, name)
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, ".DS_Store"), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, stderr := runCmd(t, "find", "--stats", root)
+	if code != 0 {
+		t.Fatalf("find failed: %s", stderr)
+	}
+	lines := strings.Split(stdout, "\n")
+	if len(lines) != 7 || !strings.Contains(lines[2], "trailing data") || !strings.Contains(lines[3], "error") ||
+		lines[5] != "2 stores, 1 failed, 12 records, 4 files" {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+
+	code, stdout, _ = runCmd(t, "find", "--json", root)
+	var stores []foundStore
+	if err = json.Unmarshal([]byte(stdout), &stores); code != 0 || err != nil || len(stores) != 3 ||
+		stores[0].Records != 6 || stores[0].Size != int64(len(data)) {
+		t.Errorf("unexpected JSON %s", stdout)
+	}
+}
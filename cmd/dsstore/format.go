@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// readStore reads the store file
+func readStore(name string) (*dsstore.Store, error) {
+	s := &dsstore.Store{}
+	if err := s.ReadFile(name); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// formatValue returns decoded value of the record as text, raw data in hex when it can't be decoded
+func formatValue(r dsstore.Record) string {
+	v, err := r.Value()
+	if err != nil {
+		return hex.EncodeToString(r.Data)
+	}
+	return formatAny(v)
+}
+
+// formatAny formats decoded value, property list dictionaries are formatted with sorted keys
+func formatAny(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return hex.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatAny(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		items := make([]string, 0, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			items = append(items, k+": "+formatAny(v[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonValue returns decoded value of the record for JSON output, binary data is in hex
+func jsonValue(r dsstore.Record) any {
+	v, err := r.Value()
+	if err != nil {
+		return hex.EncodeToString(r.Data)
+	}
+	return jsonAny(v)
+}
+
+func jsonAny(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = jsonAny(item)
+		}
+		return items
+	case map[string]any:
+		items := make(map[string]any, len(v))
+		for k, item := range v {
+			items[k] = jsonAny(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
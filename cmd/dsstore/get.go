@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runGet(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print the value as JSON")
+	asHex := flags.Bool("hex", false, "print raw data of the record in hex")
+	if err := parse(flags, args, 3, 3); err != nil {
+		return err
+	}
+	s, err := readStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	name, code := flags.Arg(1), flags.Arg(2)
+	for _, r := range s.Records {
+		if r.FileName != name || r.Code() != code {
+			continue
+		}
+		switch {
+		case *asHex:
+			_, err = fmt.Fprintln(stdout, hex.EncodeToString(r.Data))
+		case *asJSON:
+			err = json.NewEncoder(stdout).Encode(jsonValue(r))
+		default:
+			value := formatValue(r)
+			if v, _ := r.Value(); v != nil {
+				if text, ok := v.(string); ok {
+					// text is printed as is for shell pipelines
+					value = text
+				}
+			}
+			_, err = fmt.Fprintln(stdout, value)
+		}
+		return err
+	}
+	// absence is signaled by the exit code only
+	return exitError{code: 1}
+}
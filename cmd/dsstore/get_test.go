@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	for _, test := range []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{".", "vSrn"}, 0, "1\n"},
+		{[]string{"--hex", "Applications", "Iloc"}, 0, "0000010c00000040ffffffffffff0000\n"},
+		{[]string{".", "cmmt"}, 1, ""},
+		{[]string{"missing", "Iloc"}, 1, ""},
+	} {
+		args := append([]string{"get"}, test.args[:len(test.args)-2]...)
+		args = append(args, testStore, test.args[len(test.args)-2], test.args[len(test.args)-1])
+		code, stdout, stderr := runCmd(t, args...)
+		if code != test.code || test.expected != "" && stdout != test.expected || code != 0 && stderr != "" {
+			t.Errorf("%v: unexpected output %d: %q %q", test.args, code, stdout, stderr)
+		}
+	}
+	if code, stdout, _ := runCmd(t, "get", "--json", testStore, ".", "bwsp"); code != 0 || stdout[0] != '{' {
+		t.Errorf("unexpected JSON %d: %s", code, stdout)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/strongo/dsstore"
+)
+
+func runGolden(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	dir := flags.String("dir", "testdata/golden", "directory of golden corpus")
+	name := flags.String("name", "", "name of the fixture, name of the folder of the file by default")
+	scrub := flags.Bool("scrub", false, "remove comments, dates, bookmarks and aliases like scrub does by default")
+	anonymize := flags.Bool("anonymize-names", false, "replace file names by HMAC pseudonyms with random key keeping extensions")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	var opts dsstore.GoldenOptions
+	if *scrub {
+		opts.Scrub = &dsstore.DefaultScrubPolicy
+	}
+	if *anonymize {
+		p := &dsstore.Pseudonymizer{Key: make([]byte, 32), KeepExtensions: true}
+		_, _ = rand.Read(p.Key)
+		opts.Pseudonymize = p
+	}
+	f, err := dsstore.CaptureGoldenFile(flags.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		f.Name = *name
+	}
+	if err = f.Register(*dir); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(stdout, "registered %s%s and %s%s in %s\n", f.Name, dsstore.GoldenDataExt, f.Name, dsstore.GoldenJSONExt, *dir)
+	return nil
+}
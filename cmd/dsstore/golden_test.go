@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestGolden(t *testing.T) {
+	root := t.TempDir()
+	folder := filepath.Join(root, "Desktop")
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := &dsstore.Store{Records: []dsstore.Record{dsstore.TextRecord("secret.txt", "cmmt", "note")}}
+	file := filepath.Join(folder, dsstore.StoreFileName)
+	if err := s.WriteFile(file, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "golden")
+	code, stdout, stderr := runCmd(t, "golden", "--dir", dir, "--scrub", "--anonymize-names", file)
+	if code != 0 || !strings.Contains(stdout, "registered Desktop.DS_Store") {
+		t.Fatalf("unexpected output %d: %s%s", code, stdout, stderr)
+	}
+	if err := dsstore.VerifyGoldenCorpus(dir); err != nil {
+		t.Errorf("expected valid corpus, got %v", err)
+	}
+	expected, err := os.ReadFile(filepath.Join(dir, "Desktop.json"))
+	if err != nil || strings.Contains(string(expected), "secret") || strings.Contains(string(expected), "cmmt") {
+		t.Errorf("expected scrubbed decoding, got %v: %s", err, expected)
+	}
+	if code, _, _ = runCmd(t, "golden", "--dir", dir, file); code == 0 {
+		t.Error("expected registered fixture not to be overwritten")
+	}
+	if code, _, stderr = runCmd(t, "golden", "--dir", dir, "--name", "other", file); code != 0 {
+		t.Errorf("unexpected failure %d: %s", code, stderr)
+	}
+}
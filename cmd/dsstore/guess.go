@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// unknownCode is an unknown structure ID of many stores
+type unknownCode struct {
+	dsstore.UnknownCode
+	Stores int `json:"stores"` // count of stores with records of the code
+}
+
+func runGuess(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print unknown structure IDs as JSON")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	codes := make(map[string]*unknownCode)
+	results := dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, func(path string, s *dsstore.Store) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, u := range dsstore.GuessUnknown(s) {
+			c, ok := codes[u.Code]
+			if !ok {
+				c = &unknownCode{UnknownCode: dsstore.UnknownCode{Code: u.Code, Type: u.Type}}
+				codes[u.Code] = c
+			}
+			c.Records += u.Records
+			c.Stores++
+			if u.Guess.Confidence > c.Guess.Confidence {
+				c.Guess = u.Guess
+			}
+		}
+		return nil
+	})
+	unknown := make([]*unknownCode, 0, len(codes))
+	for _, code := range slices.Sorted(maps.Keys(codes)) {
+		unknown = append(unknown, codes[code])
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(unknown); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "CODE\tTYPE\tRECORDS\tSTORES\tGUESS\tCONFIDENCE\tDETAIL")
+		for _, u := range unknown {
+			_, _ = fmt.Fprintf(w, "%q\t%s\t%d\t%d\t%s\t%.1f\t%s\n", u.Code, u.Type, u.Records, u.Stores, u.Guess.Kind, u.Guess.Confidence, u.Guess.Detail)
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	return nil
+}
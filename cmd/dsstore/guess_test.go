@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestGuess(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		s := &dsstore.Store{Records: []dsstore.Record{dsstore.TextRecord("x", "zzzz", "hidden note"), dsstore.TextRecord("x", "cmmt", "known")}}
+		if err := s.WriteFile(filepath.Join(root, dir, dsstore.StoreFileName), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, stderr := runCmd(t, "guess", root)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if code != 0 || len(lines) != 2 || !strings.HasPrefix(lines[0], "CODE") ||
+		strings.Join(strings.Fields(lines[1]), " ") != `"zzzz" ustr 2 2 text 1.0 hidden note` {
+		t.Errorf("unexpected guesses %d:\n%s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/strongo/dsstore"
+)
+
+func runInfo(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s := &dsstore.Store{}
+	if err := s.ReadFile(flags.Arg(0)); err != nil {
+		return err
+	}
+	info := dsstore.FormatInfo(s)
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+	_, err := fmt.Fprint(stdout, info.Summary())
+	return err
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestInfo(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "info", testStore)
+	if code != 0 {
+		t.Fatalf("info failed: %s", stderr)
+	}
+	for _, want := range []string{"written by Finder of Mac OS X 10.6 or later (bwsp, icvp, pBBk)\n", "icon view settings (icvp): full\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, stdout)
+		}
+	}
+	code, stdout, _ = runCmd(t, "info", "--json", testStore)
+	var info dsstore.FormatInfoReport
+	if err := json.Unmarshal([]byte(stdout), &info); code != 0 || err != nil || info.Generation != dsstore.FinderPlist {
+		t.Errorf("unexpected JSON %s: %v", stdout, err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/strongo/dsstore"
+)
+
+// parseIcon parses icon position like "App.app=140,120"
+func parseIcon(s string) (string, image.Point, error) {
+	var p image.Point
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return "", p, fmt.Errorf("invalid icon %q, expected name=x,y", s)
+	}
+	if _, err := fmt.Sscanf(s[i+1:], "%d,%d", &p.X, &p.Y); err != nil {
+		return "", p, fmt.Errorf("invalid icon %q, expected name=x,y", s)
+	}
+	return s[:i], p, nil
+}
+
+// volumeName returns name of the volume of the path mounted to /Volumes, like "App" of "/Volumes/App/.background"
+func volumeName(path string) string {
+	rest, ok := strings.CutPrefix(filepath.ToSlash(path), "/Volumes/")
+	if !ok {
+		return ""
+	}
+	volume, _, _ := strings.Cut(rest, "/")
+	return volume
+}
+
+func runLayout(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	positions := make(map[string]image.Point)
+	flags.Func("icon", "icon position like App.app=140,120, can be repeated", func(s string) error {
+		name, p, err := parseIcon(s)
+		positions[name] = p
+		return err
+	})
+	window := flags.String("window", "", "window size like 600x400")
+	iconSize := flags.Float64("icon-size", 0, "icon size")
+	textSize := flags.Float64("text-size", 0, "text size")
+	background := flags.String("background", "", "color like #ffffff or picture path inside of the volume")
+	volume := flags.String("volume", "", "volume name for background picture, by default from the /Volumes/<name> path of the file")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	name := flags.Arg(0)
+	s, perm, err := openStore(name)
+	if err != nil {
+		return err
+	}
+	settings, err := s.FolderSettings()
+	if err != nil {
+		return err
+	}
+	defaults, _ := dsstore.Spec{}.Settings()
+	if settings.View == "" {
+		settings.View = defaults.View
+	}
+	if settings.Window == nil {
+		settings.Window = defaults.Window
+	}
+	if settings.IconView == nil {
+		settings.IconView = defaults.IconView
+	}
+	if *window != "" {
+		var width, height int
+		if _, err = fmt.Sscanf(*window, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+			return fmt.Errorf("invalid window size %q, expected WxH", *window)
+		}
+		settings.Window.Bounds.Max = settings.Window.Bounds.Min.Add(image.Pt(width, height))
+	}
+	if *iconSize > 0 {
+		settings.IconView.IconSize = *iconSize
+	}
+	if *textSize > 0 {
+		settings.IconView.TextSize = *textSize
+	}
+	if *volume == "" {
+		*volume = volumeName(name)
+	}
+	if err = settings.IconView.SetBackground(*volume, *background); err != nil {
+		return err
+	}
+	settings.Positions = positions
+	if err = s.SetFolderSettings(settings); err != nil {
+		return err
+	}
+	return saveStore(name, s, perm)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), ".DS_Store")
+	if err = os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, _, stderr := runCmd(t, "layout", file, "--icon", "My=App.app=140,120", "--icon", "Applications=400,120",
+		"--window", "600x400", "--icon-size", "96", "--background", "#000000")
+	if code != 0 {
+		t.Fatalf("layout failed: %s", stderr)
+	}
+	s, err := readStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := s.FolderSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Window.Bounds.Min.X != 200 || settings.Window.Bounds.Dx() != 600 || settings.Window.Bounds.Dy() != 400 {
+		t.Errorf("unexpected window bounds %v", settings.Window.Bounds)
+	}
+	if v := settings.IconView; v.IconSize != 96 || v.TextSize != 12 || v.BackgroundType != 1 || v.BackgroundColor != [3]float64{} {
+		t.Errorf("unexpected icon view settings %+v", v)
+	}
+	if len(settings.Positions) != 3 || settings.Positions["My=App.app"].X != 140 || settings.Positions["Applications"].X != 400 {
+		t.Errorf("unexpected positions %v", settings.Positions)
+	}
+
+	for _, args := range [][]string{
+		{"--icon", "App.app"},
+		{"--window", "600"},
+		{"--background", "bg.png"},
+	} {
+		if code, _, _ = runCmd(t, append([]string{"layout", file}, args...)...); code == 0 {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+	if volume := volumeName("/Volumes/App/.DS_Store"); volume != "App" {
+		t.Errorf("unexpected volume name %q", volume)
+	}
+}
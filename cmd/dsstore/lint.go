@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/strongo/dsstore"
+)
+
+func runLint(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	policyFile := flags.String("policy", "", "YAML policy file")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	if *policyFile == "" {
+		return errors.New("--policy is required")
+	}
+	policy, err := dsstore.LoadPolicy(*policyFile)
+	if err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	violations := make(map[string][]string)
+	results := dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, func(path string, s *dsstore.Store) error {
+		var lines []string
+		for _, r := range s.Records {
+			if rule, allowed := policy.Decide(filepath.ToSlash(path), r); !allowed {
+				violation := &dsstore.PolicyViolation{Rule: *rule}
+				lines = append(lines, fmt.Sprintf("%s: %q %s: %v", path, r.FileName, r.Code(), violation))
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		violations[path] = lines
+		return nil
+	})
+	denied := 0
+	for _, path := range slices.Sorted(maps.Keys(violations)) {
+		for _, line := range violations[path] {
+			_, _ = fmt.Fprintln(stdout, line)
+			denied++
+		}
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	if denied != 0 {
+		return exitError{code: 1, msg: fmt.Sprintf("found %d denied records", denied)}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	rules := "rules:\n  - {action: deny, codes: [pBBk], reason: bookmarks reveal paths}\n  - {action: deny, codes: [Iloc], files: ['*.app']}\n"
+	if err := os.WriteFile(policy, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr := runCmd(t, "lint", "--policy", policy, testStore)
+	expected := testStore + `: "." pBBk: denied by policy: bookmarks reveal paths` + "\n" +
+		testStore + `: "Getscreen.me.app" Iloc: denied by policy rule codes ["Iloc"] files ["*.app"]` + "\n"
+	if code != 1 || stdout != expected || !strings.Contains(stderr, "found 2 denied records") {
+		t.Errorf("unexpected lint result %d:\n%s%s", code, stdout, stderr)
+	}
+
+	out := filepath.Join(t.TempDir(), ".DS_Store")
+	code, stdout, stderr = runCmd(t, "scrub", "--drop", "", "--plist-keys", "", "--policy", policy, "--out", out, testStore)
+	if code != 0 || stdout != "removed 2 of 6 records\n" {
+		t.Errorf("scrub failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, _, stderr = runCmd(t, "lint", "--policy", policy, out); code != 0 {
+		t.Errorf("scrubbed store is denied: %s", stderr)
+	}
+}
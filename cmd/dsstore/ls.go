@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runLs(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	all := flags.Bool("a", false, "list records of the folder itself (file name \".\") too")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s, err := readStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]bool)
+	for _, r := range s.Records {
+		if listed[r.FileName] || r.FileName == "." && !*all {
+			continue
+		}
+		listed[r.FileName] = true
+		if _, err = fmt.Fprintln(stdout, r.FileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
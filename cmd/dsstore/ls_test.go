@@ -0,0 +1,12 @@
+package main
+
+import "testing"
+
+func TestLs(t *testing.T) {
+	if code, stdout, _ := runCmd(t, "ls", testStore); code != 0 || stdout != "Applications\nGetscreen.me.app\n" {
+		t.Errorf("unexpected list %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ := runCmd(t, "ls", "-a", testStore); code != 0 || stdout != ".\nApplications\nGetscreen.me.app\n" {
+		t.Errorf("unexpected list %d:\n%s", code, stdout)
+	}
+}
@@ -0,0 +1,184 @@
+// Command dsstore inspects and edits .DS_Store files.
+//
+// Usage:
+//
+//	dsstore <command> [flags] [arguments]
+//
+// Run "dsstore help" for the list of commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	name    string
+	args    string // usage of arguments
+	summary string
+	run     func(flags *flag.FlagSet, args []string, stdout io.Writer) error
+}
+
+// commands are subcommands in the order of the help
+var commands = []command{
+	{"dump", "[--json] [--hex] <file>", "print decoded records of the store", runDump},
+	{"ls", "[-a] <file>", "list file names referenced by records", runLs},
+	{"get", "[--json] [--hex] <file> <name> <code>", "print value of the record, exit code 1 if absent", runGet},
+	{"set", "<file> <name> <code> <type> <value>", "set value of the record, blob value is hex or @path", runSet},
+	{"rm", "<file> <name> [code]", "remove records of the file name", runRm},
+	{"clean", "[--recursive] [--dry-run] [--check] [--older-than age] [--policy file] <path>",
+		"delete .DS_Store files or remove records denied by the policy", runClean},
+	{"diff", "[--json] <old> <new>", "print changes of records, exit code 1 if stores differ", runDiff},
+	{"create", "--spec <file> [--var name=value]... [--out file]", "create the store of the layout specification", runCreate},
+	{"layout", "[--icon name=x,y]... [--window WxH] [--icon-size n] [--background color|path] <file>",
+		"set window and icon view settings and icon positions", runLayout},
+	{"repair", "[--out file] <file>", "salvage records of the damaged store and write it again", runRepair},
+	{"scrub", "[--drop codes] [--blank codes] [--plist-keys keys] [--anonymize-names [--key key]] [--policy file] [--manifest file] [--out file] <file>",
+		"remove sensitive records for sharing", runScrub},
+	{"verify-scrub", "--manifest file <original> <scrubbed>",
+		"verify that the scrubbed store differs from the original only by redactions of the manifest", runVerifyScrub},
+	{"find", "[--stats] [--json] [--workers n] <root>", "list .DS_Store files of the tree with anomalies", runFind},
+	{"web", "[--recursive] [--rate n/s] [--max-requests n] [--out tree.json] <url>",
+		"reconstruct files of a web server from exposed .DS_Store files", runWeb},
+	{"carve", "[--out-dir dir] <image>", "find and extract stores of a raw disk image", runCarve},
+	{"watch", "<dir>", "print changes of records every time .DS_Store of the directory is written", runWatch},
+	{"stats", "[--json] [--min-stores k] <path>...", "print histograms of structure IDs, types, sizes and geometry of stores", runStats},
+	{"convert", "[--from format] [--to format] [--evidence] <in> <out>",
+		"convert the store between binary, JSON, YAML, plist and CSV formats", runConvert},
+	{"audit", "[--json] <file>", "report what the store leaks: file names, comments, timestamps, volumes, paths", runAudit},
+	{"timeline", "[--json] <path>...",
+		"print timestamps of records, bookmarks and aliases of stores sorted by time", runTimeline},
+	{"recover", "[--json] [--all] [--min-confidence n] <file>",
+		"print remnants of deleted records found in free blocks and unused space of nodes", runRecover},
+	{"tree", "[--json] <path>...", "print merged tree of files referenced by stores with their mentions", runTree},
+	{"cluster", "[--json] [--threshold n] <path>...",
+		"group identical and near-identical stores by fingerprints and similarity of records", runCluster},
+	{"paths", "[--json] <path>...", "print paths, volume names and UUIDs of aliases and bookmarks of stores", runPaths},
+	{"report", "[--format markdown|html|json] [--out file] <path>...",
+		"write forensic report of stores with findings, timelines, paths and anomalies", runReport},
+	{"lint", "--policy file <path>...", "print records denied by the policy, exit code 1 if any is found", runLint},
+	{"tamper", "[--json] [--tolerance d] <dir>",
+		"print files missing on disk or modified unlike dates of records, exit code 1 if any is found", runTamper},
+	{"guess", "[--json] <path>...",
+		"print structure IDs missing in the table of known codes with guesses of their data", runGuess},
+	{"golden", "[--dir dir] [--name name] [--scrub] [--anonymize-names] <file>",
+		"add normalized store with its expected decoding to golden corpus of the reader tests", runGolden},
+	{"info", "[--json] <file>", "print generation of Finder which likely wrote the store and support of its features", runInfo},
+}
+
+// exitError is an error with the exit code, its message is empty when nothing should be printed
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e exitError) Error() string {
+	return e.msg
+}
+
+// errUsage is returned for invalid arguments
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of the arguments and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		flags.Usage = func() {
+			_, _ = fmt.Fprintf(stderr, "usage: dsstore %s %s\n", cmd.name, cmd.args)
+			flags.PrintDefaults()
+		}
+		err := cmd.run(flags, args[1:], stdout)
+		var exitErr exitError
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			flags.Usage()
+			return 2
+		case errors.As(err, &exitErr):
+			if exitErr.msg != "" {
+				_, _ = fmt.Fprintf(stderr, "dsstore %s: %s\n", cmd.name, exitErr.msg)
+			}
+			return exitErr.code
+		default:
+			_, _ = fmt.Fprintf(stderr, "dsstore %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "dsstore: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "usage: dsstore <command> [flags] [arguments]\n\ncommands:")
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(w, "  %-*s %s\n", width, cmd.name, cmd.summary)
+	}
+}
+
+// isNumber reports whether the argument is a number, so it isn't a flag even if it starts with "-"
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+// parse parses flags and checks count of positional arguments
+func parse(flags *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	// flags may follow arguments, like "dsstore layout .DS_Store --icon-size 96",
+	// but negative numbers are arguments, like "dsstore set .DS_Store a ICVO long -7"
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return exitError{code: 2}
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+		for len(args) > 0 && isNumber(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+	}
+	_ = flags.Parse(append([]string{"--"}, positional...))
+	if flags.NArg() < minArgs || maxArgs >= 0 && flags.NArg() > maxArgs {
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testStore is the path of the test store
+var testStore = filepath.Join("..", "..", "testdata", "00.DS_Store")
+
+// runCmd runs the tool and returns the exit code and the output
+func runCmd(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	if code, _, stderr := runCmd(t); code != 2 || !strings.Contains(stderr, "dump") {
+		t.Errorf("expected usage, got %d: %s", code, stderr)
+	}
+	code, _, stderr := runCmd(t, "help")
+	if code != 0 {
+		t.Errorf("expected help, got %d", code)
+	}
+	// summaries of commands are aligned
+	column := -1
+	for _, line := range strings.Split(stderr, "\n") {
+		name, _, ok := strings.Cut(strings.TrimPrefix(line, "  "), " ")
+		if !strings.HasPrefix(line, "  ") || !ok {
+			continue
+		}
+		summary := len(line) - len(strings.TrimLeft(line[2+len(name):], " "))
+		if column >= 0 && summary != column {
+			t.Errorf("summary of %s starts at %d, expected %d", name, summary, column)
+		}
+		column = summary
+	}
+	if code, _, stderr := runCmd(t, "unknown"); code != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Errorf("expected unknown command, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCmd(t, "dump", "--unknown", testStore); code != 2 {
+		t.Errorf("expected invalid flag, got %d", code)
+	}
+	if code, _, stderr := runCmd(t, "dump", "missing"); code != 1 || !strings.Contains(stderr, "dsstore dump: ") {
+		t.Errorf("expected error, got %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// storePath is an embedded path of a store
+type storePath struct {
+	Store string `json:"store"`
+	dsstore.EmbeddedPath
+}
+
+func runPaths(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print paths as JSON")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	files, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	var paths []storePath
+	results := dsstore.ProcessAllContext(context.Background(), files, 0, dsstore.ReadOptions{}, func(file string, s *dsstore.Store) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range s.EmbeddedPaths() {
+			paths = append(paths, storePath{Store: file, EmbeddedPath: p})
+		}
+		return nil
+	})
+	sort.SliceStable(paths, func(i, j int) bool {
+		return paths[i].Store < paths[j].Store
+	})
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(paths); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "STORE\tNAME\tCODE\tKIND\tVOLUME\tUUID\tPATH")
+		for _, p := range paths {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Store, p.FileName, p.Code, p.Kind, p.Volume, p.VolumeUUID, p.Path)
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	return nil
+}
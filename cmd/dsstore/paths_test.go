@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "paths", testStore)
+	if code != 0 {
+		t.Fatalf("paths failed: %s", stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "icvp  alias     Macintosh HD") ||
+		!strings.Contains(lines[2], "pBBk  bookmark  Macintosh HD  81DED881-7FF2-4FBB-A8F8-DC8A4BC4C10E  /Users/gwend/") {
+		t.Errorf("unexpected paths:\n%s", stdout)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// recoveredRecord is JSON of a recovered record
+type recoveredRecord struct {
+	Offset     int64   `json:"offset"`
+	InSlack    bool    `json:"inSlack"`
+	Live       bool    `json:"live"`
+	Confidence float64 `json:"confidence"`
+	Name       string  `json:"name"`
+	Code       string  `json:"code"`
+	Type       string  `json:"type"`
+	Value      any     `json:"value"`
+}
+
+func runRecover(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print records as JSON")
+	all := flags.Bool("all", false, "print also old copies of records which the store has")
+	minConfidence := flags.Float64("min-confidence", 0.6, "minimal confidence of printed records from 0 to 1")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	s := &dsstore.Store{}
+	if err := s.ReadFileWithOptions(flags.Arg(0), dsstore.ReadOptions{Fidelity: true}); err != nil {
+		return err
+	}
+	recovered, err := s.RecoverDeleted()
+	if err != nil {
+		return err
+	}
+	var records []dsstore.RecoveredRecord
+	for _, r := range recovered {
+		if r.Confidence >= *minConfidence && (!r.Live || *all) {
+			records = append(records, r)
+		}
+	}
+	if *asJSON {
+		items := make([]recoveredRecord, len(records))
+		for i, r := range records {
+			items[i] = recoveredRecord{Offset: r.Offset, InSlack: r.InSlack, Live: r.Live, Confidence: r.Confidence,
+				Name: r.FileName, Code: r.Code(), Type: r.Type, Value: jsonValue(r.Record)}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "OFFSET\tWHERE\tCONFIDENCE\tNAME\tCODE\tTYPE\tVALUE")
+	for _, r := range records {
+		where := "free block"
+		if r.InSlack {
+			where = "node slack"
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%.1f\t%s\t%s\t%s\t%s\n", r.Offset, where, r.Confidence, r.FileName, r.Code(), r.Type,
+			formatValue(r.Record))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestRecover(t *testing.T) {
+	s := &dsstore.Store{Records: []dsstore.Record{dsstore.TextRecord("kept", "cmmt", "note")}}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	// remnant of record of "gone" after the only record of the node
+	data := buf.Bytes()
+	record := []byte("\x00\x00\x00\x04\x00k\x00e\x00p\x00tcmmtustr\x00\x00\x00\x04\x00n\x00o\x00t\x00e")
+	i := bytes.Index(data, record)
+	if i < 0 {
+		t.Fatal("record is not found")
+	}
+	remnant := bytes.Replace(record, []byte("\x00k\x00e\x00p\x00t"), []byte("\x00g\x00o\x00n\x00e"), 1)
+	copy(data[i+len(record):], remnant)
+	file := filepath.Join(t.TempDir(), ".DS_Store")
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr := runCmd(t, "recover", file)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if code != 0 || len(lines) != 2 || !strings.Contains(lines[1], "node slack  1.0         gone  cmmt  ustr  \"note\"") {
+		t.Errorf("unexpected output %q: %s", stdout, stderr)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/strongo/dsstore"
+)
+
+func runRepair(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	out := flags.String("out", "", "output file, the repaired file is replaced by default")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	name := flags.Arg(0)
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	s, report, err := dsstore.Repair(data, dsstore.ReadOptions{})
+	if err != nil {
+		return fmt.Errorf("nothing can be salvaged: %w", err)
+	}
+	for _, e := range report.Lost {
+		_, _ = fmt.Fprintf(stdout, "lost\t%v\n", e)
+	}
+	for _, e := range report.Dropped {
+		_, _ = fmt.Fprintf(stdout, "dropped\t%v\n", e)
+	}
+	for _, w := range report.Warnings {
+		_, _ = fmt.Fprintf(stdout, "fixed\t%v\n", w)
+	}
+	_, _ = fmt.Fprintf(stdout, "salvaged\t%d records\n", report.Salvaged)
+	if *out == "" {
+		*out = name
+	}
+	return saveStore(*out, s, info.Mode().Perm())
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".DS_Store")
+	// trailing garbage is dropped by writing the store again
+	if err = os.WriteFile(file, append(data, "garbage"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "fixed.DS_Store")
+	code, stdout, stderr := runCmd(t, "repair", file, "--out", out)
+	if code != 0 || !strings.Contains(stdout, "salvaged\t6 records") {
+		t.Fatalf("repair failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, stdout, _ = runCmd(t, "ls", out); code != 0 || stdout != "Applications\nGetscreen.me.app\n" {
+		t.Errorf("unexpected files of repaired store %q", stdout)
+	}
+
+	if err = os.WriteFile(file, []byte("broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr = runCmd(t, "repair", file); code != 1 || !strings.Contains(stderr, "nothing can be salvaged") {
+		t.Errorf("expected error for broken store, got %d: %s", code, stderr)
+	}
+}
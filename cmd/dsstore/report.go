@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/strongo/dsstore"
+)
+
+func runReport(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	format := flags.String("format", "markdown", "format of the report: markdown, html or json")
+	out := flags.String("out", "", "output file, the report is printed by default")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	write := map[string]func(r *dsstore.ForensicReport, w io.Writer) error{
+		"markdown": (*dsstore.ForensicReport).WriteMarkdown,
+		"md":       (*dsstore.ForensicReport).WriteMarkdown,
+		"html":     (*dsstore.ForensicReport).WriteHTML,
+		"json":     (*dsstore.ForensicReport).WriteJSON,
+	}[*format]
+	if write == nil {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	report := dsstore.NewForensicReport()
+	// fidelity keeps the file data, so names of deleted files are found in free blocks
+	report.AddResults(dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{Fidelity: true},
+		func(path string, s *dsstore.Store) error {
+			return report.Add(path, s, nil)
+		}))
+	if *out == "" {
+		return write(report, stdout)
+	}
+	var b bytes.Buffer
+	if err = write(report, &b); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, b.Bytes(), 0o644)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "report", testStore)
+	if code != 0 {
+		t.Fatalf("report failed: %s", stderr)
+	}
+	for _, expected := range []string{"# .DS_Store report", "- Getscreen.me.app", "### Embedded paths", "SHA-256 `", "Macintosh HD", "```json"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("no %q in the report:\n%s", expected, stdout)
+		}
+	}
+	out := filepath.Join(t.TempDir(), "report.html")
+	if code, _, stderr = runCmd(t, "report", "--format", "html", "--out", out, testStore); code != 0 {
+		t.Fatalf("report failed: %s", stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "<!DOCTYPE html>") || !strings.Contains(string(data), "<details>") {
+		t.Errorf("unexpected HTML report:\n%s", data)
+	}
+	if code, _, _ = runCmd(t, "report", "--format", "pdf", testStore); code == 0 {
+		t.Error("unknown format is accepted")
+	}
+}
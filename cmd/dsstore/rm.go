@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"slices"
+
+	"github.com/strongo/dsstore"
+)
+
+func runRm(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	if err := parse(flags, args, 2, 3); err != nil {
+		return err
+	}
+	s, perm, err := openStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	name, code := flags.Arg(1), flags.Arg(2)
+	n := len(s.Records)
+	s.Records = slices.DeleteFunc(s.Records, func(r dsstore.Record) bool {
+		return r.FileName == name && (code == "" || r.Code() == code)
+	})
+	if len(s.Records) == n {
+		return exitError{code: 1, msg: "no matching records"}
+	}
+	return saveStore(flags.Arg(0), s, perm)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRm(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), ".DS_Store")
+	if err = os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := runCmd(t, "rm", file, ".", "vSrn"); code != 0 {
+		t.Fatalf("rm failed: %s", stderr)
+	}
+	if code, _, _ := runCmd(t, "get", file, ".", "vSrn"); code != 1 {
+		t.Errorf("expected removed record, got %d", code)
+	}
+	if code, _, _ := runCmd(t, "get", file, ".", "bwsp"); code != 0 {
+		t.Errorf("expected other records of the file to be kept, got %d", code)
+	}
+
+	// all records of the file are removed without structure ID
+	if code, _, stderr := runCmd(t, "rm", file, "."); code != 0 {
+		t.Fatalf("rm failed: %s", stderr)
+	}
+	if _, stdout, _ := runCmd(t, "ls", "-a", file); stdout != "Applications\nGetscreen.me.app\n" {
+		t.Errorf("unexpected names %q", stdout)
+	}
+
+	// missing records
+	for _, args := range [][]string{{".", "vSrn"}, {"missing"}, {"Applications", "cmmt"}} {
+		if code, _, stderr := runCmd(t, append([]string{"rm", file}, args...)...); code != 1 || stderr == "" {
+			t.Errorf("expected no matching records for %v, got %d: %s", args, code, stderr)
+		}
+	}
+
+	// missing file isn't created
+	missing := filepath.Join(t.TempDir(), ".DS_Store")
+	if code, _, stderr := runCmd(t, "rm", missing, "a"); code != 1 || stderr == "" {
+		t.Errorf("expected no matching records of missing file, got %d: %s", code, stderr)
+	}
+	if _, err = os.Stat(missing); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing file not to be created, got %v", err)
+	}
+
+	if code, _, _ := runCmd(t, "rm", file); code != 2 {
+		t.Errorf("expected usage error without file name, got %d", code)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/strongo/dsstore"
+)
+
+// splitList returns non-empty elements of the comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func runScrub(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	policy := dsstore.DefaultScrubPolicy
+	drop := flags.String("drop", strings.Join(policy.Drop, ","), "comma separated structure IDs of removed records")
+	blank := flags.String("blank", "", "comma separated structure IDs of records which data is replaced by zero bytes")
+	keys := flags.String("plist-keys", strings.Join(policy.PlistKeys, ","), "comma separated keys removed from property lists")
+	anonymize := flags.Bool("anonymize-names", false, "replace file names by HMAC pseudonyms keeping extensions")
+	key := flags.String("key", "", "HMAC key of pseudonyms, the same key gives the same pseudonyms, random by default")
+	rules := flags.String("policy", "", "YAML policy file, records it denies are removed too")
+	manifestFile := flags.String("manifest", "", "write JSON manifest with unsalted hashes of removed and changed records for auditors, don't publish it")
+	out := flags.String("out", "", "output file, the scrubbed file is replaced by default")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	name := flags.Arg(0)
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	s, err := readStore(name)
+	if err != nil {
+		return err
+	}
+	policy = dsstore.ScrubPolicy{Drop: splitList(*drop), Blank: splitList(*blank), PlistKeys: splitList(*keys)}
+	if *rules != "" {
+		if policy.Rules, err = dsstore.LoadPolicy(*rules); err != nil {
+			return err
+		}
+		policy.Path = filepath.ToSlash(name)
+	}
+	if *anonymize {
+		p := dsstore.Pseudonymizer{Key: []byte(*key), KeepExtensions: true}
+		if *key == "" {
+			p.Key = make([]byte, 32)
+			_, _ = rand.Read(p.Key)
+		}
+		policy.Rename = p.Name
+	}
+	total := len(s.Records)
+	scrubbed, manifest := dsstore.ScrubWithManifest(s, policy)
+	for _, scrubbed := range scrubbed {
+		switch scrubbed.Action {
+		case dsstore.ScrubBlanked:
+			_, _ = fmt.Fprintf(stdout, "blanked %s %s\n", scrubbed.Name, scrubbed.Code)
+		case dsstore.ScrubRewritten:
+			_, _ = fmt.Fprintf(stdout, "removed %s of %s %s\n", strings.Join(scrubbed.Keys, ", "), scrubbed.Name, scrubbed.Code)
+		}
+	}
+	_, _ = fmt.Fprintf(stdout, "removed %d of %d records\n", total-len(s.Records), total)
+	if *manifestFile != "" {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(*manifestFile, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		*out = name
+	}
+	return saveStore(*out, s, info.Mode().Perm())
+}
+
+func runVerifyScrub(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	manifestFile := flags.String("manifest", "", "JSON manifest written by scrub")
+	if err := parse(flags, args, 2, 2); err != nil {
+		return err
+	}
+	if *manifestFile == "" {
+		return errors.New("--manifest is required")
+	}
+	data, err := os.ReadFile(*manifestFile)
+	if err != nil {
+		return err
+	}
+	var manifest dsstore.RedactionManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("%s: %w", *manifestFile, err)
+	}
+	original, err := readStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	scrubbed, err := readStore(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err = manifest.Verify(original, scrubbed); err != nil {
+		return exitError{code: 1, msg: err.Error()}
+	}
+	_, err = fmt.Fprintf(stdout, "verified %d redactions\n", len(manifest.Redactions))
+	return err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestScrub(t *testing.T) {
+	out := filepath.Join(t.TempDir(), ".DS_Store")
+	code, stdout, stderr := runCmd(t, "scrub", "--drop", "pBBk,vSrn", "--blank", "bwsp", "--anonymize-names", "--key", "secret", "--out", out, testStore)
+	if code != 0 || stdout != "blanked . bwsp\nremoved backgroundImageAlias of . icvp\nremoved 2 of 6 records\n" {
+		t.Fatalf("scrub failed with %d: %s%s", code, stdout, stderr)
+	}
+	code, stdout, _ = runCmd(t, "ls", "-a", out)
+	p := dsstore.Pseudonymizer{Key: []byte("secret"), KeepExtensions: true}
+	if code != 0 || strings.Contains(stdout, "Applications") || !strings.Contains(stdout, p.Name("Getscreen.me.app")) {
+		t.Errorf("unexpected files %q", stdout)
+	}
+}
+
+func TestVerifyScrub(t *testing.T) {
+	dir := t.TempDir()
+	out, manifest := filepath.Join(dir, ".DS_Store"), filepath.Join(dir, "manifest.json")
+	if code, _, stderr := runCmd(t, "scrub", "--manifest", manifest, "--out", out, testStore); code != 0 {
+		t.Fatalf("scrub failed: %s", stderr)
+	}
+	code, stdout, stderr := runCmd(t, "verify-scrub", "--manifest", manifest, testStore, out)
+	if code != 0 || stdout != "verified 2 redactions\n" {
+		t.Errorf("verify-scrub failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, _, stderr = runCmd(t, "verify-scrub", "--manifest", manifest, testStore, testStore); code != 1 ||
+		!strings.Contains(stderr, "don't match the redaction manifest") {
+		t.Errorf("unscrubbed store is verified: %s", stderr)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// openStore reads the store file for editing, missing file is an empty store
+func openStore(name string) (*dsstore.Store, os.FileMode, error) {
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &dsstore.Store{}, 0o644, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	s, err := readStore(name)
+	return s, info.Mode().Perm(), err
+}
+
+// saveStore writes the edited store atomically
+func saveStore(name string, s *dsstore.Store, perm os.FileMode) error {
+	return s.WriteFileWithOptions(name, perm, dsstore.WriteOptions{Atomic: true})
+}
+
+// parseRecord returns record of the type with the value parsed from text:
+// bool, int (long, shor, comp), RFC 3339 time (dutc), 4 characters (type), text (ustr),
+// hex or @path of file with the data, like binary property list (blob)
+func parseRecord(name, code, typ, value string) (dsstore.Record, error) {
+	r := dsstore.Record{FileName: name, Type: typ}
+	switch typ {
+	case "bool":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return r, err
+		}
+		r.Data = []byte{0}
+		if v {
+			r.Data[0] = 1
+		}
+	case "long", "shor":
+		v, err := strconv.ParseInt(value, 0, 32)
+		if err != nil {
+			return r, err
+		}
+		r.Data = binary.BigEndian.AppendUint32(nil, uint32(v))
+	case "comp":
+		v, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return r, err
+		}
+		r.Data = binary.BigEndian.AppendUint64(nil, uint64(v))
+	case "dutc":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return r, err
+		}
+		return dsstore.TimeRecord(name, code, t), nil
+	case "type":
+		if len(value) != 4 {
+			return r, fmt.Errorf("value of type must have 4 characters, got %q", value)
+		}
+		r.Data = []byte(value)
+	case "ustr":
+		if value == "" {
+			return r, errors.New("value of ustr must not be empty")
+		}
+		return dsstore.TextRecord(name, code, value), nil
+	case "blob":
+		var err error
+		if path, ok := strings.CutPrefix(value, "@"); ok {
+			r.Data, err = os.ReadFile(path)
+		} else {
+			r.Data, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return r, err
+		}
+		r.DataLen = uint32(len(r.Data))
+	default:
+		return r, fmt.Errorf("unknown type %q", typ)
+	}
+	r.SetCode(code)
+	return r, r.Validate()
+}
+
+func runSet(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	if err := parse(flags, args, 5, 5); err != nil {
+		return err
+	}
+	name, code := flags.Arg(1), flags.Arg(2)
+	if len(code) != 4 {
+		return fmt.Errorf("code must have 4 characters, got %q", code)
+	}
+	r, err := parseRecord(name, code, flags.Arg(3), flags.Arg(4))
+	if err != nil {
+		return err
+	}
+	s, perm, err := openStore(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(s.Records, func(o dsstore.Record) bool {
+		return o.FileName == name && o.Extra == r.Extra
+	})
+	if i >= 0 {
+		s.Records[i] = r
+	} else {
+		s.Records = append(s.Records, r)
+	}
+	return saveStore(flags.Arg(0), s, perm)
+}
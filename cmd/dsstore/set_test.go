@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetRm(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".DS_Store")
+	plist := filepath.Join(dir, "bwsp.plist")
+	if err := os.WriteFile(plist, []byte("bplist00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"a", "cmmt", "ustr", "first"},
+		{"a", "cmmt", "ustr", "comment"},
+		{"a", "Iloc", "blob", "0000008c00000078ffffffffffff0000"},
+		{"a", "dscl", "bool", "true"},
+		{"a", "ICVO", "long", "-7"},
+		{".", "vstl", "type", "icnv"},
+		{".", "bwsp", "blob", "@" + plist},
+		{"a", "moDD", "dutc", "2020-01-02T03:04:05Z"},
+		{"a", "logS", "comp", "0x100"},
+	} {
+		if code, _, stderr := runCmd(t, append([]string{"set", file}, args...)...); code != 0 {
+			t.Fatalf("set %v failed: %s", args, stderr)
+		}
+	}
+	for _, test := range [][2]string{
+		{"cmmt", "comment\n"},
+		{"ICVO", "-7\n"},
+		{"moDD", "2020-01-02T03:04:05Z\n"},
+		{"logS", "256\n"},
+	} {
+		if code, stdout, _ := runCmd(t, "get", file, "a", test[0]); code != 0 || stdout != test[1] {
+			t.Errorf("unexpected value of %s: %q", test[0], stdout)
+		}
+	}
+	if _, stdout, _ := runCmd(t, "ls", "-a", file); stdout != ".\na\n" {
+		t.Errorf("unexpected names %q", stdout)
+	}
+	for _, args := range [][]string{
+		{"a", "cmmt", "ustr", ""},
+		{"a", "dscl", "bool", "maybe"},
+		{"a", "vstl", "type", "long type"},
+		{"a", "Iloc", "blob", "xyz"},
+		{"a", "Iloc", "none", "1"},
+		{"a", "code", "long", "99999999999"},
+		{"a", "toolong", "long", "1"},
+		{"a/b", "dscl", "bool", "true"},
+		{"..", "dscl", "bool", "true"},
+	} {
+		if code, _, _ := runCmd(t, append([]string{"set", file}, args...)...); code != 1 {
+			t.Errorf("expected error for %v, got %d", args, code)
+		}
+	}
+
+	if code, _, _ := runCmd(t, "rm", file, "a", "cmmt"); code != 0 {
+		t.Errorf("rm failed: %d", code)
+	}
+	if code, _, _ := runCmd(t, "get", file, "a", "cmmt"); code != 1 {
+		t.Errorf("expected removed record, got %d", code)
+	}
+	if code, _, _ := runCmd(t, "rm", file, "a"); code != 0 {
+		t.Errorf("rm failed: %d", code)
+	}
+	if code, _, stderr := runCmd(t, "rm", file, "a"); code != 1 || stderr == "" {
+		t.Errorf("expected no matching records, got %d: %s", code, stderr)
+	}
+	if _, stdout, _ := runCmd(t, "ls", "-a", file); stdout != ".\n" {
+		t.Errorf("unexpected names %q", stdout)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/strongo/dsstore"
+)
+
+// printHistogram prints the histogram with keys sorted by counts in descending order
+func printHistogram(w io.Writer, title string, h map[string]int) {
+	_, _ = fmt.Fprintf(w, "%s\n", title)
+	keys := slices.SortedFunc(maps.Keys(h), func(a, b string) int {
+		return cmp.Or(cmp.Compare(h[b], h[a]), cmp.Compare(a, b))
+	})
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "  %s\t%d\n", k, h[k])
+	}
+}
+
+// printBuckets prints the numeric histogram in order of buckets with labels of their lower bounds
+func printBuckets(w io.Writer, title string, h map[int64]int, label func(bound int64) string) {
+	_, _ = fmt.Fprintf(w, "%s\n", title)
+	for _, bound := range slices.Sorted(maps.Keys(h)) {
+		_, _ = fmt.Fprintf(w, "  %s\t%d\n", label(bound), h[bound])
+	}
+}
+
+func runStats(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print histograms as JSON")
+	minStores := flags.Int("min-stores", 0, "merge structure IDs found in fewer stores into \"other\" for publishing")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	st := dsstore.NewCorpusStats()
+	st.AddResults(dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, st.Add))
+	if *minStores > 0 {
+		st = st.Anonymize(*minStores)
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(st)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%d stores, %d failed\n", st.Stores, st.Failed)
+	printHistogram(w, "structure IDs", st.Codes)
+	printHistogram(w, "types", st.Types)
+	decades := func(bound int64) string {
+		if bound == 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%d-%d", bound, bound*10-1)
+	}
+	bytes := func(bound int64) string {
+		return fmt.Sprintf("%d-%d bytes", bound, max(bound*2-1, bound))
+	}
+	printBuckets(w, "records", st.Records, decades)
+	printBuckets(w, "file sizes", st.Sizes, bytes)
+	printBuckets(w, "blob sizes", st.BlobSizes, bytes)
+	printBuckets(w, "tree depths", st.Depths, func(bound int64) string {
+		return fmt.Sprint(bound)
+	})
+	printBuckets(w, "tree nodes", st.Nodes, decades)
+	printBuckets(w, "free space", st.FreeRatios, func(bound int64) string {
+		return fmt.Sprintf("%d-%d%%", bound, bound+9)
+	})
+	printBuckets(w, "free blocks", st.FreeBlocks, func(bound int64) string {
+		return fmt.Sprintf("%d bytes", bound)
+	})
+	return w.Flush()
+}
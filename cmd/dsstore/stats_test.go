@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestStats(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "a")
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, ".DS_Store"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr := runCmd(t, "stats", filepath.Dir(dir), testStore)
+	if code != 0 {
+		t.Fatalf("stats failed: %s", stderr)
+	}
+	for _, want := range []string{"2 stores, 0 failed", "  Iloc  4\n", "  blob  10\n", "  1-9  2\n", "tree depths\n  1  2\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, stdout)
+		}
+	}
+	code, stdout, _ = runCmd(t, "stats", "--json", testStore)
+	var st dsstore.CorpusStats
+	if err = json.Unmarshal([]byte(stdout), &st); code != 0 || err != nil || st.Stores != 1 || st.Codes["vSrn"] != 1 {
+		t.Errorf("unexpected JSON %s", stdout)
+	}
+	code, stdout, _ = runCmd(t, "stats", "--min-stores", "3", filepath.Dir(dir), testStore)
+	if code != 0 || !strings.Contains(stdout, "  other  ") || strings.Contains(stdout, "vSrn") {
+		t.Errorf("structure IDs are not anonymized:\n%s", stdout)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func runTamper(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print findings as JSON")
+	tolerance := flags.Duration("tolerance", 2*time.Second, "maximal ignored difference of modification times")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	dir := flags.Arg(0)
+	s, err := readStore(filepath.Join(dir, dsstore.StoreFileName))
+	if err != nil {
+		return err
+	}
+	findings, err := dsstore.CheckTampering(s, os.DirFS(dir), ".", dsstore.TamperOptions{Tolerance: *tolerance})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "KIND\tNAME\tRECORDED\tON DISK\tDELTA")
+		for _, f := range findings {
+			delta := "-"
+			if f.Kind != dsstore.TamperMissing {
+				delta = f.Delta.String()
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Kind, f.Name, formatTime(f.StoreTime), formatTime(f.DiskTime), delta)
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(findings) != 0 {
+		return exitError{code: 1}
+	}
+	return nil
+}
+
+// formatTime formats the time in UTC, zero time is "-"
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestTamper(t *testing.T) {
+	dir := t.TempDir()
+	modified := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "a.txt"), modified, modified.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	s := &dsstore.Store{Records: []dsstore.Record{
+		dsstore.TimeRecord("a.txt", "modD", modified),
+		dsstore.TimeRecord("gone.txt", "modD", modified),
+	}}
+	if err := s.WriteFile(filepath.Join(dir, dsstore.StoreFileName), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, _ := runCmd(t, "tamper", dir)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if code != 1 || len(lines) != 3 || !strings.HasPrefix(lines[1], "modified  a.txt") || !strings.HasSuffix(lines[1], "1h0m0s") ||
+		!strings.HasPrefix(lines[2], "missing   gone.txt") {
+		t.Errorf("unexpected findings %d:\n%s", code, stdout)
+	}
+	if code, _, _ = runCmd(t, "tamper", "--tolerance", "2h", dir); code != 1 {
+		t.Errorf("missing file is not found")
+	}
+}
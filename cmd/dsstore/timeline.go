@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func runTimeline(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print events as JSON")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	var timeline dsstore.Timeline
+	results := dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, func(path string, s *dsstore.Store) error {
+		mu.Lock()
+		defer mu.Unlock()
+		timeline.Add(path, s)
+		return nil
+	})
+	timeline.Sort()
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(timeline); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "TIME\tSOURCE\tNAME\tCODE\tKIND")
+		for _, e := range timeline {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Format(time.RFC3339), e.Source, e.FileName, e.Code, e.Kind)
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTimeline(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "timeline", testStore)
+	if code != 0 {
+		t.Fatalf("timeline failed: %s", stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TIME") || !strings.HasPrefix(lines[1], "2019-10-13T12:26:06Z") ||
+		!strings.HasSuffix(lines[2], "pBBk  bookmark volume") {
+		t.Errorf("unexpected timeline:\n%s", stdout)
+	}
+	code, stdout, _ = runCmd(t, "timeline", "--json", testStore)
+	var events []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &events); code != 0 || err != nil || len(events) != 2 || events[0]["source"] != testStore {
+		t.Errorf("unexpected events %s: %v", stdout, err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func runTree(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	paths, err := storePaths(flags.Args())
+	if err != nil {
+		return err
+	}
+	tree := dsstore.NewFileTree()
+	results := dsstore.ProcessAllContext(context.Background(), paths, 0, dsstore.ReadOptions{}, func(path string, s *dsstore.Store) error {
+		tree.Add(path, s)
+		return nil
+	})
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(tree.Root()); err != nil {
+			return err
+		}
+	} else {
+		// common folders of all stores are printed as one path
+		top := tree.Root()
+		for len(top.Children) == 1 && len(top.Children[0].Mentions) == 0 {
+			top = top.Children[0]
+		}
+		depth := 0
+		if top.Path != "" {
+			_, _ = fmt.Fprintln(stdout, top.Path+"/")
+			depth = 1
+		}
+		printFileNode(stdout, top, depth)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return exitError{code: 1, msg: fmt.Sprintf("%s: %v", result.Path, result.Err)}
+		}
+	}
+	return nil
+}
+
+// printFileNode prints names of descendants of the node indented by depth with count of mentioning stores
+// and the latest time
+func printFileNode(w io.Writer, n *dsstore.FileNode, depth int) {
+	for _, c := range n.Children {
+		name := c.Name
+		if len(c.Children) != 0 {
+			name += "/"
+		}
+		line := strings.Repeat("  ", depth) + name
+		if len(c.Mentions) != 0 {
+			line += fmt.Sprintf("  [%d stores", len(c.Mentions))
+			if latest := c.Latest(); !latest.IsZero() {
+				line += ", " + latest.Format(time.RFC3339)
+			}
+			line += "]"
+		}
+		_, _ = fmt.Fprintln(w, line)
+		printFileNode(w, c, depth+1)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	data, err := os.ReadFile(testStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		if err = os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(root, dir, ".DS_Store"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, stderr := runCmd(t, "tree", root)
+	if code != 0 {
+		t.Fatalf("tree failed: %s", stderr)
+	}
+	want := filepath.ToSlash(root)[1:] + "/\n  a/  [1 stores]\n    Applications  [1 stores]\n"
+	if !strings.HasPrefix(stdout, want) || !strings.Contains(stdout, "\n  b/  [1 stores]\n") ||
+		strings.Count(stdout, "Getscreen.me.app  [1 stores]\n") != 2 {
+		t.Errorf("unexpected tree:\n%s", stdout)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// interruptContext returns context which is done on interrupt signal
+var interruptContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func runWatch(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	dir := flags.Arg(0)
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	var printErr error
+	err := dsstore.Watch(ctx, dir, func(event dsstore.Event, _ *dsstore.Store) {
+		line := fmt.Sprintf("%s %s %s", time.Now().Format(time.TimeOnly), event.Kind, event.Path)
+		if event.Err != nil {
+			line += ": " + event.Err.Error()
+		}
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			printErr = err
+			cancel()
+			return
+		}
+		if err := printDiff(stdout, event.Changes); err != nil {
+			printErr = err
+			cancel()
+		}
+	})
+	if printErr != nil {
+		return printErr
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	interruptContext = func() (context.Context, context.CancelFunc) {
+		return ctx, cancel
+	}
+	defer func() {
+		interruptContext = func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}
+	}()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		s := &dsstore.Store{Records: []dsstore.Record{dsstore.TextRecord("a", "cmmt", "hello")}}
+		if err := s.WriteFile(filepath.Join(dir, ".DS_Store"), 0o644); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(500 * time.Millisecond)
+		cancel()
+	}()
+	code, stdout, stderr := runCmd(t, "watch", dir)
+	if code != 0 {
+		t.Fatalf("watch failed: %s", stderr)
+	}
+	if !strings.Contains(stdout, " created ") || !strings.Contains(stdout, "hello") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+	if code, _, _ = runCmd(t, "watch", filepath.Join(dir, "missing")); code != 1 {
+		t.Errorf("expected error for missing directory, got %d", code)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strongo/dsstore/webscan"
+)
+
+// webNode is JSON of a node of the reconstructed tree
+type webNode struct {
+	Name     string     `json:"name,omitempty"`
+	URL      string     `json:"url"`
+	Dir      bool       `json:"dir,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Children []*webNode `json:"children,omitempty"`
+}
+
+func newWebNode(n *webscan.Node) *webNode {
+	node := &webNode{Name: n.Name, URL: n.URL, Dir: n.IsDir()}
+	if n.Err != nil {
+		node.Error = n.Err.Error()
+	}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, newWebNode(child))
+	}
+	return node
+}
+
+// parseRate parses rate of requests like "2/s", "30/m" or "2" per second and returns interval between requests
+func parseRate(s string) (time.Duration, error) {
+	count, unit, _ := strings.Cut(s, "/")
+	per := time.Second
+	switch unit {
+	case "", "s":
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	return time.Duration(float64(per) / n), nil
+}
+
+func runWeb(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	recursive := flags.Bool("recursive", false, "probe listed entries as subdirectories with their own .DS_Store")
+	rate := flags.String("rate", "", "maximal rate of requests, like 2/s or 30/m")
+	maxRequests := flags.Int("max-requests", 0, "maximal count of requests")
+	out := flags.String("out", "", "write the tree as JSON to the file")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	opts := webscan.Options{Recursive: *recursive, MaxRequests: *maxRequests, UserAgent: "dsstore"}
+	if *rate != "" {
+		var err error
+		if opts.Interval, err = parseRate(*rate); err != nil {
+			return err
+		}
+	}
+	root, err := webscan.Scan(context.Background(), flags.Arg(0), opts)
+	switch {
+	case errors.Is(err, webscan.ErrTooManyRequests):
+		// the tree is partial, it is printed before the error
+		err = fmt.Errorf("%w, the tree is partial", err)
+	case err != nil:
+		return err
+	case root.Store == nil:
+		return exitError{code: 1, msg: "no .DS_Store found"}
+	}
+	root.Walk(func(p string, n *webscan.Node) {
+		switch {
+		case p == "":
+			p = n.URL
+		case n.IsDir():
+			p += "/"
+		}
+		if n.Err != nil {
+			p += "\t" + n.Err.Error()
+		}
+		_, _ = fmt.Fprintln(stdout, p)
+	})
+	if *out != "" {
+		data, jsonErr := json.MarshalIndent(newWebNode(root), "", "  ")
+		if jsonErr != nil {
+			return jsonErr
+		}
+		if writeErr := os.WriteFile(*out, append(data, '\n'), 0o644); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
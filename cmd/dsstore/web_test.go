@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+func TestWeb(t *testing.T) {
+	store := func(names ...string) []byte {
+		var s dsstore.Store
+		for _, name := range names {
+			s.Records = append(s.Records, dsstore.TextRecord(name, "cmmt", "x"))
+		}
+		var buf bytes.Buffer
+		if err := s.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	files := map[string][]byte{
+		"/.DS_Store":        store("index.html", "static"),
+		"/static/.DS_Store": store("app.js"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, ok := files[r.URL.Path]; ok {
+			_, _ = w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "tree.json")
+	code, stdout, stderr := runCmd(t, "web", "--recursive", "--rate", "1000/s", "--out", out, server.URL)
+	if code != 0 {
+		t.Fatalf("web failed: %s", stderr)
+	}
+	if want := server.URL + "/\nindex.html\nstatic/\nstatic/app.js\n"; stdout != want {
+		t.Errorf("unexpected tree %q", stdout)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree webNode
+	if err = json.Unmarshal(data, &tree); err != nil || len(tree.Children) != 2 || !tree.Children[1].Dir {
+		t.Errorf("unexpected JSON tree %s", data)
+	}
+
+	if code, _, _ = runCmd(t, "web", server.URL+"/missing/"); code != 1 {
+		t.Errorf("expected exit code 1 without .DS_Store, got %d", code)
+	}
+	if interval, err := parseRate("30/m"); err != nil || interval != 2*time.Second {
+		t.Errorf("unexpected interval %v, %v", interval, err)
+	}
+	if _, err = parseRate("2/d"); err == nil {
+		t.Error("expected error for invalid rate")
+	}
+}
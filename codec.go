@@ -0,0 +1,75 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Codec decodes and encodes values of records of a structure ID, see RegisterCodec
+type Codec interface {
+	// Decode returns value of the record
+	Decode(r Record) (any, error)
+	// Encode returns type and data of record with the value. Values of JSON import are decoded
+	// by encoding/json, so they are maps, slices, strings, float64 and bool.
+	Encode(v any) (typ string, data []byte, err error)
+}
+
+// CodecFuncs is Codec of functions, nil EncodeFunc makes values read-only
+type CodecFuncs struct {
+	DecodeFunc func(r Record) (any, error)
+	EncodeFunc func(v any) (string, []byte, error)
+}
+
+// ErrNotEncodable is returned by CodecFuncs without EncodeFunc
+var ErrNotEncodable = errors.New("value can't be encoded")
+
+// Decode calls DecodeFunc
+func (c CodecFuncs) Decode(r Record) (any, error) {
+	return c.DecodeFunc(r)
+}
+
+// Encode calls EncodeFunc
+func (c CodecFuncs) Encode(v any) (string, []byte, error) {
+	if c.EncodeFunc == nil {
+		return "", nil, ErrNotEncodable
+	}
+	return c.EncodeFunc(v)
+}
+
+// codecs are registered codecs by structure IDs
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: make(map[string]Codec)}
+
+// RegisterCodec registers codec of the structure ID, it replaces the previously registered one.
+// Record.Value, JSON export and import of values and the dsstore command use registered codecs,
+// and structure IDs of codecs are known to IsKnownCode.
+// It panics when the code doesn't have 4 bytes or the codec is nil.
+func RegisterCodec(code string, codec Codec) {
+	if len(code) != 4 {
+		panic(fmt.Sprintf("dsstore: code %q of codec must have 4 bytes", code))
+	}
+	if codec == nil {
+		panic("dsstore: codec of " + code + " is nil")
+	}
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[code] = codec
+}
+
+// UnregisterCodec removes codec of the structure ID
+func UnregisterCodec(code string) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	delete(codecs.m, code)
+}
+
+// LookupCodec returns codec registered for the structure ID
+func LookupCodec(code string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	codec, ok := codecs.m[code]
+	return codec, ok
+}
@@ -0,0 +1,81 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pointCodec decodes blobs of two big-endian int32 coordinates
+var pointCodec = CodecFuncs{
+	DecodeFunc: func(r Record) (any, error) {
+		if r.Type != "blob" || len(r.Data) != 8 {
+			return nil, errors.New("expected blob of 8 bytes")
+		}
+		return map[string]any{"x": int32(binary.BigEndian.Uint32(r.Data)), "y": int32(binary.BigEndian.Uint32(r.Data[4:]))}, nil
+	},
+	EncodeFunc: func(v any) (string, []byte, error) {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", nil, errors.New("expected object")
+		}
+		x, _ := m["x"].(float64)
+		y, _ := m["y"].(float64)
+		return "blob", binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(int32(x))), uint32(int32(y))), nil
+	},
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("zpnt", pointCodec)
+	t.Cleanup(func() { UnregisterCodec("zpnt") })
+	if !IsKnownCode("zpnt") {
+		t.Error("expected code of registered codec to be known")
+	}
+
+	r := Record{FileName: "a", Type: "blob", DataLen: 8, Data: []byte{0, 0, 0, 10, 0xff, 0xff, 0xff, 0xfe}}
+	r.SetCode("zpnt")
+	v, err := r.Value()
+	if m, ok := v.(map[string]any); err != nil || !ok || m["x"] != int32(10) || m["y"] != int32(-2) {
+		t.Errorf("unexpected value %v: %v", v, err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil || !strings.Contains(string(data), `"value":{"x":10,"y":-2}`) {
+		t.Errorf("expected value in JSON, got %s: %v", data, err)
+	}
+
+	// value of JSON written by hand is encoded by the codec
+	var decoded Record
+	if err = json.Unmarshal([]byte(`{"name":"a","code":"zpnt","value":{"x":10,"y":-2}}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !decoded.Equal(r) {
+		t.Errorf("expected %+v, got %+v", r, decoded)
+	}
+
+	RegisterCodec("zpnt", CodecFuncs{DecodeFunc: pointCodec.DecodeFunc})
+	err = json.Unmarshal([]byte(`{"name":"a","code":"zpnt","value":{"x":10,"y":-2}}`), &decoded)
+	if !errors.Is(err, ErrNotEncodable) {
+		t.Errorf("expected ErrNotEncodable, got %v", err)
+	}
+
+	UnregisterCodec("zpnt")
+	if v, err = r.Value(); err != nil || IsKnownCode("zpnt") {
+		t.Errorf("expected raw value of unregistered codec, got %v: %v", v, err)
+	}
+	if _, ok := v.([]byte); !ok {
+		t.Errorf("expected raw data, got %T", v)
+	}
+
+	for _, code := range []string{"abc", "abcde"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for code %q", code)
+				}
+			}()
+			RegisterCodec(code, pointCodec)
+		}()
+	}
+}
@@ -0,0 +1,112 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownCode is returned for records with unknown structure ID when UnknownCodeError policy is used
+var ErrUnknownCode = errors.New("unknown record code")
+
+// KnownCodes are structure IDs (record codes) of .DS_Store records with their descriptions
+var KnownCodes = map[string]string{
+	"BKGD": "background (legacy)",
+	"GRP0": "unknown, seen as ustr",
+	"ICVO": "icon view options (legacy)",
+	"Iloc": "icon location",
+	"LSVO": "list view options (legacy)",
+	"bwsp": "browser window properties",
+	"clip": "text clipping",
+	"cmmt": "Finder comment",
+	"dilc": "desktop icon location",
+	"dscl": "disclosure state in list view",
+	"extn": "file extension",
+	"fdsc": "disclosure state of sidebar",
+	"fwi0": "Finder window information",
+	"fwsw": "Finder window sidebar width",
+	"fwvh": "Finder window vertical height",
+	"icgo": "icon view options (unknown)",
+	"icsp": "icon view scroll position",
+	"icvo": "icon view options",
+	"icvp": "icon view properties",
+	"icvt": "icon view text size",
+	"info": "information window",
+	"logS": "logical size",
+	"lg1S": "logical size",
+	"lssp": "list view scroll position",
+	"lsvC": "list view columns",
+	"lsvo": "list view options",
+	"lsvp": "list view properties",
+	"lsvP": "list view properties",
+	"lsvt": "list view text size",
+	"modD": "modification date",
+	"moDD": "modification date",
+	"pBB0": "bookmark data",
+	"pBBk": "background image bookmark",
+	"ph1S": "physical size",
+	"phyS": "physical size",
+	"pict": "background image alias",
+	"ptbL": "trash put back location",
+	"ptbN": "trash put back name",
+	"vSrn": "version of store format",
+	"vstl": "view style",
+}
+
+// Code returns structure ID of the record (4-bytes code stored in Extra field)
+func (r Record) Code() string {
+	code := make([]byte, 4)
+	binary.BigEndian.PutUint32(code, r.Extra)
+	return string(code)
+}
+
+// SetCode sets structure ID of the record (4-bytes code stored in Extra field)
+func (r *Record) SetCode(code string) {
+	b := make([]byte, 4)
+	copy(b, code)
+	r.Extra = binary.BigEndian.Uint32(b)
+}
+
+// IsKnownCode reports whether the structure ID is in KnownCodes table or has registered codec
+func IsKnownCode(code string) bool {
+	if _, ok := KnownCodes[code]; ok {
+		return true
+	}
+	_, ok := LookupCodec(code)
+	return ok
+}
+
+// UnknownCodePolicy defines handling of records with structure IDs which are not in KnownCodes
+type UnknownCodePolicy int
+
+// Policies of unknown structure IDs
+const (
+	UnknownCodeKeep     UnknownCodePolicy = iota // keep records
+	UnknownCodeDrop                              // drop records
+	UnknownCodeError                             // fail with ErrUnknownCode
+	UnknownCodeCallback                          // ask ReadOptions.OnUnknownCode
+)
+
+// acceptCode applies unknown structure ID policy to the record
+func (s *Store) acceptCode(r Record) (bool, error) {
+	if s.opts.UnknownCodes == UnknownCodeKeep {
+		return true, nil
+	}
+	code := r.Code()
+	if IsKnownCode(code) {
+		return true, nil
+	}
+	switch s.opts.UnknownCodes {
+	case UnknownCodeDrop:
+		return false, nil
+	case UnknownCodeError:
+		return false, fmt.Errorf("%w %q of %q", ErrUnknownCode, code, r.FileName)
+	case UnknownCodeCallback:
+		if s.opts.OnUnknownCode == nil {
+			return true, nil
+		}
+		return s.opts.OnUnknownCode(r)
+	default:
+		return true, nil
+	}
+}
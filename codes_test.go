@@ -0,0 +1,63 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRecordCode(t *testing.T) {
+	var r Record
+	r.SetCode("Iloc")
+	if r.Extra != 0x496c6f63 {
+		t.Errorf("unexpected extra %#x", r.Extra)
+	}
+	if r.Code() != "Iloc" {
+		t.Errorf("unexpected code %q", r.Code())
+	}
+	if !IsKnownCode("Iloc") || IsKnownCode("zzzz") {
+		t.Error("unexpected known codes")
+	}
+}
+
+func TestUnknownCodePolicy(t *testing.T) {
+	s := &Store{}
+	known := Record{FileName: "a", Type: "bool", Data: []byte{1}}
+	known.SetCode("dscl")
+	unknown := Record{FileName: "b", Type: "bool", Data: []byte{1}}
+	unknown.SetCode("zzzz")
+	s.Records = append(s.Records, known, unknown)
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name    string
+		opts    ReadOptions
+		records int
+		err     error
+	}{
+		{"Keep", ReadOptions{UnknownCodes: UnknownCodeKeep}, 2, nil},
+		{"Drop", ReadOptions{UnknownCodes: UnknownCodeDrop}, 1, nil},
+		{"Error", ReadOptions{UnknownCodes: UnknownCodeError}, 1, ErrUnknownCode},
+		{"CallbackNil", ReadOptions{UnknownCodes: UnknownCodeCallback}, 2, nil},
+		{"CallbackDrop", ReadOptions{
+			UnknownCodes:  UnknownCodeCallback,
+			OnUnknownCode: func(r Record) (bool, error) { return r.Code() != "zzzz", nil },
+		}, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s2 Store
+			err := s2.ReadWithOptions(bytes.NewReader(data), tt.opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if len(s2.Records) != tt.records {
+				t.Errorf("expected %d records, got %d", tt.records, len(s2.Records))
+			}
+		})
+	}
+}
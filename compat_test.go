@@ -0,0 +1,258 @@
+//go:build compat
+
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"unicode/utf16"
+)
+
+// Compatibility tests round-trip stores through reference implementations and diff the results.
+// They are built with the compat build tag and skipped when the tools aren't available:
+//
+//	pip install ds_store
+//	go test -tags compat -run Compat .
+
+// compatScript converts stores to JSON records and back with Python ds_store package
+var compatScript = filepath.Join(".", "testdata", "compat", "ds_store_json.py")
+
+// compatRecord is a record in the form of the script: value is bool, number, text of ustr or hex of data
+type compatRecord struct {
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// newCompatRecord returns the record in the form of the script
+func newCompatRecord(r Record) compatRecord {
+	c := compatRecord{Name: r.FileName, Code: r.Code(), Type: r.Type}
+	switch r.Type {
+	case "bool":
+		c.Value = r.Data[0] != 0
+	case "long", "shor":
+		c.Value = json.Number(strconv.FormatUint(uint64(binary.BigEndian.Uint32(r.Data)), 10))
+	case "comp", "dutc":
+		c.Value = json.Number(strconv.FormatUint(binary.BigEndian.Uint64(r.Data), 10))
+	case "ustr":
+		c.Value, _ = r.Text()
+	default:
+		c.Value = hex.EncodeToString(r.Data)
+	}
+	return c
+}
+
+// record returns the record of the form of the script
+func (c compatRecord) record() (Record, error) {
+	r := Record{FileName: c.Name, Type: c.Type}
+	r.SetCode(c.Code)
+	switch v := c.Value.(type) {
+	case bool:
+		r.Data = []byte{0}
+		if v {
+			r.Data[0] = 1
+		}
+	case json.Number:
+		n, err := strconv.ParseUint(string(v), 10, 64)
+		if err != nil {
+			return r, err
+		}
+		if size := typeSizes[c.Type]; size == 4 {
+			r.Data = binary.BigEndian.AppendUint32(nil, uint32(n))
+		} else {
+			r.Data = binary.BigEndian.AppendUint64(nil, n)
+		}
+	case string:
+		if c.Type == "ustr" {
+			units := utf16.Encode([]rune(v))
+			r.DataLen = uint32(len(units))
+			for _, u := range units {
+				r.Data = binary.BigEndian.AppendUint16(r.Data, u)
+			}
+			return r, nil
+		}
+		var err error
+		if r.Data, err = hex.DecodeString(v); err != nil {
+			return r, err
+		}
+		if c.Type == "blob" {
+			r.DataLen = uint32(len(r.Data))
+		}
+	default:
+		return r, fmt.Errorf("unexpected value %T of %q %s", c.Value, c.Name, c.Code)
+	}
+	return r, nil
+}
+
+// compatRecords returns records in the form of the script sorted like B-tree
+func compatRecords(records []Record) []compatRecord {
+	records = slices.Clone(records)
+	slices.SortStableFunc(records, compareRecords)
+	compat := make([]compatRecord, len(records))
+	for i, r := range records {
+		compat[i] = newCompatRecord(r)
+	}
+	return compat
+}
+
+// python returns the interpreter with ds_store package, the test is skipped without it
+func python(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not found")
+	}
+	if err = exec.Command(path, "-c", "import ds_store").Run(); err != nil {
+		t.Skip("Python ds_store package is not installed")
+	}
+	return path
+}
+
+// compatStores returns stores of golden corpus and random stores
+func compatStores(t *testing.T) map[string]*Store {
+	t.Helper()
+	stores := make(map[string]*Store)
+	fixtures, err := LoadGoldenCorpus(goldenDir)
+	if err != nil {
+		t.Fatalf("LoadGoldenCorpus failed: %v", err)
+	}
+	for _, f := range fixtures {
+		s := &Store{}
+		if err = s.Read(bytes.NewReader(f.Data)); err != nil {
+			t.Fatalf("Read of %s failed: %v", f.Name, err)
+		}
+		stores[f.Name] = s
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := range 20 {
+		stores[fmt.Sprintf("random%d", i)] = GenerateRandomStore(rnd, GenerateOptions{})
+	}
+	return stores
+}
+
+func TestCompatPythonRead(t *testing.T) {
+	python := python(t)
+	for name, s := range compatStores(t) {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), StoreFileName)
+			if err := s.WriteFile(path, 0o644); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+			out, err := exec.Command(python, compatScript, "read", path).Output()
+			if err != nil {
+				t.Fatalf("ds_store failed to read the store: %v", err)
+			}
+			decoder := json.NewDecoder(bytes.NewReader(out))
+			decoder.UseNumber()
+			var got []compatRecord
+			if err = decoder.Decode(&got); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			var records []Record
+			for _, c := range got {
+				r, err := c.record()
+				if err != nil {
+					t.Fatalf("record of ds_store: %v", err)
+				}
+				records = append(records, r)
+			}
+			if expected := compatRecords(s.Records); !reflect.DeepEqual(compatRecords(records), expected) {
+				t.Errorf("ds_store read different records:\n%v\nexpected:\n%v", compatRecords(records), expected)
+			}
+		})
+	}
+}
+
+func TestCompatPythonWrite(t *testing.T) {
+	python := python(t)
+	for name, s := range compatStores(t) {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), StoreFileName)
+			input, err := json.Marshal(compatRecords(s.Records))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			cmd := exec.Command(python, compatScript, "write", path)
+			cmd.Stdin = bytes.NewReader(input)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("ds_store failed to write the store: %v: %s", err, out)
+			}
+			var written Store
+			if err = written.ReadFile(path); err != nil {
+				t.Fatalf("ReadFile of store written by ds_store failed: %v", err)
+			}
+			if err = written.ValidateFreeList(); err != nil {
+				t.Errorf("store written by ds_store has invalid free list: %v", err)
+			}
+			if got, expected := compatRecords(written.Records), compatRecords(s.Records); !reflect.DeepEqual(got, expected) {
+				t.Errorf("ds_store wrote different records:\n%v\nexpected:\n%v", got, expected)
+			}
+		})
+	}
+}
+
+func TestCompatPlutil(t *testing.T) {
+	plutil, err := exec.LookPath("plutil")
+	if err != nil {
+		t.Skip("plutil is not found")
+	}
+	var blobs []Record
+	for _, s := range compatStores(t) {
+		for _, r := range s.Records {
+			if r.Type == "blob" && bytes.HasPrefix(r.Data, bplistHeader) {
+				blobs = append(blobs, r)
+			}
+		}
+	}
+	settings := FolderSettings{View: "icon view", Window: &WindowSettings{ShowToolbar: true},
+		IconView: &IconViewSettings{IconSize: 64, TextSize: 12, ArrangeBy: "none"}}
+	records, err := settings.Records()
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	for _, r := range records {
+		if r.Type == "blob" {
+			blobs = append(blobs, r)
+		}
+	}
+	dir := t.TempDir()
+	for i, r := range blobs {
+		// property list is converted to XML and back to binary by plutil and decoded again
+		bin, xml := filepath.Join(dir, fmt.Sprintf("%d.plist", i)), filepath.Join(dir, fmt.Sprintf("%d.xml", i))
+		if err = os.WriteFile(bin, r.Data, 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		for _, args := range [][]string{{"-lint", bin}, {"-convert", "xml1", "-o", xml, bin}, {"-convert", "binary1", "-o", bin, xml}} {
+			if out, err := exec.Command(plutil, args...).CombinedOutput(); err != nil {
+				t.Fatalf("plutil %v of %q %s failed: %v: %s", args, r.FileName, r.Code(), err, out)
+			}
+		}
+		data, err := os.ReadFile(bin)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		expected, err := decodePlist(r.Data)
+		if err != nil {
+			t.Fatalf("decodePlist of %q %s failed: %v", r.FileName, r.Code(), err)
+		}
+		got, err := decodePlist(data)
+		if err != nil {
+			t.Fatalf("decodePlist of plutil output of %q %s failed: %v", r.FileName, r.Code(), err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("plutil changed property list of %q %s:\n%v\nexpected:\n%v", r.FileName, r.Code(), got, expected)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package dsstore
+
+import (
+	"context"
+	"io"
+)
+
+// writeChunk is size of data written between checks of context on WriteContext
+const writeChunk = 64 << 10
+
+// ctxErr returns error of the context of the current reading
+func (s *Store) ctxErr() error {
+	if s.ctx == nil {
+		return nil
+	}
+	return s.ctx.Err()
+}
+
+// ctxReader stops reading when the context is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// ReadContext reads .DS_Store from io.Reader. Context is checked between reads and B-tree nodes,
+// so reading stops with the context error when the context is done.
+func (s *Store) ReadContext(ctx context.Context, r io.Reader) error {
+	return s.ReadContextWithOptions(ctx, r, ReadOptions{})
+}
+
+// ReadContextWithOptions reads .DS_Store from io.Reader using options until the context is done
+func (s *Store) ReadContextWithOptions(ctx context.Context, r io.Reader, opts ReadOptions) error {
+	s.ctx = ctx
+	defer func() {
+		s.ctx = nil
+	}()
+	return s.ReadWithOptions(ctxReader{ctx, r}, opts)
+}
+
+// WriteContext writes .DS_Store to io.Writer. Context is checked between encoded B-tree nodes
+// and written chunks, so writing stops with the context error when the context is done.
+func (s *Store) WriteContext(ctx context.Context, w io.Writer) error {
+	return s.WriteContextWithOptions(ctx, w, WriteOptions{})
+}
+
+// WriteContextWithOptions writes .DS_Store to io.Writer using options until the context is done
+func (s *Store) WriteContextWithOptions(ctx context.Context, w io.Writer, opts WriteOptions) error {
+	e := getEncoder()
+	defer putEncoder(e)
+	e.ctx = ctx
+	defer func() {
+		e.ctx = nil
+	}()
+	fileData, err := e.EncodeWithOptions(s, opts)
+	if err != nil {
+		return err
+	}
+	for len(fileData) > 0 {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		n, err := w.Write(fileData[:min(len(fileData), writeChunk)])
+		if err != nil {
+			return err
+		}
+		fileData = fileData[n:]
+		e.progress.BytesWritten += int64(n)
+		e.reportProgress()
+	}
+	return nil
+}
+
+// ctxErr returns error of the context of the current encoding
+func (e *Encoder) ctxErr() error {
+	if e.ctx == nil {
+		return nil
+	}
+	return e.ctx.Err()
+}
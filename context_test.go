@@ -0,0 +1,92 @@
+package dsstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cancelingReader cancels the context when all data is read
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (cr cancelingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if err == io.EOF {
+		cr.cancel()
+	}
+	return n, err
+}
+
+func TestReadContext(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var s Store
+	if err = s.ReadContext(context.Background(), bytes.NewReader(fileData)); err != nil {
+		t.Fatalf("ReadContext failed: %v", err)
+	}
+	if len(s.Records) != 6 {
+		t.Errorf("expected 6 records, got %d", len(s.Records))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = s.ReadContext(ctx, bytes.NewReader(fileData)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// context is done after the file is read, B-tree is not parsed even in best-effort mode
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	err = s.ReadContextWithOptions(ctx, cancelingReader{bytes.NewReader(fileData), cancel}, ReadOptions{BestEffort: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWriteContext(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := s.WriteContext(context.Background(), buf); err != nil {
+		t.Fatalf("WriteContext failed: %v", err)
+	}
+	expected := new(bytes.Buffer)
+	if err := s.Write(expected); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected.Bytes()) {
+		t.Error("expected the same data as Write")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.WriteContext(ctx, io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProcessAllContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	testdata := filepath.Join(".", "testdata", "00.DS_Store")
+	results := ProcessAllContext(ctx, []string{testdata, testdata}, 1, ReadOptions{}, func(string, *Store) error {
+		t.Error("expected no files to be processed")
+		return nil
+	})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", r.Err)
+		}
+	}
+}
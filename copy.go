@@ -0,0 +1,69 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRecordConflict is returned by CopyRecordsWithPolicy with MergeError policy for records of existing keys
+var ErrRecordConflict = errors.New("record already exists")
+
+// MergePolicy defines handling of copied records with file names and structure IDs of existing records.
+// File names are compared case-insensitively like Finder does.
+type MergePolicy int
+
+// Merge policies
+const (
+	MergeReplace MergePolicy = iota // copied record replaces the existing one
+	MergeKeep                       // existing record is kept
+	MergeError                      // copying fails with ErrRecordConflict
+)
+
+// CopyRecords copies records of src to dst renaming their files with the mapping, like records of the app bundle
+// of a DMG renamed per release. Records are copied when the mapping returns true, nil mapping copies all records
+// keeping their names. Copied records replace records of the same keys, see CopyRecordsWithPolicy.
+func CopyRecords(dst, src *Store, mapping func(filename string) (string, bool)) error {
+	return CopyRecordsWithPolicy(dst, src, mapping, MergeReplace)
+}
+
+// CopyRecordsWithPolicy is CopyRecords handling collisions with existing records and between copied
+// records using the policy. Mapped names must be valid, see ValidateFileName. Records of dst are not changed
+// on errors, copied records have own copies of data.
+func CopyRecordsWithPolicy(dst, src *Store, mapping func(filename string) (string, bool), policy MergePolicy) error {
+	records := append([]Record(nil), dst.Records...)
+	indexes := make(map[recordKey]int, len(records))
+	for i, r := range records {
+		indexes[recordKey{strings.ToLower(r.FileName), r.Code()}] = i
+	}
+	for _, r := range src.Records {
+		if mapping != nil {
+			name, ok := mapping(r.FileName)
+			if !ok {
+				continue
+			}
+			if err := ValidateFileName(name); err != nil {
+				return fmt.Errorf("mapping of %q: %w", r.FileName, err)
+			}
+			r.FileName = name
+		}
+		r = r.Materialize()
+		key := recordKey{strings.ToLower(r.FileName), r.Code()}
+		i, exists := indexes[key]
+		switch {
+		case !exists:
+			indexes[key] = len(records)
+			records = append(records, r)
+		case policy == MergeReplace:
+			records[i] = r
+		case policy == MergeKeep:
+		case policy == MergeError:
+			return fmt.Errorf("%w: %q %s", ErrRecordConflict, r.FileName, r.Code())
+		default:
+			return fmt.Errorf("unknown merge policy %d", policy)
+		}
+	}
+	dst.Records = records
+	dst.mods++
+	return nil
+}
@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCopyRecords(t *testing.T) {
+	record := func(name, code string, v byte) Record {
+		r := Record{FileName: name, Type: "bool", Data: []byte{v}}
+		r.SetCode(code)
+		return r
+	}
+	src := &Store{Records: []Record{
+		record("App 1.0.app", "Iloc", 1),
+		record("App 1.0.app", "dscl", 1),
+		record("Applications", "Iloc", 1),
+		record("README", "Iloc", 1),
+	}}
+	rename := func(name string) (string, bool) {
+		if name == "README" {
+			return "", false
+		}
+		return strings.ReplaceAll(name, "1.0", "2.0"), true
+	}
+	dst := &Store{Records: []Record{record("app 2.0.app", "Iloc", 0), record(".", "vstl", 0)}}
+	if err := CopyRecordsWithPolicy(dst, src, rename, MergeError); !errors.Is(err, ErrRecordConflict) {
+		t.Errorf("expected ErrRecordConflict, got %v", err)
+	}
+	if len(dst.Records) != 2 {
+		t.Errorf("expected records not to be changed on error, got %d", len(dst.Records))
+	}
+
+	if err := CopyRecordsWithPolicy(dst, src, rename, MergeKeep); err != nil {
+		t.Fatalf("CopyRecords failed: %v", err)
+	}
+	if len(dst.Records) != 4 || dst.Records[0].Data[0] != 0 || dst.Records[2].FileName != "App 2.0.app" {
+		t.Errorf("expected existing record to be kept, got %v", dst.Records)
+	}
+
+	if err := CopyRecords(dst, src, rename); err != nil {
+		t.Fatalf("CopyRecords failed: %v", err)
+	}
+	if len(dst.Records) != 4 || dst.Records[0].FileName != "App 2.0.app" || dst.Records[0].Data[0] != 1 {
+		t.Errorf("expected existing record to be replaced, got %v", dst.Records)
+	}
+	src.Records[0].Data[0] = 2
+	if dst.Records[0].Data[0] != 1 {
+		t.Error("expected copied records to have own data")
+	}
+
+	err := CopyRecords(dst, src, func(name string) (string, bool) { return "a/" + name, true })
+	if !errors.Is(err, ErrInvalidFileName) {
+		t.Errorf("expected ErrInvalidFileName, got %v", err)
+	}
+	all := &Store{}
+	if err = CopyRecords(all, src, nil); err != nil || len(all.Records) != len(src.Records) {
+		t.Errorf("expected all records to be copied, got %d: %v", len(all.Records), err)
+	}
+}
@@ -0,0 +1,181 @@
+package dsstore
+
+import (
+	"maps"
+	"os"
+	"sync"
+)
+
+// CorpusOther is the key of rare structure IDs merged by CorpusStats.Anonymize
+const CorpusOther = "other"
+
+// CorpusStats are distributions of many stores, like thousands of stores collected by researchers,
+// so decoders of the most frequent structure IDs can be built first. Numeric histograms count values
+// by lower bounds of their buckets. It is safe for concurrent use, so Add can be the ProcessAll callback.
+type CorpusStats struct {
+	mu         sync.Mutex
+	Stores     int            `json:"stores"`
+	Failed     int            `json:"failed"`
+	Codes      map[string]int `json:"codes"`      // count of records by structure IDs
+	CodeStores map[string]int `json:"codeStores"` // count of stores with records of structure IDs
+	Types      map[string]int `json:"types"`      // count of records by types
+	CodeTypes  map[string]int `json:"codeTypes"`  // count of records by structure IDs and types, like "Iloc/blob"
+	Records    map[int64]int  `json:"records"`    // count of records of stores by powers of 10
+	Sizes      map[int64]int  `json:"sizes"`      // file size in bytes by powers of 2
+	BlobSizes  map[int64]int  `json:"blobSizes"`  // data size of blobs in bytes by powers of 2
+	Depths     map[int64]int  `json:"depths"`     // depth of B-tree
+	Nodes      map[int64]int  `json:"nodes"`      // count of B-tree nodes by powers of 10
+	FreeRatios map[int64]int  `json:"freeRatios"` // free space in percents by tens
+	FreeBlocks map[int64]int  `json:"freeBlocks"` // size of free blocks in bytes by powers of 2
+}
+
+// NewCorpusStats returns empty statistics
+func NewCorpusStats() *CorpusStats {
+	return &CorpusStats{Codes: map[string]int{}, CodeStores: map[string]int{}, Types: map[string]int{},
+		CodeTypes: map[string]int{}, Records: map[int64]int{}, Sizes: map[int64]int{}, BlobSizes: map[int64]int{},
+		Depths: map[int64]int{}, Nodes: map[int64]int{}, FreeRatios: map[int64]int{}, FreeBlocks: map[int64]int{}}
+}
+
+// decade returns lower bound of the power of 10 bucket of n
+func decade(n int64) int64 {
+	bound := int64(1)
+	for n >= bound*10 {
+		bound *= 10
+	}
+	return min(bound, n)
+}
+
+// powerOf2 returns lower bound of the power of 2 bucket of n
+func powerOf2(n int64) int64 {
+	bound := int64(1)
+	for n >= bound*2 {
+		bound *= 2
+	}
+	return min(bound, n)
+}
+
+// Add adds the store read from the path. The file size is known for stores read with ReadOptions.Fidelity
+// and for local files. Add has the signature of ProcessAll callbacks, it always returns nil.
+func (c *CorpusStats) Add(path string, s *Store) error {
+	size := int64(-1)
+	if s.layout != nil {
+		size = int64(len(s.layout.fileData))
+	} else if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	st := s.Stats()
+	g, geometry := s.Geometry()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Stores++
+	for code, n := range st.Codes {
+		c.Codes[code] += n
+		c.CodeStores[code]++
+	}
+	for typ, n := range st.Types {
+		c.Types[typ] += n
+	}
+	for _, r := range s.Records {
+		c.CodeTypes[r.Code()+"/"+r.Type]++
+		if r.Type == "blob" {
+			c.BlobSizes[powerOf2(int64(len(r.Data)))]++
+		}
+	}
+	c.Records[decade(int64(st.Records))]++
+	if size >= 0 {
+		c.Sizes[powerOf2(size)]++
+	}
+	if geometry {
+		c.Depths[int64(st.Depth)]++
+		c.Nodes[decade(int64(g.Nodes))]++
+		c.FreeRatios[int64(st.FreeRatio*10)*10]++
+		for i, list := range s.alloc.freeLists {
+			if len(list) != 0 {
+				c.FreeBlocks[int64(1)<<i] += len(list)
+			}
+		}
+	}
+	return nil
+}
+
+// AddResults counts failures of ProcessAll results, successfully processed stores are added by Add
+func (c *CorpusStats) AddResults(results []Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, result := range results {
+		if result.Err != nil {
+			c.Failed++
+		}
+	}
+}
+
+// Merge adds statistics collected separately, like by other researchers and decoded from JSON
+func (c *CorpusStats) Merge(other *CorpusStats) {
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Stores += other.Stores
+	c.Failed += other.Failed
+	for _, pair := range []struct {
+		to   *map[string]int
+		from map[string]int
+	}{
+		{&c.Codes, other.Codes}, {&c.CodeStores, other.CodeStores}, {&c.Types, other.Types}, {&c.CodeTypes, other.CodeTypes},
+	} {
+		*pair.to = mergeCounts(*pair.to, pair.from)
+	}
+	for _, pair := range []struct {
+		to   *map[int64]int
+		from map[int64]int
+	}{
+		{&c.Records, other.Records}, {&c.Sizes, other.Sizes}, {&c.BlobSizes, other.BlobSizes},
+		{&c.Depths, other.Depths}, {&c.Nodes, other.Nodes}, {&c.FreeRatios, other.FreeRatios}, {&c.FreeBlocks, other.FreeBlocks},
+	} {
+		*pair.to = mergeCounts(*pair.to, pair.from)
+	}
+}
+
+// mergeCounts adds counts of the histogram, nil histogram is created
+func mergeCounts[K comparable](to, from map[K]int) map[K]int {
+	if to == nil {
+		to = make(map[K]int, len(from))
+	}
+	for k, n := range from {
+		to[k] += n
+	}
+	return to
+}
+
+// Anonymize returns copy of the statistics safe to publish: structure IDs found in less than minStores stores,
+// which may identify an application or a person, are merged into CorpusOther, its count of stores is the sum
+// of counts of merged structure IDs. Statistics have no names and paths.
+func (c *CorpusStats) Anonymize(minStores int) *CorpusStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a := &CorpusStats{Stores: c.Stores, Failed: c.Failed, Codes: map[string]int{}, CodeStores: map[string]int{},
+		Types: maps.Clone(c.Types), CodeTypes: map[string]int{}, Records: maps.Clone(c.Records), Sizes: maps.Clone(c.Sizes),
+		BlobSizes: maps.Clone(c.BlobSizes), Depths: maps.Clone(c.Depths), Nodes: maps.Clone(c.Nodes),
+		FreeRatios: maps.Clone(c.FreeRatios), FreeBlocks: maps.Clone(c.FreeBlocks)}
+	rare := func(code string) bool {
+		return c.CodeStores[code] < minStores
+	}
+	for code, n := range c.Codes {
+		if rare(code) {
+			a.Codes[CorpusOther] += n
+			a.CodeStores[CorpusOther] += c.CodeStores[code]
+		} else {
+			a.Codes[code] = n
+			a.CodeStores[code] = c.CodeStores[code]
+		}
+	}
+	for codeType, n := range c.CodeTypes {
+		// structure IDs have 4 bytes
+		if rare(codeType[:4]) {
+			a.CodeTypes[CorpusOther+codeType[4:]] += n
+		} else {
+			a.CodeTypes[codeType] = n
+		}
+	}
+	return a
+}
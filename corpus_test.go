@@ -0,0 +1,44 @@
+package dsstore
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestCorpusStats(t *testing.T) {
+	c := NewCorpusStats()
+	name := filepath.Join("testdata", "00.DS_Store")
+	c.AddResults(ProcessAll([]string{name, name, filepath.Join("testdata", "absent")}, 0, c.Add))
+	if c.Stores != 2 || c.Failed != 1 || c.Codes["Iloc"] != 4 || c.CodeStores["Iloc"] != 2 || c.CodeTypes["vSrn/long"] != 2 ||
+		c.Records[1] != 2 || c.Depths[1] != 2 || len(c.Sizes) != 1 || len(c.BlobSizes) == 0 {
+		t.Errorf("unexpected statistics %+v", c)
+	}
+
+	s := &Store{Records: []Record{TextRecord("a", "XyZ1", "rare")}}
+	_ = c.Add("", s)
+	a := c.Anonymize(2)
+	if a.Codes["XyZ1"] != 0 || a.Codes[CorpusOther] != 1 || a.CodeStores[CorpusOther] != 1 || a.CodeTypes[CorpusOther+"/ustr"] != 1 ||
+		a.Codes["Iloc"] != 4 || a.Stores != 3 || c.Codes["XyZ1"] != 1 {
+		t.Errorf("unexpected anonymized statistics %+v", a)
+	}
+
+	// statistics of other researchers are merged from JSON
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded CorpusStats
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	merged := &CorpusStats{}
+	merged.Merge(&decoded)
+	merged.Merge(&decoded)
+	if merged.Stores != 6 || merged.Failed != 2 || merged.Codes["Iloc"] != 8 || merged.Records[1] != 6 {
+		t.Errorf("unexpected merged statistics %+v", merged)
+	}
+	if decade(0) != 0 || decade(6) != 1 || decade(250) != 100 || powerOf2(5000) != 4096 {
+		t.Error("unexpected buckets")
+	}
+}
@@ -0,0 +1,96 @@
+package dsstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// modulePath is the import path of the module
+const modulePath = "github.com/strongo/dsstore"
+
+// Evidence is a store with chain-of-custody metadata of its acquisition, so findings of exported stores
+// and reports can be traced back to the exact source bytes. It is encoded as JSON with the store.
+type Evidence struct {
+	Source   string    `json:"source"`   // path or URL the bytes were acquired from
+	SHA256   string    `json:"sha256"`   // hex SHA-256 of the original bytes
+	Size     int64     `json:"size"`     // count of the original bytes
+	Acquired time.Time `json:"acquired"` // time of the acquisition
+	Tool     string    `json:"tool"`     // module path and version of the parser, like "github.com/strongo/dsstore v1.2.0"
+	Store    *Store    `json:"store"`    // parsed store, it may be partial or nil when the bytes can't be read
+}
+
+// ErrEvidenceMismatch is returned by Evidence.Verify for bytes which differ from the acquired ones
+var ErrEvidenceMismatch = errors.New("bytes don't match SHA-256 of the evidence")
+
+// toolVersion returns module path and version of the library from the build information of the binary
+var toolVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return modulePath
+	}
+	version := info.Main.Version
+	if info.Main.Path != modulePath {
+		version = ""
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+			}
+		}
+	}
+	if version == "" {
+		return modulePath
+	}
+	return modulePath + " " + version
+})
+
+// NewEvidence parses the bytes acquired from the source using options and records their hash,
+// size, time of the call and version of the library. The evidence is returned with read error too,
+// so failures are traced as well; its store is nil when nothing is read.
+func NewEvidence(source string, data []byte, opts ReadOptions) (*Evidence, error) {
+	sum := sha256.Sum256(data)
+	e := &Evidence{Source: source, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data)),
+		Acquired: time.Now().UTC(), Tool: toolVersion()}
+	s := &Store{}
+	err := s.ReadWithOptions(bytes.NewReader(data), opts)
+	if err == nil || len(s.Records) != 0 {
+		e.Store = s
+	}
+	if err != nil {
+		return e, fmt.Errorf("%s: %w", source, err)
+	}
+	return e, nil
+}
+
+// AcquireFile reads the file as evidence, see NewEvidence
+func AcquireFile(name string, opts ReadOptions) (*Evidence, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewEvidence(name, data, opts)
+}
+
+// Verify checks that the bytes are the acquired ones
+func (e *Evidence) Verify(data []byte) error {
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != e.SHA256 || int64(len(data)) != e.Size {
+		return ErrEvidenceMismatch
+	}
+	return nil
+}
+
+// fileHash returns hex SHA-256 of the bytes of the store read with ReadOptions.Fidelity, empty for other stores
+func fileHash(s *Store) string {
+	if s.layout == nil {
+		return ""
+	}
+	sum := sha256.Sum256(s.layout.fileData)
+	return hex.EncodeToString(sum[:])
+}
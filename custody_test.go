@@ -0,0 +1,52 @@
+package dsstore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEvidence(t *testing.T) {
+	name := filepath.Join("testdata", "00.DS_Store")
+	e, err := AcquireFile(name, ReadOptions{})
+	if err != nil {
+		t.Fatalf("AcquireFile failed: %v", err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Source != name || len(e.SHA256) != 64 || e.Size != int64(len(data)) || e.Acquired.IsZero() ||
+		!strings.HasPrefix(e.Tool, modulePath) || e.Store == nil || len(e.Store.Records) != 6 {
+		t.Errorf("unexpected evidence %+v", e)
+	}
+	if err = e.Verify(data); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+	data[len(data)-1] ^= 1
+	if err = e.Verify(data); !errors.Is(err, ErrEvidenceMismatch) {
+		t.Errorf("expected mismatch of changed bytes, got %v", err)
+	}
+
+	encoded, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Evidence
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.SHA256 != e.SHA256 || !decoded.Acquired.Equal(e.Acquired) || !slices.EqualFunc(decoded.Store.Records, e.Store.Records, Record.Equal) {
+		t.Errorf("evidence changed by JSON:\n%+v\n%+v", decoded, e)
+	}
+
+	// failures are traced too
+	e, err = NewEvidence("garbage", []byte("garbage"), ReadOptions{})
+	if err == nil || e == nil || e.Store != nil || e.Size != 7 {
+		t.Errorf("unexpected evidence of garbage %+v: %v", e, err)
+	}
+}
@@ -0,0 +1,78 @@
+package dsstore
+
+import (
+	"slices"
+)
+
+// ChangeKind is kind of record change
+type ChangeKind int
+
+// Kinds of record changes
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+// String returns name of the change kind
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is change of record between stores. Records are identified by file name and structure ID.
+type Change struct {
+	Kind ChangeKind
+	Old  Record // record of the old store, zero for added record
+	New  Record // record of the new store, zero for removed record
+}
+
+// Diff returns changes of records from the old store to the new one, sorted like records are written.
+// When stores have records with the same file name and structure ID, they are matched in order.
+func Diff(old, new *Store) []Change {
+	oldRecords := make(map[recordKey][]Record, len(old.Records))
+	for _, r := range old.Records {
+		key := recordKey{r.FileName, r.Code()}
+		oldRecords[key] = append(oldRecords[key], r)
+	}
+	var changes []Change
+	for _, r := range new.Records {
+		key := recordKey{r.FileName, r.Code()}
+		matched := oldRecords[key]
+		if len(matched) == 0 {
+			changes = append(changes, Change{Kind: ChangeAdded, New: r})
+			continue
+		}
+		oldRecords[key] = matched[1:]
+		if !matched[0].Equal(r) {
+			changes = append(changes, Change{Kind: ChangeModified, Old: matched[0], New: r})
+		}
+	}
+	for _, r := range old.Records {
+		key := recordKey{r.FileName, r.Code()}
+		if matched := oldRecords[key]; len(matched) > 0 {
+			changes = append(changes, Change{Kind: ChangeRemoved, Old: matched[0]})
+			oldRecords[key] = matched[1:]
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return compareRecords(a.record(), b.record())
+	})
+	return changes
+}
+
+// record returns the new record of the change, or the old one of removed record
+func (c Change) record() Record {
+	if c.Kind == ChangeRemoved {
+		return c.Old
+	}
+	return c.New
+}
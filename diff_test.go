@@ -0,0 +1,33 @@
+package dsstore
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	rec := func(name, code string, data byte) Record {
+		r := Record{FileName: name, Type: "long", Data: []byte{0, 0, 0, data}}
+		r.SetCode(code)
+		return r
+	}
+	old := &Store{Records: []Record{rec("b", "Iloc", 1), rec("a", "Iloc", 1), rec("c", "Iloc", 1), rec("c", "Iloc", 2)}}
+	new := &Store{Records: []Record{rec("a", "Iloc", 2), rec("b", "Iloc", 1), rec("a", "modD", 1), rec("c", "Iloc", 1)}}
+	changes := Diff(old, new)
+	expected := []Change{
+		{Kind: ChangeModified, Old: rec("a", "Iloc", 1), New: rec("a", "Iloc", 2)},
+		{Kind: ChangeAdded, New: rec("a", "modD", 1)},
+		{Kind: ChangeRemoved, Old: rec("c", "Iloc", 2)},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), changes)
+	}
+	for i, c := range changes {
+		e := expected[i]
+		if c.Kind != e.Kind || !c.Old.Equal(e.Old) || !c.New.Equal(e.New) {
+			t.Errorf("change %d: expected %s %v, got %s %v", i, e.Kind, e.record(), c.Kind, c.record())
+		}
+	}
+	if changes := Diff(new, new); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
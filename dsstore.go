@@ -1,5 +1,34 @@
 package dsstore
 
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/strongo/dsstore/btree"
+)
+
+// ErrLimitExceeded is returned when .DS_Store data exceeds one of ReadOptions limits
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// ErrMalformedTree is returned when B-tree of .DS_Store has cycles or is too deep
+var ErrMalformedTree = btree.ErrMalformed
+
+// TruncatedError is returned by best-effort reading of truncated .DS_Store.
+// Records of fully present nodes are read anyway.
+type TruncatedError struct {
+	Offset  int64 // size of available data
+	Skipped int   // count of skipped nodes
+}
+
+// Error returns description of the truncation
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("file is truncated at %d bytes, %d nodes are skipped", e.Offset, e.Skipped)
+}
+
 // Record in .DS_Store
 type Record struct {
 	FileName string // file name
@@ -9,21 +38,79 @@ type Record struct {
 	Data     []byte // raw data
 }
 
-// Store of .DS_Store file
+// Equal reports whether records have the same file name, code, type and data
+func (r Record) Equal(o Record) bool {
+	return r.FileName == o.FileName && r.Extra == o.Extra && r.Type == o.Type &&
+		r.DataLen == o.DataLen && bytes.Equal(r.Data, o.Data)
+}
+
+// Materialize returns the record with own copy of data,
+// it detaches the record read with ReadOptions.ZeroCopy from the file data
+func (r Record) Materialize() Record {
+	r.Data = bytes.Clone(r.Data)
+	return r
+}
+
+// DataFrom sets the record data to length bytes read from src and DataLen according to the type,
+// records without type become blobs. Fixed size types must have data of their size.
+func (r *Record) DataFrom(src io.Reader, length int64) error {
+	if r.Type == "" {
+		r.Type = "blob"
+	}
+	if length < 0 || length > math.MaxUint32 {
+		return fmt.Errorf("invalid record data length %d", length)
+	}
+	dataLen := uint32(0)
+	if size, ok := typeSizes[r.Type]; ok {
+		if length != int64(size) {
+			return fmt.Errorf("data of type %q must have %d bytes, got %d", r.Type, size, length)
+		}
+	} else if r.Type == "ustr" {
+		if length%2 != 0 {
+			return fmt.Errorf("ustr data must have even number of bytes, got %d", length)
+		}
+		dataLen = uint32(length / 2)
+	} else {
+		dataLen = uint32(length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(src, data); err != nil {
+		return fmt.Errorf("record data: %w", err)
+	}
+	r.Data, r.DataLen = data, dataLen
+	return nil
+}
+
+// Store of .DS_Store file.
+// Store is not safe for concurrent modification, use SyncStore to share it between goroutines.
+// Records must not be added or removed while ranging All, see ErrConcurrentModification.
 type Store struct {
 	HeaderExtra []byte   // header extra data (unknown)
 	RootExtra   []byte   // root (bookkeeping) extra data (unknown)
 	DSDBExtra   []byte   // DSDB extra data (unknown)
 	Records     []Record // records
-}
 
-const headerMagic1 uint32 = 0x1
-const headerMagic2 uint32 = 0x42756431
+	opts     ReadOptions // options of the current reading
+	trailing []byte      // data after the allocated region
+	alloc    allocation  // allocation of blocks on reading
+	layout   *layout     // layout of the read file in fidelity mode
+	truncErr *TruncatedError
+	orderErr error           // error of read records out of canonical order
+	mods     uint64          // count of modifications of records by methods, checked by iteration of All
+	readErrs []error         // recovered errors of best-effort reading
+	progress Progress        // progress of the current reading
+	ctx      context.Context // context of the current reading, nil when it is not cancellable
+}
 
-func blockSize(offset uint32) uint32 {
-	return uint32(1) << (offset & uint32(0x1f))
+// Materialize detaches all records from the file data read with ReadOptions.ZeroCopy
+func (s *Store) Materialize() {
+	for i, r := range s.Records {
+		s.Records[i] = r.Materialize()
+	}
+	s.trailing = bytes.Clone(s.trailing)
 }
 
-func blockOffset(offset uint32) uint32 {
-	return offset & ^uint32(0x1f)
+// Trailing returns data found after the last allocated block of read .DS_Store
+func (s *Store) Trailing() []byte {
+	return s.trailing
 }
@@ -2,8 +2,11 @@ package dsstore
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -142,3 +145,39 @@ func TestMassive(t *testing.T) {
 		testMassiveFile(t, filepath.Join(testdata, f.Name()))
 	}
 }
+
+func TestRecordData(t *testing.T) {
+	data := bytes.Repeat([]byte{1, 2, 3}, 20000)
+	var r Record
+	r.FileName = "a"
+	r.SetCode("bwsp")
+	if err := r.DataFrom(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("DataFrom failed: %v", err)
+	}
+	if r.Type != "blob" || r.DataLen != uint32(len(data)) || r.Validate() != nil {
+		t.Errorf("expected valid blob of %d bytes, got %q of %d", len(data), r.Type, r.DataLen)
+	}
+	if !bytes.Equal(r.Data, data) {
+		t.Error("expected data to be the same")
+	}
+
+	r = Record{FileName: "a", Type: "ustr"}
+	err := r.DataFrom(strings.NewReader("\x00a\x00b"), 4)
+	if err != nil || r.DataLen != 2 {
+		t.Errorf("expected ustr of 2 characters, got %d: %v", r.DataLen, err)
+	}
+	if err = r.DataFrom(strings.NewReader("\x00a\x00"), 3); err == nil {
+		t.Error("expected odd length of ustr to fail")
+	}
+	r = Record{FileName: "a", Type: "long"}
+	if err = r.DataFrom(strings.NewReader("12345678"), 8); err == nil {
+		t.Error("expected wrong length of long to fail")
+	}
+	r = Record{FileName: "a"}
+	if err = r.DataFrom(strings.NewReader("short"), 10); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	if r.Data != nil {
+		t.Error("expected data to be unchanged on failure")
+	}
+}
@@ -0,0 +1,137 @@
+package dsstore
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// maxPooledBuffer is the maximal capacity of buffer returned to the pool,
+// bigger buffers are left to garbage collector
+const maxPooledBuffer = 1 << 20
+
+// bufferPool pools temporary buffers of reading and writing
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	b := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+// encoderPool pools encoders of Write functions
+var encoderPool = sync.Pool{New: func() any { return NewEncoder() }}
+
+func getEncoder() *Encoder {
+	return encoderPool.Get().(*Encoder)
+}
+
+func putEncoder(e *Encoder) {
+	if cap(e.buf) <= maxPooledBuffer {
+		e.Reset()
+		encoderPool.Put(e)
+	}
+}
+
+// Encoder encodes stores to .DS_Store file data reusing its internal buffers,
+// so encoding of many stores doesn't allocate new buffers for each of them.
+// Encoder is not safe for concurrent use.
+type Encoder struct {
+	buf      []byte       // file data
+	records  bytes.Buffer // encoded records
+	ends     []int        // ends of encoded records
+	nodes    bytes.Buffer // encoded B-tree nodes
+	nodeEnds []int        // ends of encoded B-tree nodes
+	sorted   []Record     // sorted records
+	// preserveOrder keeps canonical order of records of the store instead of sorting them
+	preserveOrder bool
+	pageSize      int             // page size of B-tree nodes of the current encoding
+	fanout        int             // maximal count of records of B-tree nodes, 0 is no limit
+	ctx           context.Context // context of the current encoding, nil when it is not cancellable
+	// progress of the current encoding
+	progress   Progress
+	onProgress func(Progress)
+	logger     *slog.Logger // logger of the current encoding
+}
+
+// NewEncoder creates Encoder
+func NewEncoder() *Encoder {
+	return &Encoder{pageSize: DefaultPageSize}
+}
+
+// Reset drops references to the encoded store, buffers are kept for the next encoding
+func (e *Encoder) Reset() {
+	e.buf = e.buf[:0]
+	e.records.Reset()
+	e.ends = e.ends[:0]
+	e.nodes.Reset()
+	e.nodeEnds = e.nodeEnds[:0]
+	clear(e.sorted)
+	e.sorted = e.sorted[:0]
+	e.onProgress = nil
+	e.logger = nil
+	e.pageSize, e.fanout = DefaultPageSize, 0
+	e.preserveOrder = false
+}
+
+// Encode returns .DS_Store file data of the store.
+// The data is valid until the next call of Encode, EncodeWithOptions or Reset.
+func (e *Encoder) Encode(s *Store) ([]byte, error) {
+	return e.EncodeWithOptions(s, WriteOptions{})
+}
+
+// EncodeWithOptions returns .DS_Store file data of the store using options.
+// The data is valid until the next call of Encode, EncodeWithOptions or Reset.
+func (e *Encoder) EncodeWithOptions(s *Store, opts WriteOptions) ([]byte, error) {
+	e.Reset()
+	pageSize, fanout, err := opts.tree()
+	if err != nil {
+		return nil, err
+	}
+	e.pageSize, e.fanout = pageSize, fanout
+	e.onProgress = opts.OnProgress
+	e.logger = opts.Logger
+	e.preserveOrder = opts.PreserveOrder
+	e.progress = Progress{}
+	if opts.Target != TargetAsIs {
+		records, err := opts.Target.apply(s.Records)
+		if err != nil {
+			return nil, err
+		}
+		// records of the target are written by the copy of the store, so its layout is kept where possible
+		target := *s
+		target.Records = records
+		s = &target
+	}
+	fileData, err := s.encodeLayout(e)
+	if err == nil && fileData == nil {
+		fileData, err = s.encodeTree(e)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.PreserveTrailing {
+		fileData = append(fileData, s.trailing...)
+		e.buf = fileData
+	}
+	if opts.Verify {
+		if err := s.verify(fileData, e.sorted); err != nil {
+			return nil, err
+		}
+	}
+	e.debug("encode store", "records", len(s.Records), "bytes", len(fileData))
+	return fileData, nil
+}
+
+// fileData returns zeroed file data of the size in the buffer of the encoder
+func (e *Encoder) fileData(size int) []byte {
+	e.buf = append(e.buf[:0], make([]byte, size)...)
+	return e.buf
+}
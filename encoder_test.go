@@ -0,0 +1,75 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	e := NewEncoder()
+	for i := 0; i < 3; i++ {
+		e.Reset()
+		fileData, err := e.Encode(&s)
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if !bytes.Equal(fileData, buf.Bytes()) {
+			t.Fatalf("encoding %d differs from written data", i)
+		}
+	}
+
+	// the encoder is reused for another store
+	other := &Store{Records: []Record{{FileName: "a", Type: "bool", Data: []byte{1}}}}
+	fileData, err := e.EncodeWithOptions(other, WriteOptions{Verify: true})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	var s2 Store
+	if err = s2.Read(bytes.NewReader(fileData)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(s2.Records) != 1 {
+		t.Errorf("expected 1 record, got %d", len(s2.Records))
+	}
+}
+
+func benchmarkStore() *Store {
+	s := &Store{}
+	for i := 0; i < 500; i++ {
+		s.Records = append(s.Records, Record{FileName: fmt.Sprintf("file%04d", i), Type: "long", Data: []byte{0, 0, 0, 1}})
+	}
+	return s
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	s := benchmarkStore()
+	e := NewEncoder()
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := e.Encode(s); err != nil {
+			b.Fatalf("Encode failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	s := benchmarkStore()
+	b.ReportAllocs()
+	for range b.N {
+		if err := s.Write(io.Discard); err != nil {
+			b.Fatalf("Write failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/strongo/dsstore/btree"
+	"github.com/strongo/dsstore/buddy"
+)
+
+// layout of .DS_Store file read in fidelity mode
+type layout struct {
+	fileData   []byte // data of the read file
+	rootOffset uint32 // offset of the root block from the header
+	rootSize   uint32 // size of the root block from the header
+	dataRoot   uint32 // block index of B-tree root node
+}
+
+// recordKey is key of record in B-tree
+type recordKey struct {
+	name, code string
+}
+
+// layoutNode is B-tree node of the read file
+type layoutNode struct {
+	index     uint32   // block index of the node
+	rightmost uint32   // block index of the rightmost child, 0 for leaf node
+	children  []uint32 // block indexes of children before records, nil for leaf node
+	records   []int    // in-order positions of records of the node
+}
+
+// shapeReader reads B-tree nodes of the read file without records data
+type shapeReader struct {
+	s       *Store
+	src     blockSource
+	nodes   []layoutNode // nodes bottom-up like buildTree returns them
+	keys    []recordKey  // keys of records in-order
+	visited map[uint32]bool
+	height  int
+}
+
+func (sr *shapeReader) read(index uint32, depth int) error {
+	if sr.visited[index] || depth > sr.s.opts.withDefaults().MaxDepth || int(index) >= len(sr.s.alloc.offsets) {
+		return fmt.Errorf("%w: node %d", ErrMalformedTree, index)
+	}
+	sr.visited[index] = true
+	sr.height = max(sr.height, depth)
+	offset := sr.s.alloc.offsets[index]
+	block, err := sr.src.readBlock(buddy.Offset(offset), buddy.Size(offset))
+	if err != nil {
+		return err
+	}
+	n, err := btree.ReadNode(block, sr.s.readParseFile)
+	if err != nil {
+		return err
+	}
+	node := layoutNode{index: index, rightmost: n.Rightmost, children: n.Children}
+	for i, r := range n.Records {
+		if !n.Leaf() {
+			if err = sr.read(n.Children[i], depth+1); err != nil {
+				return err
+			}
+		}
+		node.records = append(node.records, len(sr.keys))
+		sr.keys = append(sr.keys, recordKey{r.FileName, r.Code()})
+	}
+	if !n.Leaf() {
+		if err = sr.read(n.Rightmost, depth+1); err != nil {
+			return err
+		}
+	}
+	sr.nodes = append(sr.nodes, node)
+	return nil
+}
+
+// layoutWriter places blocks into data of the read file
+type layoutWriter struct {
+	fileData  []byte
+	offsets   []uint32
+	allocator *buddy.Allocator
+	changed   bool     // allocation is changed
+	encoder   *Encoder // encoder logging placement of blocks
+}
+
+// place writes block in place when it fits into the current block with the same index,
+// otherwise the block is reallocated
+func (lw *layoutWriter) place(index uint32, data []byte, minSize int) error {
+	for int(index) >= len(lw.offsets) {
+		lw.offsets = append(lw.offsets, 0)
+	}
+	offset := lw.offsets[index]
+	if offset != 0 && len(data) <= int(buddy.Size(offset)) {
+		block := lw.block(offset)
+		if !bytes.Equal(block[:len(data)], data) {
+			copy(block, data)
+			clear(block[len(data):])
+			lw.encoder.debug("write block in place", "index", index, "offset", buddy.Offset(offset), "size", len(data))
+		}
+		return nil
+	}
+	lw.release(index)
+	offset, err := lw.allocator.Alloc(uint32(max(len(data), minSize)))
+	if err != nil {
+		return err
+	}
+	lw.offsets[index] = offset
+	lw.changed = true
+	copy(lw.block(offset), data)
+	lw.encoder.debug("reallocate block", "index", index, "offset", buddy.Offset(offset), "size", buddy.Size(offset))
+	return nil
+}
+
+// release frees block by index and clears its data
+func (lw *layoutWriter) release(index uint32) {
+	if offset := lw.offsets[index]; offset != 0 {
+		clear(lw.block(offset))
+		lw.allocator.Free(offset)
+		lw.offsets[index] = 0
+		lw.changed = true
+		lw.encoder.debug("free block", "index", index, "offset", buddy.Offset(offset), "size", buddy.Size(offset))
+	}
+}
+
+// block returns data of the block, file data is extended when needed
+func (lw *layoutWriter) block(offset uint32) []byte {
+	start := 4 + int(buddy.Offset(offset))
+	end := start + int(buddy.Size(offset))
+	if end > len(lw.fileData) {
+		lw.fileData = append(lw.fileData, make([]byte, end-len(lw.fileData))...)
+	}
+	return lw.fileData[start:end]
+}
+
+// encodeLayout returns file data keeping layout of the file read in fidelity mode:
+// unchanged blocks stay at their original offsets, changed blocks are written in place
+// when they fit and are reallocated by buddy allocator otherwise.
+// B-tree nodes are kept when keys of records are not changed, otherwise B-tree is rebuilt
+// reusing block indexes of the read nodes.
+// It returns nil when there is no layout to keep.
+func (s *Store) encodeLayout(e *Encoder) ([]byte, error) {
+	l := s.layout
+	// blocks of nodes of other page size can't be kept
+	if l == nil {
+		return nil, nil
+	}
+	if e.pageSize != int(s.alloc.pageSize) {
+		e.debug("layout is not kept", "reason", "page size is changed", "pageSize", s.alloc.pageSize)
+		return nil, nil
+	}
+	// read B-tree of the read file
+	sr := &shapeReader{s: s, src: bytesSource(l.fileData), visited: make(map[uint32]bool)}
+	if err := sr.read(l.dataRoot, 1); err != nil {
+		return nil, nil
+	}
+	dsdbIndex, ok := s.alloc.topics["DSDB"]
+	rootIndex := slices.IndexFunc(s.alloc.offsets, func(offset uint32) bool {
+		return offset != 0 && buddy.Offset(offset) == l.rootOffset
+	})
+	if !ok || rootIndex < 0 {
+		return nil, nil
+	}
+	// prepare B-tree nodes
+	records, err := s.encodeRecords(e)
+	if err != nil {
+		return nil, err
+	}
+	sameKeys := len(records) == len(sr.keys)
+	for i, r := range e.sorted {
+		if !sameKeys || sr.keys[i] != (recordKey{r.FileName, r.Code()}) {
+			sameKeys = false
+			break
+		}
+	}
+	// nodes with more records than fanout or grown over the page are rebuilt,
+	// so the tree is split into nodes of the page like on writing of a new file
+	for _, n := range sr.nodes {
+		if !sameKeys {
+			break
+		}
+		size := 8 + 4*len(n.children)
+		for _, pos := range n.records {
+			size += len(records[pos])
+		}
+		if e.fanout > 0 && len(n.records) > e.fanout || size > e.pageSize {
+			sameKeys = false
+		}
+	}
+	var nodes []treeNode
+	var levels int
+	var released []uint32
+	indexes := make([]uint32, 0, len(sr.nodes))
+	if sameKeys {
+		for _, n := range sr.nodes {
+			node := treeNode{Rightmost: n.rightmost, Children: n.children}
+			for _, pos := range n.records {
+				node.Records = append(node.Records, records[pos])
+			}
+			nodes = append(nodes, node)
+			indexes = append(indexes, n.index)
+		}
+		levels = sr.height - 1
+		e.debug("keep tree", "nodes", len(nodes), "levels", levels)
+	} else {
+		// the lowest indexes are reused, so freed indexes are at the end of offsets
+		reused := make([]uint32, 0, len(sr.nodes))
+		for _, n := range sr.nodes {
+			reused = append(reused, n.index)
+		}
+		slices.Sort(reused)
+		index := func(n int) uint32 {
+			if n < len(reused) {
+				return reused[n]
+			}
+			return uint32(len(s.alloc.offsets) + n - len(reused))
+		}
+		nodes, levels = buildTree(records, e.pageSize, e.fanout, index)
+		for n := range nodes {
+			indexes = append(indexes, index(n))
+		}
+		released = reused[min(len(nodes), len(reused)):]
+		e.debug("rebuild tree", "nodes", len(nodes), "levels", levels, "reused", min(len(nodes), len(reused)))
+	}
+	// place blocks
+	lw := &layoutWriter{
+		fileData:  append(e.buf[:0], l.fileData[:len(l.fileData)-len(s.trailing)]...),
+		offsets:   slices.Clone(s.alloc.offsets),
+		allocator: buddy.AllocatorFrom(s.alloc.freeLists),
+		encoder:   e,
+	}
+	for _, index := range released {
+		lw.release(index)
+	}
+	blockNode := getBuffer()
+	defer putBuffer(blockNode)
+	for i, node := range nodes {
+		if err = e.ctxErr(); err != nil {
+			return nil, err
+		}
+		blockNode.Reset()
+		if err = s.writeBlockNode(blockNode, node); err != nil {
+			return nil, err
+		}
+		if err = lw.place(indexes[i], blockNode.Bytes(), e.pageSize); err != nil {
+			return nil, err
+		}
+		e.progress.RecordsWritten += len(node.Records)
+		e.reportProgress()
+	}
+	blockDSDB := getBuffer()
+	defer putBuffer(blockDSDB)
+	if err = s.writeBlockDSDB(blockDSDB, indexes[len(indexes)-1], uint32(levels), uint32(len(nodes)), uint32(e.pageSize)); err != nil {
+		return nil, err
+	}
+	if err = lw.place(dsdbIndex, blockDSDB.Bytes(), 32); err != nil {
+		return nil, err
+	}
+	// freed blocks must be at the end of offsets, because offsets can't have zero entries
+	for len(lw.offsets) > 0 && lw.offsets[len(lw.offsets)-1] == 0 {
+		lw.offsets = lw.offsets[:len(lw.offsets)-1]
+	}
+	if slices.Contains(lw.offsets, 0) {
+		return nil, nil
+	}
+	// root block is written only when allocation is changed
+	rootOffset, rootSize := l.rootOffset, l.rootSize
+	blockRoot := getBuffer()
+	defer putBuffer(blockRoot)
+	for lw.changed {
+		lw.changed = false
+		blockRoot.Reset()
+		extra := bytes.TrimRight(s.RootExtra, "\x00")
+		if err = s.writeBlockRoot(blockRoot, lw.offsets, s.alloc.topics, &lw.allocator.FreeLists, extra); err != nil {
+			return nil, err
+		}
+		// root block can be reallocated, so allocation is changed again
+		if err = lw.place(uint32(rootIndex), blockRoot.Bytes(), 32); err != nil {
+			return nil, err
+		}
+		if offset := buddy.Offset(lw.offsets[rootIndex]); offset != rootOffset || uint32(blockRoot.Len()) > rootSize {
+			rootOffset, rootSize = offset, uint32(blockRoot.Len())
+		}
+	}
+	blockHeader := getBuffer()
+	defer putBuffer(blockHeader)
+	if err = s.writeHeader(blockHeader, rootOffset, rootSize); err != nil {
+		return nil, err
+	}
+	copy(lw.fileData, blockHeader.Bytes())
+	e.buf = lw.fileData
+	return lw.fileData, nil
+}
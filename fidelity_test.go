@@ -0,0 +1,149 @@
+package dsstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/strongo/dsstore/buddy"
+)
+
+func readFidelity(t *testing.T, fileData []byte) *Store {
+	t.Helper()
+	s := &Store{}
+	if err := s.ReadWithOptions(bytes.NewReader(fileData), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return s
+}
+
+func TestWriteFidelity(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := readFidelity(t, fileData)
+	buf := new(bytes.Buffer)
+	if err = s.WriteWithOptions(buf, WriteOptions{PreserveTrailing: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), fileData) {
+		t.Fatal("expected unchanged store to be written byte for byte")
+	}
+
+	// changed record is written in place
+	s.Records[len(s.Records)-1].Data[0] ^= 0xff
+	buf.Reset()
+	if err = s.WriteWithOptions(buf, WriteOptions{PreserveTrailing: true, Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	written := buf.Bytes()
+	if len(written) != len(fileData) {
+		t.Fatalf("expected %d bytes, got %d", len(fileData), len(written))
+	}
+	leaf := s.alloc.offsets[2]
+	start, end := 4+int(buddy.Offset(leaf)), 4+int(buddy.Offset(leaf)+buddy.Size(leaf))
+	if !bytes.Equal(written[:start], fileData[:start]) || !bytes.Equal(written[end:], fileData[end:]) {
+		t.Error("expected only the leaf block to be changed")
+	}
+}
+
+func TestWriteFidelityRelocate(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := readFidelity(t, fileData)
+	dsdb := s.alloc.offsets[1]
+
+	// the leaf doesn't fit into its block anymore
+	s.Records = append(s.Records, Record{FileName: "big", Type: "blob", DataLen: 5000, Data: make([]byte, 5000)})
+	if data, err := s.encodeLayout(NewEncoder()); err != nil || data == nil {
+		t.Fatalf("expected layout to be kept, got %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err = s.WriteWithOptions(buf, WriteOptions{Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s2 := readFidelity(t, buf.Bytes())
+	if len(s2.Records) != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	if err = s2.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+	if s2.alloc.offsets[1] != dsdb {
+		t.Errorf("expected DSDB block to stay at %#x, got %#x", dsdb, s2.alloc.offsets[1])
+	}
+
+	// removing records frees blocks
+	s2.Records = s2.Records[:1]
+	if data, err := s2.encodeLayout(NewEncoder()); err != nil || data == nil {
+		t.Fatalf("expected layout to be kept, got %v", err)
+	}
+	buf.Reset()
+	if err = s2.WriteWithOptions(buf, WriteOptions{Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s3 := readFidelity(t, buf.Bytes())
+	if err = s3.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+}
+
+func TestWriteFidelityPageSize(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := readFidelity(t, fileData)
+
+	// nodes of other page size can't stay in their blocks
+	e := NewEncoder()
+	e.pageSize = 512
+	if data, err := s.encodeLayout(e); err != nil || data != nil {
+		t.Fatalf("expected layout not to be kept, got %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err = s.WriteWithOptions(buf, WriteOptions{PageSize: 512, Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s2 := readFidelity(t, buf.Bytes())
+	if s2.alloc.pageSize != 512 {
+		t.Errorf("expected page size 512, got %d", s2.alloc.pageSize)
+	}
+}
+
+func TestWriteFidelityGrownNode(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := readFidelity(t, fileData)
+
+	// the grown record fits into a page, but the leaf with it doesn't
+	for i, r := range s.Records {
+		if r.Code() == "pBBk" {
+			s.Records[i].Data = make([]byte, 3500)
+			s.Records[i].DataLen = 3500
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err = s.WriteWithOptions(buf, WriteOptions{Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s2 := readFidelity(t, buf.Bytes())
+	sr := &shapeReader{s: s2, src: bytesSource(s2.layout.fileData), visited: make(map[uint32]bool)}
+	if err = sr.read(s2.layout.dataRoot, 1); err != nil {
+		t.Fatalf("reading of the tree failed: %v", err)
+	}
+	if len(sr.nodes) < 2 {
+		t.Errorf("expected the leaf to be split, got %d nodes", len(sr.nodes))
+	}
+	for _, n := range sr.nodes {
+		if size := buddy.Size(s2.alloc.offsets[n.index]); size > 4096 {
+			t.Errorf("node %d has block of %d bytes, expected at most 4096", n.index, size)
+		}
+	}
+}
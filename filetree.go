@@ -0,0 +1,120 @@
+package dsstore
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileTree is a merged tree of file and folder names referenced by stores of many folders,
+// like stores found on a disk or fetched from a web server. It is safe for concurrent use.
+type FileTree struct {
+	mu   sync.Mutex
+	root *FileNode
+}
+
+// FileNode is a file or a folder of FileTree
+type FileNode struct {
+	Name     string      `json:"name"`               // name of the file, empty for the root
+	Path     string      `json:"path"`               // slash-separated path from the root
+	Mentions []Mention   `json:"mentions,omitempty"` // stores which reference the file, in the order of adding
+	Children []*FileNode `json:"children,omitempty"` // entries of the folder sorted by name
+
+	index map[string]*FileNode // children by nameKey
+}
+
+// Mention is a reference of a file by a store
+type Mention struct {
+	Source string      `json:"source"`          // path or URL of the store
+	Codes  []string    `json:"codes"`           // structure IDs of records of the file
+	Times  []time.Time `json:"times,omitempty"` // times of "dutc" records of the file
+}
+
+// NewFileTree creates empty FileTree
+func NewFileTree() *FileTree {
+	return &FileTree{root: &FileNode{index: make(map[string]*FileNode)}}
+}
+
+// Add adds files referenced by the store of the source: path of the store file or its URL.
+// The folder of the store is the parent of the files; URLs are placed under their host names.
+// Names which differ only in case or Unicode normalization are the same file, like Finder treats them.
+func (t *FileTree) Add(source string, s *Store) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	folder := t.root
+	for _, element := range strings.Split(sourceDir(source), "/") {
+		if element != "" {
+			folder = folder.child(element)
+		}
+	}
+	mentions := make(map[*FileNode]*Mention)
+	for _, r := range s.Records {
+		node := folder
+		if r.FileName != "." {
+			node = folder.child(r.FileName)
+		}
+		m, ok := mentions[node]
+		if !ok {
+			node.Mentions = append(node.Mentions, Mention{Source: source})
+			m = &node.Mentions[len(node.Mentions)-1]
+			mentions[node] = m
+		}
+		if code := r.Code(); !slices.Contains(m.Codes, code) {
+			m.Codes = append(m.Codes, code)
+		}
+		if tm, ok := r.Time(); ok {
+			m.Times = append(m.Times, tm)
+		}
+	}
+}
+
+// sourceDir returns slash-separated path of the folder of the store path or URL
+func sourceDir(source string) string {
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" && u.Host != "" {
+		return path.Dir(path.Join(u.Host, u.Path))
+	}
+	return path.Dir(filepath.ToSlash(source))
+}
+
+// child returns the child of the name, it is added when it is absent
+func (n *FileNode) child(name string) *FileNode {
+	key := nameKey(name)
+	if c, ok := n.index[key]; ok {
+		return c
+	}
+	c := &FileNode{Name: name, Path: path.Join(n.Path, name), index: make(map[string]*FileNode)}
+	n.index[key] = c
+	i, _ := slices.BinarySearchFunc(n.Children, name, func(c *FileNode, name string) int {
+		return strings.Compare(nameKey(c.Name), nameKey(name))
+	})
+	n.Children = slices.Insert(n.Children, i, c)
+	return c
+}
+
+// Root returns the root of the tree
+func (t *FileTree) Root() *FileNode {
+	return t.root
+}
+
+// Walk calls fn for the node and its descendants in depth-first order
+func (n *FileNode) Walk(fn func(n *FileNode)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Latest returns the latest time of mentions of the file, zero when they have no times
+func (n *FileNode) Latest() time.Time {
+	var latest time.Time
+	for _, m := range n.Mentions {
+		for _, t := range m.Times {
+			latest = maxTime(latest, t)
+		}
+	}
+	return latest
+}
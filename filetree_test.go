@@ -0,0 +1,34 @@
+package dsstore
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileTree(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tree := NewFileTree()
+	tree.Add("/srv/www/.DS_Store", &Store{Records: []Record{TextRecord("static", "cmmt", "x"), TextRecord(".", "cmmt", "root")}})
+	tree.Add("/srv/www/static/.DS_Store", &Store{Records: []Record{TimeRecord("app.js", "moDD", modified), TextRecord("App.js", "cmmt", "y")}})
+	tree.Add("https://example.com/static/.DS_Store", &Store{Records: []Record{TextRecord("index.html", "cmmt", "z")}})
+	var paths []string
+	tree.Root().Walk(func(n *FileNode) {
+		paths = append(paths, n.Path)
+	})
+	want := []string{"", "example.com", "example.com/static", "example.com/static/index.html",
+		"srv", "srv/www", "srv/www/static", "srv/www/static/app.js"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("want %q, got %q", want, paths)
+	}
+	www := tree.Root().Children[1].Children[0]
+	static := www.Children[0]
+	if len(www.Mentions) != 1 || www.Mentions[0].Source != "/srv/www/.DS_Store" ||
+		len(static.Mentions) != 1 || static.Mentions[0].Source != "/srv/www/.DS_Store" {
+		t.Errorf("unexpected mentions of folders %+v, %+v", www.Mentions, static.Mentions)
+	}
+	app := static.Children[0]
+	if len(app.Mentions) != 1 || !slices.Equal(app.Mentions[0].Codes, []string{"moDD", "cmmt"}) || !app.Latest().Equal(modified) {
+		t.Errorf("unexpected mentions of file %+v", app.Mentions)
+	}
+}
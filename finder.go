@@ -0,0 +1,200 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"unicode/utf16"
+)
+
+// Extended attributes of macOS files with Finder metadata
+const (
+	FinderCommentAttr = "com.apple.metadata:kMDItemFinderComment" // Finder comment as property list string
+	FinderInfoAttr    = "com.apple.FinderInfo"                    // 32 bytes of FinderInfo with label color
+)
+
+// LabelColor is Finder label color of a file
+type LabelColor int
+
+// Finder label colors in the order of FinderInfo flags
+const (
+	LabelNone LabelColor = iota
+	LabelGray
+	LabelGreen
+	LabelPurple
+	LabelBlue
+	LabelYellow
+	LabelRed
+	LabelOrange
+)
+
+var labelNames = [...]string{"none", "gray", "green", "purple", "blue", "yellow", "red", "orange"}
+
+// String returns name of the color
+func (c LabelColor) String() string {
+	if c < 0 || int(c) >= len(labelNames) {
+		return fmt.Sprintf("LabelColor(%d)", int(c))
+	}
+	return labelNames[c]
+}
+
+// FinderMetadata is native metadata of a file shown by Finder
+type FinderMetadata struct {
+	Comment string
+	Label   LabelColor
+}
+
+// Text returns text of "ustr" record
+func (r Record) Text() (string, bool) {
+	if r.Type != "ustr" || len(r.Data)%2 != 0 {
+		return "", false
+	}
+	units := make([]uint16, len(r.Data)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(r.Data[2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// TextRecord returns "ustr" record of the file with the structure ID and the text
+func TextRecord(fileName, code, text string) Record {
+	units := utf16.Encode([]rune(text))
+	r := Record{FileName: fileName, Type: "ustr", DataLen: uint32(len(units)), Data: make([]byte, 0, 2*len(units))}
+	for _, u := range units {
+		r.Data = binary.BigEndian.AppendUint16(r.Data, u)
+	}
+	r.SetCode(code)
+	return r
+}
+
+// Comment returns Finder comment of the file from its "cmmt" record
+func (s *Store) Comment(fileName string) (string, bool) {
+	for _, r := range s.Records {
+		if r.FileName == fileName && r.Code() == "cmmt" {
+			return r.Text()
+		}
+	}
+	return "", false
+}
+
+// SetComment sets Finder comment of the file, empty comment removes its "cmmt" record
+func (s *Store) SetComment(fileName, comment string) {
+	i := slices.IndexFunc(s.Records, func(r Record) bool {
+		return r.FileName == fileName && r.Code() == "cmmt"
+	})
+	s.mods++
+	switch {
+	case comment == "" && i >= 0:
+		s.Records = slices.Delete(s.Records, i, i+1)
+	case comment == "":
+	case i >= 0:
+		s.Records[i] = TextRecord(fileName, "cmmt", comment)
+	default:
+		s.Records = append(s.Records, TextRecord(fileName, "cmmt", comment))
+	}
+}
+
+// CommentDirection is direction of SyncComments
+type CommentDirection int
+
+// Directions of synchronization of Finder comments
+const (
+	CommentsToStore   CommentDirection = iota // records are set from extended attributes of files
+	CommentsFromStore                         // extended attributes of files are set from records
+)
+
+// SyncComments synchronizes "cmmt" records of the store with FinderCommentAttr extended attributes
+// of files of the directory and returns names of files which comments are changed.
+// FinderMetadataSupported is false on platforms other than macOS and nothing is changed there.
+func (s *Store) SyncComments(dir string, direction CommentDirection) ([]string, error) {
+	if !FinderMetadataSupported {
+		return nil, nil
+	}
+	var changed []string
+	var errs []error
+	for _, name := range s.syncCommentNames(dir, direction, &errs) {
+		stored, _ := s.Comment(name)
+		m, err := ReadFinderMetadata(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if m.Comment == stored {
+			continue
+		}
+		if direction == CommentsToStore {
+			s.SetComment(name, m.Comment)
+		} else {
+			m.Comment = stored
+			if err = WriteFinderMetadata(filepath.Join(dir, name), m); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		changed = append(changed, name)
+	}
+	return changed, errors.Join(errs...)
+}
+
+// syncCommentNames returns names of files which comments are synchronized:
+// files of the directory or files with "cmmt" records
+func (s *Store) syncCommentNames(dir string, direction CommentDirection, errs *[]error) []string {
+	var names []string
+	if direction == CommentsToStore {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			*errs = append(*errs, err)
+		}
+		for _, entry := range entries {
+			if entry.Name() != StoreFileName {
+				names = append(names, entry.Name())
+			}
+		}
+		return names
+	}
+	for _, r := range s.Records {
+		if r.FileName != "." && r.Code() == "cmmt" && !slices.Contains(names, r.FileName) {
+			names = append(names, r.FileName)
+		}
+	}
+	return names
+}
+
+// labelFromFinderInfo returns label color of FinderInfo: bits 1-3 of Finder flags at offset 8
+func labelFromFinderInfo(info []byte) LabelColor {
+	if len(info) < 10 {
+		return LabelNone
+	}
+	return LabelColor(binary.BigEndian.Uint16(info[8:]) >> 1 & 7)
+}
+
+// setFinderInfoLabel returns FinderInfo with the label color, nil info is zero FinderInfo
+func setFinderInfoLabel(info []byte, label LabelColor) []byte {
+	result := make([]byte, max(32, len(info)))
+	copy(result, info)
+	flags := binary.BigEndian.Uint16(result[8:])&^(7<<1) | uint16(label&7)<<1
+	binary.BigEndian.PutUint16(result[8:], flags)
+	return result
+}
+
+// encodeCommentAttr encodes the comment as binary property list with one string object
+func encodeCommentAttr(comment string) []byte {
+	data, _ := encodePlist(comment)
+	return data
+}
+
+// decodeCommentAttr decodes the comment from binary property list with top string object
+func decodeCommentAttr(data []byte) (string, error) {
+	v, err := decodePlist(data)
+	if err != nil {
+		return "", err
+	}
+	comment, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("comment is %T, not a string", v)
+	}
+	return comment, nil
+}
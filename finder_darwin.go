@@ -0,0 +1,53 @@
+//go:build darwin
+
+package dsstore
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// FinderMetadataSupported reports whether Finder metadata of files can be read and written
+const FinderMetadataSupported = true
+
+// ReadFinderMetadata reads Finder comment and label color from extended attributes of the file
+func ReadFinderMetadata(filename string) (FinderMetadata, error) {
+	var m FinderMetadata
+	value, err := getXattr(filename, FinderCommentAttr)
+	switch {
+	case errors.Is(err, unix.ENOATTR):
+	case err != nil:
+		return m, err
+	default:
+		if m.Comment, err = decodeCommentAttr(value); err != nil {
+			return m, err
+		}
+	}
+	info, err := getXattr(filename, FinderInfoAttr)
+	if err != nil && !errors.Is(err, unix.ENOATTR) {
+		return m, err
+	}
+	m.Label = labelFromFinderInfo(info)
+	return m, nil
+}
+
+// WriteFinderMetadata writes Finder comment and label color to extended attributes of the file,
+// empty comment removes the attribute. Finder may keep showing the comment of its "cmmt" record.
+func WriteFinderMetadata(filename string, m FinderMetadata) error {
+	if m.Comment == "" {
+		if err := unix.Removexattr(filename, FinderCommentAttr); err != nil && !errors.Is(err, unix.ENOATTR) {
+			return err
+		}
+	} else if err := unix.Setxattr(filename, FinderCommentAttr, encodeCommentAttr(m.Comment), 0); err != nil {
+		return err
+	}
+	info, err := getXattr(filename, FinderInfoAttr)
+	if err != nil && !errors.Is(err, unix.ENOATTR) {
+		return err
+	}
+	if labelFromFinderInfo(info) == m.Label {
+		return nil
+	}
+	return unix.Setxattr(filename, FinderInfoAttr, setFinderInfoLabel(info, m.Label), 0)
+}
@@ -0,0 +1,16 @@
+//go:build !darwin
+
+package dsstore
+
+// FinderMetadataSupported reports whether Finder metadata of files can be read and written
+const FinderMetadataSupported = false
+
+// ReadFinderMetadata returns no metadata on this platform
+func ReadFinderMetadata(string) (FinderMetadata, error) {
+	return FinderMetadata{}, nil
+}
+
+// WriteFinderMetadata does nothing on this platform
+func WriteFinderMetadata(string, FinderMetadata) error {
+	return nil
+}
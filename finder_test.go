@@ -0,0 +1,72 @@
+package dsstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextRecord(t *testing.T) {
+	r := TextRecord("file", "cmmt", "comment ✓ 𝄞")
+	if r.Code() != "cmmt" || r.DataLen != 12 || r.Validate() != nil {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if text, ok := r.Text(); !ok || text != "comment ✓ 𝄞" {
+		t.Errorf("unexpected text %q", text)
+	}
+	if _, ok := (Record{Type: "long", Data: []byte{0, 0, 0, 1}}).Text(); ok {
+		t.Error("expected no text of long record")
+	}
+}
+
+func TestComment(t *testing.T) {
+	var s Store
+	s.SetComment("file", "first")
+	s.SetComment("file", "second")
+	if comment, ok := s.Comment("file"); !ok || comment != "second" || len(s.Records) != 1 {
+		t.Errorf("unexpected comment %q of %v", comment, s.Records)
+	}
+	s.SetComment("file", "")
+	s.SetComment("other", "")
+	if _, ok := s.Comment("file"); ok || len(s.Records) != 0 {
+		t.Errorf("expected no records, got %v", s.Records)
+	}
+}
+
+func TestFinderInfoLabel(t *testing.T) {
+	info := setFinderInfoLabel(nil, LabelRed)
+	if len(info) != 32 || labelFromFinderInfo(info) != LabelRed {
+		t.Errorf("unexpected FinderInfo %x", info)
+	}
+	info[8] = 0x80
+	if info = setFinderInfoLabel(info, LabelBlue); info[8] != 0x80 || labelFromFinderInfo(info) != LabelBlue {
+		t.Errorf("expected other flags to be kept, got %x", info)
+	}
+	if LabelOrange.String() != "orange" || LabelColor(9).String() != "LabelColor(9)" {
+		t.Error("unexpected label names")
+	}
+}
+
+func TestCommentAttr(t *testing.T) {
+	for _, comment := range []string{"", "short", strings.Repeat("long ", 10), "unicode ✓", strings.Repeat("𝄞", 20)} {
+		got, err := decodeCommentAttr(encodeCommentAttr(comment))
+		if err != nil || got != comment {
+			t.Errorf("expected %q, got %q: %v", comment, got, err)
+		}
+	}
+	// written by macOS: one-byte offsets
+	data := []byte("bplist00\x55hello\x08" + "\x00\x00\x00\x00\x00\x00\x01\x01" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01" + "\x00\x00\x00\x00\x00\x00\x00\x00" + "\x00\x00\x00\x00\x00\x00\x00\x0e")
+	if got, err := decodeCommentAttr(data); err != nil || got != "hello" {
+		t.Errorf("expected hello, got %q: %v", got, err)
+	}
+	for _, data := range [][]byte{
+		[]byte("not a plist"),
+		data[:len(data)-1],
+		[]byte("bplist00\x09\x08" + strings.Repeat("\x00", 6) + "\x01\x01" + strings.Repeat("\x00", 7) + "\x01" +
+			strings.Repeat("\x00", 15) + "\x09"),
+	} {
+		if _, err := decodeCommentAttr(data); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package dsstore
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"maps"
+	"slices"
+)
+
+// recordHash returns hash of the canonical record: file name compared like Finder does,
+// structure ID, type and data
+func recordHash(r Record) [sha256.Size]byte {
+	h := sha256.New()
+	for _, field := range [][]byte{[]byte(nameKey(r.FileName)), []byte(r.Code()), []byte(r.Type), r.Data} {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(field)))
+		h.Write(field)
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// Fingerprint returns hex SHA-256 hash of canonical records of the store: records are sorted like they are written
+// and names are compared like Finder does, so stores with the same records have the same fingerprint
+// regardless of their layout, order of records and unknown extra data
+func (s *Store) Fingerprint() string {
+	hashes := make([][sha256.Size]byte, len(s.Records))
+	for i, r := range s.Records {
+		hashes[i] = recordHash(r)
+	}
+	slices.SortFunc(hashes, func(a, b [sha256.Size]byte) int {
+		return slices.Compare(a[:], b[:])
+	})
+	h := sha256.New()
+	for _, sum := range hashes {
+		h.Write(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Similarity returns Jaccard similarity of records of the stores from 0 to 1:
+// count of equal records divided by count of records of both stores. Empty stores are equal.
+func Similarity(a, b *Store) float64 {
+	counts := func(s *Store) map[[sha256.Size]byte]int {
+		m := make(map[[sha256.Size]byte]int, len(s.Records))
+		for _, r := range s.Records {
+			m[recordHash(r)]++
+		}
+		return m
+	}
+	ca, cb := counts(a), counts(b)
+	var common, all int
+	for h, n := range ca {
+		common += min(n, cb[h])
+		all += max(n, cb[h])
+	}
+	for h, n := range cb {
+		if _, ok := ca[h]; !ok {
+			all += n
+		}
+	}
+	if all == 0 {
+		return 1
+	}
+	return float64(common) / float64(all)
+}
+
+// Cluster groups sources of the stores, like paths, by similarity of their records:
+// stores with the same fingerprint are in one cluster, and clusters which have stores
+// with similarity at least the threshold are joined. Clusters and their sources are sorted.
+func Cluster(stores map[string]*Store, threshold float64) [][]string {
+	byFingerprint := make(map[string][]string)
+	for _, source := range slices.Sorted(maps.Keys(stores)) {
+		fp := stores[source].Fingerprint()
+		byFingerprint[fp] = append(byFingerprint[fp], source)
+	}
+	var clusters [][]string
+	for _, fp := range slices.Sorted(maps.Keys(byFingerprint)) {
+		clusters = append(clusters, byFingerprint[fp])
+	}
+	// single linkage by representatives of identical stores
+	parent := make([]int, len(clusters))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range clusters {
+		for j := i + 1; j < len(clusters); j++ {
+			if find(i) != find(j) && Similarity(stores[clusters[i][0]], stores[clusters[j][0]]) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+	joined := make(map[int][]string)
+	for i, c := range clusters {
+		joined[find(i)] = append(joined[find(i)], c...)
+	}
+	result := make([][]string, 0, len(joined))
+	for _, c := range joined {
+		slices.Sort(c)
+		result = append(result, c)
+	}
+	slices.SortFunc(result, func(a, b []string) int {
+		return slices.Compare(a, b)
+	})
+	return result
+}
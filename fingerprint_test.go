@@ -0,0 +1,32 @@
+package dsstore
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := &Store{Records: []Record{TextRecord("a", "cmmt", "x"), TextRecord("b", "cmmt", "y")}}
+	b := &Store{Records: []Record{TextRecord("B", "cmmt", "y"), TextRecord("a", "cmmt", "x")}, HeaderExtra: []byte{1}}
+	c := &Store{Records: []Record{TextRecord("a", "cmmt", "x"), TextRecord("b", "cmmt", "z")}}
+	if a.Fingerprint() != b.Fingerprint() || a.Fingerprint() == c.Fingerprint() || len(a.Fingerprint()) != 64 {
+		t.Errorf("unexpected fingerprints %s, %s, %s", a.Fingerprint(), b.Fingerprint(), c.Fingerprint())
+	}
+	if s := Similarity(a, b); s != 1 {
+		t.Errorf("want similarity 1, got %v", s)
+	}
+	if s := Similarity(a, c); s != 1.0/3 {
+		t.Errorf("want similarity 1/3, got %v", s)
+	}
+	if s := Similarity(&Store{}, &Store{}); s != 1 {
+		t.Errorf("want similarity 1 of empty stores, got %v", s)
+	}
+	d := &Store{Records: []Record{TextRecord("q", "cmmt", "q")}}
+	clusters := Cluster(map[string]*Store{"a": a, "b": b, "c": c, "d": d}, 0.3)
+	if len(clusters) != 2 || !slices.Equal(clusters[0], []string{"a", "b", "c"}) || !slices.Equal(clusters[1], []string{"d"}) {
+		t.Errorf("unexpected clusters %q", clusters)
+	}
+	if clusters = Cluster(map[string]*Store{"a": a, "b": b, "c": c}, 0.5); len(clusters) != 2 {
+		t.Errorf("unexpected clusters %q", clusters)
+	}
+}
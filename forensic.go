@@ -0,0 +1,274 @@
+package dsstore
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ForensicReport is privacy and forensic analyses of stores, like stores of a pentest engagement,
+// rendered as Markdown or HTML with the raw JSON attached for machine processing.
+// It is safe for concurrent use, so Add can be called by ProcessAll callbacks.
+type ForensicReport struct {
+	mu      sync.Mutex
+	Summary *Report         `json:"summary"` // corpus-level summary
+	Stores  []StoreFindings `json:"stores"`  // findings of every read store sorted by path
+}
+
+// StoreFindings is analyses of one store of ForensicReport
+type StoreFindings struct {
+	Path    string          `json:"path"`
+	SHA256  string          `json:"sha256,omitempty"` // hex SHA-256 of the bytes of stores read with ReadOptions.Fidelity
+	Privacy PrivacyReport   `json:"privacy"`
+	Paths   []EmbeddedPath  `json:"paths,omitempty"`
+	Events  []TimelineEvent `json:"events,omitempty"`
+	Unknown []UnknownCode   `json:"unknown,omitempty"` // records of unknown structure IDs with guesses
+}
+
+// NewForensicReport returns empty report
+func NewForensicReport() *ForensicReport {
+	return &ForensicReport{Summary: NewReport()}
+}
+
+// Add analyzes the store read from the path, nil store is a file which can't be read.
+// Add has the signature of WalkFunc. It always returns nil.
+func (r *ForensicReport) Add(path string, s *Store, err error) error {
+	_ = r.Summary.Add(path, s, err)
+	if s == nil {
+		return nil
+	}
+	findings := StoreFindings{Path: path, SHA256: fileHash(s), Privacy: Analyze(s), Paths: s.EmbeddedPaths(), Events: s.Events(path), Unknown: GuessUnknown(s)}
+	slices.SortStableFunc(findings.Events, func(a, b TimelineEvent) int {
+		return a.Time.Compare(b.Time)
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, _ := slices.BinarySearchFunc(r.Stores, path, func(f StoreFindings, path string) int {
+		return cmp.Compare(f.Path, path)
+	})
+	r.Stores = slices.Insert(r.Stores, i, findings)
+	return nil
+}
+
+// AddResults adds failures of ProcessAll results to the report,
+// successfully processed stores are added by the ProcessAll callback
+func (r *ForensicReport) AddResults(results []Result) {
+	for _, result := range results {
+		if result.Err != nil {
+			_ = r.Add(result.Path, nil, result.Err)
+		}
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *ForensicReport) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteMarkdown writes the report as Markdown document with the raw JSON in the last section
+func (r *ForensicReport) WriteMarkdown(w io.Writer) error {
+	data, err := r.templateData()
+	if err != nil {
+		return err
+	}
+	return markdownReport.Execute(w, data)
+}
+
+// WriteHTML writes the report as standalone HTML page with the raw JSON in a collapsed section
+func (r *ForensicReport) WriteHTML(w io.Writer) error {
+	data, err := r.templateData()
+	if err != nil {
+		return err
+	}
+	return htmlReport.Execute(w, data)
+}
+
+// reportData is data of report templates
+type reportData struct {
+	*ForensicReport
+	Codes []string // structure IDs sorted by count of records
+	JSON  string
+}
+
+func (r *ForensicReport) templateData() (reportData, error) {
+	var b bytes.Buffer
+	if err := r.WriteJSON(&b); err != nil {
+		return reportData{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	codes := make([]string, 0, len(r.Summary.Codes))
+	for code := range r.Summary.Codes {
+		codes = append(codes, code)
+	}
+	slices.SortFunc(codes, func(a, b string) int {
+		return cmp.Or(cmp.Compare(r.Summary.Codes[b], r.Summary.Codes[a]), cmp.Compare(a, b))
+	})
+	return reportData{ForensicReport: r, Codes: codes, JSON: strings.TrimSuffix(b.String(), "\n")}, nil
+}
+
+// reportFuncs are functions of report templates
+var reportFuncs = map[string]any{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown"
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+	"join":  strings.Join,
+	"lines": func(s string) []string { return strings.Split(strings.TrimSuffix(s, "\n"), "\n") },
+	// cell escapes text of Markdown table cell
+	"cell": strings.NewReplacer("|", `\|`, "\n", " ").Replace,
+}
+
+var markdownReport = template.Must(template.New("markdown").Funcs(reportFuncs).Parse(
+	`# .DS_Store report
+
+{{with .Summary}}{{.Stores}} stores ({{.Failed}} failed), {{.Records}} records, {{.Files}} file names
+{{- if not .Earliest.IsZero}}, timestamps from {{time .Earliest}} to {{time .Latest}}{{end}}.
+{{end}}
+{{- if .Stores}}
+| Store | Files | Deleted files | Comments | Users | Volumes |
+|---|---|---|---|---|---|
+{{range .Stores}}| {{cell .Path}} | {{len .Privacy.Files}} | {{len .Privacy.DeletedFiles}} | {{len .Privacy.Comments}} | {{cell (join .Privacy.Users ", ")}} | {{cell (join .Privacy.Volumes ", ")}} |
+{{end}}{{end}}
+{{- if .Codes}}
+## Structure IDs
+
+| Code | Records |
+|---|---|
+{{range .Codes}}| {{cell .}} | {{index $.Summary.Codes .}} |
+{{end}}{{end}}
+{{- range .Stores}}
+## {{.Path}}
+
+{{if .SHA256}}SHA-256 ` + "`{{.SHA256}}`" + `
+
+{{end}}{{range lines .Privacy.Summary}}- {{.}}
+{{end}}
+### Files
+
+{{range .Privacy.Files}}- {{.}}
+{{end}}{{range .Privacy.DeletedFiles}}- {{.}} (deleted)
+{{end}}
+{{- if .Privacy.Comments}}
+### Finder comments
+
+| Name | Comment |
+|---|---|
+{{range .Privacy.Comments}}| {{cell .Name}} | {{cell .Comment}} |
+{{end}}{{end}}
+{{- if .Paths}}
+### Embedded paths
+
+| Name | Code | Kind | Volume | UUID | Path |
+|---|---|---|---|---|---|
+{{range .Paths}}| {{cell .FileName}} | {{.Code}} | {{.Kind}} | {{cell .Volume}} | {{.VolumeUUID}} | {{cell .Path}} |
+{{end}}{{end}}
+{{- if .Unknown}}
+### Unknown structure IDs
+
+| Code | Type | Records | Guess | Confidence | Detail |
+|---|---|---|---|---|---|
+{{range .Unknown}}| {{cell .Code}} | {{.Type}} | {{.Records}} | {{.Guess.Kind}} | {{.Guess.Confidence}} | {{cell .Guess.Detail}} |
+{{end}}{{end}}
+{{- if .Events}}
+### Timeline
+
+| Time | Name | Code | Kind |
+|---|---|---|---|
+{{range .Events}}| {{time .Time}} | {{cell .FileName}} | {{.Code}} | {{.Kind}} |
+{{end}}{{end}}
+{{- end}}
+{{- if .Summary.Anomalies}}
+## Anomalies
+
+| Store | Kind | Message |
+|---|---|---|
+{{range .Summary.Anomalies}}| {{cell .Path}} | {{.Kind}} | {{cell .Message}} |
+{{end}}{{end}}
+## Raw data
+
+` + "```json\n{{.JSON}}\n```\n"))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>.DS_Store report</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 70em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; text-align: left; vertical-align: top; }
+th { background: #f3f3f3; }
+code, pre { font-family: Menlo, Consolas, monospace; font-size: .9em; }
+pre { background: #f7f7f7; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>.DS_Store report</h1>
+{{with .Summary}}<p>{{.Stores}} stores ({{.Failed}} failed), {{.Records}} records, {{.Files}} file names
+{{- if not .Earliest.IsZero}}, timestamps from {{time .Earliest}} to {{time .Latest}}{{end}}.</p>{{end}}
+{{if .Stores}}<table>
+<tr><th>Store</th><th>Files</th><th>Deleted files</th><th>Comments</th><th>Users</th><th>Volumes</th></tr>
+{{range .Stores}}<tr><td><a href="#store-{{.Path}}">{{.Path}}</a></td><td>{{len .Privacy.Files}}</td><td>{{len .Privacy.DeletedFiles}}</td><td>{{len .Privacy.Comments}}</td><td>{{join .Privacy.Users ", "}}</td><td>{{join .Privacy.Volumes ", "}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Codes}}<h2>Structure IDs</h2>
+<table>
+<tr><th>Code</th><th>Records</th></tr>
+{{range .Codes}}<tr><td><code>{{.}}</code></td><td>{{index $.Summary.Codes .}}</td></tr>
+{{end}}</table>{{end}}
+{{range .Stores}}<h2 id="store-{{.Path}}">{{.Path}}</h2>
+{{if .SHA256}}<p>SHA-256 <code>{{.SHA256}}</code></p>{{end}}
+<ul>
+{{range lines .Privacy.Summary}}<li>{{.}}</li>
+{{end}}</ul>
+<h3>Files</h3>
+<ul>
+{{range .Privacy.Files}}<li>{{.}}</li>
+{{end}}{{range .Privacy.DeletedFiles}}<li>{{.}} (deleted)</li>
+{{end}}</ul>
+{{if .Privacy.Comments}}<h3>Finder comments</h3>
+<table>
+<tr><th>Name</th><th>Comment</th></tr>
+{{range .Privacy.Comments}}<tr><td>{{.Name}}</td><td>{{.Comment}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Paths}}<h3>Embedded paths</h3>
+<table>
+<tr><th>Name</th><th>Code</th><th>Kind</th><th>Volume</th><th>UUID</th><th>Path</th></tr>
+{{range .Paths}}<tr><td>{{.FileName}}</td><td><code>{{.Code}}</code></td><td>{{.Kind}}</td><td>{{.Volume}}</td><td>{{.VolumeUUID}}</td><td>{{.Path}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Unknown}}<h3>Unknown structure IDs</h3>
+<table>
+<tr><th>Code</th><th>Type</th><th>Records</th><th>Guess</th><th>Confidence</th><th>Detail</th></tr>
+{{range .Unknown}}<tr><td><code>{{.Code}}</code></td><td>{{.Type}}</td><td>{{.Records}}</td><td>{{.Guess.Kind}}</td><td>{{.Guess.Confidence}}</td><td>{{.Guess.Detail}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Events}}<h3>Timeline</h3>
+<table>
+<tr><th>Time</th><th>Name</th><th>Code</th><th>Kind</th></tr>
+{{range .Events}}<tr><td>{{time .Time}}</td><td>{{.FileName}}</td><td><code>{{.Code}}</code></td><td>{{.Kind}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{if .Summary.Anomalies}}<h2>Anomalies</h2>
+<table>
+<tr><th>Store</th><th>Kind</th><th>Message</th></tr>
+{{range .Summary.Anomalies}}<tr><td>{{.Path}}</td><td>{{.Kind}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{end}}
+<details>
+<summary>Raw data</summary>
+<pre>{{.JSON}}</pre>
+</details>
+</body>
+</html>
+`))
@@ -0,0 +1,57 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestForensicReport(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	r := NewForensicReport()
+	_ = r.Add("b/.DS_Store", &Store{Records: []Record{TextRecord("x|y", "cmmt", "<script>")}}, nil)
+	_ = r.Add("a/.DS_Store", s, nil)
+	_ = r.Add("c/.DS_Store", nil, errors.New("broken"))
+	if len(r.Stores) != 2 || r.Stores[0].Path != "a/.DS_Store" || r.Summary.Failed != 1 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"2 stores (1 failed), 7 records, 3 file names.\n\n| Store",
+		"| a/.DS_Store | 2 | 0 | 0 | gwend | Macintosh HD |\n",
+		"## a/.DS_Store\n\n- 2 file names are listed\n",
+		"| . | pBBk | bookmark | Macintosh HD | 81DED881-7FF2-4FBB-A8F8-DC8A4BC4C10E | /Users/gwend/",
+		"| 2019-10-13T12:26:06Z | . | pBBk | bookmark |\n",
+		"| c/.DS_Store | error | broken |\n",
+		"### Files\n\n- x|y\n",
+		"| x\\|y | <script> |\n",
+		"```json\n{\n  \"summary\": {",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown doesn't contain %q:\n%s", want, md.String())
+		}
+	}
+	var html bytes.Buffer
+	if err := r.WriteHTML(&html); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if !strings.Contains(html.String(), "<li>2 file names are listed</li>") || strings.Contains(html.String(), "<script>") ||
+		!strings.Contains(html.String(), "&lt;script&gt;") {
+		t.Errorf("unexpected HTML:\n%s", html.String())
+	}
+	var decoded struct {
+		Stores []StoreFindings `json:"stores"`
+	}
+	var raw bytes.Buffer
+	if err := r.WriteJSON(&raw); err != nil || json.Unmarshal(raw.Bytes(), &decoded) != nil || len(decoded.Stores) != 2 {
+		t.Errorf("unexpected JSON %s: %v", raw.String(), err)
+	}
+}
@@ -0,0 +1,193 @@
+package dsstore
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FinderGeneration is a generation of Finder told by structure IDs it writes
+type FinderGeneration int
+
+// Generations of Finder, newer generations replace records of older ones
+const (
+	FinderUnknown FinderGeneration = iota // no records specific to a generation
+	FinderLegacy                          // "icvo", "pict", "lsvo", "fwi0" records of Mac OS X 10.5 and earlier
+	FinderPlist                           // property lists of "icvp", "bwsp", "lsvp" and bookmarks of "pBBk" of Mac OS X 10.6 and later
+	FinderRecent                          // "lsvP" records of recent versions of macOS
+)
+
+// String returns description of the generation
+func (g FinderGeneration) String() string {
+	switch g {
+	case FinderLegacy:
+		return "Mac OS X 10.5 or earlier"
+	case FinderPlist:
+		return "Mac OS X 10.6 or later"
+	case FinderRecent:
+		return "recent macOS"
+	default:
+		return "unknown"
+	}
+}
+
+// generationCodes are structure IDs written by Finder of a generation and the ones replacing them in newer generations
+var generationCodes = []struct {
+	code       string
+	generation FinderGeneration
+	replacedBy []string
+}{
+	{"ICVO", FinderLegacy, []string{"icvo", "icvp"}},
+	{"LSVO", FinderLegacy, []string{"lsvo", "lsvp", "lsvP"}},
+	{"BKGD", FinderLegacy, []string{"icvp"}},
+	{"icvo", FinderLegacy, []string{"icvp"}},
+	{"lsvo", FinderLegacy, []string{"lsvp", "lsvP"}},
+	{"fwi0", FinderLegacy, []string{"bwsp"}},
+	{"pict", FinderLegacy, []string{"pBBk"}},
+	{"icvp", FinderPlist, nil},
+	{"bwsp", FinderPlist, nil},
+	{"pBBk", FinderPlist, nil},
+	{"lsvp", FinderPlist, []string{"lsvP"}},
+	{"lsvP", FinderRecent, nil},
+}
+
+// Support is a level of support of a feature by the library
+type Support int
+
+// Levels of support
+const (
+	SupportRaw  Support = iota // records are kept byte for byte, but not decoded
+	SupportRead                // records are decoded, but written only as raw data
+	SupportFull                // records are decoded and written by typed API
+)
+
+// String returns description of the level
+func (s Support) String() string {
+	switch s {
+	case SupportFull:
+		return "full"
+	case SupportRead:
+		return "read"
+	default:
+		return "raw"
+	}
+}
+
+// FeatureSupport is a feature of records of the store with its support by the library
+type FeatureSupport struct {
+	Feature string
+	Codes   []string // structure IDs of the feature present in the store
+	Support Support
+	Note    string // what to expect on editing, empty for fully supported features
+}
+
+// features are features of records with their support, in order of reporting
+var features = []struct {
+	feature string
+	codes   []string
+	support Support
+	note    string
+}{
+	{"view style", []string{"vstl"}, SupportFull, ""},
+	{"icon positions", []string{"Iloc"}, SupportFull, ""},
+	{"Finder comments", []string{"cmmt"}, SupportFull, ""},
+	{"icon view settings", []string{"icvp"}, SupportFull, ""},
+	{"legacy icon view settings", []string{"icvo", "ICVO"}, SupportRaw,
+		"Finder of Mac OS X 10.6 and later reads icvp, FolderSettings doesn't decode them"},
+	{"window settings", []string{"bwsp"}, SupportFull, ""},
+	{"legacy window settings", []string{"fwi0"}, SupportRead,
+		"bounds and view style are decoded, but FolderSettings.Records writes bwsp instead"},
+	{"background bookmark", []string{"pBBk"}, SupportRead,
+		"bookmarks are decoded, but not written; SetBackground changes the alias of icvp, which Finder may ignore while pBBk is present"},
+	{"legacy background", []string{"pict", "BKGD"}, SupportRead,
+		"aliases of pict are decoded, BKGD is kept raw; SetBackground writes the alias of icvp instead"},
+	{"list view settings", []string{"lsvp", "lsvP"}, SupportRead,
+		"property lists are decoded by Record.Value, but there is no typed API for them"},
+	{"legacy list view settings", []string{"lsvo", "LSVO"}, SupportRaw, ""},
+}
+
+// FormatInfoReport is the generation of Finder which likely wrote the store and support of its features
+type FormatInfoReport struct {
+	Generation FinderGeneration // newest generation of present records
+	Evidence   []string         // structure IDs of the generation, sorted
+	Stale      []string         // structure IDs of older generations replaced by present records, Finder doesn't read them
+	Features   []FeatureSupport // features of present records
+	Unknown    []string         // structure IDs which are not known, they are kept byte for byte
+	Library    string           // module path and version of the library, like "github.com/strongo/dsstore v1.2.0"
+}
+
+// FormatInfo reports which generation of Finder likely wrote the store, telling it by present structure IDs
+// (like "icvo" or "icvp", "pict" or "pBBk", "lsvp" or "lsvP"), and which features of the store the library
+// fully supports, so reports like "why doesn't my edit stick" can be triaged
+func FormatInfo(s *Store) FormatInfoReport {
+	present := make(map[string]bool)
+	for _, r := range s.Records {
+		present[r.Code()] = true
+	}
+	info := FormatInfoReport{Library: toolVersion()}
+	for _, g := range generationCodes {
+		if present[g.code] && g.generation > info.Generation {
+			info.Generation = g.generation
+		}
+	}
+	for _, g := range generationCodes {
+		if !present[g.code] {
+			continue
+		}
+		if g.generation == info.Generation {
+			info.Evidence = append(info.Evidence, g.code)
+		}
+		if slices.ContainsFunc(g.replacedBy, func(code string) bool { return present[code] }) {
+			info.Stale = append(info.Stale, g.code)
+		}
+	}
+	slices.Sort(info.Evidence)
+	slices.Sort(info.Stale)
+	for _, f := range features {
+		var codes []string
+		for _, code := range f.codes {
+			if present[code] {
+				codes = append(codes, code)
+			}
+		}
+		if codes != nil {
+			info.Features = append(info.Features, FeatureSupport{Feature: f.feature, Codes: codes, Support: f.support, Note: f.note})
+		}
+	}
+	for code := range present {
+		if !IsKnownCode(code) {
+			info.Unknown = append(info.Unknown, code)
+		}
+	}
+	slices.Sort(info.Unknown)
+	return info
+}
+
+// Summary returns human-readable summary of the report
+func (r FormatInfoReport) Summary() string {
+	var b strings.Builder
+	if r.Generation == FinderUnknown {
+		b.WriteString("generation of Finder is unknown")
+	} else {
+		_, _ = fmt.Fprintf(&b, "written by Finder of %s", r.Generation)
+	}
+	if r.Evidence != nil {
+		_, _ = fmt.Fprintf(&b, " (%s)", strings.Join(r.Evidence, ", "))
+	}
+	b.WriteString("\n")
+	if r.Stale != nil {
+		_, _ = fmt.Fprintf(&b, "stale records of older Finder: %s\n", strings.Join(r.Stale, ", "))
+	}
+	for _, f := range r.Features {
+		_, _ = fmt.Fprintf(&b, "%s (%s): %s", f.Feature, strings.Join(f.Codes, ", "), f.Support)
+		if f.Note != "" {
+			_, _ = fmt.Fprintf(&b, ", %s", f.Note)
+		}
+		b.WriteString("\n")
+	}
+	if r.Unknown != nil {
+		_, _ = fmt.Fprintf(&b, "unknown structure IDs are kept raw: %s\n", strings.Join(r.Unknown, ", "))
+	}
+	_, _ = fmt.Fprintf(&b, "library: %s\n", r.Library)
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package dsstore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatInfo(t *testing.T) {
+	s := &Store{}
+	for _, code := range []string{"icvo", "icvp", "pBBk", "Iloc", "zzzz"} {
+		r := Record{FileName: ".", Type: "blob", DataLen: 1, Data: []byte{0}}
+		r.SetCode(code)
+		s.Records = append(s.Records, r)
+	}
+	info := FormatInfo(s)
+	if info.Generation != FinderPlist {
+		t.Errorf("expected %v, got %v", FinderPlist, info.Generation)
+	}
+	if !reflect.DeepEqual(info.Evidence, []string{"icvp", "pBBk"}) || !reflect.DeepEqual(info.Stale, []string{"icvo"}) ||
+		!reflect.DeepEqual(info.Unknown, []string{"zzzz"}) {
+		t.Errorf("unexpected evidence %v, stale %v, unknown %v", info.Evidence, info.Stale, info.Unknown)
+	}
+	var features []string
+	for _, f := range info.Features {
+		features = append(features, f.Feature+" "+f.Support.String())
+	}
+	expected := []string{"icon positions full", "icon view settings full", "legacy icon view settings raw", "background bookmark read"}
+	if !reflect.DeepEqual(features, expected) {
+		t.Errorf("expected features %v, got %v", expected, features)
+	}
+	summary := info.Summary()
+	for _, want := range []string{"written by Finder of Mac OS X 10.6 or later (icvp, pBBk)", "stale records of older Finder: icvo",
+		"background bookmark (pBBk): read, bookmarks are decoded", "kept raw: zzzz", "library: github.com/strongo/dsstore"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary doesn't contain %q:\n%s", want, summary)
+		}
+	}
+
+	r := Record{FileName: ".", Type: "blob", DataLen: 1, Data: []byte{0}}
+	r.SetCode("lsvP")
+	s.Records = append(s.Records, r)
+	if info = FormatInfo(s); info.Generation != FinderRecent || !reflect.DeepEqual(info.Evidence, []string{"lsvP"}) {
+		t.Errorf("expected recent generation of lsvP, got %v %v", info.Generation, info.Evidence)
+	}
+	if info = FormatInfo(&Store{}); info.Generation != FinderUnknown || info.Features != nil {
+		t.Errorf("expected unknown generation of empty store, got %+v", info)
+	}
+}
@@ -0,0 +1,69 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/strongo/dsstore/buddy"
+)
+
+// allocatorSpace is size of the address space managed by buddy allocator
+const allocatorSpace = uint64(1) << 31
+
+// allocation of blocks in read .DS_Store
+type allocation struct {
+	read      bool              // allocation is read from file
+	offsets   []uint32          // addresses of allocated blocks
+	topics    map[string]uint32 // block indexes by topic name
+	freeLists [32][]uint32      // offsets of free blocks by power of 2 of block size
+	levels    uint32            // depth of data B-tree from DSDB block
+	nodes     uint32            // count of data B-tree nodes from DSDB block
+	pageSize  uint32            // page size of data B-tree nodes from DSDB block
+}
+
+type addressRange struct {
+	start, size uint64
+	what        string
+}
+
+// ValidateFreeList checks that buddy allocator free lists of read .DS_Store
+// exactly cover the space which is not used by allocated blocks:
+// free blocks don't overlap allocated blocks and there are no gaps.
+// All found discrepancies are joined into one error.
+func (s *Store) ValidateFreeList() error {
+	if !s.alloc.read {
+		return errors.New("allocation is not read")
+	}
+	// the first 32 bytes are used by the file header
+	ranges := []addressRange{{0, 32, "header"}}
+	for i, offset := range s.alloc.offsets {
+		ranges = append(ranges, addressRange{uint64(buddy.Offset(offset)), uint64(buddy.Size(offset)), fmt.Sprintf("block %d", i)})
+	}
+	for i, list := range s.alloc.freeLists {
+		for _, offset := range list {
+			ranges = append(ranges, addressRange{uint64(offset), uint64(1) << i, "free block"})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+	var errs []error
+	var end uint64
+	for _, r := range ranges {
+		if r.start%r.size != 0 {
+			errs = append(errs, fmt.Errorf("%s at %#x is not aligned to its size %#x", r.what, r.start, r.size))
+		}
+		if r.start < end {
+			errs = append(errs, fmt.Errorf("%s at %#x overlaps previous block ending at %#x", r.what, r.start, end))
+		}
+		if r.start > end {
+			errs = append(errs, fmt.Errorf("gap from %#x to %#x is neither allocated nor free", end, r.start))
+		}
+		end = max(end, r.start+r.size)
+	}
+	if end < allocatorSpace {
+		errs = append(errs, fmt.Errorf("gap from %#x to %#x is neither allocated nor free", end, allocatorSpace))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package dsstore
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFreeList(t *testing.T) {
+	var s Store
+	if err := s.ValidateFreeList(); err == nil {
+		t.Error("expected error for not read store")
+	}
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if err := s.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+
+	valid := s.alloc
+	tests := []struct {
+		name   string
+		modify func(a *allocation)
+		err    string
+	}{
+		{"Gap", func(a *allocation) { a.freeLists[5] = a.freeLists[5][1:] }, "gap from 0x20 to 0x40"},
+		{"GapAtEnd", func(a *allocation) { a.freeLists[30] = nil }, "gap from 0x40000000 to 0x80000000"},
+		{"Overlap", func(a *allocation) { a.freeLists[5] = append(a.freeLists[5], 0x40) }, "free block at 0x40 overlaps"},
+		{"NotAligned", func(a *allocation) { a.freeLists[6] = []uint32{0x20} }, "free block at 0x20 is not aligned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.alloc = valid
+			for i := range s.alloc.freeLists {
+				s.alloc.freeLists[i] = append([]uint32(nil), valid.freeLists[i]...)
+			}
+			tt.modify(&s.alloc)
+			err := s.ValidateFreeList()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected %q error, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateFreeListWritten(t *testing.T) {
+	s := &Store{}
+	s.Records = append(s.Records, Record{FileName: "test", Type: "bool", Data: []byte{1}})
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var s2 Store
+	if err := s2.Read(buf); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if err := s2.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list of written store, got %v", err)
+	}
+}
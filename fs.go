@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ReadFS reads .DS_Store from the file of the file system, like embed.FS or zip.Reader
+func (s *Store) ReadFS(fsys fs.FS, name string) error {
+	return s.ReadFSWithOptions(fsys, name, ReadOptions{})
+}
+
+// ReadFSWithOptions reads .DS_Store from the file of the file system using options
+func (s *Store) ReadFSWithOptions(fsys fs.FS, name string, opts ReadOptions) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return s.ReadWithOptions(f, opts)
+}
+
+// OpenFS opens .DS_Store file of the file system for reading records on demand.
+// The Reader must be closed to close the file.
+func OpenFS(fsys fs.FS, name string) (*Reader, error) {
+	return OpenFSWithOptions(fsys, name, ReadOptions{})
+}
+
+// OpenFSWithOptions opens .DS_Store file of the file system using options.
+// Files which don't implement io.ReaderAt are read to memory.
+// The Reader must be closed to close the file.
+func OpenFSWithOptions(fsys fs.FS, name string, opts ReadOptions) (*Reader, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		return openSource(ra, readerAtSource{r: ra, fileSize: info.Size()}, info.Size(), opts, f.Close)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	limit := opts.withDefaults().MaxFileSize + 1
+	data, err := readAll(f, info.Size(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return openBytes(data, int64(len(data)), opts, nil)
+}
@@ -0,0 +1,108 @@
+package dsstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata/00.DS_Store
+var testdataFS embed.FS
+
+func testFileSystems(t *testing.T) map[string]fs.FS {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("testdata/00.DS_Store")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err = w.Write(fileData); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	return map[string]fs.FS{
+		"os":    os.DirFS("."),
+		"embed": testdataFS,
+		"map":   fstest.MapFS{"testdata/00.DS_Store": {Data: fileData}},
+		"zip":   zr,
+	}
+}
+
+func TestReadFS(t *testing.T) {
+	var expected Store
+	if err := expected.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for name, fsys := range testFileSystems(t) {
+		t.Run(name, func(t *testing.T) {
+			var s Store
+			if err := s.ReadFS(fsys, "testdata/00.DS_Store"); err != nil {
+				t.Fatalf("ReadFS failed: %v", err)
+			}
+			if len(s.Records) != len(expected.Records) {
+				t.Fatalf("expected %d records, got %d", len(expected.Records), len(s.Records))
+			}
+			for i := range s.Records {
+				if !s.Records[i].Equal(expected.Records[i]) {
+					t.Errorf("record %d is different", i)
+				}
+			}
+			// reader reads the same records
+			r, err := OpenFS(fsys, "testdata/00.DS_Store")
+			if err != nil {
+				t.Fatalf("OpenFS failed: %v", err)
+			}
+			defer func() {
+				if err := r.Close(); err != nil {
+					t.Errorf("Close failed: %v", err)
+				}
+			}()
+			i := 0
+			for record := range r.Records() {
+				if i >= len(expected.Records) || !record.Equal(expected.Records[i]) {
+					t.Errorf("record %d is different", i)
+				}
+				i++
+			}
+			if err = r.Err(); err != nil || i != len(expected.Records) {
+				t.Errorf("expected %d records, got %d: %v", len(expected.Records), i, err)
+			}
+		})
+	}
+}
+
+func TestReadFSErrors(t *testing.T) {
+	fsys := fstest.MapFS{"big/.DS_Store": {Data: make([]byte, 100)}}
+	var s Store
+	if err := s.ReadFS(fsys, "missing/.DS_Store"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if _, err := OpenFS(fsys, "missing/.DS_Store"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if _, err := OpenFS(fsys, "big"); err == nil {
+		t.Error("expected error for directory")
+	}
+	if err := s.ReadFSWithOptions(fsys, "big/.DS_Store", ReadOptions{MaxFileSize: 10}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected limit error, got %v", err)
+	}
+	if _, err := OpenFSWithOptions(fsys, "big/.DS_Store", ReadOptions{MaxFileSize: 10}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected limit error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fuzzSeeds(f *testing.F) {
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		f.Fatalf("ReadFile failed: %v", err)
+	}
+	f.Add(data)
+	s := &Store{}
+	s.Records = append(s.Records, Record{FileName: "test", Type: "bool", Data: []byte{1}})
+	buf := new(bytes.Buffer)
+	if err = s.Write(buf); err != nil {
+		f.Fatalf("Write failed: %v", err)
+	}
+	f.Add(buf.Bytes())
+}
+
+func FuzzRead(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Store
+		_ = s.Read(bytes.NewReader(data))
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s1 Store
+		if err := s1.Read(bytes.NewReader(data)); err != nil {
+			return
+		}
+		buf := new(bytes.Buffer)
+		if err := s1.Write(buf); err != nil {
+			// records like ones with empty file names are read, but they can't be written
+			if errors.Is(err, ErrInvalidRecord) && s1.Validate() != nil {
+				return
+			}
+			t.Fatalf("Write of read store failed: %v", err)
+		}
+		var s2 Store
+		if err := s2.Read(buf); err != nil {
+			t.Fatalf("Read of written store failed: %v", err)
+		}
+		if len(s1.Records) != len(s2.Records) {
+			t.Fatalf("expected %d records, got %d", len(s1.Records), len(s2.Records))
+		}
+		// records are written sorted by B-tree keys
+		records := s1.sortedRecords()
+		for i := range records {
+			r1, r2 := records[i], s2.Records[i]
+			if r1.FileName != r2.FileName || r1.Extra != r2.Extra || r1.Type != r2.Type ||
+				r1.DataLen != r2.DataLen || !bytes.Equal(r1.Data, r2.Data) {
+				t.Fatalf("record %d is different: %+v != %+v", i, r1, r2)
+			}
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Defaults of GenerateOptions
+const (
+	DefaultGenerateMaxRecords = 200
+	DefaultGenerateMaxNameLen = 64
+	DefaultGenerateMaxDataLen = 1024
+)
+
+// GenerateOptions of random store generation
+type GenerateOptions struct {
+	MaxRecords int  // maximal count of records, zero value selects default
+	MaxNameLen int  // maximal length of file names in characters, zero value selects default
+	MaxDataLen int  // maximal size of blob data in bytes and of ustr data in characters, zero value selects default
+	ASCII      bool // file names have only printable ASCII characters, otherwise they have any characters including ones out of BMP
+	KnownCodes bool // only structure IDs of KnownCodes are used, otherwise random codes are used too
+}
+
+func (o GenerateOptions) withDefaults() GenerateOptions {
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = DefaultGenerateMaxRecords
+	}
+	if o.MaxNameLen <= 0 {
+		o.MaxNameLen = DefaultGenerateMaxNameLen
+	}
+	if o.MaxDataLen <= 0 {
+		o.MaxDataLen = DefaultGenerateMaxDataLen
+	}
+	return o
+}
+
+// generatedTypes are types of generated records
+var generatedTypes = []string{"bool", "type", "long", "shor", "comp", "dutc", "blob", "ustr"}
+
+// generatedRunes are ranges of characters of generated file names:
+// ASCII, Latin-1, Cyrillic, CJK and emoji out of BMP
+var generatedRunes = [][2]rune{{0x20, 0x7e}, {0xa0, 0xff}, {0x400, 0x4ff}, {0x4e00, 0x4fff}, {0x1f600, 0x1f64f}}
+
+// GenerateRandomStore returns structurally valid store with random records for property-based testing.
+// Records have unique keys, random file names, structure IDs, types and data, so written stores
+// have random tree shapes: from one leaf to several levels of nodes with big records.
+// The same seed of rand generates the same store. Rand of math/rand is used for testing/quick.
+func GenerateRandomStore(rand *rand.Rand, opts GenerateOptions) *Store {
+	opts = opts.withDefaults()
+	codes := slices.Sorted(maps.Keys(KnownCodes))
+	// few file names have many records like in real stores, so nodes are split inside of records of a file
+	names := make([]string, 1+rand.Intn(opts.MaxRecords))
+	for i := range names {
+		names[i] = generateName(rand, opts)
+	}
+	s := &Store{}
+	keys := make(map[recordKey]bool)
+	count := rand.Intn(opts.MaxRecords + 1)
+	for range count {
+		r := generateRecord(rand, opts, codes)
+		r.FileName = names[rand.Intn(len(names))]
+		key := recordKey{strings.ToLower(r.FileName), r.Code()}
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+		s.Records = append(s.Records, r)
+	}
+	return s
+}
+
+// generateName returns random valid file name of 1 to MaxNameLen characters
+func generateName(rand *rand.Rand, opts GenerateOptions) string {
+	for {
+		if name := generateAnyName(rand, opts); ValidateFileName(name) == nil {
+			return name
+		}
+	}
+}
+
+// generateAnyName returns random file name of 1 to MaxNameLen characters, it may be invalid
+func generateAnyName(rand *rand.Rand, opts GenerateOptions) string {
+	// short names are more common
+	n := 1 + rand.Intn(opts.MaxNameLen)
+	if rand.Intn(4) > 0 {
+		n = 1 + rand.Intn(min(opts.MaxNameLen, 16))
+	}
+	var b strings.Builder
+	for range n {
+		ranges := generatedRunes
+		if opts.ASCII || rand.Intn(2) == 0 {
+			ranges = generatedRunes[:1]
+		}
+		r := ranges[rand.Intn(len(ranges))]
+		b.WriteRune(r[0] + rand.Int31n(r[1]-r[0]+1))
+	}
+	return b.String()
+}
+
+// generateRecord returns record with random structure ID, type and data without file name
+func generateRecord(rand *rand.Rand, opts GenerateOptions, codes []string) Record {
+	r := Record{Type: generatedTypes[rand.Intn(len(generatedTypes))]}
+	if opts.KnownCodes || rand.Intn(2) == 0 {
+		r.SetCode(codes[rand.Intn(len(codes))])
+	} else {
+		code := make([]byte, 4)
+		for i := range code {
+			code[i] = byte('a' + rand.Intn(26))
+		}
+		r.SetCode(string(code))
+	}
+	// small data is more common, big data makes nodes with few records
+	size := 1 + rand.Intn(min(opts.MaxDataLen, 32))
+	if rand.Intn(8) == 0 {
+		size = 1 + rand.Intn(opts.MaxDataLen)
+	}
+	switch r.Type {
+	case "blob":
+		r.DataLen = uint32(size)
+		r.Data = make([]byte, size)
+		rand.Read(r.Data)
+	case "ustr":
+		r.DataLen = uint32(size)
+		r.Data = make([]byte, 0, 2*size)
+		for range size {
+			r.Data = binary.BigEndian.AppendUint16(r.Data, uint16(0x20+rand.Intn(0xd7ff-0x20)))
+		}
+	default:
+		r.Data = make([]byte, typeSizes[r.Type])
+		rand.Read(r.Data)
+	}
+	return r
+}
+
+// Generate implements quick.Generator, size limits count of records
+func (*Store) Generate(rand *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(GenerateRandomStore(rand, GenerateOptions{MaxRecords: max(size, 1)}))
+}
+
+// Generate implements quick.Generator, size limits size of data
+func (Record) Generate(rand *rand.Rand, size int) reflect.Value {
+	opts := GenerateOptions{MaxDataLen: max(size, 1)}.withDefaults()
+	r := generateRecord(rand, opts, slices.Sorted(maps.Keys(KnownCodes)))
+	r.FileName = generateName(rand, opts)
+	return reflect.ValueOf(r)
+}
@@ -0,0 +1,70 @@
+package dsstore
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestGenerateRandomStore(t *testing.T) {
+	s1 := GenerateRandomStore(rand.New(rand.NewSource(1)), GenerateOptions{})
+	s2 := GenerateRandomStore(rand.New(rand.NewSource(1)), GenerateOptions{})
+	if len(s1.Records) == 0 || !reflect.DeepEqual(s1.Records, s2.Records) {
+		t.Error("expected the same records for the same seed")
+	}
+
+	ascii := GenerateRandomStore(rand.New(rand.NewSource(2)), GenerateOptions{ASCII: true, KnownCodes: true, MaxRecords: 50})
+	if len(ascii.Records) > 50 {
+		t.Errorf("expected at most 50 records, got %d", len(ascii.Records))
+	}
+	for _, r := range ascii.Records {
+		if !IsKnownCode(r.Code()) {
+			t.Errorf("expected known code, got %q", r.Code())
+		}
+		for _, c := range r.FileName {
+			if c < 0x20 || c > 0x7e {
+				t.Errorf("expected ASCII file name, got %q", r.FileName)
+			}
+		}
+	}
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	roundTrip := func(s *Store) bool {
+		if err := s.Validate(); err != nil {
+			t.Errorf("expected valid store, got %v", err)
+			return false
+		}
+		buf := new(bytes.Buffer)
+		if err := s.WriteWithOptions(buf, WriteOptions{PageSize: 256}); err != nil {
+			t.Errorf("Write failed: %v", err)
+			return false
+		}
+		var s2 Store
+		if err := s2.Read(buf); err != nil {
+			t.Errorf("Read failed: %v", err)
+			return false
+		}
+		records := s.sortedRecords()
+		if len(records) != len(s2.Records) {
+			return false
+		}
+		for i := range records {
+			if !records[i].Equal(s2.Records[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, &quick.Config{Rand: rand.New(rand.NewSource(3))}); err != nil {
+		t.Error(err)
+	}
+	validRecord := func(r Record) bool {
+		return r.Validate() == nil
+	}
+	if err := quick.Check(validRecord, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,44 @@
+package dsstore
+
+import "github.com/strongo/dsstore/buddy"
+
+// Geometry describes B-tree and allocated space of read .DS_Store
+type Geometry struct {
+	Depth     int   // levels of data B-tree counting from 1 at the root, 0 for a tree without nodes
+	Nodes     int   // count of data B-tree nodes
+	Blocks    int   // count of allocated blocks
+	Allocated int64 // size of the allocated region, from the start of the file to the end of the last block
+	Free      int64 // size of free blocks inside of the allocated region
+}
+
+// FreeRatio returns part of the allocated region which is free, from 0 to 1
+func (g Geometry) FreeRatio() float64 {
+	if g.Allocated == 0 {
+		return 0
+	}
+	return float64(g.Free) / float64(g.Allocated)
+}
+
+// Geometry returns geometry of read .DS_Store, it returns false when the store isn't read
+func (s *Store) Geometry() (Geometry, bool) {
+	if !s.alloc.read {
+		return Geometry{}, false
+	}
+	g := Geometry{Nodes: int(s.alloc.nodes), Blocks: len(s.alloc.offsets)}
+	if g.Nodes > 0 {
+		// DSDB block has levels below the root, so a tree of one leaf has 0 levels
+		g.Depth = int(s.alloc.levels) + 1
+	}
+	// the first 32 bytes are used by the file header
+	end := int64(32)
+	for _, offset := range s.alloc.offsets {
+		end = max(end, int64(buddy.Offset(offset))+int64(buddy.Size(offset)))
+	}
+	g.Allocated = end
+	for i, list := range s.alloc.freeLists {
+		for _, offset := range list {
+			g.Free += max(0, min(end, int64(offset)+int64(1)<<i)-int64(offset))
+		}
+	}
+	return g, true
+}
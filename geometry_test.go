@@ -0,0 +1,44 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeometry(t *testing.T) {
+	var s Store
+	if _, ok := s.Geometry(); ok {
+		t.Error("expected no geometry of store which isn't read")
+	}
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	g, ok := s.Geometry()
+	if !ok || g.Depth != 1 || g.Nodes != 1 || g.Blocks < 3 || g.Allocated == 0 || g.Free >= g.Allocated {
+		t.Errorf("unexpected geometry %+v", g)
+	}
+	if ratio := g.FreeRatio(); ratio < 0 || ratio >= 1 {
+		t.Errorf("unexpected free ratio %v", ratio)
+	}
+}
+
+func TestGeometryDepth(t *testing.T) {
+	s := &Store{}
+	for i := range 1000 {
+		s.Records = append(s.Records, TextRecord(fmt.Sprintf("file%04d", i), "cmmt", "comment"))
+	}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var read Store
+	if err := read.Read(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	// the root node and its leaves
+	if g, _ := read.Geometry(); g.Depth != 2 || g.Nodes < 3 {
+		t.Errorf("expected 2 levels of nodes, got %+v", g)
+	}
+}
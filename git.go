@@ -0,0 +1,122 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+)
+
+// ErrBlocked is returned by GitCleanFilter for .DS_Store files in GitBlock mode
+var ErrBlocked = errors.New(".DS_Store files must not be committed")
+
+// GitFilterMode selects handling of .DS_Store files by GitCleanFilter
+type GitFilterMode int
+
+// Modes of GitCleanFilter
+const (
+	GitBlock GitFilterMode = iota // fail, so git refuses to add the file
+	GitStrip                      // replace content of the file by empty content
+)
+
+// GitCleanFilter is git clean filter for .DS_Store files, configured in .gitattributes by
+// ".DS_Store filter=dsstore" and in git config by "filter.dsstore.clean" and "filter.dsstore.required".
+// Content of the file at the path is read from r and the cleaned content is written to w.
+// Content of files other than .DS_Store is copied as is.
+func GitCleanFilter(w io.Writer, r io.Reader, filePath string, mode GitFilterMode) error {
+	if path.Base(filePath) != StoreFileName {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	// content is drained, so git doesn't fail writing to the filter
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if mode == GitBlock {
+		return fmt.Errorf("%s: %w", filePath, ErrBlocked)
+	}
+	return nil
+}
+
+// Finding is .DS_Store or AppleDouble file found by CheckTree
+type Finding struct {
+	Path  string   // slash-separated path of the file
+	Names []string // file names leaked by records of the store, sorted
+	Err   error    // error of reading the store
+}
+
+// String returns description of the finding for hooks output
+func (f Finding) String() string {
+	switch {
+	case f.Err != nil:
+		return fmt.Sprintf("%s: unreadable store: %v", f.Path, f.Err)
+	case path.Base(f.Path) != StoreFileName:
+		return f.Path + ": AppleDouble file of .DS_Store"
+	default:
+		return fmt.Sprintf("%s: store with %d file names", f.Path, len(f.Names))
+	}
+}
+
+// isStoreFile reports whether the file is .DS_Store or its AppleDouble file
+func isStoreFile(name string) bool {
+	name = path.Base(name)
+	return name == StoreFileName || name == "._"+StoreFileName
+}
+
+// CheckTree finds .DS_Store files and their AppleDouble "._.DS_Store" files of the file tree
+// for pre-commit hooks, .git directory is skipped. Errors of walking are reported as findings.
+func CheckTree(fsys fs.FS) []Finding {
+	var findings []Finding
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			findings = append(findings, Finding{Path: p, Err: err})
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && isStoreFile(p) {
+			findings = append(findings, checkFile(fsys, p))
+		}
+		return nil
+	})
+	return findings
+}
+
+// CheckPaths is CheckTree for the files of the file system, like staged files listed by
+// "git diff --cached --name-only". Files other than .DS_Store and missing files are skipped.
+func CheckPaths(fsys fs.FS, paths []string) []Finding {
+	var findings []Finding
+	for _, p := range paths {
+		if !isStoreFile(p) {
+			continue
+		}
+		if _, err := fs.Stat(fsys, p); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		findings = append(findings, checkFile(fsys, p))
+	}
+	return findings
+}
+
+// checkFile reads file names leaked by the store
+func checkFile(fsys fs.FS, p string) Finding {
+	finding := Finding{Path: p}
+	if path.Base(p) != StoreFileName {
+		return finding
+	}
+	var s Store
+	if finding.Err = s.ReadFSWithOptions(fsys, p, ReadOptions{BestEffort: true}); finding.Err != nil && !s.readable() {
+		return finding
+	}
+	for _, r := range s.Records {
+		if r.FileName != "." {
+			finding.Names = append(finding.Names, r.FileName)
+		}
+	}
+	slices.Sort(finding.Names)
+	finding.Names = slices.Compact(finding.Names)
+	return finding
+}
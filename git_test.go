@@ -0,0 +1,63 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGitCleanFilter(t *testing.T) {
+	var out bytes.Buffer
+	if err := GitCleanFilter(&out, strings.NewReader("text"), "dir/file.txt", GitBlock); err != nil || out.String() != "text" {
+		t.Errorf("expected content to be copied, got %q: %v", out.String(), err)
+	}
+	out.Reset()
+	if err := GitCleanFilter(&out, strings.NewReader("store"), "dir/.DS_Store", GitBlock); !errors.Is(err, ErrBlocked) || out.Len() != 0 {
+		t.Errorf("expected blocked error, got %v", err)
+	}
+	if err := GitCleanFilter(&out, strings.NewReader("store"), "dir/.DS_Store", GitStrip); err != nil || out.Len() != 0 {
+		t.Errorf("expected empty content, got %q: %v", out.String(), err)
+	}
+}
+
+func TestCheckTree(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	fsys := fstest.MapFS{
+		".DS_Store":          {Data: fileData},
+		".git/.DS_Store":     {Data: fileData},
+		"src/main.go":        {Data: []byte("package main")},
+		"src/.DS_Store":      {Data: []byte("garbage")},
+		"src/._.DS_Store":    {Data: []byte("apple double")},
+		"docs/.DS_Store.txt": {Data: fileData},
+	}
+	findings := CheckTree(fsys)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Path)
+	}
+	if !slices.Equal(got, []string{".DS_Store", "src/.DS_Store", "src/._.DS_Store"}) {
+		t.Fatalf("unexpected findings %v", got)
+	}
+	if len(findings[0].Names) == 0 || findings[0].Err != nil || slices.Contains(findings[0].Names, ".") {
+		t.Errorf("expected leaked names, got %+v", findings[0])
+	}
+	if findings[1].Err == nil || !strings.Contains(findings[1].String(), "unreadable") {
+		t.Errorf("expected error of unreadable store, got %v", findings[1])
+	}
+	if s := findings[2].String(); !strings.Contains(s, "AppleDouble") {
+		t.Errorf("unexpected description %s", s)
+	}
+
+	findings = CheckPaths(fsys, []string{"src/main.go", ".DS_Store", "deleted/.DS_Store"})
+	if len(findings) != 1 || findings[0].Path != ".DS_Store" {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
@@ -2,4 +2,15 @@ module github.com/strongo/dsstore
 
 go 1.25.5
 
-require golang.org/x/text v0.38.0
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/sys v0.47.0
+	golang.org/x/text v0.38.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/rogpeppe/go-internal v1.12.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)
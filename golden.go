@@ -0,0 +1,211 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Extensions of files of golden corpus
+const (
+	GoldenDataExt = ".DS_Store"
+	GoldenJSONExt = ".json"
+)
+
+// GoldenFixture is a .DS_Store fixture of golden corpus with its expected decoding.
+// Corpus directory has Name.DS_Store files with Name.json decodings next to them,
+// so samples from new macOS versions are contributed by adding both files.
+type GoldenFixture struct {
+	Name     string // name of the fixture, like "macos-15-icon-view"
+	Data     []byte // .DS_Store file data
+	Expected []byte // expected decoding, indented JSON of Store
+}
+
+// GoldenOptions of capturing of fixtures
+type GoldenOptions struct {
+	// Scrub removes sensitive records and data before the fixture is captured. Scrubbed stores are
+	// rewritten, so layout of the original file is kept only when scrubbing doesn't change records.
+	Scrub *ScrubPolicy
+	// Pseudonymize replaces file names by pseudonyms, the store is rewritten like on scrubbing
+	Pseudonymize *Pseudonymizer
+}
+
+// CaptureGolden returns the normalized fixture of .DS_Store file data with its decoding
+func CaptureGolden(name string, data []byte, opts GoldenOptions) (*GoldenFixture, error) {
+	if err := validGoldenName(name); err != nil {
+		return nil, err
+	}
+	var s Store
+	if err := s.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if opts.Scrub != nil || opts.Pseudonymize != nil {
+		original := slices.Clone(s.Records)
+		if opts.Scrub != nil {
+			Scrub(&s, *opts.Scrub)
+		}
+		if opts.Pseudonymize != nil {
+			opts.Pseudonymize.Anonymize(&s)
+		}
+		if !slices.EqualFunc(original, s.Records, Record.Equal) {
+			buf := new(bytes.Buffer)
+			if err := s.Write(buf); err != nil {
+				return nil, err
+			}
+			data = buf.Bytes()
+		}
+	}
+	data, err := NormalizeGolden(data)
+	if err != nil {
+		return nil, err
+	}
+	f := &GoldenFixture{Name: name, Data: data}
+	if f.Expected, err = f.decode(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CaptureGoldenFile returns the normalized fixture of .DS_Store file,
+// name of the fixture is name of the folder of the file
+func CaptureGoldenFile(filename string, opts GoldenOptions) (*GoldenFixture, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	return CaptureGolden(filepath.Base(filepath.Dir(abs)), data, opts)
+}
+
+// NormalizeGolden returns .DS_Store file data with zeroed free blocks and unused rest of B-tree nodes
+// and without trailing data, so fixtures keep the layout of the file but not remnants of old records
+func NormalizeGolden(data []byte) ([]byte, error) {
+	var s Store
+	if err := s.ReadWithOptions(bytes.NewReader(data), ReadOptions{Fidelity: true}); err != nil {
+		return nil, err
+	}
+	normalized := slices.Clone(s.layout.fileData[:len(s.layout.fileData)-len(s.trailing)])
+	for _, region := range s.unusedRegions() {
+		clear(normalized[min(region.start, int64(len(normalized))):min(region.end, int64(len(normalized)))])
+	}
+	return normalized, nil
+}
+
+// validGoldenName checks that the name can be used as a file name in corpus directory
+func validGoldenName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid fixture name %q", name)
+	}
+	return nil
+}
+
+// decode returns indented JSON of the store decoded from the fixture data
+func (f *GoldenFixture) decode() ([]byte, error) {
+	var s Store
+	if err := s.Read(bytes.NewReader(f.Data)); err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(&s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Register writes the fixture to corpus directory, existing fixtures are not overwritten
+func (f *GoldenFixture) Register(dir string) error {
+	if err := validGoldenName(f.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range []string{f.Name + GoldenDataExt, f.Name + GoldenJSONExt} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("fixture %q: %w", name, os.ErrExist)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, f.Name+GoldenDataExt), f.Data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, f.Name+GoldenJSONExt), f.Expected, 0o644)
+}
+
+// Verify decodes the fixture data and compares the decoding with the expected one
+func (f *GoldenFixture) Verify() error {
+	got, err := f.decode()
+	if err != nil {
+		return fmt.Errorf("fixture %q: %w", f.Name, err)
+	}
+	var expected, actual Store
+	if err = json.Unmarshal(f.Expected, &expected); err != nil {
+		return fmt.Errorf("fixture %q: expected decoding: %w", f.Name, err)
+	}
+	if err = json.Unmarshal(got, &actual); err != nil {
+		return fmt.Errorf("fixture %q: %w", f.Name, err)
+	}
+	var errs []error
+	for _, extra := range []struct {
+		what             string
+		expected, actual []byte
+	}{{"header", expected.HeaderExtra, actual.HeaderExtra}, {"root", expected.RootExtra, actual.RootExtra},
+		{"DSDB", expected.DSDBExtra, actual.DSDBExtra}} {
+		if !bytes.Equal(extra.expected, extra.actual) {
+			errs = append(errs, fmt.Errorf("extra data of %s block is %x, expected %x", extra.what, extra.actual, extra.expected))
+		}
+	}
+	for _, c := range Diff(&expected, &actual) {
+		r := c.record()
+		errs = append(errs, fmt.Errorf("record %q %s is %v", r.FileName, r.Code(), c.Kind))
+	}
+	if err = errors.Join(errs...); err != nil {
+		return fmt.Errorf("fixture %q: %w", f.Name, err)
+	}
+	return nil
+}
+
+// LoadGoldenCorpus reads fixtures of corpus directory sorted by names.
+// Data files without expected decodings are errors, so incomplete contributions are noticed.
+func LoadGoldenCorpus(dir string) ([]GoldenFixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+GoldenDataExt))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	var fixtures []GoldenFixture
+	for _, path := range paths {
+		f := GoldenFixture{Name: strings.TrimSuffix(filepath.Base(path), GoldenDataExt)}
+		if f.Name == "" {
+			continue
+		}
+		if f.Data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+		if f.Expected, err = os.ReadFile(filepath.Join(dir, f.Name+GoldenJSONExt)); err != nil {
+			return nil, fmt.Errorf("fixture %q: %w", f.Name, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// VerifyGoldenCorpus verifies all fixtures of corpus directory, failures are joined into one error
+func VerifyGoldenCorpus(dir string) error {
+	fixtures, err := LoadGoldenCorpus(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range fixtures {
+		errs = append(errs, fixtures[i].Verify())
+	}
+	return errors.Join(errs...)
+}
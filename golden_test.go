@@ -0,0 +1,114 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// goldenDir is the golden corpus, contributed samples are added with CaptureGoldenFile and Register
+var goldenDir = filepath.Join(".", "testdata", "golden")
+
+func TestGoldenCorpus(t *testing.T) {
+	fixtures, err := LoadGoldenCorpus(goldenDir)
+	if err != nil {
+		t.Fatalf("LoadGoldenCorpus failed: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("expected fixtures in golden corpus")
+	}
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			if err := f.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCaptureGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	f, err := CaptureGolden("sample", append(data, "trailing"...), GoldenOptions{})
+	if err != nil {
+		t.Fatalf("CaptureGolden failed: %v", err)
+	}
+	if len(f.Data) != len(data) {
+		t.Errorf("expected trailing data to be dropped, got %d bytes", len(f.Data))
+	}
+	var s Store
+	if err = s.ReadWithOptions(bytes.NewReader(f.Data), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if recovered, _ := s.RecoverDeleted(); len(recovered) > 0 {
+		t.Errorf("expected no remnants of old records, got %d", len(recovered))
+	}
+
+	dir := t.TempDir()
+	if err = f.Register(dir); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err = f.Register(dir); !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected ErrExist for registered fixture, got %v", err)
+	}
+	if err = VerifyGoldenCorpus(dir); err != nil {
+		t.Errorf("expected valid corpus, got %v", err)
+	}
+
+	// the reader doesn't match the changed decoding
+	f.Name = "changed"
+	f.Expected = bytes.Replace(f.Expected, []byte(`"type": "blob"`), []byte(`"type": "ustr"`), 1)
+	if err = f.Register(dir); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err = VerifyGoldenCorpus(dir); err == nil {
+		t.Error("expected changed fixture to fail")
+	}
+
+	// data without decoding is incomplete
+	if err = os.WriteFile(filepath.Join(dir, "incomplete"+GoldenDataExt), data, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err = LoadGoldenCorpus(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist for fixture without decoding, got %v", err)
+	}
+
+	for _, name := range []string{"", "..", ".hidden", "a/b"} {
+		if _, err = CaptureGolden(name, data, GoldenOptions{}); err == nil {
+			t.Errorf("expected invalid fixture name %q to fail", name)
+		}
+	}
+}
+
+func TestCaptureGoldenScrub(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	f, err := CaptureGolden("scrubbed", data, GoldenOptions{Pseudonymize: &Pseudonymizer{Key: []byte("key")}})
+	if err != nil {
+		t.Fatalf("CaptureGolden failed: %v", err)
+	}
+	var original, s Store
+	if err = original.Read(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if err = s.Read(bytes.NewReader(f.Data)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(s.Records) != len(original.Records) {
+		t.Fatalf("expected %d records, got %d", len(original.Records), len(s.Records))
+	}
+	for _, r := range original.Records {
+		if r.FileName != "." && bytes.Contains(f.Expected, []byte(`"name": "`+r.FileName+`"`)) {
+			t.Errorf("expected file name %q to be replaced", r.FileName)
+		}
+	}
+	if err = f.Verify(); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package dsstore
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Kinds of Guess
+const (
+	GuessPlist    = "plist"    // binary property list, Detail is its top level keys
+	GuessBookmark = "bookmark" // bookmark data, Detail is the path
+	GuessAlias    = "alias"    // version 2 alias record, Detail is the path
+	GuessLocation = "location" // 16 bytes of coordinates like "Iloc" records
+	GuessText     = "text"     // UTF-16 or UTF-8 text, Detail is the text
+	GuessTime     = "time"     // date, Detail is the time
+	GuessFlag     = "flag"     // boolean
+	GuessNumber   = "number"   // integer, Detail is the number
+	GuessCode     = "code"     // four-character code, Detail is the code
+	GuessBinary   = "binary"   // data of unknown format
+)
+
+// Guess is a heuristic classification of data of a record, like a record with unknown structure ID
+type Guess struct {
+	Kind       string  `json:"kind"`             // GuessPlist, GuessText and others
+	Confidence float64 `json:"confidence"`       // from 0 to 1, how likely the data is of the kind
+	Detail     string  `json:"detail,omitempty"` // short description of the value
+}
+
+// maxGuessDetail is the maximal length of Guess.Detail in bytes
+const maxGuessDetail = 80
+
+// Guess classifies data of the record by heuristics: blobs are tried as property lists, bookmarks,
+// aliases, icon locations and texts; data of other types is classified by the type with checks of the value.
+func (r Record) Guess() Guess {
+	var g Guess
+	switch r.Type {
+	case "blob":
+		g = guessBlob(r.Data)
+	case "ustr":
+		text, ok := r.Text()
+		g = Guess{Kind: GuessText, Confidence: 1, Detail: text}
+		if !ok {
+			g = Guess{Kind: GuessBinary, Confidence: 0.2}
+		} else if !printable(text) {
+			g.Confidence = 0.6
+		}
+	case "dutc":
+		t, _ := r.Time()
+		g = Guess{Kind: GuessTime, Confidence: 1, Detail: t.UTC().Format(time.RFC3339)}
+		if t.Year() < 1984 || t.Year() > 2100 {
+			g.Confidence = 0.5
+		}
+	case "bool":
+		g = Guess{Kind: GuessFlag, Confidence: 1}
+		if len(r.Data) == 1 && r.Data[0] > 1 {
+			g.Confidence = 0.6
+		}
+	case "long", "shor", "comp":
+		v, _ := r.Value()
+		g = Guess{Kind: GuessNumber, Confidence: 1, Detail: fmt.Sprint(v)}
+	case "type":
+		g = Guess{Kind: GuessCode, Confidence: 0.9, Detail: string(r.Data)}
+		if !printable(string(r.Data)) {
+			g.Confidence = 0.5
+		}
+	default:
+		g = Guess{Kind: GuessBinary, Confidence: 0.1}
+	}
+	if len(g.Detail) > maxGuessDetail {
+		i := maxGuessDetail
+		for i > 0 && !utf8.RuneStart(g.Detail[i]) {
+			i--
+		}
+		g.Detail = g.Detail[:i] + "…"
+	}
+	return g
+}
+
+// guessBlob classifies data of a blob by its content
+func guessBlob(data []byte) Guess {
+	if bytes.HasPrefix(data, bplistHeader) {
+		v, err := decodePlist(data)
+		if err != nil {
+			return Guess{Kind: GuessPlist, Confidence: 0.5}
+		}
+		g := Guess{Kind: GuessPlist, Confidence: 0.95}
+		if dict, ok := v.(map[string]any); ok {
+			g.Detail = strings.Join(slices.Sorted(maps.Keys(dict)), ", ")
+		}
+		return g
+	}
+	if b, err := DecodeBookmark(data); err == nil {
+		return Guess{Kind: GuessBookmark, Confidence: 0.9, Detail: b.Path}
+	}
+	if a, err := DecodeAlias(data); err == nil && (a.Volume != "" || a.Name != "") {
+		return Guess{Kind: GuessAlias, Confidence: 0.8, Detail: cmp.Or(a.Path, a.Name)}
+	}
+	if len(data) == 16 && bytes.Equal(data[8:14], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) {
+		return Guess{Kind: GuessLocation, Confidence: 0.8}
+	}
+	// UTF-16 text of ASCII characters has zero bytes, which are not printable UTF-8
+	if len(data) >= 4 && utf8.Valid(data) && printable(string(data)) {
+		return Guess{Kind: GuessText, Confidence: 0.5, Detail: string(data)}
+	}
+	if len(data) >= 4 && len(data)%2 == 0 {
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(data[2*i:])
+		}
+		if text := string(utf16.Decode(units)); printable(text) {
+			return Guess{Kind: GuessText, Confidence: 0.6, Detail: text}
+		}
+	}
+	return Guess{Kind: GuessBinary, Confidence: 0.2}
+}
+
+// printable reports whether the text is not empty and has only printable characters and white space
+func printable(text string) bool {
+	return text != "" && strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}) < 0
+}
+
+// UnknownCode is a structure ID which is not in KnownCodes with the guess of its records
+type UnknownCode struct {
+	Code    string `json:"code"`
+	Type    string `json:"type"`    // type of the first record
+	Records int    `json:"records"` // count of records
+	Guess   Guess  `json:"guess"`   // the most confident guess of the records
+}
+
+// GuessUnknown classifies records of the store with structure IDs which are not in KnownCodes,
+// so reports show what they likely are and the code table can be extended. Codes are sorted.
+func GuessUnknown(s *Store) []UnknownCode {
+	index := make(map[string]int)
+	var unknown []UnknownCode
+	for _, r := range s.Records {
+		code := r.Code()
+		if IsKnownCode(code) {
+			continue
+		}
+		i, ok := index[code]
+		if !ok {
+			i = len(unknown)
+			index[code] = i
+			unknown = append(unknown, UnknownCode{Code: code, Type: r.Type})
+		}
+		u := &unknown[i]
+		u.Records++
+		if g := r.Guess(); g.Confidence > u.Guess.Confidence {
+			u.Guess = g
+		}
+	}
+	slices.SortFunc(unknown, func(a, b UnknownCode) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+	return unknown
+}
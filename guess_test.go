@@ -0,0 +1,50 @@
+package dsstore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuess(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join("testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	kinds := map[string]string{"bwsp": GuessPlist, "icvp": GuessPlist, "pBBk": GuessBookmark, "vSrn": GuessNumber, "Iloc": GuessLocation}
+	for _, r := range s.Records {
+		if g := r.Guess(); g.Kind != kinds[r.Code()] || g.Confidence < 0.8 {
+			t.Errorf("%s of %q: unexpected guess %+v", r.Code(), r.FileName, g)
+		}
+	}
+
+	text := Record{FileName: "a", Type: "blob", Data: TextRecord("", "", "Hello, world").Data}
+	alias := Record{FileName: "a", Type: "blob", Data: encodeAlias("Disk", "/.background/bg.png")}
+	long := TextRecord("a", "XXXX", strings.Repeat("ж", 100))
+	for _, test := range []struct {
+		r      Record
+		kind   string
+		detail string
+	}{
+		{text, GuessText, "Hello, world"},
+		{alias, GuessAlias, "/.background/bg.png"},
+		{Record{FileName: "a", Type: "blob", Data: []byte("plain text")}, GuessText, "plain text"},
+		{Record{FileName: "a", Type: "blob", Data: []byte{0, 1, 2, 0xff, 0xfe}}, GuessBinary, ""},
+		{TimeRecord("a", "XXXX", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), GuessTime, "2020-01-02T03:04:05Z"},
+		{Record{FileName: "a", Type: "type", Data: []byte("icnv")}, GuessCode, "icnv"},
+		{long, GuessText, strings.Repeat("ж", 40) + "…"},
+	} {
+		if g := test.r.Guess(); g.Kind != test.kind || g.Detail != test.detail {
+			t.Errorf("expected %s %q, got %+v", test.kind, test.detail, g)
+		}
+	}
+
+	s.Records = append(s.Records, long, TextRecord("b", "XXXX", "x"), alias)
+	s.Records[len(s.Records)-1].SetCode("AAAA")
+	unknown := GuessUnknown(&s)
+	if len(unknown) != 2 || unknown[0].Code != "AAAA" || unknown[0].Guess.Kind != GuessAlias ||
+		unknown[1].Code != "XXXX" || unknown[1].Records != 2 || unknown[1].Type != "ustr" || unknown[1].Guess.Confidence != 1 {
+		t.Errorf("unexpected unknown codes %+v", unknown)
+	}
+}
@@ -0,0 +1,32 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrConcurrentModification is returned by iteration of Store.All when records are added, removed or reordered during it
+var ErrConcurrentModification = errors.New("store is modified during iteration")
+
+// All returns iterator over records of the store in their order.
+//
+// Records may be replaced in place by assigning elements of Store.Records during iteration,
+// the iterator yields the replaced records. Adding, removing or reordering records, directly or
+// by methods of Store like SetComment or Normalize, would skip or repeat records, so the iteration
+// ends with ErrConcurrentModification instead. The check compares the slice of records and a counter
+// of modifications by methods after each record, so records sorted in place directly aren't noticed.
+func (s *Store) All() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		records, mods := s.Records, s.mods
+		for i := range records {
+			if !yield(s.Records[i], nil) {
+				return
+			}
+			if s.mods != mods || len(s.Records) != len(records) || &s.Records[0] != &records[0] {
+				yield(Record{}, fmt.Errorf("%w: after %d of %d records", ErrConcurrentModification, i+1, len(records)))
+				return
+			}
+		}
+	}
+}
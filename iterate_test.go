@@ -0,0 +1,69 @@
+package dsstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStoreAll(t *testing.T) {
+	newStore := func() *Store {
+		s := &Store{}
+		for _, name := range []string{"a", "b", "c"} {
+			s.SetComment(name, name)
+		}
+		return s
+	}
+	s := newStore()
+	var names []string
+	for r, err := range s.All() {
+		if err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		if r.FileName == "a" {
+			s.Records[1] = TextRecord("b", "cmmt", "replaced")
+		}
+		text, _ := r.Text()
+		names = append(names, text)
+	}
+	if len(names) != 3 || names[1] != "replaced" {
+		t.Errorf("expected records replaced in place to be yielded, got %v", names)
+	}
+
+	for name, modify := range map[string]func(s *Store){
+		"append":  func(s *Store) { s.Records = append(s.Records[:3:3], TextRecord("d", "cmmt", "d")) },
+		"delete":  func(s *Store) { s.Records = s.Records[1:] },
+		"comment": func(s *Store) { s.SetComment("b", "") },
+		"method":  func(s *Store) { s.SetComment("a", "changed") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			var count int
+			var err error
+			for _, err = range s.All() {
+				if err != nil {
+					break
+				}
+				count++
+				if count == 1 {
+					modify(s)
+				}
+			}
+			if !errors.Is(err, ErrConcurrentModification) || count != 1 {
+				t.Errorf("expected ErrConcurrentModification after the first record, got %v after %d", err, count)
+			}
+		})
+	}
+
+	// modification after the last record is reported too
+	s = newStore()
+	var r Record
+	var err error
+	for r, err = range s.All() {
+		if err == nil && r.FileName == "c" {
+			s.SetComment("d", "d")
+		}
+	}
+	if !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+}
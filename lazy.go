@@ -0,0 +1,189 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Reader reads records of .DS_Store on demand.
+// Only the header, the root (allocator) block and the DSDB block are read on opening,
+// B-tree nodes are read from io.ReaderAt when records are iterated.
+type Reader struct {
+	s        Store // header and extra data of the store, reading options
+	src      blockSource
+	offsets  []uint32     // offsets of blocks
+	dataRoot uint32       // root node of data B-tree
+	release  func() error // releases resources of the source
+	cursor   *treeWalker  // state of iteration by Next
+	done     bool         // iteration by Next is finished
+	err      error        // error of the last iteration by Records
+}
+
+// OpenReaderAt opens .DS_Store of the given size for reading records on demand
+func OpenReaderAt(r io.ReaderAt, size int64) (*Reader, error) {
+	return OpenReaderAtWithOptions(r, size, ReadOptions{})
+}
+
+// OpenReaderAtWithOptions opens .DS_Store of the given size for reading records on demand using options
+func OpenReaderAtWithOptions(r io.ReaderAt, size int64, opts ReadOptions) (*Reader, error) {
+	return openSource(r, readerAtSource{r: r, fileSize: size}, size, opts, nil)
+}
+
+// openBytes opens .DS_Store in memory.
+// The release is called on closing of the reader or on failed opening.
+func openBytes(data []byte, size int64, opts ReadOptions, release func() error) (*Reader, error) {
+	return openSource(bytes.NewReader(data), bytesSource(data), size, opts, release)
+}
+
+func openSource(r io.ReaderAt, src blockSource, size int64, opts ReadOptions, release func() error) (*Reader, error) {
+	reader := &Reader{src: src, release: release}
+	if err := reader.open(r, size, opts); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// open reads the header, root and DSDB blocks
+func (r *Reader) open(ra io.ReaderAt, size int64, opts ReadOptions) error {
+	r.s.reset(opts)
+	if size > r.s.opts.MaxFileSize {
+		return fmt.Errorf("%w: file is bigger than %d bytes", ErrLimitExceeded, r.s.opts.MaxFileSize)
+	}
+	header := make([]byte, max(min(size, 36), 0))
+	if _, err := ra.ReadAt(header, 0); err != nil && err != io.EOF {
+		return err
+	}
+	rootOffset, rootSize, err := r.s.readHeader(header)
+	if err != nil {
+		return err
+	}
+	r.offsets, r.dataRoot, err = r.s.readRoot(r.src, rootOffset, rootSize)
+	return err
+}
+
+// Close releases resources of the reader, like memory mapping of the file
+func (r *Reader) Close() error {
+	if r.release == nil {
+		return nil
+	}
+	release := r.release
+	r.release = nil
+	return release()
+}
+
+// walker creates B-tree walker reading nodes on demand
+func (r *Reader) walker() *treeWalker {
+	r.s.truncErr = nil
+	r.s.readErrs = nil
+	return r.s.newTreeWalker(r.dataRoot, func(node uint32) (*bytes.Buffer, error) {
+		return r.s.readNodeBlock(r.src, r.offsets, node)
+	})
+}
+
+// ForEach calls fn for records in-order of B-tree until fn returns false
+func (r *Reader) ForEach(fn func(Record) bool) error {
+	w := r.walker()
+	for {
+		record, ok, err := w.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return r.s.readErr()
+		}
+		keep, err := r.s.acceptCode(record)
+		if err != nil {
+			return err
+		}
+		if keep && !fn(record) {
+			return nil
+		}
+	}
+}
+
+// Store reads all records into the new Store
+func (r *Reader) Store() (*Store, error) {
+	s := &Store{
+		HeaderExtra: r.s.HeaderExtra,
+		RootExtra:   r.s.RootExtra,
+		DSDBExtra:   r.s.DSDBExtra,
+		opts:        r.s.opts,
+		alloc:       r.s.alloc,
+	}
+	if err := s.readParseDataFrom(r.src, r.offsets, r.dataRoot); err != nil {
+		return nil, err
+	}
+	return s, s.readErr()
+}
+
+// Lookup finds the record by file name and structure ID walking B-tree using key comparisons,
+// so only nodes on the path to the record are read. It relies on records sorted the way Finder
+// sorts them: by file names case-insensitively, then by structure IDs.
+func (r *Reader) Lookup(filename, code string) (Record, bool, error) {
+	r.s.truncErr = nil
+	r.s.readErrs = nil
+	record, ok, err := r.s.lookup(r.dataRoot, func(node uint32) (*bytes.Buffer, error) {
+		return r.s.readNodeBlock(r.src, r.offsets, node)
+	}, filename, code)
+	if err != nil || !ok {
+		if err == nil {
+			err = r.s.readErr()
+		}
+		return Record{}, false, err
+	}
+	keep, err := r.s.acceptCode(record)
+	if err != nil || !keep {
+		return Record{}, false, err
+	}
+	return record, true, nil
+}
+
+// Next returns the next record in-order of B-tree, records are decoded as they are read.
+// It returns io.EOF when there are no more records.
+// Errors of best-effort reading are returned joined instead of the first io.EOF.
+func (r *Reader) Next() (Record, error) {
+	if r.done {
+		return Record{}, io.EOF
+	}
+	if r.cursor == nil {
+		r.cursor = r.walker()
+	}
+	for {
+		record, ok, err := r.cursor.next()
+		if err != nil {
+			r.done = true
+			return Record{}, err
+		}
+		if !ok {
+			r.done = true
+			if err = r.s.readErr(); err != nil {
+				return Record{}, err
+			}
+			return Record{}, io.EOF
+		}
+		keep, err := r.s.acceptCode(record)
+		if err != nil {
+			r.done = true
+			return Record{}, err
+		}
+		if keep {
+			return record, nil
+		}
+	}
+}
+
+// Records returns iterator over records in-order of B-tree.
+// Each call starts a new iteration, the error of iteration is returned by Err.
+func (r *Reader) Records() iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		r.err = r.ForEach(yield)
+	}
+}
+
+// Err returns the error of the last iteration by Records
+func (r *Reader) Err() error {
+	return r.err
+}
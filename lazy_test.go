@@ -0,0 +1,150 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countingReaderAt counts bytes read from the underlying data
+type countingReaderAt struct {
+	r     *bytes.Reader
+	reads int
+	bytes int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	c.bytes += len(p)
+	return c.r.ReadAt(p, off)
+}
+
+func TestOpenReaderAt(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var s Store
+	if err = s.Read(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	ra := &countingReaderAt{r: bytes.NewReader(data)}
+	r, err := OpenReaderAt(ra, int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+	if ra.bytes >= len(data)/2 {
+		t.Errorf("expected only header, root and DSDB blocks to be read, got %d bytes", ra.bytes)
+	}
+
+	// stop on the first record
+	var first Record
+	if err = r.ForEach(func(record Record) bool {
+		first = record
+		return false
+	}); err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+	if !first.Equal(s.Records[0]) {
+		t.Errorf("expected %v, got %v", s.Records[0], first)
+	}
+
+	s2, err := r.Store()
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Fatalf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	for i := range s.Records {
+		if !s.Records[i].Equal(s2.Records[i]) {
+			t.Errorf("record %d is different", i)
+		}
+	}
+	if !bytes.Equal(s.DSDBExtra, s2.DSDBExtra) || !bytes.Equal(s.RootExtra, s2.RootExtra) {
+		t.Error("extra data is different")
+	}
+}
+
+func TestOpenReaderAtErrors(t *testing.T) {
+	if _, err := OpenReaderAt(bytes.NewReader(make([]byte, 10)), 10); err == nil || err.Error() != "invalid file header" {
+		t.Errorf("expected 'invalid file header' error, got %v", err)
+	}
+	_, err := OpenReaderAtWithOptions(bytes.NewReader(make([]byte, 100)), 100, ReadOptions{MaxFileSize: 50})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestReaderNext(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	r, err := OpenFileMmap(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("OpenFileMmap failed: %v", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var records []Record
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		records = append(records, record)
+	}
+	if _, err = r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF after the end, got %v", err)
+	}
+	if len(records) != len(s.Records) {
+		t.Fatalf("expected %d records, got %d", len(s.Records), len(records))
+	}
+
+	i := 0
+	for record := range r.Records() {
+		if !record.Equal(s.Records[i]) {
+			t.Errorf("record %d is different", i)
+		}
+		i++
+	}
+	if r.Err() != nil {
+		t.Errorf("unexpected error %v", r.Err())
+	}
+	if i != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), i)
+	}
+}
+
+func TestReaderLookup(t *testing.T) {
+	r, err := OpenFileMmap(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("OpenFileMmap failed: %v", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	record, ok, err := r.Lookup(".", "vSrn")
+	if err != nil || !ok {
+		t.Fatalf("expected vSrn record, got %v, %v", ok, err)
+	}
+	if record.Type != "long" {
+		t.Errorf("unexpected record %v", record)
+	}
+	if _, ok, err = r.Lookup("Applications", "Iloc"); err != nil || !ok {
+		t.Errorf("expected Iloc record, got %v, %v", ok, err)
+	}
+	if _, ok, err = r.Lookup("Applications", "icvp"); err != nil || ok {
+		t.Errorf("expected no record, got %v, %v", ok, err)
+	}
+}
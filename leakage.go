@@ -0,0 +1,130 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// epoch1904 is the start of timestamps of "dutc" records
+var epoch1904 = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Time returns time of "dutc" record: 1/65536 seconds since 1904
+func (r Record) Time() (time.Time, bool) {
+	if r.Type != "dutc" || len(r.Data) != 8 {
+		return time.Time{}, false
+	}
+	v := binary.BigEndian.Uint64(r.Data)
+	seconds := int64(v >> 16)
+	nanos := int64(v&0xffff) * int64(time.Second) >> 16
+	return epoch1904.Add(time.Duration(seconds) * time.Second).Add(time.Duration(nanos)), true
+}
+
+// Presence tells where a file name is found
+type Presence int
+
+// Presences of file names
+const (
+	InStoreOnly Presence = iota // file is deleted or renamed, but its name leaks through the store
+	OnDiskOnly                  // file has no records
+	InBoth
+)
+
+// String returns description of the presence
+func (p Presence) String() string {
+	switch p {
+	case InStoreOnly:
+		return "store only"
+	case OnDiskOnly:
+		return "disk only"
+	case InBoth:
+		return "both"
+	default:
+		return "unknown"
+	}
+}
+
+// LeakageEntry is a file name of the leakage report
+type LeakageEntry struct {
+	Name        string
+	DisplayName string // name shown by Finder, it differs from Name for localized folders, see Localize
+	Presence    Presence
+	Codes       []string  // structure IDs of records of the file
+	StoreTime   time.Time // modification time from records of the file, zero when unknown
+	DiskTime    time.Time // modification time of the file on disk, zero for file not on disk
+}
+
+// LeakageReport compares file names of the store with files of its directory
+type LeakageReport struct {
+	Entries []LeakageEntry // entries sorted like records are written
+}
+
+// Leaked returns entries of file names present only in the store
+func (r LeakageReport) Leaked() []LeakageEntry {
+	var leaked []LeakageEntry
+	for _, e := range r.Entries {
+		if e.Presence == InStoreOnly {
+			leaked = append(leaked, e)
+		}
+	}
+	return leaked
+}
+
+// Leakage reports file names present only in the store (deleted or renamed files),
+// only in the directory of the file system, or in both. Names are compared like Store.Reconcile does,
+// records of the directory itself and hidden files on disk are skipped.
+// Display names of localized folders are in English.
+func Leakage(s *Store, fsys fs.FS, dir string) (LeakageReport, error) {
+	dirEntries, err := fs.ReadDir(fsys, path.Clean(dir))
+	if err != nil {
+		return LeakageReport{}, err
+	}
+	var report LeakageReport
+	index := make(map[string]int)
+	for _, r := range s.Records {
+		if r.FileName == "." {
+			continue
+		}
+		key := nameKey(r.FileName)
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Entries)
+			index[key] = i
+			report.Entries = append(report.Entries, LeakageEntry{Name: r.FileName, Presence: InStoreOnly})
+		}
+		e := &report.Entries[i]
+		if code := r.Code(); !slices.Contains(e.Codes, code) {
+			e.Codes = append(e.Codes, code)
+		}
+		if t, ok := r.Time(); ok && t.After(e.StoreTime) && (r.Code() == "modD" || r.Code() == "moDD") {
+			e.StoreTime = t
+		}
+	}
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		var diskTime time.Time
+		if info, err := entry.Info(); err == nil {
+			diskTime = info.ModTime()
+		}
+		if i, ok := index[nameKey(name)]; ok {
+			report.Entries[i].Presence = InBoth
+			report.Entries[i].DiskTime = diskTime
+			continue
+		}
+		report.Entries = append(report.Entries, LeakageEntry{Name: name, Presence: OnDiskOnly, DiskTime: diskTime})
+	}
+	names, _ := Localize(fsys, path.Clean(dir), LocalizeOptions{})
+	for i := range report.Entries {
+		report.Entries[i].DisplayName = names.Name(report.Entries[i].Name)
+	}
+	slices.SortStableFunc(report.Entries, func(a, b LeakageEntry) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return report, nil
+}
@@ -0,0 +1,67 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func dutcRecord(name string, t time.Time) Record {
+	r := Record{FileName: name, Type: "dutc", Data: make([]byte, 8)}
+	r.SetCode("modD")
+	binary.BigEndian.PutUint64(r.Data, uint64(t.Sub(epoch1904)/time.Second)<<16|0x8000)
+	return r
+}
+
+func TestRecordTime(t *testing.T) {
+	expected := time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)
+	got, ok := dutcRecord("a", expected).Time()
+	if !ok || !got.Equal(expected.Add(time.Second/2)) {
+		t.Errorf("expected %v, got %v", expected.Add(time.Second/2), got)
+	}
+	if _, ok = (Record{Type: "long", Data: make([]byte, 4)}).Time(); ok {
+		t.Error("expected no time of long record")
+	}
+}
+
+func TestLeakage(t *testing.T) {
+	modTime := time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"dir/Kept.txt":  {ModTime: modTime},
+		"dir/new.txt":   {ModTime: modTime},
+		"dir/.DS_Store": {},
+	}
+	storeTime := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	iloc := Record{FileName: "secret plan.pdf", Type: "long", Data: make([]byte, 4)}
+	iloc.SetCode("Iloc")
+	root := Record{FileName: ".", Type: "long", Data: make([]byte, 4)}
+	root.SetCode("vSrn")
+	s := &Store{Records: []Record{root, iloc, dutcRecord("secret plan.pdf", storeTime), dutcRecord("kept.txt", storeTime)}}
+
+	report, err := Leakage(s, fsys, "dir")
+	if err != nil {
+		t.Fatalf("Leakage failed: %v", err)
+	}
+	if len(report.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", report.Entries)
+	}
+	kept, added, leaked := report.Entries[0], report.Entries[1], report.Entries[2]
+	if kept.Name != "kept.txt" || kept.DisplayName != "kept.txt" || kept.Presence != InBoth || !kept.DiskTime.Equal(modTime) {
+		t.Errorf("unexpected entry %+v", kept)
+	}
+	if added.Name != "new.txt" || added.Presence != OnDiskOnly || added.Codes != nil {
+		t.Errorf("unexpected entry %+v", added)
+	}
+	if leaked.Name != "secret plan.pdf" || leaked.Presence != InStoreOnly || !slices.Equal(leaked.Codes, []string{"Iloc", "modD"}) ||
+		leaked.StoreTime.Truncate(time.Second) != storeTime || !leaked.DiskTime.IsZero() {
+		t.Errorf("unexpected entry %+v", leaked)
+	}
+	if l := report.Leaked(); len(l) != 1 || l[0].Name != "secret plan.pdf" {
+		t.Errorf("unexpected leaked entries %+v", l)
+	}
+	if _, err = Leakage(s, fsys, "missing"); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
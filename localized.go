@@ -0,0 +1,250 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// LocalizedSuffix is the suffix of names of folders localized by their own .strings files
+const LocalizedSuffix = ".localized"
+
+// LocalizeOptions are options of Localize
+type LocalizeOptions struct {
+	// Languages are preferred languages, like "de" or "pt-BR", English by default
+	Languages []string
+	// System maps names of system folders marked by empty .localized file, like "Applications",
+	// to their display names. It is data of SystemFolderLocalizations.strings parsed by ParseStrings.
+	System map[string]string
+}
+
+// DisplayNames maps file names to names shown by Finder
+type DisplayNames map[string]string
+
+// Name returns display name of the file, the file name itself when it isn't localized
+func (names DisplayNames) Name(fileName string) string {
+	if name, ok := names[fileName]; ok {
+		return name
+	}
+	return fileName
+}
+
+// Localize returns display names of localized entries of the directory, so records can be reported
+// with names Finder shows. Folder "Name.localized" is shown by the translation of "Name" from
+// .localized/<language>.strings inside of it, or as "Name" without translation. Folder containing
+// empty .localized file is shown by its translation from LocalizeOptions.System.
+func Localize(fsys fs.FS, dir string, opts LocalizeOptions) (DisplayNames, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	languages := opts.Languages
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+	names := make(DisplayNames)
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		entryPath := path.Join(dir, name)
+		if base, ok := strings.CutSuffix(name, LocalizedSuffix); ok && base != "" {
+			display, err := localizedName(fsys, path.Join(entryPath, LocalizedSuffix), base, languages)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", entryPath, err))
+			}
+			names[name] = display
+			continue
+		}
+		if info, err := fs.Stat(fsys, path.Join(entryPath, LocalizedSuffix)); err == nil && info.Mode().IsRegular() {
+			if display, ok := opts.System[name]; ok {
+				names[name] = display
+			}
+		}
+	}
+	return names, errors.Join(errs...)
+}
+
+// localizedName returns translation of the name from .strings files of the directory
+func localizedName(fsys fs.FS, dir, name string, languages []string) (string, error) {
+	for _, language := range languages {
+		data, err := fs.ReadFile(fsys, path.Join(dir, language+".strings"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return name, err
+		}
+		translations, err := ParseStrings(data)
+		if err != nil {
+			return name, err
+		}
+		if display, ok := translations[name]; ok {
+			return display, nil
+		}
+	}
+	return name, nil
+}
+
+// ParseStrings parses .strings file: text in UTF-8 or UTF-16 with byte order mark,
+// like `"Name" = "Translation";`, or binary property list dictionary
+func ParseStrings(data []byte) (map[string]string, error) {
+	if bytes.HasPrefix(data, bplistHeader) {
+		v, err := decodePlist(data)
+		if err != nil {
+			return nil, err
+		}
+		dict, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("strings property list is %T, not a dictionary", v)
+		}
+		result := make(map[string]string, len(dict))
+		for k, v := range dict {
+			if s, ok := v.(string); ok {
+				result[k] = s
+			}
+		}
+		return result, nil
+	}
+	if bytes.HasPrefix(data, []byte{0xfe, 0xff}) || bytes.HasPrefix(data, []byte{0xff, 0xfe}) {
+		decoder := unicode.BOMOverride(unicode.UTF8.NewDecoder())
+		var err error
+		if data, _, err = transform.Bytes(decoder, data); err != nil {
+			return nil, err
+		}
+	}
+	p := &stringsParser{text: strings.TrimPrefix(string(data), "\ufeff")}
+	return p.parse()
+}
+
+// stringsParser parses text .strings format: key = value; pairs with C comments
+type stringsParser struct {
+	text string
+}
+
+func (p *stringsParser) parse() (map[string]string, error) {
+	result := make(map[string]string)
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.text == "" {
+			return result, nil
+		}
+		key, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.skipSpace(); err != nil {
+			return nil, err
+		}
+		value := key
+		if strings.HasPrefix(p.text, "=") {
+			p.text = p.text[1:]
+			if err = p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if value, err = p.token(); err != nil {
+				return nil, err
+			}
+			if err = p.skipSpace(); err != nil {
+				return nil, err
+			}
+		}
+		if !strings.HasPrefix(p.text, ";") {
+			return nil, fmt.Errorf("expected ; after %q", key)
+		}
+		p.text = p.text[1:]
+		result[key] = value
+	}
+}
+
+// skipSpace skips white space and comments
+func (p *stringsParser) skipSpace() error {
+	for {
+		p.text = strings.TrimLeft(p.text, " \t\r\n")
+		switch {
+		case strings.HasPrefix(p.text, "//"):
+			_, p.text, _ = strings.Cut(p.text, "\n")
+		case strings.HasPrefix(p.text, "/*"):
+			var ok bool
+			if _, p.text, ok = strings.Cut(p.text[2:], "*/"); !ok {
+				return errors.New("unterminated comment")
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// token reads quoted string or unquoted word
+func (p *stringsParser) token() (string, error) {
+	if !strings.HasPrefix(p.text, `"`) {
+		end := strings.IndexAny(p.text, " \t\r\n=;\"")
+		switch {
+		case p.text == "":
+			return "", errors.New("unexpected end of strings")
+		case end == 0:
+			return "", fmt.Errorf("unexpected %q", firstRune(p.text))
+		case end < 0:
+			end = len(p.text)
+		}
+		token := p.text[:end]
+		p.text = p.text[end:]
+		return token, nil
+	}
+	var b strings.Builder
+	text := p.text[1:]
+	for {
+		i := strings.IndexAny(text, `"\`)
+		if i < 0 {
+			return "", errors.New("unterminated string")
+		}
+		b.WriteString(text[:i])
+		if text[i] == '"' {
+			p.text = text[i+1:]
+			return b.String(), nil
+		}
+		if i+1 >= len(text) {
+			return "", errors.New("unterminated string")
+		}
+		c := text[i+1]
+		text = text[i+2:]
+		switch c {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'U', 'u':
+			if len(text) < 4 {
+				return "", errors.New("invalid unicode escape")
+			}
+			r, err := strconv.ParseUint(text[:4], 16, 16)
+			if err != nil {
+				return "", errors.New("invalid unicode escape")
+			}
+			b.WriteRune(rune(r))
+			text = text[4:]
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// firstRune returns the first character of the text for error messages
+func firstRune(text string) string {
+	r, _ := utf8.DecodeRuneInString(text)
+	return string(r)
+}
@@ -0,0 +1,64 @@
+package dsstore
+
+import (
+	"maps"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestParseStrings(t *testing.T) {
+	text := `/* comment */
+"Documents" = "Dokumente"; // comment
+Key = "Escaped \"quote\"\n\U00e4";
+"Same";
+`
+	expected := map[string]string{"Documents": "Dokumente", "Key": "Escaped \"quote\"\nä", "Same": "Same"}
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{[]byte(text), []byte("\ufeff" + text), utf16} {
+		got, err := ParseStrings(data)
+		if err != nil || !maps.Equal(got, expected) {
+			t.Errorf("unexpected strings %q: %v", got, err)
+		}
+	}
+	for _, text := range []string{`"a" = "b"`, `"a" = ;`, `"a`, `/* a`, `"a" = "\U12";`, `"a" "b";`} {
+		if _, err = ParseStrings([]byte(text)); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/Reports.localized/.localized/de.strings": {Data: []byte(`"Reports" = "Berichte";`)},
+		"dir/Reports.localized/.localized/en.strings": {Data: []byte(`"Reports" = "Reports (en)";`)},
+		"dir/Plain.localized/file":                    {},
+		"dir/Applications/.localized":                 {},
+		"dir/Other/file":                              {},
+		"dir/Broken.localized/.localized/en.strings":  {Data: []byte(`"Broken`)},
+		"dir/file.localized":                          {},
+	}
+	names, err := Localize(fsys, "dir", LocalizeOptions{
+		Languages: []string{"fr", "de"},
+		System:    map[string]string{"Applications": "Programme"},
+	})
+	if err != nil {
+		t.Errorf("Localize failed: %v", err)
+	}
+	expected := DisplayNames{"Reports.localized": "Berichte", "Plain.localized": "Plain",
+		"Applications": "Programme", "Broken.localized": "Broken"}
+	if !maps.Equal(names, expected) {
+		t.Errorf("unexpected names %q", names)
+	}
+	if names.Name("Other") != "Other" {
+		t.Errorf("unexpected name of not localized folder %q", names.Name("Other"))
+	}
+	names, err = Localize(fsys, "dir", LocalizeOptions{})
+	if err == nil || names["Reports.localized"] != "Reports (en)" {
+		t.Errorf("expected error for broken strings and English name, got %q: %v", names["Reports.localized"], err)
+	}
+}
@@ -0,0 +1,99 @@
+package dsstore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Default retries of LockOptions
+const (
+	DefaultLockRetries = 5
+	DefaultLockBackoff = 10 * time.Millisecond
+)
+
+// ErrLocked is returned when the file stays locked by another process after all retries
+var ErrLocked = errors.New("file is locked")
+
+// LockOptions of advisory locking of .DS_Store file while it is read or written.
+// On unix systems locks are taken with flock, so they protect only from processes which
+// also take locks. On other systems locking is not supported and files are not locked.
+// Atomic writes replace the locked file, so writers take the lock of the file at the path
+// and retry with the new file when it's replaced while they wait.
+type LockOptions struct {
+	Enabled bool          // take shared lock for reading and exclusive lock for writing
+	Retries int           // count of retries while the file is locked, zero value selects default
+	Backoff time.Duration // delay before the first retry, it is doubled for each next retry
+}
+
+func (o LockOptions) withDefaults() LockOptions {
+	if o.Retries <= 0 {
+		o.Retries = DefaultLockRetries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultLockBackoff
+	}
+	return o
+}
+
+// lockFile takes advisory lock of the file retrying with backoff while the file is locked
+func lockFile(f *os.File, exclusive bool, opts LockOptions) error {
+	if !opts.Enabled {
+		return nil
+	}
+	opts = opts.withDefaults()
+	delay := opts.Backoff
+	for i := 0; ; i++ {
+		locked, err := tryLock(f, exclusive)
+		if err != nil || locked {
+			return err
+		}
+		if i >= opts.Retries {
+			return ErrLocked
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// openLocked opens the file for writing with exclusive lock. When the file is replaced by atomic writing
+// of another process while the lock is waited for, the lock of the replaced file is released and the new
+// file is locked, so writers replacing the file exclude each other.
+func openLocked(filename string, perm os.FileMode, opts LockOptions) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, perm)
+		if err != nil {
+			return nil, err
+		}
+		if err = lockFile(f, true, opts); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		replaced, err := isReplaced(f, filename)
+		if err == nil && !replaced {
+			return f, nil
+		}
+		_ = unlockFile(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isReplaced reports whether the opened file is not the file at the path anymore
+func isReplaced(f *os.File, filename string) (bool, error) {
+	opened, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	current, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(opened, current), nil
+}
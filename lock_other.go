@@ -0,0 +1,15 @@
+//go:build !unix
+
+package dsstore
+
+import "os"
+
+// tryLock doesn't lock files, locking is not supported on this platform
+func tryLock(*os.File, bool) (bool, error) {
+	return true, nil
+}
+
+// unlockFile doesn't unlock files, locking is not supported on this platform
+func unlockFile(*os.File) error {
+	return nil
+}
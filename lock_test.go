@@ -0,0 +1,112 @@
+//go:build unix
+
+package dsstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLocking(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	target := filepath.Join(t.TempDir(), ".DS_Store")
+	if err := s.WriteFile(target, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	locking := LockOptions{Enabled: true, Retries: 2, Backoff: time.Millisecond}
+
+	// another process holds exclusive lock
+	f, err := os.Open(target)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer f.Close()
+	if err = lockFile(f, true, locking); err != nil {
+		t.Fatalf("lockFile failed: %v", err)
+	}
+	var s2 Store
+	if err = s2.ReadFileWithOptions(target, ReadOptions{Locking: locking}); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked on reading, got %v", err)
+	}
+	if err = s.WriteFileWithOptions(target, 0644, WriteOptions{Locking: locking}); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked on writing, got %v", err)
+	}
+
+	// the lock is released while retrying
+	unlocked := make(chan struct{})
+	go func() {
+		defer close(unlocked)
+		time.Sleep(5 * time.Millisecond)
+		_ = unlockFile(f)
+	}()
+	locking.Retries = 10
+	err = s2.ReadFileWithOptions(target, ReadOptions{Locking: locking})
+	<-unlocked
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	for _, atomic := range []bool{false, true} {
+		if err = s.WriteFileWithOptions(target, 0644, WriteOptions{Locking: locking, Atomic: atomic}); err != nil {
+			t.Errorf("WriteFile failed: %v", err)
+		}
+		if err = s2.ReadFile(target); err != nil || len(s2.Records) != len(s.Records) {
+			t.Errorf("expected %d records, got %d (%v)", len(s.Records), len(s2.Records), err)
+		}
+	}
+}
+
+func TestLockingAtomic(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	target := filepath.Join(t.TempDir(), ".DS_Store")
+	if err := s.WriteFile(target, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	locking := LockOptions{Enabled: true, Retries: 3, Backoff: 5 * time.Millisecond}
+
+	// a writer holds the lock of the file
+	old, err := os.Open(target)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer old.Close()
+	if err = lockFile(old, true, locking); err != nil {
+		t.Fatalf("lockFile failed: %v", err)
+	}
+	written := make(chan error)
+	go func() {
+		written <- s.WriteFileWithOptions(target, 0644, WriteOptions{Locking: locking, Atomic: true})
+	}()
+
+	time.Sleep(2 * time.Millisecond)
+
+	// the writer replaces the file and another one locks the new file before the lock of the old one is released
+	if err = s.WriteFileWithOptions(target, 0644, WriteOptions{Atomic: true}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	current, err := os.Open(target)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer current.Close()
+	if err = lockFile(current, true, locking); err != nil {
+		t.Fatalf("lockFile failed: %v", err)
+	}
+	if err = unlockFile(old); err != nil {
+		t.Fatalf("unlockFile failed: %v", err)
+	}
+	if err = <-written; !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked while the new file is locked, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package dsstore
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logContext returns the context of the current reading for logging
+func (s *Store) logContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// debug logs the event of reading to ReadOptions.Logger if it is set
+func (s *Store) debug(msg string, args ...any) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.Log(s.logContext(), slog.LevelDebug, msg, args...)
+	}
+}
+
+// debug logs the event of encoding to WriteOptions.Logger if it is set
+func (e *Encoder) debug(msg string, args ...any) {
+	if e.logger == nil {
+		return
+	}
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.logger.Log(ctx, slog.LevelDebug, msg, args...)
+}
@@ -0,0 +1,65 @@
+package dsstore
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	out := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	var s Store
+	err = s.ReadWithOptions(bytes.NewReader(append(fileData, "trailing"...)), ReadOptions{Logger: logger, Strict: true, Fidelity: true})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	for _, event := range []string{`msg="read header"`, `msg="read root block"`, `msg="read DSDB block"`,
+		`msg="visit node" node=2`, `level=WARN msg="8 bytes after the allocated region`, `kind="trailing data"`} {
+		if !strings.Contains(out.String(), event) {
+			t.Errorf("expected %s event, got:\n%s", event, out)
+		}
+	}
+
+	out.Reset()
+	s.Records = append(s.Records, Record{FileName: "big", Type: "blob", DataLen: 5000, Data: make([]byte, 5000)})
+	if err = s.WriteWithOptions(new(bytes.Buffer), WriteOptions{Logger: logger}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, event := range []string{`msg="rebuild tree"`, `msg="reallocate block"`, `msg="encode store"`} {
+		if !strings.Contains(out.String(), event) {
+			t.Errorf("expected %s event, got:\n%s", event, out)
+		}
+	}
+
+	out.Reset()
+	s2 := &Store{Records: s.Records}
+	if err = s2.WriteWithOptions(new(bytes.Buffer), WriteOptions{Logger: logger}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, event := range []string{`msg="build tree"`, `msg="allocate block" index=0`} {
+		if !strings.Contains(out.String(), event) {
+			t.Errorf("expected %s event, got:\n%s", event, out)
+		}
+	}
+
+	// blocks are read on demand by Reader
+	out.Reset()
+	r, err := OpenReaderAtWithOptions(bytes.NewReader(fileData), int64(len(fileData)), ReadOptions{Logger: logger})
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+	if _, _, err = r.Lookup(s.Records[0].FileName, s.Records[0].Code()); err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `msg="read block" index=2`) {
+		t.Errorf("expected read block event, got:\n%s", out)
+	}
+}
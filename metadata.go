@@ -0,0 +1,47 @@
+package dsstore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// fileMetadata of the target file kept on rewriting with WriteOptions.PreserveMetadata
+type fileMetadata struct {
+	mode     os.FileMode
+	atime    time.Time
+	mtime    time.Time
+	platform platformMetadata
+}
+
+// readMetadata returns metadata of the file or nil if the file doesn't exist
+func readMetadata(filename string) (*fileMetadata, error) {
+	info, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &fileMetadata{mode: info.Mode().Perm(), atime: info.ModTime(), mtime: info.ModTime()}
+	if err = m.readPlatform(filename, info); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// apply sets metadata to the file: mode, extended attributes, times and flags,
+// flags go the last because they can make the file immutable
+func (m *fileMetadata) apply(filename string) error {
+	if err := os.Chmod(filename, m.mode); err != nil {
+		return err
+	}
+	if err := m.applyPlatform(filename); err != nil {
+		return err
+	}
+	if err := os.Chtimes(filename, m.atime, m.mtime); err != nil {
+		return err
+	}
+	return m.applyFlags(filename)
+}
@@ -0,0 +1,83 @@
+//go:build darwin
+
+package dsstore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// platformMetadata are file flags (like hidden) and extended attributes
+type platformMetadata struct {
+	flags  uint32
+	xattrs map[string][]byte
+}
+
+// readPlatform reads access time, flags and extended attributes of the file
+func (m *fileMetadata) readPlatform(filename string, _ os.FileInfo) error {
+	var st unix.Stat_t
+	if err := unix.Stat(filename, &st); err != nil {
+		return err
+	}
+	m.atime = time.Unix(st.Atim.Unix())
+	m.platform.flags = st.Flags
+	names, err := listXattrs(filename)
+	if err != nil {
+		return err
+	}
+	m.platform.xattrs = make(map[string][]byte, len(names))
+	for _, name := range names {
+		value, err := getXattr(filename, name)
+		if errors.Is(err, unix.ENOATTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		m.platform.xattrs[name] = value
+	}
+	return nil
+}
+
+// applyPlatform sets extended attributes
+func (m *fileMetadata) applyPlatform(filename string) error {
+	for name, value := range m.platform.xattrs {
+		if err := unix.Setxattr(filename, name, value, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFlags sets flags, they are set after times because they can make file immutable
+func (m *fileMetadata) applyFlags(filename string) error {
+	return unix.Chflags(filename, int(m.platform.flags))
+}
+
+func listXattrs(filename string) ([]string, error) {
+	size, err := unix.Listxattr(filename, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if size, err = unix.Listxattr(filename, buf); err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(string(buf[:size]), func(r rune) bool { return r == 0 }), nil
+}
+
+func getXattr(filename, name string) ([]byte, error) {
+	size, err := unix.Getxattr(filename, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, size)
+	if size, err = unix.Getxattr(filename, name, value); err != nil {
+		return nil, err
+	}
+	return value[:size], nil
+}
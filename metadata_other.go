@@ -0,0 +1,21 @@
+//go:build !unix
+
+package dsstore
+
+import "os"
+
+// platformMetadata has no additional metadata on this platform
+type platformMetadata struct{}
+
+// readPlatform doesn't read access time on this platform, modification time is used instead
+func (m *fileMetadata) readPlatform(string, os.FileInfo) error {
+	return nil
+}
+
+func (m *fileMetadata) applyPlatform(string) error {
+	return nil
+}
+
+func (m *fileMetadata) applyFlags(string) error {
+	return nil
+}
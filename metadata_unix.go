@@ -0,0 +1,31 @@
+//go:build unix && !darwin
+
+package dsstore
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// platformMetadata has no additional metadata on this platform
+type platformMetadata struct{}
+
+// readPlatform reads access time of the file
+func (m *fileMetadata) readPlatform(filename string, _ os.FileInfo) error {
+	var st unix.Stat_t
+	if err := unix.Stat(filename, &st); err != nil {
+		return err
+	}
+	m.atime = time.Unix(st.Atim.Unix())
+	return nil
+}
+
+func (m *fileMetadata) applyPlatform(string) error {
+	return nil
+}
+
+func (m *fileMetadata) applyFlags(string) error {
+	return nil
+}
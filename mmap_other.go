@@ -0,0 +1,39 @@
+//go:build !unix
+
+package dsstore
+
+import (
+	"io"
+	"os"
+)
+
+// OpenFileMmap opens .DS_Store file for reading records on demand.
+// Memory mapping is not supported on this platform, so the file is read into memory.
+func OpenFileMmap(path string) (*Reader, error) {
+	return OpenFileMmapWithOptions(path, ReadOptions{})
+}
+
+// OpenFileMmapWithOptions opens .DS_Store file for reading records on demand using options.
+// Memory mapping is not supported on this platform, so the file is read into memory.
+func OpenFileMmapWithOptions(path string, opts ReadOptions) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if size := info.Size(); size > opts.withDefaults().MaxFileSize {
+		// too big file is rejected by limits without reading it
+		return openBytes(nil, size, opts, nil)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return openBytes(data, int64(len(data)), opts, nil)
+}
@@ -0,0 +1,52 @@
+package dsstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFileMmap(t *testing.T) {
+	testdata := filepath.Join(".", "testdata", "00.DS_Store")
+	var s Store
+	if err := s.ReadFile(testdata); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	r, err := OpenFileMmap(testdata)
+	if err != nil {
+		t.Fatalf("OpenFileMmap failed: %v", err)
+	}
+	s2, err := r.Store()
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	// records must stay valid after unmapping
+	if len(s2.Records) != len(s.Records) {
+		t.Fatalf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	for i := range s.Records {
+		if !s.Records[i].Equal(s2.Records[i]) {
+			t.Errorf("record %d is different", i)
+		}
+	}
+}
+
+func TestOpenFileMmapErrors(t *testing.T) {
+	if _, err := OpenFileMmap("non-existent-file"); err == nil {
+		t.Error("expected error for non-existent file")
+	}
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := OpenFileMmap(empty); err == nil || err.Error() != "invalid file header" {
+		t.Errorf("expected 'invalid file header' error, got %v", err)
+	}
+}
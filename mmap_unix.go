@@ -0,0 +1,46 @@
+//go:build unix
+
+package dsstore
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenFileMmap opens .DS_Store file mapped to memory for reading records on demand.
+// The Reader must be closed to unmap the file.
+func OpenFileMmap(path string) (*Reader, error) {
+	return OpenFileMmapWithOptions(path, ReadOptions{})
+}
+
+// OpenFileMmapWithOptions opens .DS_Store file mapped to memory using options.
+// The Reader must be closed to unmap the file.
+//
+// The file is mapped privately, but pages not read yet still come from the file, so truncation
+// of the file while it's mapped, like by Finder rewriting it in place, crashes the process with SIGBUS.
+// Map only files the caller controls, other files are safer read by ReadFile or OpenReaderAt.
+func OpenFileMmapWithOptions(path string, opts ReadOptions) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 || size > opts.withDefaults().MaxFileSize {
+		// empty file can't be mapped, too big file is rejected by limits
+		return openBytes(nil, size, opts, nil)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	return openBytes(data, size, opts, func() error {
+		return syscall.Munmap(data)
+	})
+}
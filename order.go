@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrNonCanonicalOrder is reported by Store.Validate for read B-tree with records out of canonical order
+var ErrNonCanonicalOrder = errors.New("records are not in canonical order")
+
+// compareKeys compares keys of records in the order Finder sorts them in B-tree:
+// file names case-insensitively, then structure IDs.
+func compareKeys(name1, code1, name2, code2 string) int {
+	if c := strings.Compare(strings.ToLower(name1), strings.ToLower(name2)); c != 0 {
+		return c
+	}
+	return strings.Compare(code1, code2)
+}
+
+// compareRecords compares records by keys like compareKeys.
+// Structure IDs are big-endian, so they are compared as numbers without converting to strings.
+func compareRecords(a, b Record) int {
+	if c := strings.Compare(strings.ToLower(a.FileName), strings.ToLower(b.FileName)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Extra, b.Extra)
+}
+
+// compareRecordsUTF16 compares records like compareRecords, but file names are compared by UTF-16 code units
+// like Finder compares them, so names with characters out of BMP sort before ones with U+E000 to U+FFFF
+func compareRecordsUTF16(a, b Record) int {
+	if c := slices.Compare(utf16.Encode([]rune(strings.ToLower(a.FileName))), utf16.Encode([]rune(strings.ToLower(b.FileName)))); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Extra, b.Extra)
+}
+
+// misordered returns index of the first record out of canonical order or -1 when the order is canonical.
+// Order is canonical when every record follows the previous one comparing them by code points or by UTF-16
+// code units, so orders of this library and of Finder are both canonical.
+func misordered(records []Record) int {
+	for i := 1; i < len(records); i++ {
+		if compareRecords(records[i-1], records[i]) > 0 && compareRecordsUTF16(records[i-1], records[i]) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkOrder remembers the error of read records out of canonical order for Validate
+func (s *Store) checkOrder() {
+	s.orderErr = nil
+	if i := misordered(s.Records); i >= 0 {
+		s.orderErr = fmt.Errorf("%w: record %d (%q, %q) of the read B-tree follows (%q, %q)", ErrNonCanonicalOrder,
+			i, s.Records[i].FileName, s.Records[i].Code(), s.Records[i-1].FileName, s.Records[i-1].Code())
+	}
+}
@@ -0,0 +1,40 @@
+package dsstore
+
+import (
+	"path"
+)
+
+// EmbeddedPath is a path of an alias or a bookmark embedded in a blob record, like "pict", "pBBk"
+// and "backgroundImageAlias" of "icvp". Such paths often reveal home folders and machine names of authors.
+type EmbeddedPath struct {
+	FileName   string `json:"filename"`
+	Code       string `json:"code"`
+	Kind       string `json:"kind"` // "alias" or "bookmark"
+	Path       string `json:"path"` // absolute path of the file
+	Volume     string `json:"volume,omitempty"`
+	VolumePath string `json:"volumePath,omitempty"` // path of the mounted volume
+	VolumeUUID string `json:"volumeUUID,omitempty"` // only bookmarks have UUIDs
+}
+
+// EmbeddedPaths returns paths of aliases and bookmarks of blob records and of their property lists
+// in the order of records
+func (s *Store) EmbeddedPaths() []EmbeddedPath {
+	var paths []EmbeddedPath
+	for _, r := range s.Records {
+		if r.Type != "blob" {
+			continue
+		}
+		for _, data := range embeddedData(r) {
+			p := EmbeddedPath{FileName: r.FileName, Code: r.Code()}
+			if b, err := DecodeBookmark(data); err == nil {
+				p.Kind, p.Path, p.Volume, p.VolumePath, p.VolumeUUID = "bookmark", b.Path, b.Volume, b.VolumePath, b.VolumeUUID
+			} else if a, err := DecodeAlias(data); err == nil {
+				p.Kind, p.Path, p.Volume, p.VolumePath = "alias", path.Join("/", a.MountPoint, a.Path), a.Volume, a.MountPoint
+			} else {
+				continue
+			}
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
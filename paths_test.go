@@ -0,0 +1,26 @@
+package dsstore
+
+import (
+	"testing"
+)
+
+func TestEmbeddedPaths(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	paths := s.EmbeddedPaths()
+	if len(paths) != 2 {
+		t.Fatalf("want 2 paths, got %+v", paths)
+	}
+	const background = "/Users/gwend/Library/Mobile Documents/com~apple~CloudDocs/Getscreen/Background_Black.png"
+	alias, bookmark := paths[0], paths[1]
+	if alias.Code != "icvp" || alias.Kind != "alias" || alias.Path != background || alias.Volume != "Macintosh HD" ||
+		alias.VolumeUUID != "" {
+		t.Errorf("unexpected alias path %+v", alias)
+	}
+	if bookmark.Code != "pBBk" || bookmark.Kind != "bookmark" || bookmark.Path != background || bookmark.VolumePath != "/" ||
+		bookmark.VolumeUUID != "81DED881-7FF2-4FBB-A8F8-DC8A4BC4C10E" {
+		t.Errorf("unexpected bookmark path %+v", bookmark)
+	}
+}
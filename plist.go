@@ -0,0 +1,334 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+	"slices"
+	"unicode/utf16"
+)
+
+// bplistHeader starts binary property lists
+var bplistHeader = []byte("bplist00")
+
+// maxPlistDepth limits nesting of arrays and dictionaries, so cyclic references fail
+const maxPlistDepth = 32
+
+// plistDecoder decodes binary property lists of blob records, like "bwsp" and "icvp".
+// Objects are decoded as bool, int64, float64, string, []byte, []any and map[string]any.
+type plistDecoder struct {
+	data       []byte
+	offsets    []uint64
+	refSize    int
+	objectsEnd uint64
+}
+
+// decodePlist decodes the top object of the binary property list
+func decodePlist(data []byte) (any, error) {
+	if len(data) < len(bplistHeader)+32 || !bytes.HasPrefix(data, bplistHeader) {
+		return nil, errors.New("not a binary property list")
+	}
+	trailer := data[len(data)-32:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+	end := uint64(len(data) - 32)
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 || top >= numObjects ||
+		tableOffset > end || (end-tableOffset)/uint64(offsetSize) < numObjects {
+		return nil, errors.New("malformed binary property list trailer")
+	}
+	d := &plistDecoder{data: data, offsets: make([]uint64, numObjects), refSize: refSize, objectsEnd: tableOffset}
+	for i := range d.offsets {
+		d.offsets[i] = readUint(data[tableOffset+uint64(i*offsetSize):][:offsetSize])
+	}
+	return d.object(top, 0)
+}
+
+// readUint reads big-endian unsigned integer of 1-8 bytes
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// object decodes the object with the reference
+func (d *plistDecoder) object(ref uint64, depth int) (any, error) {
+	if ref >= uint64(len(d.offsets)) || d.offsets[ref] < uint64(len(bplistHeader)) || d.offsets[ref] >= d.objectsEnd {
+		return nil, fmt.Errorf("invalid property list object reference %d", ref)
+	}
+	if depth > maxPlistDepth {
+		return nil, errors.New("property list is nested too deep")
+	}
+	b := d.data[d.offsets[ref]:d.objectsEnd]
+	marker := b[0]
+	switch marker >> 4 {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+	case 0x1:
+		size := 1 << (marker & 0xf)
+		if size > 8 || len(b) < 1+size {
+			break
+		}
+		return int64(readUint(b[1 : 1+size])), nil
+	case 0x2:
+		switch size := 1 << (marker & 0xf); {
+		case size == 4 && len(b) >= 5:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b[1:]))), nil
+		case size == 8 && len(b) >= 9:
+			return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), nil
+		}
+	case 0x4, 0x5, 0x6, 0xa, 0xd:
+		count, b, err := d.count(b)
+		if err != nil {
+			return nil, err
+		}
+		return d.container(marker>>4, count, b, depth)
+	}
+	return nil, fmt.Errorf("unsupported property list object 0x%02x", marker)
+}
+
+// count returns count of elements of the object and data following it
+func (d *plistDecoder) count(b []byte) (uint64, []byte, error) {
+	count := uint64(b[0] & 0xf)
+	b = b[1:]
+	if count != 0xf {
+		return count, b, nil
+	}
+	if len(b) < 1 || b[0]>>4 != 1 || b[0]&0xf > 3 || len(b) < 1+1<<(b[0]&0xf) {
+		return 0, nil, errors.New("malformed property list object length")
+	}
+	size := 1 << (b[0] & 0xf)
+	return readUint(b[1 : 1+size]), b[1+size:], nil
+}
+
+// container decodes data, strings, arrays and dictionaries of count elements
+func (d *plistDecoder) container(kind byte, count uint64, b []byte, depth int) (any, error) {
+	size := uint64(1)
+	switch kind {
+	case 0x6:
+		size = 2
+	case 0xa:
+		size = uint64(d.refSize)
+	case 0xd:
+		size = 2 * uint64(d.refSize)
+	}
+	if count > uint64(len(b))/size {
+		return nil, errors.New("property list object exceeds data")
+	}
+	switch kind {
+	case 0x4:
+		return bytes.Clone(b[:count]), nil
+	case 0x5:
+		return string(b[:count]), nil
+	case 0x6:
+		units := make([]uint16, count)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	case 0xa:
+		array := make([]any, count)
+		for i := range array {
+			v, err := d.object(d.ref(b, uint64(i)), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = v
+		}
+		return array, nil
+	default:
+		dict := make(map[string]any, count)
+		for i := range count {
+			k, err := d.object(d.ref(b, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errors.New("property list dictionary key is not a string")
+			}
+			if dict[key], err = d.object(d.ref(b, count+i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+}
+
+// ref returns i-th object reference of the array or dictionary data
+func (d *plistDecoder) ref(b []byte, i uint64) uint64 {
+	return readUint(b[i*uint64(d.refSize):][:d.refSize])
+}
+
+// plistEncoder encodes binary property lists of bool, int, int64, float64, string, []byte,
+// []any and map[string]any values, dictionary keys are sorted
+type plistEncoder struct {
+	objects [][]byte
+	refSize int
+}
+
+// encodePlist encodes the value as binary property list
+func encodePlist(v any) ([]byte, error) {
+	count, err := countPlistObjects(v)
+	if err != nil {
+		return nil, err
+	}
+	e := &plistEncoder{objects: make([][]byte, 0, count), refSize: uintSize(uint64(count))}
+	e.encode(v)
+	b := bytes.NewBuffer(slices.Clone(bplistHeader))
+	offsets := make([]uint64, len(e.objects))
+	for i, obj := range e.objects {
+		offsets[i] = uint64(b.Len())
+		b.Write(obj)
+	}
+	tableOffset := uint64(b.Len())
+	offsetSize := uintSize(tableOffset)
+	for _, offset := range offsets {
+		b.Write(appendUint(nil, offset, offsetSize))
+	}
+	var trailer [32]byte
+	trailer[6] = byte(offsetSize)
+	trailer[7] = byte(e.refSize)
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(e.objects)))
+	binary.BigEndian.PutUint64(trailer[24:], tableOffset)
+	b.Write(trailer[:])
+	return b.Bytes(), nil
+}
+
+// countPlistObjects returns count of objects of the value and checks that they can be encoded
+func countPlistObjects(v any) (int, error) {
+	count := 1
+	switch v := v.(type) {
+	case bool, int, int64, float64, string, []byte:
+	case []any:
+		for _, item := range v {
+			n, err := countPlistObjects(item)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+	case map[string]any:
+		for _, item := range v {
+			n, err := countPlistObjects(item)
+			if err != nil {
+				return 0, err
+			}
+			count += n + 1
+		}
+	default:
+		return 0, fmt.Errorf("unsupported property list value %T", v)
+	}
+	return count, nil
+}
+
+// encode adds objects of the value and returns reference of it
+func (e *plistEncoder) encode(v any) uint64 {
+	ref := uint64(len(e.objects))
+	e.objects = append(e.objects, nil)
+	var obj []byte
+	switch v := v.(type) {
+	case bool:
+		obj = []byte{0x08}
+		if v {
+			obj[0] = 0x09
+		}
+	case int:
+		obj = plistInt(int64(v))
+	case int64:
+		obj = plistInt(v)
+	case float64:
+		obj = binary.BigEndian.AppendUint64([]byte{0x23}, math.Float64bits(v))
+	case string:
+		obj = plistString(v)
+	case []byte:
+		obj = append(plistHeader(0x4, len(v)), v...)
+	case []any:
+		refs := make([]uint64, len(v))
+		for i, item := range v {
+			refs[i] = e.encode(item)
+		}
+		obj = plistHeader(0xa, len(v))
+		for _, r := range refs {
+			obj = appendUint(obj, r, e.refSize)
+		}
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(v))
+		refs := make([]uint64, 0, 2*len(keys))
+		for _, k := range keys {
+			refs = append(refs, e.encode(k))
+		}
+		for _, k := range keys {
+			refs = append(refs, e.encode(v[k]))
+		}
+		obj = plistHeader(0xd, len(keys))
+		for _, r := range refs {
+			obj = appendUint(obj, r, e.refSize)
+		}
+	}
+	e.objects[ref] = obj
+	return ref
+}
+
+// plistHeader returns marker of the object kind with count of elements
+func plistHeader(kind byte, count int) []byte {
+	if count < 0xf {
+		return []byte{kind<<4 | byte(count)}
+	}
+	return append([]byte{kind<<4 | 0xf}, plistInt(int64(count))...)
+}
+
+// plistInt encodes integer in the smallest size, negative integers take 8 bytes
+func plistInt(v int64) []byte {
+	size := 8
+	if v >= 0 {
+		size = uintSize(uint64(v))
+		if size == 3 {
+			size = 4
+		} else if size > 4 {
+			size = 8
+		}
+	}
+	return appendUint([]byte{0x10 | byte(bits.TrailingZeros(uint(size)))}, uint64(v), size)
+}
+
+// plistString encodes ASCII string or UTF-16 string with other characters
+func plistString(s string) []byte {
+	for _, c := range s {
+		if c >= 0x80 {
+			units := utf16.Encode([]rune(s))
+			obj := plistHeader(0x6, len(units))
+			for _, u := range units {
+				obj = binary.BigEndian.AppendUint16(obj, u)
+			}
+			return obj
+		}
+	}
+	return append(plistHeader(0x5, len(s)), s...)
+}
+
+// uintSize returns count of bytes needed for the unsigned integer
+func uintSize(v uint64) int {
+	return max(1, (bits.Len64(v)+7)/8)
+}
+
+// appendUint appends big-endian unsigned integer of the size
+func appendUint(b []byte, v uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package dsstore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodePlist(t *testing.T) {
+	// {"a": [true, 1, 1.5, <00>]}
+	data := []byte("bplist00" +
+		"\xd1\x01\x02" + // dictionary of 1 entry: key 1, value 2
+		"\x51a" +
+		"\xa4\x03\x04\x05\x06" +
+		"\x09" +
+		"\x10\x01" +
+		"\x23\x3f\xf8\x00\x00\x00\x00\x00\x00" +
+		"\x41\x00" +
+		"\x08\x0b\x0d\x12\x13\x15\x1e" + // offsets of 7 objects
+		"\x00\x00\x00\x00\x00\x00\x01\x01" + "\x00\x00\x00\x00\x00\x00\x00\x07" +
+		"\x00\x00\x00\x00\x00\x00\x00\x00" + "\x00\x00\x00\x00\x00\x00\x00\x20")
+	v, err := decodePlist(data)
+	if err != nil {
+		t.Fatalf("decodePlist failed: %v", err)
+	}
+	array, ok := v.(map[string]any)["a"].([]any)
+	if !ok || len(array) != 4 || array[0] != true || array[1] != int64(1) || array[2] != 1.5 {
+		t.Errorf("unexpected value %#v", v)
+	}
+
+	// dictionary containing itself
+	cyclic := []byte("bplist00" + "\xd1\x01\x00" + "\x51a" + "\x08\x0b" +
+		"\x00\x00\x00\x00\x00\x00\x01\x01" + "\x00\x00\x00\x00\x00\x00\x00\x02" +
+		"\x00\x00\x00\x00\x00\x00\x00\x00" + "\x00\x00\x00\x00\x00\x00\x00\x0d")
+	for _, data := range [][]byte{cyclic, []byte("bplist00" + strings.Repeat("\x00", 32)), data[:len(data)-1]} {
+		if _, err = decodePlist(data); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestEncodePlist(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	v := map[string]any{
+		"bool": true, "int": int64(-1), "big": int64(1) << 40, "real": 0.5, "text": "Café", "long": long,
+		"data": []byte{1, 2}, "array": []any{int64(300), false, map[string]any{}},
+	}
+	data, err := encodePlist(v)
+	if err != nil {
+		t.Fatalf("encodePlist failed: %v", err)
+	}
+	decoded, err := decodePlist(data)
+	if err != nil {
+		t.Fatalf("decodePlist failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, v) {
+		t.Errorf("unexpected decoded value %#v", decoded)
+	}
+	if _, err = encodePlist(map[string]any{"a": uint8(1)}); err == nil {
+		t.Error("expected error for unsupported value")
+	}
+}
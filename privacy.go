@@ -0,0 +1,167 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PrivacyReport classifies what the store leaks, see Analyze
+type PrivacyReport struct {
+	Files        []string           `json:"files"`                  // file names referenced by records
+	DeletedFiles []string           `json:"deletedFiles,omitempty"` // file names of record remnants in free blocks
+	Comments     []PrivacyComment   `json:"comments,omitempty"`
+	Timestamps   []PrivacyTimestamp `json:"timestamps,omitempty"`
+	Volumes      []string           `json:"volumes,omitempty"`     // volume names of aliases and bookmarks
+	VolumeUUIDs  []string           `json:"volumeUUIDs,omitempty"` // volume UUIDs of bookmarks
+	Paths        []string           `json:"paths,omitempty"`       // absolute paths of aliases and bookmarks
+	Users        []string           `json:"users,omitempty"`       // user names of home folders in the paths
+}
+
+// PrivacyComment is a Finder comment of the file
+type PrivacyComment struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+}
+
+// PrivacyTimestamp is a time of "dutc" record of the file
+type PrivacyTimestamp struct {
+	Name string    `json:"name"`
+	Code string    `json:"code"`
+	Time time.Time `json:"time"`
+}
+
+// Analyze reports file names, Finder comments, timestamps, volume names, UUIDs and paths of aliases and bookmarks
+// and user names of home folders in these paths. File names of deleted records are found in free blocks
+// only for stores read with ReadOptions.Fidelity, which keeps the file data, see Store.RecoverDeleted.
+func Analyze(s *Store) PrivacyReport {
+	var report PrivacyReport
+	files := make(map[string]bool)
+	volumes := make(map[string]bool)
+	paths := make(map[string]bool)
+	for _, r := range s.Records {
+		if r.FileName != "." && !files[nameKey(r.FileName)] {
+			files[nameKey(r.FileName)] = true
+			report.Files = append(report.Files, r.FileName)
+		}
+		if r.Code() == "cmmt" {
+			if comment, ok := r.Text(); ok && comment != "" {
+				report.Comments = append(report.Comments, PrivacyComment{Name: r.FileName, Comment: comment})
+			}
+		}
+		if t, ok := r.Time(); ok {
+			report.Timestamps = append(report.Timestamps, PrivacyTimestamp{Name: r.FileName, Code: r.Code(), Time: t})
+		}
+	}
+	uuids := make(map[string]bool)
+	for _, p := range s.EmbeddedPaths() {
+		volumes[p.Volume] = true
+		uuids[p.VolumeUUID] = true
+		paths[p.Path] = true
+	}
+	recovered, _ := s.RecoverDeleted()
+	for _, r := range recovered {
+		if r.Confidence >= 0.6 && !files[nameKey(r.FileName)] {
+			files[nameKey(r.FileName)] = true
+			report.DeletedFiles = append(report.DeletedFiles, r.FileName)
+		}
+	}
+	delete(volumes, "")
+	delete(uuids, "")
+	delete(paths, "/")
+	users := make(map[string]bool)
+	for p := range paths {
+		elements := strings.Split(p, "/")
+		for i := 1; i+1 < len(elements); i++ {
+			if (elements[i] == "Users" || elements[i] == "home") && elements[i+1] != "Shared" && elements[i+1] != "" {
+				users[elements[i+1]] = true
+			}
+		}
+	}
+	report.Volumes = slices.Sorted(maps.Keys(volumes))
+	report.VolumeUUIDs = slices.Sorted(maps.Keys(uuids))
+	report.Paths = slices.Sorted(maps.Keys(paths))
+	report.Users = slices.Sorted(maps.Keys(users))
+	return report
+}
+
+// Summary returns human readable summary of the report, one finding per line
+func (r PrivacyReport) Summary() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%d file names are listed\n", len(r.Files))
+	if len(r.DeletedFiles) != 0 {
+		_, _ = fmt.Fprintf(&b, "%d names of deleted files remain in free space: %s\n",
+			len(r.DeletedFiles), strings.Join(r.DeletedFiles, ", "))
+	}
+	if len(r.Comments) != 0 {
+		_, _ = fmt.Fprintf(&b, "%d Finder comments\n", len(r.Comments))
+	}
+	if len(r.Timestamps) != 0 {
+		first, last := r.Timestamps[0].Time, r.Timestamps[0].Time
+		for _, t := range r.Timestamps {
+			first, last = minTime(first, t.Time), maxTime(last, t.Time)
+		}
+		_, _ = fmt.Fprintf(&b, "%d timestamps from %s to %s\n", len(r.Timestamps),
+			first.Format(time.DateOnly), last.Format(time.DateOnly))
+	}
+	if len(r.Volumes) != 0 {
+		_, _ = fmt.Fprintf(&b, "volume names: %s\n", strings.Join(r.Volumes, ", "))
+	}
+	if len(r.VolumeUUIDs) != 0 {
+		_, _ = fmt.Fprintf(&b, "volume UUIDs: %s\n", strings.Join(r.VolumeUUIDs, ", "))
+	}
+	if len(r.Users) != 0 {
+		_, _ = fmt.Fprintf(&b, "user names: %s\n", strings.Join(r.Users, ", "))
+	}
+	for _, p := range r.Paths {
+		_, _ = fmt.Fprintf(&b, "path: %s\n", p)
+	}
+	return b.String()
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// embeddedData returns data of the blob record and data values of its property list,
+// which may be aliases or bookmarks
+func embeddedData(r Record) [][]byte {
+	if !bytes.HasPrefix(r.Data, bplistHeader) {
+		return [][]byte{r.Data}
+	}
+	v, err := decodePlist(r.Data)
+	if err != nil {
+		return nil
+	}
+	var found [][]byte
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []byte:
+			found = append(found, v)
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return found
+}
@@ -0,0 +1,71 @@
+package dsstore
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	s.Records = append(s.Records, TextRecord("Applications", "cmmt", "drag here"),
+		TimeRecord("Applications", "moDD", time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)))
+	report := Analyze(s)
+	if !slices.Equal(report.Files, []string{"Applications", "Getscreen.me.app"}) ||
+		!slices.Equal(report.Volumes, []string{"Macintosh HD"}) || len(report.VolumeUUIDs) != 1 || !slices.Equal(report.Users, []string{"gwend"}) ||
+		len(report.Comments) != 1 || len(report.Timestamps) != 1 || len(report.DeletedFiles) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if !slices.Contains(report.Paths, "/Users/gwend/Library/Mobile Documents/com~apple~CloudDocs/Getscreen/Background_Black.png") {
+		t.Errorf("bookmark path is not reported: %q", report.Paths)
+	}
+	summary := report.Summary()
+	for _, line := range []string{"2 file names are listed", "1 Finder comments", "1 timestamps from 2021-03-04 to 2021-03-04",
+		"volume names: Macintosh HD", "user names: gwend"} {
+		if !strings.Contains(summary, line+"\n") {
+			t.Errorf("summary has no %q:\n%s", line, summary)
+		}
+	}
+}
+
+func TestAnalyzeDeletedFiles(t *testing.T) {
+	s := &Store{Records: []Record{TextRecord("kept.txt", "cmmt", "x")}}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if err := s.ReadWithOptions(bytes.NewReader(data), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatal(err)
+	}
+	// remnant of the record of a deleted file in the largest free block inside of the file
+	b := new(bytes.Buffer)
+	if err := s.writeRecord(b, TextRecord("secret.pdf", "cmmt", "plan")); err != nil {
+		t.Fatal(err)
+	}
+	remnant := b.Bytes()
+	written := false
+	for i := len(s.alloc.freeLists) - 1; i >= 0 && !written; i-- {
+		for _, offset := range s.alloc.freeLists[i] {
+			if int(offset)+4+len(remnant) <= len(data) && 1<<i >= len(remnant) {
+				copy(data[offset+4:], remnant)
+				written = true
+				break
+			}
+		}
+	}
+	if !written {
+		t.Fatal("no free block for the remnant")
+	}
+	if err := s.ReadWithOptions(bytes.NewReader(data), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatal(err)
+	}
+	if report := Analyze(s); !slices.Equal(report.DeletedFiles, []string{"secret.pdf"}) {
+		t.Errorf("unexpected deleted files %q", report.DeletedFiles)
+	}
+}
@@ -0,0 +1,42 @@
+package dsstore
+
+import "io"
+
+// Progress of reading or writing reported by OnProgress callbacks of options
+type Progress struct {
+	BytesRead      int64 // bytes read from the reader
+	NodesParsed    int   // B-tree nodes parsed
+	RecordsRead    int   // records read
+	RecordsWritten int   // records encoded into B-tree nodes
+	BytesWritten   int64 // bytes written to the writer
+}
+
+// reportProgress calls OnProgress callback of reading
+func (s *Store) reportProgress() {
+	if s.opts.OnProgress != nil {
+		s.progress.RecordsRead = len(s.Records)
+		s.opts.OnProgress(s.progress)
+	}
+}
+
+// progressReader reports bytes read from the reader
+type progressReader struct {
+	s *Store
+	r io.Reader
+}
+
+func (pr progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.s.progress.BytesRead += int64(n)
+		pr.s.reportProgress()
+	}
+	return n, err
+}
+
+// reportProgress calls OnProgress callback of writing
+func (e *Encoder) reportProgress() {
+	if e.onProgress != nil {
+		e.onProgress(e.progress)
+	}
+}
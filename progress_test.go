@@ -0,0 +1,65 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadProgress(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var last Progress
+	calls := 0
+	var s Store
+	err = s.ReadWithOptions(bytes.NewReader(fileData), ReadOptions{OnProgress: func(p Progress) {
+		if p.BytesRead < last.BytesRead || p.NodesParsed < last.NodesParsed || p.RecordsRead < last.RecordsRead {
+			t.Errorf("progress goes back: %+v after %+v", p, last)
+		}
+		last = p
+		calls++
+	}})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expected := Progress{BytesRead: int64(len(fileData)), NodesParsed: 1, RecordsRead: 6}
+	if last != expected || calls < 3 {
+		t.Errorf("expected the last progress %+v, got %+v after %d calls", expected, last, calls)
+	}
+}
+
+func TestWriteProgress(t *testing.T) {
+	s := &Store{}
+	for i := 0; i < 1000; i++ {
+		s.Records = append(s.Records, Record{FileName: fmt.Sprintf("file%04d", i), Type: "long", Data: []byte{0, 0, 0, 1}})
+	}
+	var progress []Progress
+	buf := new(bytes.Buffer)
+	if err := s.WriteWithOptions(buf, WriteOptions{OnProgress: func(p Progress) {
+		progress = append(progress, p)
+	}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	last := progress[len(progress)-1]
+	if last.RecordsWritten != 1000 || last.BytesWritten != int64(buf.Len()) {
+		t.Errorf("expected all records and bytes to be written, got %+v", last)
+	}
+	if progress[0].RecordsWritten == 0 || progress[0].RecordsWritten == 1000 {
+		t.Errorf("expected progress for each node, got %+v first", progress[0])
+	}
+
+	target := filepath.Join(t.TempDir(), ".DS_Store")
+	progress = nil
+	if err := s.WriteFileWithOptions(target, 0644, WriteOptions{OnProgress: func(p Progress) {
+		progress = append(progress, p)
+	}}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if last = progress[len(progress)-1]; last.BytesWritten != int64(buf.Len()) {
+		t.Errorf("expected %d bytes to be written, got %+v", buf.Len(), last)
+	}
+}
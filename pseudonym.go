@@ -0,0 +1,44 @@
+package dsstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+)
+
+// Pseudonymizer replaces file names by deterministic pseudonyms: HMAC-SHA256 of the name with the key,
+// so the same names get the same pseudonyms while the key is kept and names can't be guessed without it.
+// Names which differ only in case or Unicode normalization get the same pseudonym, like Finder treats them.
+type Pseudonymizer struct {
+	Key            []byte
+	KeepExtensions bool // keep extensions, like ".app", so kinds of files remain visible
+}
+
+// Name returns pseudonym of the file name, the folder itself "." is kept
+func (p Pseudonymizer) Name(name string) string {
+	if name == "." {
+		return name
+	}
+	mac := hmac.New(sha256.New, p.Key)
+	mac.Write([]byte(nameKey(name)))
+	pseudonym := hex.EncodeToString(mac.Sum(nil)[:8])
+	if p.KeepExtensions {
+		pseudonym += path.Ext(name)
+	}
+	return pseudonym
+}
+
+// Anonymize replaces file names of all records of the store by pseudonyms
+// and returns the original names by pseudonyms, so the owner of the key can map findings back
+func (p Pseudonymizer) Anonymize(s *Store) map[string]string {
+	names := make(map[string]string)
+	for i, r := range s.Records {
+		pseudonym := p.Name(r.FileName)
+		if _, ok := names[pseudonym]; !ok {
+			names[pseudonym] = r.FileName
+		}
+		s.Records[i].FileName = pseudonym
+	}
+	return names
+}
@@ -0,0 +1,26 @@
+package dsstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	p := Pseudonymizer{Key: []byte("key"), KeepExtensions: true}
+	name := p.Name("Getscreen.me.app")
+	if len(name) != 20 || !strings.HasSuffix(name, ".app") || name != p.Name("GETSCREEN.me.app") {
+		t.Errorf("unexpected pseudonym %q", name)
+	}
+	if other := (Pseudonymizer{Key: []byte("other")}).Name("Getscreen.me.app"); len(other) != 16 || strings.HasPrefix(name, other) {
+		t.Errorf("unexpected pseudonym of other key %q", other)
+	}
+	if p.Name(".") != "." {
+		t.Error("folder itself must not be renamed")
+	}
+	s := &Store{Records: []Record{TextRecord("a.txt", "cmmt", "x"), TextRecord("A.txt", "cmmt", "y"), TextRecord(".", "cmmt", "z")}}
+	names := p.Anonymize(s)
+	if s.Records[0].FileName != s.Records[1].FileName || s.Records[2].FileName != "." || len(names) != 2 ||
+		names[s.Records[0].FileName] != "a.txt" {
+		t.Errorf("unexpected records %+v and names %v", s.Records, names)
+	}
+}
@@ -2,97 +2,208 @@ package dsstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
 
+	"github.com/strongo/dsstore/buddy"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
-func (s *Store) readBlock(fileData []byte, offset, size uint32) *bytes.Buffer {
+// Default limits of ReadOptions
+const (
+	DefaultMaxFileSize int64 = 64 << 20 // 64 MiB
+	DefaultMaxRecords        = 1 << 20
+	DefaultMaxBlobLen        = 16 << 20 // 16 MiB
+	DefaultMaxNodes          = 1 << 16
+	DefaultMaxDepth          = 64
+)
+
+// ReadOptions of .DS_Store reading.
+// Limits protect from memory exhaustion on hostile inputs,
+// zero values select defaults.
+type ReadOptions struct {
+	MaxFileSize int64 // maximal file size in bytes
+	MaxRecords  int   // maximal count of records
+	MaxBlobLen  int   // maximal data size of one record in bytes
+	MaxNodes    int   // maximal count of blocks and B-tree nodes
+	MaxDepth    int   // maximal depth of B-tree
+
+	// OnWarning is called for recoverable anomalies, which don't fail reading
+	OnWarning func(Warning)
+	// Strict also reports anomalies commonly produced by other tools, like trailing data
+	Strict bool
+	// BestEffort skips nodes which are not fully present in truncated file
+	// and nodes which can't be decoded. All problems are returned as one joined error
+	// (including *TruncatedError) together with records of decoded nodes
+	BestEffort bool
+	// UnknownCodes is the policy for records with structure IDs which are not in KnownCodes
+	UnknownCodes UnknownCodePolicy
+	// OnUnknownCode decides whether to keep record with unknown structure ID for UnknownCodeCallback policy
+	OnUnknownCode func(Record) (keep bool, err error)
+	// Fidelity keeps layout of the read file, so writing keeps unchanged blocks at their original offsets
+	Fidelity bool
+	// OnProgress is called while reading with bytes read, nodes parsed and records read so far
+	OnProgress func(Progress)
+	// Locking takes shared advisory lock of the file while ReadFile reads it
+	Locking LockOptions
+	// ZeroCopy makes Record.Data reference the read file data instead of copying it.
+	// Such data must not be modified and it is valid while the file data is valid
+	// (for OpenFileMmap until Reader.Close). Record.Materialize returns record with own copy of data
+	ZeroCopy bool
+	// Logger receives debug events of reading: block reads, node visits and skipped nodes,
+	// warnings are logged at warning level. Nil logger logs nothing
+	Logger *slog.Logger
+}
+
+// withDefaults returns options with zero limits replaced by defaults
+func (o ReadOptions) withDefaults() ReadOptions {
+	if o.MaxFileSize <= 0 {
+		o.MaxFileSize = DefaultMaxFileSize
+	}
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = DefaultMaxRecords
+	}
+	if o.MaxBlobLen <= 0 {
+		o.MaxBlobLen = DefaultMaxBlobLen
+	}
+	if o.MaxNodes <= 0 {
+		o.MaxNodes = DefaultMaxNodes
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	return o
+}
+
+// blockRange returns bounds of block data in file data.
+// Calculations are done in 64 bits, so hostile offsets and sizes can't overflow.
+func blockRange(fileLen int64, offset, size uint32) (start, end int64, err error) {
+	// blocks are addressed after 4 bytes of file prefix
+	start64 := uint64(offset) + 4
+	end64 := start64 + uint64(size)
+	if end64 > uint64(fileLen) {
+		return 0, 0, fmt.Errorf("block at offset %d with size %d exceeds file size %d", offset, size, fileLen)
+	}
+	return int64(start64), int64(end64), nil
+}
+
+// blockSource provides blocks of .DS_Store file
+type blockSource interface {
+	size() int64
+	readBlock(offset, size uint32) (*bytes.Buffer, error)
+}
+
+// bytesSource provides blocks of .DS_Store loaded to memory
+type bytesSource []byte
+
+func (b bytesSource) size() int64 {
+	return int64(len(b))
+}
+
+func (b bytesSource) readBlock(offset, size uint32) (*bytes.Buffer, error) {
 	// check size
-	if offset+4+size > uint32(len(fileData)) {
-		return nil
+	start, end, err := blockRange(b.size(), offset, size)
+	if err != nil {
+		return nil, err
 	}
 	// alloc reading buffer
-	return bytes.NewBuffer(fileData[offset+4 : offset+4+size])
+	return bytes.NewBuffer(b[start:end]), nil
 }
 
-func (s *Store) readOffsets(b *bytes.Buffer) ([]uint32, error) {
-	var count uint32
-	if err := binary.Read(b, binary.BigEndian, &count); err != nil {
+// readerAtSource provides blocks of .DS_Store reading them on demand
+type readerAtSource struct {
+	r        io.ReaderAt
+	fileSize int64
+}
+
+func (ra readerAtSource) size() int64 {
+	return ra.fileSize
+}
+
+func (ra readerAtSource) readBlock(offset, size uint32) (*bytes.Buffer, error) {
+	start, end, err := blockRange(ra.fileSize, offset, size)
+	if err != nil {
 		return nil, err
 	}
-	// read dummy value
-	var value uint32
-	if err := binary.Read(b, binary.BigEndian, &value); err != nil {
+	data := make([]byte, end-start)
+	if _, err = ra.r.ReadAt(data, start); err != nil {
 		return nil, err
 	}
-	// read offsets
-	offsets := make([]uint32, 0)
-	for offcount := int(count); offcount > 0; offcount -= 256 {
-		for i := 0; i < 256; i++ {
-			if err := binary.Read(b, binary.BigEndian, &value); err != nil {
-				return nil, err
-			}
-			if value == 0 {
-				continue
+	return bytes.NewBuffer(data), nil
+}
+
+func (s *Store) readBlock(fileData []byte, offset, size uint32) (*bytes.Buffer, error) {
+	return bytesSource(fileData).readBlock(offset, size)
+}
+
+// readUint32 reads big-endian uint32 like binary.Read does, but without allocations
+func readUint32(b *bytes.Buffer) (uint32, error) {
+	if b.Len() < 4 {
+		if b.Len() == 0 {
+			return 0, io.EOF
+		}
+		b.Next(b.Len())
+		return 0, io.ErrUnexpectedEOF
+	}
+	return binary.BigEndian.Uint32(b.Next(4)), nil
+}
+
+// readOffsets reads addresses of blocks skipping zero ones
+func (s *Store) readOffsets(b *bytes.Buffer) ([]uint32, error) {
+	table, count, err := buddy.ReadOffsets(b, s.opts.withDefaults().MaxNodes)
+	if errors.Is(err, buddy.ErrTooManyBlocks) {
+		return nil, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	offsets := make([]uint32, 0, count)
+	for index, value := range table {
+		if value == 0 {
+			if index < count {
+				s.warn(WarningZeroOffset, "offset %d of %d is zero", index, count)
 			}
-			offsets = append(offsets, value)
+			continue
 		}
+		offsets = append(offsets, value)
 	}
-	// offsets
 	return offsets, nil
 }
 
+// readTopics reads block indexes by topic names, only DSDB topic is used
 func (s *Store) readTopics(b *bytes.Buffer) (map[string]uint32, error) {
-	// read topic count
-	var count uint32
-	if err := binary.Read(b, binary.BigEndian, &count); err != nil {
+	topics, err := buddy.ReadDirectory(b)
+	if err != nil {
 		return nil, err
 	}
-	// read topics
-	topics := make(map[string]uint32)
-	for i := count; i > 0; i-- {
-		// read topic readBytesCount
-		readBytesCount, err := b.ReadByte()
-		if err != nil {
-			return nil, err
-		}
-		// read topic name
-		name := make([]byte, readBytesCount)
-		_, err = b.Read(name)
-		if err != nil {
-			return nil, err
-		}
-		// read topic index
-		var index uint32
-		if err := binary.Read(b, binary.BigEndian, &index); err != nil {
-			return nil, err
+	for _, name := range slices.Sorted(maps.Keys(topics)) {
+		if name != "DSDB" {
+			s.warn(WarningUnusedTopic, "topic %q is not used", name)
 		}
-		// add topic
-		topics[string(name)] = index
 	}
 	return topics, nil
 }
 
+// readFreeBlocks reads free lists of the allocator
 func (s *Store) readFreeBlocks(b *bytes.Buffer) error {
-	for i := 0; i < 32; i++ {
-		var count uint32
-		if err := binary.Read(b, binary.BigEndian, &count); err != nil {
-			return err
-		}
-		if count == 0 {
-			continue
-		}
-		for k := 0; k < int(count); k++ {
-			var value uint32
-			if err := binary.Read(b, binary.BigEndian, &value); err != nil {
-				return err
+	freeLists, err := buddy.ReadFreeLists(b)
+	if err != nil {
+		return err
+	}
+	for i, list := range freeLists {
+		for _, value := range list {
+			if value&(uint32(1)<<i-1) != 0 {
+				s.warn(WarningFreeList, "free block %#x is not aligned to its size %d", value, uint32(1)<<i)
 			}
+			s.alloc.freeLists[i] = append(s.alloc.freeLists[i], value)
 		}
 	}
 	return nil
@@ -105,8 +216,14 @@ func (s *Store) readParseFile(b *bytes.Buffer) (Record, error) {
 	if err := binary.Read(b, binary.BigEndian, &lenBytes); err != nil {
 		return r, err
 	}
-	// name
-	name16 := make([]byte, 2*lenBytes)
+	if 2*uint64(lenBytes) > uint64(b.Len()) {
+		return r, errors.New("record name exceeds block")
+	}
+	// name is read into pooled buffer, it is decoded to string
+	nameBuf := getBuffer()
+	defer putBuffer(nameBuf)
+	nameBuf.Grow(2 * int(lenBytes))
+	name16 := nameBuf.AvailableBuffer()[:2*lenBytes]
 	if _, err := b.Read(name16); err != nil {
 		return r, err
 	}
@@ -121,7 +238,7 @@ func (s *Store) readParseFile(b *bytes.Buffer) (Record, error) {
 	}
 	r.Type = string(stype)
 
-	byteToRead := -1
+	var byteToRead uint64
 	// read data
 	switch r.Type {
 	case "bool":
@@ -134,21 +251,33 @@ func (s *Store) readParseFile(b *bytes.Buffer) (Record, error) {
 		if err := binary.Read(b, binary.BigEndian, &r.DataLen); err != nil {
 			return r, err
 		}
-		byteToRead = int(r.DataLen)
+		byteToRead = uint64(r.DataLen)
 	case "ustr":
 		if err := binary.Read(b, binary.BigEndian, &r.DataLen); err != nil {
 			return r, err
 		}
-		byteToRead = int(2 * r.DataLen)
+		byteToRead = 2 * uint64(r.DataLen)
 	default:
 		break
 	}
-	if byteToRead <= 0 {
+	if byteToRead == 0 {
 		return r, fmt.Errorf("unknown record format [%s]", r.Type)
 	}
-	r.Data = make([]byte, byteToRead)
-	if _, err := b.Read(r.Data); err != nil {
-		return r, err
+	if maxBlobLen := s.opts.withDefaults().MaxBlobLen; byteToRead > uint64(maxBlobLen) {
+		return r, fmt.Errorf("%w: record data of %d bytes, maximum is %d", ErrLimitExceeded, byteToRead, maxBlobLen)
+	}
+	if byteToRead > uint64(b.Len()) {
+		return r, errors.New("record data exceeds block")
+	}
+	if s.opts.ZeroCopy {
+		// capacity is limited, so appending to data doesn't overwrite the file data
+		r.Data = b.Next(int(byteToRead))
+		r.Data = r.Data[:len(r.Data):len(r.Data)]
+	} else {
+		r.Data = make([]byte, byteToRead)
+		if _, err := b.Read(r.Data); err != nil {
+			return r, err
+		}
 	}
 	name, _, err := transform.Bytes(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder(), name16)
 	if err != nil {
@@ -158,190 +287,331 @@ func (s *Store) readParseFile(b *bytes.Buffer) (Record, error) {
 	return r, nil
 }
 
-func (s *Store) readParseData(fileData []byte, offsets []uint32, node uint32) error {
-	// check node
-	if int(node) >= len(offsets) {
-		return errors.New("invalid data block")
-	}
-	// prepare data block
-	offset := offsets[node]
-	blockData := s.readBlock(fileData, blockOffset(offset), blockSize(offset))
-	if blockData == nil {
-		return errors.New("invalid data block")
+// truncated remembers that a node is skipped because data ends at offset
+func (s *Store) truncated(offset int64) {
+	if s.truncErr == nil {
+		s.truncErr = &TruncatedError{Offset: offset}
 	}
+	s.truncErr.Skipped++
+}
 
-	var nextNode uint32
-	if err := binary.Read(blockData, binary.BigEndian, &nextNode); err != nil {
+func (s *Store) appendRecord(r Record) error {
+	if keep, err := s.acceptCode(r); err != nil || !keep {
 		return err
 	}
-	var count uint32
-	if err := binary.Read(blockData, binary.BigEndian, &count); err != nil {
-		return err
+	if maxRecords := s.opts.withDefaults().MaxRecords; len(s.Records) >= maxRecords {
+		return fmt.Errorf("%w: more than %d records", ErrLimitExceeded, maxRecords)
 	}
+	s.Records = append(s.Records, r)
+	return nil
+}
 
-	if nextNode > 0 {
-		for i := 0; i < int(count); i++ {
-			var childNode uint32
-			if err := binary.Read(blockData, binary.BigEndian, &childNode); err != nil {
-				return err
-			}
-			if err := s.readParseData(fileData, offsets, childNode); err != nil {
-				return err
-			}
-			// get the file for the current block
-			r, err := s.readParseFile(blockData)
-			if err != nil {
-				return err
-			}
-			s.Records = append(s.Records, r)
+// readParseData reads records of B-tree with root node in-order
+func (s *Store) readParseData(fileData []byte, offsets []uint32, node uint32) error {
+	return s.readParseDataFrom(bytesSource(fileData), offsets, node)
+}
+
+func (s *Store) readParseDataFrom(src blockSource, offsets []uint32, node uint32) error {
+	defer s.checkOrder()
+	if done, err := s.readSmall(src, offsets, node); done {
+		return err
+	}
+	w := s.newTreeWalker(node, func(node uint32) (*bytes.Buffer, error) {
+		return s.readNodeBlock(src, offsets, node)
+	})
+	for {
+		r, ok, err := w.next()
+		if err != nil || !ok {
+			return err
 		}
-		err := s.readParseData(fileData, offsets, nextNode)
-		if err != nil {
+		if err = s.appendRecord(r); err != nil {
 			return err
 		}
-	} else {
-		for i := 0; i < int(count); i++ {
-			r, err := s.readParseFile(blockData)
-			if err != nil {
-				return err
-			}
-			s.Records = append(s.Records, r)
+	}
+}
+
+// readNodeBlock reads block of B-tree node.
+// Nil block without error means that the node is skipped in best-effort mode.
+func (s *Store) readNodeBlock(src blockSource, offsets []uint32, node uint32) (*bytes.Buffer, error) {
+	// check node
+	if uint64(node) >= uint64(len(offsets)) {
+		return nil, errors.New("invalid data block")
+	}
+	// prepare data block
+	offset := offsets[node]
+	s.debug("read block", "index", node, "offset", buddy.Offset(offset), "size", buddy.Size(offset))
+	blockData, err := src.readBlock(buddy.Offset(offset), buddy.Size(offset))
+	if err != nil {
+		if s.opts.BestEffort {
+			s.truncated(src.size())
+			return nil, nil
 		}
+		return nil, fmt.Errorf("invalid data block %d: %w", node, err)
+	}
+	return blockData, nil
+}
+
+// recover remembers recoverable error of the node in best-effort mode,
+// so reading continues with other nodes
+func (s *Store) recover(node uint32, err error) error {
+	if err == nil || !s.opts.BestEffort || errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrUnknownCode) ||
+		s.ctxErr() != nil {
+		return err
 	}
+	s.readErrs = append(s.readErrs, fmt.Errorf("node %d: %w", node, err))
+	s.debug("skip node", "node", node, "error", err)
 	return nil
 }
 
+// checkPadding warns when unused rest of the node block is not zeroed
+func (s *Store) checkPadding(b *bytes.Buffer, node uint32) {
+	for _, v := range b.Bytes() {
+		if v != 0 {
+			s.warn(WarningPadding, "padding of node %d is not zeroed", node)
+			return
+		}
+	}
+}
+
 func (s *Store) readParseDSDB(fileData []byte, offsets []uint32, topics map[string]uint32) error {
+	src := bytesSource(fileData)
+	dataRoot, err := s.readDSDB(src, offsets, topics)
+	if err != nil {
+		return err
+	}
+	// parse data
+	return s.readParseDataFrom(src, offsets, dataRoot)
+}
+
+// readDSDB reads DSDB block and returns root node of data B-tree
+func (s *Store) readDSDB(src blockSource, offsets []uint32, topics map[string]uint32) (uint32, error) {
 	// find node by topic and check it
 	node := topics["DSDB"]
-	if int(node) >= len(offsets) {
-		return errors.New("invalid DSDB block")
+	if uint64(node) >= uint64(len(offsets)) {
+		return 0, errors.New("invalid DSDB block")
 	}
 	// find topic block
 	offset := offsets[node]
-	blockDSDB := s.readBlock(fileData, blockOffset(offset), blockSize(offset))
-	if blockDSDB == nil {
-		return errors.New("invalid DSDB block")
+	blockDSDB, err := src.readBlock(buddy.Offset(offset), buddy.Size(offset))
+	if err != nil {
+		return 0, fmt.Errorf("invalid DSDB block: %w", err)
 	}
 	// read data root node
 	var dataRoot uint32
-	err := binary.Read(blockDSDB, binary.BigEndian, &dataRoot)
-	if err != nil {
-		return err
+	if err = binary.Read(blockDSDB, binary.BigEndian, &dataRoot); err != nil {
+		return 0, err
 	}
-	// just reading
 	var levels uint32
 	if err = binary.Read(blockDSDB, binary.BigEndian, &levels); err != nil {
-		return err
+		return 0, err
 	}
 	var records uint32
 	if err = binary.Read(blockDSDB, binary.BigEndian, &records); err != nil {
-		return err
+		return 0, err
 	}
 	var nodes uint32
 	if err = binary.Read(blockDSDB, binary.BigEndian, &nodes); err != nil {
-		return err
+		return 0, err
 	}
-	var dummy uint32
-	if err = binary.Read(blockDSDB, binary.BigEndian, &dummy); err != nil {
-		return err
+	var pageSize uint32
+	if err = binary.Read(blockDSDB, binary.BigEndian, &pageSize); err != nil {
+		return 0, err
 	}
-	if dummy != 0x1000 {
-		return errors.New("invalid DSDB block")
+	if !validPageSize(int(pageSize)) {
+		return 0, errors.New("invalid DSDB block")
+	}
+	s.alloc.levels, s.alloc.nodes, s.alloc.pageSize = levels, nodes, pageSize
+	s.debug("read DSDB block", "index", node, "root", dataRoot, "levels", levels, "records", records, "nodes", nodes, "pageSize", pageSize)
+	// check limits
+	opts := s.opts.withDefaults()
+	if uint64(records) > uint64(opts.MaxRecords) {
+		return 0, fmt.Errorf("%w: %d records, maximum is %d", ErrLimitExceeded, records, opts.MaxRecords)
+	}
+	if uint64(nodes) > uint64(opts.MaxNodes) {
+		return 0, fmt.Errorf("%w: %d nodes, maximum is %d", ErrLimitExceeded, nodes, opts.MaxNodes)
 	}
 	// read extra
 	if s.DSDBExtra, err = io.ReadAll(blockDSDB); err != nil {
+		return 0, err
+	}
+	return dataRoot, nil
+}
+
+func (s *Store) readParseRoot(fileData []byte, offset, size uint32) error {
+	src := bytesSource(fileData)
+	offsets, dataRoot, err := s.readRoot(src, offset, size)
+	if err != nil {
 		return err
 	}
+	s.readTrailing(fileData, offsets, offset, size)
 	// parse data
-	return s.readParseData(fileData, offsets, dataRoot)
+	if err = s.readParseDataFrom(src, offsets, dataRoot); err != nil {
+		return err
+	}
+	if s.opts.Fidelity {
+		s.layout = &layout{fileData: fileData, rootOffset: offset, rootSize: size, dataRoot: dataRoot}
+	}
+	return nil
 }
 
-func (s *Store) readParseRoot(fileData []byte, offset, size uint32) error {
-	blockRoot := s.readBlock(fileData, offset, size)
-	if blockRoot == nil {
-		return errors.New("invalid root block")
+// readRoot reads root (bookkeeping) block and DSDB block.
+// It returns offsets of blocks and root node of data B-tree.
+func (s *Store) readRoot(src blockSource, offset, size uint32) ([]uint32, uint32, error) {
+	blockRoot, err := src.readBlock(offset, size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid root block: %w", err)
 	}
 	// read offsets
 	offsets, err := s.readOffsets(blockRoot)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
+	s.alloc.offsets = offsets
 	// read topics
 	topics, err := s.readTopics(blockRoot)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
+	s.alloc.topics = topics
 	// parse free blocks
 	if err = s.readFreeBlocks(blockRoot); err != nil {
-		return err
+		return nil, 0, err
 	}
+	s.alloc.read = true
+	s.debug("read root block", "offset", offset, "size", size, "blocks", len(offsets), "topics", len(topics))
 	// read extra root data
 	if s.RootExtra, err = io.ReadAll(blockRoot); err != nil {
-		return err
+		return nil, 0, err
 	}
 	// parse DSDB
-	return s.readParseDSDB(fileData, offsets, topics)
-}
-
-// Read reads .DS_Store from io.Reader
-func (s *Store) Read(r io.Reader) error {
-	// clear
-	s.HeaderExtra = nil
-	s.RootExtra = nil
-	s.DSDBExtra = nil
-	s.Records = nil
-	// read all
-	fileData, err := io.ReadAll(r)
+	dataRoot, err := s.readDSDB(src, offsets, topics)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-	// file size
-	fileSize := len(fileData)
-	if fileSize < 36 {
-		return errors.New("invalid file header")
+	return offsets, dataRoot, nil
+}
+
+// readTrailing keeps data after the end of the last allocated block
+func (s *Store) readTrailing(fileData []byte, offsets []uint32, rootOffset, rootSize uint32) {
+	end := uint64(rootOffset) + uint64(rootSize)
+	for _, offset := range offsets {
+		if blockEnd := uint64(buddy.Offset(offset)) + uint64(buddy.Size(offset)); blockEnd > end {
+			end = blockEnd
+		}
 	}
-	blockHeader := bytes.NewBuffer(fileData[:36])
-	var headerMagic, headerOffset1, headerSize, headerOffset2 uint32
-	// magic 1
-	if err := binary.Read(blockHeader, binary.BigEndian, &headerMagic); err != nil {
-		return err
+	// blocks are addressed after 4 bytes of file prefix
+	end += 4
+	if end >= uint64(len(fileData)) {
+		return
 	}
-	if headerMagic != headerMagic1 {
-		return errors.New("invalid first magic")
+	s.trailing = fileData[end:]
+	if s.opts.Strict {
+		s.warn(WarningTrailingData, "%d bytes after the allocated region at %d", len(s.trailing), end)
 	}
-	// magic 2
-	if err := binary.Read(blockHeader, binary.BigEndian, &headerMagic); err != nil {
+}
+
+// Read reads .DS_Store from io.Reader.
+// Records are read in-order of B-tree, so they keep the order they are sorted in the file.
+func (s *Store) Read(r io.Reader) error {
+	return s.ReadWithOptions(r, ReadOptions{})
+}
+
+// ReadWithOptions reads .DS_Store from io.Reader using options
+func (s *Store) ReadWithOptions(r io.Reader, opts ReadOptions) error {
+	s.reset(opts)
+	hint := sizeHint(r)
+	if s.opts.OnProgress != nil {
+		r = progressReader{s, r}
+	}
+	// read all, but not more than allowed
+	fileData, err := readAll(r, hint, s.opts.MaxFileSize+1)
+	if err != nil {
 		return err
 	}
-	if headerMagic != headerMagic2 {
-		return errors.New("invalid second magic")
-	}
-	// offset1
-	if err := binary.Read(blockHeader, binary.BigEndian, &headerOffset1); err != nil {
-		return err
+	if int64(len(fileData)) > s.opts.MaxFileSize {
+		return fmt.Errorf("%w: file is bigger than %d bytes", ErrLimitExceeded, s.opts.MaxFileSize)
 	}
-	// size
-	if err := binary.Read(blockHeader, binary.BigEndian, &headerSize); err != nil {
+	rootOffset, rootSize, err := s.readHeader(fileData)
+	if err != nil {
 		return err
 	}
-	// offset2
-	if err := binary.Read(blockHeader, binary.BigEndian, &headerOffset2); err != nil {
+	// parse root (bookkeeping) block
+	if err = s.readParseRoot(fileData, rootOffset, rootSize); err != nil {
 		return err
 	}
-	if headerOffset1 != headerOffset2 {
-		return errors.New("invalid header offset")
+	s.reportProgress()
+	s.debug("read store", "records", len(s.Records), "nodes", s.progress.NodesParsed, "bytes", len(fileData))
+	return s.readErr()
+}
+
+// ReadFrom reads .DS_Store from io.Reader until EOF, it implements io.ReaderFrom.
+// It reads with options of the previous reading of the store, so limits and Strict mode
+// of a store read with ReadWithOptions are kept, a new store is read with defaults.
+func (s *Store) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	err := s.ReadWithOptions(cr, s.opts)
+	return cr.n, err
+}
+
+// countingReader counts bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// reset clears the store before reading
+func (s *Store) reset(opts ReadOptions) {
+	s.HeaderExtra = nil
+	s.RootExtra = nil
+	s.DSDBExtra = nil
+	s.Records = nil
+	s.trailing = nil
+	s.alloc = allocation{}
+	s.layout = nil
+	s.progress = Progress{}
+	s.truncErr = nil
+	s.readErrs = nil
+	s.orderErr = nil
+	s.opts = opts.withDefaults()
+	s.mods++
+}
+
+// readErr returns all recovered errors of best-effort reading joined together
+func (s *Store) readErr() error {
+	if s.truncErr != nil {
+		return errors.Join(append(s.readErrs, s.truncErr)...)
 	}
-	// read header extra
-	if s.HeaderExtra, err = io.ReadAll(blockHeader); err != nil {
-		return err
+	return errors.Join(s.readErrs...)
+}
+
+// readHeader reads file header and returns offset and size of root block
+func (s *Store) readHeader(fileData []byte) (uint32, uint32, error) {
+	h, err := buddy.ReadHeader(fileData)
+	if err != nil {
+		return 0, 0, err
 	}
-	// parse root (bookkeeping) block
-	return s.readParseRoot(fileData, headerOffset1, headerSize)
+	s.HeaderExtra = h.Extra
+	s.debug("read header", "rootOffset", h.RootOffset, "rootSize", h.RootSize, "fileSize", len(fileData))
+	return h.RootOffset, h.RootSize, nil
 }
 
 // ReadFile reads .DS_Store from the file
 func (s *Store) ReadFile(filename string) error {
+	return s.ReadFileWithOptions(filename, ReadOptions{})
+}
+
+// ReadFileWithOptions reads .DS_Store from the file using options
+func (s *Store) ReadFileWithOptions(filename string, opts ReadOptions) error {
+	return s.readFile(context.Background(), filename, opts)
+}
+
+func (s *Store) readFile(ctx context.Context, filename string, opts ReadOptions) error {
 	f, err := os.Open(filename)
 	if err != nil {
 		return err
@@ -349,5 +619,13 @@ func (s *Store) ReadFile(filename string) error {
 	defer func() {
 		_ = f.Close()
 	}()
-	return s.Read(f)
+	if err = lockFile(f, false, opts.Locking); err != nil {
+		return err
+	}
+	if opts.Locking.Enabled {
+		defer func() {
+			_ = unlockFile(f)
+		}()
+	}
+	return s.ReadContextWithOptions(ctx, f, opts)
 }
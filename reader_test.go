@@ -3,8 +3,13 @@ package dsstore
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/strongo/dsstore/buddy"
 )
 
 func TestReadFile(t *testing.T) {
@@ -99,10 +104,13 @@ func TestUnknownRecordType(t *testing.T) {
 func TestReadBlockNil(t *testing.T) {
 	s := &Store{}
 	// offset + 4 + size > len(fileData)
-	buf := s.readBlock([]byte{1, 2, 3}, 0, 10)
+	buf, err := s.readBlock([]byte{1, 2, 3}, 0, 10)
 	if buf != nil {
 		t.Error("expected nil buffer for out of bounds block")
 	}
+	if err == nil || err.Error() != "block at offset 0 with size 10 exceeds file size 3" {
+		t.Errorf("expected out of bounds error, got %v", err)
+	}
 }
 
 func TestReadOffsetsError(t *testing.T) {
@@ -163,7 +171,7 @@ func TestReadParseDataInvalidNode(t *testing.T) {
 
 	// readParseData checks if node >= len(offsets)
 	err := s.readParseData(data, []uint32{0}, 10)
-	if err == nil || err.Error() != "invalid data block" {
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid data block") {
 		t.Errorf("expected 'invalid data block' error, got %v", err)
 	}
 }
@@ -171,7 +179,7 @@ func TestReadParseDataInvalidNode(t *testing.T) {
 func TestReadParseDataNilBlock(t *testing.T) {
 	s := &Store{}
 	err := s.readParseData([]byte{1, 2, 3}, []uint32{0}, 0)
-	if err == nil || err.Error() != "invalid data block" {
+	if err == nil || err.Error() != "invalid data block 0: block at offset 0 with size 1 exceeds file size 3" {
 		t.Errorf("expected 'invalid data block' error, got %v", err)
 	}
 }
@@ -179,7 +187,7 @@ func TestReadParseDataNilBlock(t *testing.T) {
 func TestReadParseRootNilBlock(t *testing.T) {
 	s := &Store{}
 	err := s.readParseRoot([]byte{1, 2, 3}, 100, 100)
-	if err == nil || err.Error() != "invalid root block" {
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid root block") {
 		t.Errorf("expected 'invalid root block' error, got %v", err)
 	}
 }
@@ -187,7 +195,7 @@ func TestReadParseRootNilBlock(t *testing.T) {
 func TestReadParseDSDBNilBlock(t *testing.T) {
 	s := &Store{}
 	err := s.readParseDSDB([]byte{1, 2, 3}, []uint32{0}, map[string]uint32{"DSDB": 0})
-	if err == nil || err.Error() != "invalid DSDB block" {
+	if err == nil || err.Error() != "invalid DSDB block: block at offset 0 with size 1 exceeds file size 3" {
 		t.Errorf("expected 'invalid DSDB block' error, got %v", err)
 	}
 }
@@ -202,11 +210,11 @@ func TestReadParseDSDBInvalidNode(t *testing.T) {
 
 func TestReadParseDataRecursive(t *testing.T) {
 	s := &Store{}
-	offsets := []uint32{0, 32 + 5, 64 + 5} // size 32
+	offsets := []uint32{0, 32 + 5, 64 + 5, 96 + 5} // size 32
 	fileData := make([]byte, 256)
 
-	// Block 1 (at offset 32): nextNode=2, count=1
-	binary.BigEndian.PutUint32(fileData[36:], 2) // nextNode
+	// Block 1 (at offset 32): nextNode=3, count=1
+	binary.BigEndian.PutUint32(fileData[36:], 3) // nextNode
 	binary.BigEndian.PutUint32(fileData[40:], 1) // count
 	// childNode
 	binary.BigEndian.PutUint32(fileData[44:], 2) // childNode points to node 2
@@ -229,6 +237,17 @@ func TestReadParseDataRecursive(t *testing.T) {
 	copy(fileData[86:], "bool")
 	fileData[90] = 1 // bool data
 
+	// Block 3 (at offset 96): nextNode=0, count=1
+	binary.BigEndian.PutUint32(fileData[100:], 0) // nextNode
+	binary.BigEndian.PutUint32(fileData[104:], 1) // count
+	// readParseFile: lenBytes=1, name="C", extra=0, type="bool", data=1
+	binary.BigEndian.PutUint32(fileData[108:], 1) // lenBytes
+	fileData[112] = 0
+	fileData[113] = 67                            // "C"
+	binary.BigEndian.PutUint32(fileData[114:], 0) // extra
+	copy(fileData[118:], "bool")
+	fileData[122] = 1 // bool data
+
 	err := s.readParseData(fileData, offsets, 1)
 	if err != nil {
 		t.Fatalf("readParseData recursive failed: %v", err)
@@ -236,6 +255,9 @@ func TestReadParseDataRecursive(t *testing.T) {
 	if len(s.Records) != 3 {
 		t.Errorf("expected 3 records, got %d", len(s.Records))
 	}
+	if s.Records[0].FileName != "B" || s.Records[1].FileName != "A" || s.Records[2].FileName != "C" {
+		t.Errorf("unexpected records order: %v, %v, %v", s.Records[0].FileName, s.Records[1].FileName, s.Records[2].FileName)
+	}
 }
 
 func TestReadOffsetsLarge(t *testing.T) {
@@ -305,3 +327,288 @@ func TestReadFreeBlocksNonZero(t *testing.T) {
 		t.Fatalf("readFreeBlocks failed: %v", err)
 	}
 }
+
+func TestReadLimits(t *testing.T) {
+	s := &Store{}
+	s.Records = append(s.Records,
+		Record{FileName: "a", Type: "bool", Data: []byte{1}},
+		Record{FileName: "b", Type: "blob", DataLen: 3, Data: []byte{1, 2, 3}},
+	)
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		opts ReadOptions
+	}{
+		{"MaxFileSize", ReadOptions{MaxFileSize: 100}},
+		{"MaxRecords", ReadOptions{MaxRecords: 1}},
+		{"MaxBlobLen", ReadOptions{MaxBlobLen: 2}},
+		{"MaxNodes", ReadOptions{MaxNodes: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s2 Store
+			err := s2.ReadWithOptions(bytes.NewReader(data), tt.opts)
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("expected ErrLimitExceeded, got %v", err)
+			}
+		})
+	}
+
+	var s2 Store
+	if err := s2.ReadWithOptions(bytes.NewReader(data), ReadOptions{MaxRecords: 2, MaxBlobLen: 3}); err != nil {
+		t.Errorf("expected no error within limits, got %v", err)
+	}
+}
+
+func TestReadParseFileHugeLength(t *testing.T) {
+	s := &Store{}
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.BigEndian, uint32(0xFFFFFFFF)) // name length
+	_, err := s.readParseFile(buf)
+	if err == nil {
+		t.Error("expected error for name length exceeding block")
+	}
+}
+
+func TestReadParseDataCycle(t *testing.T) {
+	s := &Store{}
+	offsets := []uint32{0, 32 + 5} // size 32
+	fileData := make([]byte, 128)
+	// Block 1 (at offset 32): nextNode=1 points to itself
+	binary.BigEndian.PutUint32(fileData[36:], 1) // nextNode
+	binary.BigEndian.PutUint32(fileData[40:], 0) // count
+
+	err := s.readParseData(fileData, offsets, 1)
+	if !errors.Is(err, ErrMalformedTree) {
+		t.Errorf("expected ErrMalformedTree, got %v", err)
+	}
+}
+
+func TestReadParseDataDepth(t *testing.T) {
+	s := &Store{opts: ReadOptions{MaxDepth: 3}}
+	// chain of internal nodes: 1 -> 2 -> 3 -> 4 -> 5
+	offsets := []uint32{0}
+	fileData := make([]byte, 4+32*7)
+	for node := uint32(1); node <= 5; node++ {
+		offsets = append(offsets, 32*node+5)
+		binary.BigEndian.PutUint32(fileData[4+32*node:], node+1) // nextNode
+		binary.BigEndian.PutUint32(fileData[8+32*node:], 0)      // count
+	}
+
+	err := s.readParseData(fileData, offsets, 1)
+	if !errors.Is(err, ErrMalformedTree) {
+		t.Errorf("expected ErrMalformedTree, got %v", err)
+	}
+}
+
+func TestReadBlockOverflow(t *testing.T) {
+	s := &Store{}
+	// offset + 4 + size overflows 32 bits
+	buf, err := s.readBlock(make([]byte, 100), 0xFFFFFFF0, 0x20)
+	if buf != nil || err == nil {
+		t.Error("expected error for overflowing block")
+	}
+}
+
+func TestReadRootOutOfRange(t *testing.T) {
+	data := make([]byte, 64)
+	binary.BigEndian.PutUint32(data[0:], buddy.Magic1)
+	binary.BigEndian.PutUint32(data[4:], buddy.Magic2)
+	binary.BigEndian.PutUint32(data[8:], 0xFFFFFFF0)  // offset 1
+	binary.BigEndian.PutUint32(data[12:], 0x20)       // size
+	binary.BigEndian.PutUint32(data[16:], 0xFFFFFFF0) // offset 2
+	var s Store
+	err := s.Read(bytes.NewReader(data))
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid root block") {
+		t.Errorf("expected 'invalid root block' error, got %v", err)
+	}
+}
+
+func TestReadTrailing(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var s Store
+	if err = s.Read(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if s.Trailing() != nil {
+		t.Errorf("expected no trailing data, got %d bytes", len(s.Trailing()))
+	}
+
+	data = append(data, "trailing"...)
+	var warnings []Warning
+	err = s.ReadWithOptions(bytes.NewReader(data), ReadOptions{
+		Strict:    true,
+		OnWarning: func(w Warning) { warnings = append(warnings, w) },
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if string(s.Trailing()) != "trailing" {
+		t.Errorf("expected trailing data, got %q", s.Trailing())
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningTrailingData {
+		t.Errorf("expected trailing data warning, got %v", warnings)
+	}
+}
+
+func TestReadBestEffortTruncated(t *testing.T) {
+	s := &Store{}
+	s.Records = append(s.Records, Record{FileName: "a", Type: "blob", DataLen: 3000, Data: make([]byte, 3000)})
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data := buf.Bytes()[:6000]
+
+	var s2 Store
+	if err := s2.Read(bytes.NewReader(data)); err == nil || !strings.HasPrefix(err.Error(), "invalid data block") {
+		t.Errorf("expected 'invalid data block' error, got %v", err)
+	}
+
+	err := s2.ReadWithOptions(bytes.NewReader(data), ReadOptions{BestEffort: true})
+	var truncErr *TruncatedError
+	if !errors.As(err, &truncErr) {
+		t.Fatalf("expected TruncatedError, got %v", err)
+	}
+	if truncErr.Offset != 6000 || truncErr.Skipped != 1 {
+		t.Errorf("unexpected truncation %v", truncErr)
+	}
+}
+
+func TestReadParseDataBestEffort(t *testing.T) {
+	s := &Store{opts: ReadOptions{BestEffort: true}}
+	offsets := []uint32{0, 32 + 5, 1024 + 10, 64 + 5} // node 2 is out of data
+	fileData := make([]byte, 128)
+
+	// Block 1 (at offset 32): nextNode=3, count=1
+	binary.BigEndian.PutUint32(fileData[36:], 3) // nextNode
+	binary.BigEndian.PutUint32(fileData[40:], 1) // count
+	binary.BigEndian.PutUint32(fileData[44:], 2) // childNode
+	binary.BigEndian.PutUint32(fileData[48:], 1) // lenBytes
+	fileData[53] = 65                            // "A"
+	copy(fileData[58:], "bool")
+	fileData[62] = 1 // bool data
+
+	// Block 3 (at offset 64): nextNode=0, count=1
+	binary.BigEndian.PutUint32(fileData[68:], 0) // nextNode
+	binary.BigEndian.PutUint32(fileData[72:], 1) // count
+	binary.BigEndian.PutUint32(fileData[76:], 1) // lenBytes
+	fileData[81] = 67                            // "C"
+	copy(fileData[86:], "bool")
+	fileData[90] = 1 // bool data
+
+	if err := s.readParseData(fileData, offsets, 1); err != nil {
+		t.Fatalf("readParseData failed: %v", err)
+	}
+	if len(s.Records) != 2 || s.Records[0].FileName != "A" || s.Records[1].FileName != "C" {
+		t.Errorf("unexpected records %v", s.Records)
+	}
+	if s.truncErr == nil || s.truncErr.Offset != 128 || s.truncErr.Skipped != 1 {
+		t.Errorf("unexpected truncation %v", s.truncErr)
+	}
+}
+
+func TestReadParseDataBestEffortErrors(t *testing.T) {
+	s := &Store{opts: ReadOptions{BestEffort: true}}
+	offsets := []uint32{0, 32 + 5, 64 + 5, 1024 + 10} // node 3 is out of data
+	fileData := make([]byte, 128)
+
+	// Block 1 (at offset 32): nextNode=3, count=1
+	binary.BigEndian.PutUint32(fileData[36:], 3) // nextNode
+	binary.BigEndian.PutUint32(fileData[40:], 1) // count
+	binary.BigEndian.PutUint32(fileData[44:], 2) // childNode
+	binary.BigEndian.PutUint32(fileData[48:], 1) // lenBytes
+	fileData[53] = 65                            // "A"
+	copy(fileData[58:], "bool")
+	fileData[62] = 1 // bool data
+
+	// Block 2 (at offset 64): nextNode=0, count=1, record of unknown type
+	binary.BigEndian.PutUint32(fileData[68:], 0) // nextNode
+	binary.BigEndian.PutUint32(fileData[72:], 1) // count
+	binary.BigEndian.PutUint32(fileData[76:], 1) // lenBytes
+	fileData[81] = 67                            // "C"
+	copy(fileData[86:], "xxxx")
+
+	if err := s.readParseData(fileData, offsets, 1); err != nil {
+		t.Fatalf("readParseData failed: %v", err)
+	}
+	if len(s.Records) != 1 || s.Records[0].FileName != "A" {
+		t.Errorf("unexpected records %v", s.Records)
+	}
+	if len(s.readErrs) != 1 || s.readErrs[0].Error() != "node 2: unknown record format [xxxx]" {
+		t.Errorf("unexpected errors %v", s.readErrs)
+	}
+	if s.truncErr == nil || s.truncErr.Skipped != 1 {
+		t.Errorf("unexpected truncation %v", s.truncErr)
+	}
+}
+
+func TestReadZeroCopy(t *testing.T) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var s Store
+	if err = s.ReadWithOptions(bytes.NewReader(fileData), ReadOptions{ZeroCopy: true}); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	r := s.Records[0]
+	if !bytes.Contains(fileData, r.Data) || cap(r.Data) != len(r.Data) {
+		t.Fatal("expected data with limited capacity")
+	}
+	// data aliases the file data read by Read
+	var copied Store
+	if err = copied.Read(bytes.NewReader(fileData)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	m := r.Materialize()
+	r.Data[0] ^= 0xff
+	if m.Data[0] == r.Data[0] {
+		t.Error("expected materialized record to have own data")
+	}
+	if !m.Equal(copied.Records[0]) {
+		t.Error("expected materialized record to be equal to copied one")
+	}
+}
+
+func TestStoreMaterialize(t *testing.T) {
+	var s Store
+	if err := s.ReadFileWithOptions(filepath.Join(".", "testdata", "00.DS_Store"), ReadOptions{ZeroCopy: true}); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	data := s.Records[0].Data
+	s.Materialize()
+	if &s.Records[0].Data[0] == &data[0] || !bytes.Equal(s.Records[0].Data, data) {
+		t.Error("expected records to have own copies of data")
+	}
+}
+
+func benchmarkRead(b *testing.B, opts ReadOptions) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		b.Fatalf("ReadFile failed: %v", err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		var s Store
+		if err = s.ReadWithOptions(bytes.NewReader(fileData), opts); err != nil {
+			b.Fatalf("Read failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	benchmarkRead(b, ReadOptions{})
+}
+
+func BenchmarkReadZeroCopy(b *testing.B) {
+	benchmarkRead(b, ReadOptions{ZeroCopy: true})
+}
@@ -0,0 +1,129 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+)
+
+// ErrReadOnly is returned by mutating methods of ReadOnly
+var ErrReadOnly = errors.New("store is read-only")
+
+// ReadOnly is a read-only view of Store exposing only its accessors, mutating methods return ErrReadOnly.
+// Accessors return records with own copies of data, so services caching parsed stores can hand them out
+// to concurrent requests without cloning whole stores. ReadOnly is safe for concurrent use.
+type ReadOnly struct {
+	s     *Store
+	index map[syncKey]int // positions of records by keys, the first of duplicate keys
+}
+
+// NewReadOnly creates read-only view of the store. The store must not be modified after that,
+// use Clone to get a modifiable copy.
+func NewReadOnly(s *Store) *ReadOnly {
+	ro := &ReadOnly{s: s, index: make(map[syncKey]int, len(s.Records))}
+	for i, r := range s.Records {
+		k := syncKey{strings.ToLower(r.FileName), r.Extra}
+		if _, ok := ro.index[k]; !ok {
+			ro.index[k] = i
+		}
+	}
+	return ro
+}
+
+// Len returns count of records
+func (ro *ReadOnly) Len() int {
+	return len(ro.s.Records)
+}
+
+// Lookup returns record by file name (case-insensitively) and structure ID
+func (ro *ReadOnly) Lookup(filename, code string) (Record, bool) {
+	i, ok := ro.index[newSyncKey(filename, code)]
+	if !ok {
+		return Record{}, false
+	}
+	return ro.s.Records[i].Materialize(), true
+}
+
+// All returns iterator over records in their order
+func (ro *ReadOnly) All() iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		for _, r := range ro.s.Records {
+			if !yield(r.Materialize()) {
+				return
+			}
+		}
+	}
+}
+
+// Records returns copy of records
+func (ro *ReadOnly) Records() []Record {
+	records := make([]Record, len(ro.s.Records))
+	for i, r := range ro.s.Records {
+		records[i] = r.Materialize()
+	}
+	return records
+}
+
+// Comment returns Finder comment of the file, see Store.Comment
+func (ro *ReadOnly) Comment(fileName string) (string, bool) {
+	return ro.s.Comment(fileName)
+}
+
+// FolderSettings returns window and icon view settings of the folder, see Store.FolderSettings
+func (ro *ReadOnly) FolderSettings() (FolderSettings, error) {
+	settings, err := ro.s.FolderSettings()
+	if settings.IconView != nil {
+		iconView := *settings.IconView
+		iconView.BackgroundImage = bytes.Clone(iconView.BackgroundImage)
+		settings.IconView = &iconView
+	}
+	return settings, err
+}
+
+// Stats returns statistics of records, see Store.Stats
+func (ro *ReadOnly) Stats() Stats {
+	return ro.s.Stats()
+}
+
+// Validate checks records of the store, see Store.Validate
+func (ro *ReadOnly) Validate() error {
+	return ro.s.Validate()
+}
+
+// Write writes .DS_Store to io.Writer
+func (ro *ReadOnly) Write(w io.Writer) error {
+	return ro.s.Write(w)
+}
+
+// MarshalJSON returns JSON of the store, see Store.MarshalJSON
+func (ro *ReadOnly) MarshalJSON() ([]byte, error) {
+	return ro.s.MarshalJSON()
+}
+
+// Clone returns modifiable copy of the store with own copies of data of records,
+// layout of the read file isn't copied, so the copy is written like a new store
+func (ro *ReadOnly) Clone() *Store {
+	return &Store{
+		HeaderExtra: bytes.Clone(ro.s.HeaderExtra),
+		RootExtra:   bytes.Clone(ro.s.RootExtra),
+		DSDBExtra:   bytes.Clone(ro.s.DSDBExtra),
+		Records:     ro.Records(),
+	}
+}
+
+// Set fails with ErrReadOnly
+func (ro *ReadOnly) Set(Record) error {
+	return ErrReadOnly
+}
+
+// Delete fails with ErrReadOnly
+func (ro *ReadOnly) Delete(filename, code string) error {
+	return ErrReadOnly
+}
+
+// SetComment fails with ErrReadOnly
+func (ro *ReadOnly) SetComment(fileName, comment string) error {
+	return ErrReadOnly
+}
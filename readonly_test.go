@@ -0,0 +1,66 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	ro := NewReadOnly(&s)
+	if ro.Len() != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), ro.Len())
+	}
+	r, ok := ro.Lookup("applications", "Iloc")
+	if !ok || r.FileName != "Applications" {
+		t.Fatalf("expected record of Applications, got %v %v", r, ok)
+	}
+	r.Data[0] ^= 0xff
+	if again, _ := ro.Lookup("Applications", "Iloc"); bytes.Equal(again.Data, r.Data) {
+		t.Error("expected records with own data")
+	}
+	if _, ok = ro.Lookup("Applications", "bwsp"); ok {
+		t.Error("expected missing record")
+	}
+
+	for _, err := range []error{ro.Set(r), ro.Delete("Applications", "Iloc"), ro.SetComment("Applications", "comment")} {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("expected ErrReadOnly, got %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var count int
+			for range ro.All() {
+				count++
+			}
+			if count != ro.Len() {
+				t.Errorf("expected %d records, got %d", ro.Len(), count)
+			}
+			if err := ro.Write(io.Discard); err != nil {
+				t.Errorf("Write failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	clone := ro.Clone()
+	clone.SetComment("Applications", "comment")
+	if _, ok = ro.Comment("Applications"); ok {
+		t.Error("expected changes of the clone not to change the store")
+	}
+	if len(clone.Records) != ro.Len()+1 {
+		t.Errorf("expected %d records of the clone, got %d", ro.Len()+1, len(clone.Records))
+	}
+}
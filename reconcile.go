@@ -0,0 +1,76 @@
+package dsstore
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ReconcileOptions are options of Store.Reconcile
+type ReconcileOptions struct {
+	// DryRun only reports changes without modifying records
+	DryRun bool
+	// Defaults returns records added for the file without records, like its icon location.
+	// No records are added when Defaults is nil. Hidden files are skipped.
+	Defaults func(name string, entry fs.DirEntry) []Record
+}
+
+// ReconcileReport is result of Store.Reconcile
+type ReconcileReport struct {
+	Orphans []string // names of files which no longer exist, their records are removed
+	Removed int      // count of removed records
+	Added   []string // names of files which got default records
+}
+
+// nameKey returns key of file name comparing names like macOS file systems do:
+// case-insensitively and regardless of unicode normalization
+func nameKey(name string) string {
+	return strings.ToLower(norm.NFC.String(name))
+}
+
+// Reconcile synchronizes records with files of the directory of the file system:
+// records of files which no longer exist are removed and default records are added
+// for new files. Records of the directory itself (file name ".") are kept.
+func (s *Store) Reconcile(fsys fs.FS, dir string, opts ReconcileOptions) (ReconcileReport, error) {
+	entries, err := fs.ReadDir(fsys, path.Clean(dir))
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	files := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		files[nameKey(entry.Name())] = true
+	}
+	var report ReconcileReport
+	recorded := make(map[string]bool)
+	kept := make([]Record, 0, len(s.Records))
+	for _, r := range s.Records {
+		key := nameKey(r.FileName)
+		if r.FileName != "." && !files[key] {
+			if !recorded[key] {
+				report.Orphans = append(report.Orphans, r.FileName)
+			}
+			recorded[key] = true
+			report.Removed++
+			continue
+		}
+		recorded[key] = true
+		kept = append(kept, r)
+	}
+	if opts.Defaults != nil {
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || recorded[nameKey(name)] {
+				continue
+			}
+			report.Added = append(report.Added, name)
+			kept = append(kept, opts.Defaults(name, entry)...)
+		}
+	}
+	if !opts.DryRun {
+		s.Records = kept
+		s.mods++
+	}
+	return report, nil
+}
@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReconcile(t *testing.T) {
+	rec := func(name, code string) Record {
+		r := Record{FileName: name, Type: "long", Data: []byte{0, 0, 0, 1}}
+		r.SetCode(code)
+		return r
+	}
+	fsys := fstest.MapFS{
+		"dir/App.app/Contents/Info.plist": {},
+		"dir/Read Me.txt":                 {},
+		"dir/Cafe\u0301":                  {}, // decomposed name
+		"dir/new.txt":                     {},
+		"dir/.hidden":                     {},
+	}
+	records := []Record{
+		rec(".", "vSrn"), rec("app.app", "Iloc"), rec("deleted.txt", "Iloc"), rec("deleted.txt", "modD"),
+		rec("read me.txt", "Iloc"), rec("Caf\u00e9", "Iloc"), rec("old", "Iloc"),
+	}
+	defaults := func(name string, entry fs.DirEntry) []Record {
+		return []Record{rec(name, "Iloc")}
+	}
+
+	s := &Store{Records: slices.Clone(records)}
+	report, err := s.Reconcile(fsys, "dir", ReconcileOptions{DryRun: true, Defaults: defaults})
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !slices.Equal(report.Orphans, []string{"deleted.txt", "old"}) || report.Removed != 3 ||
+		!slices.Equal(report.Added, []string{"new.txt"}) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(s.Records) != len(records) {
+		t.Error("dry run changed records")
+	}
+
+	report2, err := s.Reconcile(fsys, "dir/", ReconcileOptions{Defaults: defaults})
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !slices.Equal(report2.Orphans, report.Orphans) || report2.Removed != report.Removed {
+		t.Errorf("unexpected report %+v", report2)
+	}
+	var names []string
+	for _, r := range s.Records {
+		names = append(names, r.FileName)
+	}
+	if !slices.Equal(names, []string{".", "app.app", "read me.txt", "Caf\u00e9", "new.txt"}) {
+		t.Errorf("unexpected records %v", names)
+	}
+	if _, err = s.Reconcile(fsys, "missing", ReconcileOptions{}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
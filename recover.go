@@ -0,0 +1,132 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/strongo/dsstore/buddy"
+)
+
+// RecoveredRecord is a remnant of an old record found in unused space of the store file.
+// Finder rewrites stores frequently, so free blocks and unused rest of B-tree nodes keep records
+// of renamed and deleted files.
+type RecoveredRecord struct {
+	Record
+	Offset     int64   // offset of the remnant in the file
+	InSlack    bool    // remnant is in unused rest of a B-tree node, otherwise in a free block
+	Live       bool    // the store has an equal record, so the remnant is an old copy of it
+	Confidence float64 // from 0 to 1, how likely the remnant is a real record and not random bytes
+}
+
+// errNoFileData is returned for stores which keep no file data
+var errNoFileData = errors.New("store is not read with ReadOptions.Fidelity")
+
+// unusedRegion is unused space of the file
+type unusedRegion struct {
+	start, end int64
+	slack      bool
+}
+
+// RecoverDeleted scans free blocks and unused rest of B-tree nodes for remnants of old records:
+// UTF-16 names with lengths followed by structure IDs, types and data of the types.
+// The file data is kept only by reading with ReadOptions.Fidelity. Remnants are in the order of the file.
+func (s *Store) RecoverDeleted() ([]RecoveredRecord, error) {
+	if s.layout == nil {
+		return nil, errNoFileData
+	}
+	data := s.layout.fileData
+	regions := s.unusedRegions()
+	live := make(map[recordKey][]Record)
+	for _, r := range s.Records {
+		key := recordKey{r.FileName, r.Code()}
+		live[key] = append(live[key], r)
+	}
+	var recovered []RecoveredRecord
+	maxBlobLen := s.opts.withDefaults().MaxBlobLen
+	decoder := &Store{}
+	for _, region := range regions {
+		b := data[region.start:region.end]
+		for i := 0; i < len(b); i++ {
+			var r Record
+			size, ok := decoder.decodeRecord(b[i:], &r, maxBlobLen)
+			if !ok || r.FileName == "" {
+				continue
+			}
+			rr := RecoveredRecord{Record: r, Offset: region.start + int64(i), InSlack: region.slack, Confidence: confidence(r)}
+			for _, l := range live[recordKey{r.FileName, r.Code()}] {
+				rr.Live = rr.Live || l.Equal(r)
+			}
+			recovered = append(recovered, rr)
+			i += size - 1
+		}
+	}
+	return recovered, nil
+}
+
+// unusedRegions returns free blocks and unused rest of B-tree nodes of the file data kept in fidelity mode
+func (s *Store) unusedRegions() []unusedRegion {
+	data := s.layout.fileData
+	var regions []unusedRegion
+	for i, list := range s.alloc.freeLists {
+		for _, offset := range list {
+			start := min(int64(offset)+4, int64(len(data)))
+			regions = append(regions, unusedRegion{start: start, end: min(start+int64(1)<<i, int64(len(data)))})
+		}
+	}
+	return append(regions, s.nodeSlack(data, s.layout.dataRoot, make(map[uint32]bool), 0)...)
+}
+
+// confidence returns how likely the decoded remnant is a real record:
+// it is decoded, its name is printable, its structure ID is known and its value can be decoded
+func confidence(r Record) float64 {
+	c := 0.4
+	if strings.IndexFunc(r.FileName, func(r rune) bool { return !unicode.IsPrint(r) }) < 0 {
+		c += 0.2
+	}
+	if IsKnownCode(r.Code()) {
+		c += 0.2
+	}
+	if _, err := r.Value(); err == nil && r.Validate() == nil {
+		c += 0.2
+	}
+	return c
+}
+
+// nodeSlack returns unused rest of the B-tree node and of its children
+func (s *Store) nodeSlack(data []byte, node uint32, visited map[uint32]bool, depth int) []unusedRegion {
+	if visited[node] || depth > s.opts.withDefaults().MaxDepth || int(node) >= len(s.alloc.offsets) {
+		return nil
+	}
+	visited[node] = true
+	offset := s.alloc.offsets[node]
+	start, end, err := blockRange(int64(len(data)), buddy.Offset(offset), buddy.Size(offset))
+	if err != nil || end-start < 8 {
+		return nil
+	}
+	b := data[start:end]
+	rightmost, count := binary.BigEndian.Uint32(b), binary.BigEndian.Uint32(b[4:])
+	var regions []unusedRegion
+	pos := 8
+	maxBlobLen := s.opts.withDefaults().MaxBlobLen
+	for range count {
+		if rightmost != 0 {
+			if pos+4 > len(b) {
+				return regions
+			}
+			regions = append(regions, s.nodeSlack(data, binary.BigEndian.Uint32(b[pos:]), visited, depth+1)...)
+			pos += 4
+		}
+		var r Record
+		size, ok := (&Store{}).decodeRecord(b[pos:], &r, maxBlobLen)
+		if !ok {
+			return regions
+		}
+		pos += size
+	}
+	if rightmost != 0 {
+		regions = append(regions, s.nodeSlack(data, rightmost, visited, depth+1)...)
+	}
+	return append(regions, unusedRegion{start: start + int64(pos), end: end, slack: true})
+}
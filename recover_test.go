@@ -0,0 +1,75 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/strongo/dsstore/buddy"
+)
+
+func TestRecoverDeleted(t *testing.T) {
+	kept := TextRecord("kept.txt", "cmmt", "x")
+	s := &Store{Records: []Record{kept}}
+	if _, err := s.RecoverDeleted(); !errors.Is(err, errNoFileData) {
+		t.Errorf("want errNoFileData, got %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if err := s.ReadWithOptions(bytes.NewReader(data), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatal(err)
+	}
+	// remnant of a deleted record after the last record of the leaf node
+	// and an old copy of the kept record in a free block
+	leaf := int(buddy.Offset(s.alloc.offsets[s.layout.dataRoot])) + 4
+	deleted := new(bytes.Buffer)
+	if err := s.writeRecord(deleted, TimeRecord("deleted.pdf", "moDD", epoch1904)); err != nil {
+		t.Fatal(err)
+	}
+	slack := leaf + 8 + recordSize(kept) + 3
+	copy(data[slack:], deleted.Bytes())
+	old := new(bytes.Buffer)
+	if err := s.writeRecord(old, kept); err != nil {
+		t.Fatal(err)
+	}
+	free := -1
+	for i := len(s.alloc.freeLists) - 1; i >= 0 && free < 0; i-- {
+		for _, offset := range s.alloc.freeLists[i] {
+			if int(offset)+4+old.Len() <= len(data) && 1<<i >= old.Len() {
+				free = int(offset) + 4
+				break
+			}
+		}
+	}
+	if free < 0 {
+		t.Fatal("no free block")
+	}
+	copy(data[free:], old.Bytes())
+	if err := s.ReadWithOptions(bytes.NewReader(data), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := s.RecoverDeleted()
+	if err != nil {
+		t.Fatalf("RecoverDeleted failed: %v", err)
+	}
+	if len(recovered) != 2 {
+		t.Fatalf("want 2 recovered records, got %+v", recovered)
+	}
+	for _, r := range recovered {
+		switch {
+		case r.FileName == "deleted.pdf":
+			if !r.InSlack || r.Live || r.Offset != int64(slack) || r.Confidence != 1 {
+				t.Errorf("unexpected recovered record %+v", r)
+			}
+		case r.FileName == "kept.txt":
+			if r.InSlack || !r.Live || r.Offset != int64(free) {
+				t.Errorf("unexpected recovered record %+v", r)
+			}
+		default:
+			t.Errorf("unexpected recovered record %+v", r)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package dsstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrRedactionMismatch is returned by RedactionManifest.Verify for stores which differ not only by the redactions
+var ErrRedactionMismatch = errors.New("stores don't match the redaction manifest")
+
+// RedactionManifest is a machine-checkable proof of scrubbing: every removed or changed record is listed
+// with hashes of its bytes, so an auditor with both stores verifies that nothing else changed.
+// Hashes aren't salted, so names and values of redacted records which can be guessed, like common
+// file names, are recovered by hashing candidates. Share the manifest only with the auditors of the original.
+type RedactionManifest struct {
+	Original   string      `json:"original"` // Fingerprint of the store before scrubbing
+	Result     string      `json:"result"`   // Fingerprint of the scrubbed store
+	Redactions []Redaction `json:"redactions"`
+}
+
+// Redaction is a record removed or changed by Scrub
+type Redaction struct {
+	Code     string   `json:"code"`
+	Actions  []string `json:"actions"`          // descriptions of ScrubAction, like "dropped"
+	Keys     []string `json:"keys,omitempty"`   // removed keys of property list
+	Original string   `json:"original"`         // hex SHA-256 of the original record bytes as written in .DS_Store
+	Result   string   `json:"result,omitempty"` // hex SHA-256 of the changed record bytes, empty for dropped record
+}
+
+// ScrubWithManifest is Scrub returning the manifest of the redactions too
+func ScrubWithManifest(s *Store, policy ScrubPolicy) ([]Scrubbed, *RedactionManifest) {
+	manifest := &RedactionManifest{Original: s.Fingerprint(), Redactions: []Redaction{}}
+	scrubbed := scrub(s, policy, manifest)
+	manifest.Result = s.Fingerprint()
+	return scrubbed, manifest
+}
+
+// add adds the record changed by the actions, nil result is a dropped record. Nil manifest ignores changes.
+func (m *RedactionManifest) add(original Record, result *Record, changes []Scrubbed) {
+	if m == nil {
+		return
+	}
+	redaction := Redaction{Code: original.Code(), Original: redactionHash(original)}
+	for _, change := range changes {
+		redaction.Actions = append(redaction.Actions, change.Action.String())
+		redaction.Keys = append(redaction.Keys, change.Keys...)
+	}
+	if result != nil {
+		redaction.Result = redactionHash(*result)
+	}
+	m.Redactions = append(m.Redactions, redaction)
+}
+
+// redactionHash returns hex SHA-256 of the record bytes as written in .DS_Store
+func redactionHash(r Record) string {
+	var b bytes.Buffer
+	_ = (&Store{}).writeRecord(&b, r)
+	sum := sha256.Sum256(b.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks that the scrubbed store is the original store changed only by the redactions:
+// fingerprints of the stores match, every redacted record is in the original store and records
+// of the scrubbed store are the unchanged records and the results of the redactions.
+// Found problems are joined into one error wrapping ErrRedactionMismatch.
+func (m *RedactionManifest) Verify(original, scrubbed *Store) error {
+	var errs []error
+	if original.Fingerprint() != m.Original {
+		errs = append(errs, errors.New("fingerprint of the original store differs"))
+	}
+	if scrubbed.Fingerprint() != m.Result {
+		errs = append(errs, errors.New("fingerprint of the scrubbed store differs"))
+	}
+	expected := make(map[string]int)
+	for _, r := range original.Records {
+		expected[redactionHash(r)]++
+	}
+	for i, redaction := range m.Redactions {
+		if expected[redaction.Original] == 0 {
+			errs = append(errs, fmt.Errorf("redaction %d of %q: record is not in the original store", i, redaction.Code))
+			continue
+		}
+		expected[redaction.Original]--
+		if redaction.Result != "" {
+			expected[redaction.Result]++
+		}
+	}
+	for _, r := range scrubbed.Records {
+		expected[redactionHash(r)]--
+	}
+	unexplained := 0
+	for _, n := range expected {
+		unexplained += max(n, -n)
+	}
+	if unexplained != 0 {
+		errs = append(errs, fmt.Errorf("%d records differ without redactions", unexplained))
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%w: %w", ErrRedactionMismatch, errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedactionManifest(t *testing.T) {
+	var original Store
+	if err := original.ReadFile(filepath.Join("testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := &Store{Records: append([]Record{}, original.Records...)}
+	policy := DefaultScrubPolicy
+	policy.Rename = func(name string) string { return "x" + name }
+	scrubbed, manifest := ScrubWithManifest(s, policy)
+	if len(scrubbed) == 0 || manifest.Original != original.Fingerprint() || manifest.Result != s.Fingerprint() {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	actions := make(map[string]int)
+	for _, r := range manifest.Redactions {
+		actions[r.Code]++
+		if r.Code == "pBBk" && (r.Result != "" || r.Actions[0] != "dropped") {
+			t.Errorf("unexpected redaction of bookmark %+v", r)
+		}
+		if r.Code == "icvp" && (len(r.Keys) != 1 || r.Keys[0] != "backgroundImageAlias" || r.Result == "") {
+			t.Errorf("unexpected redaction of icon view properties %+v", r)
+		}
+	}
+	// 2 Iloc records are renamed
+	if len(manifest.Redactions) != 4 || actions["Iloc"] != 2 {
+		t.Errorf("unexpected redactions %+v", manifest.Redactions)
+	}
+
+	// the manifest is verified after JSON round trip
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RedactionManifest
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Verify(&original, s); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+
+	// unclaimed change
+	s.Records[0].Data = append([]byte{}, s.Records[0].Data...)
+	s.Records[0].Data[0] ^= 1
+	if err = decoded.Verify(&original, s); !errors.Is(err, ErrRedactionMismatch) {
+		t.Errorf("expected mismatch of unclaimed change, got %v", err)
+	}
+	s.Records[0].Data[0] ^= 1
+	decoded.Redactions[0].Original = redactionHash(TextRecord("a", "cmmt", "other"))
+	if err = decoded.Verify(&original, s); !errors.Is(err, ErrRedactionMismatch) {
+		t.Errorf("expected mismatch of unknown redacted record, got %v", err)
+	}
+}
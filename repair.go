@@ -0,0 +1,70 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrDuplicateRecord is the error of records removed by Store.Normalize because a later record has the same key
+var ErrDuplicateRecord = errors.New("duplicate record")
+
+// Normalize prepares the store for writing: records which don't pass Record.Validate are removed,
+// of records with the same file name and structure ID only the last one is kept,
+// and records are sorted like B-tree keys. Removed records are returned with their original indexes.
+func (s *Store) Normalize() []*RecordError {
+	var removed []*RecordError
+	last := make(map[[2]string]int, len(s.Records))
+	for i, r := range s.Records {
+		if err := r.Validate(); err != nil {
+			removed = append(removed, &RecordError{Index: i, FileName: r.FileName, Code: r.Code(), Err: err})
+			continue
+		}
+		key := [2]string{nameKey(r.FileName), r.Code()}
+		if j, ok := last[key]; ok {
+			removed = append(removed, &RecordError{Index: j, FileName: s.Records[j].FileName, Code: key[1], Err: ErrDuplicateRecord})
+		}
+		last[key] = i
+	}
+	records := make([]Record, 0, len(last))
+	for i, r := range s.Records {
+		if last[[2]string{nameKey(r.FileName), r.Code()}] == i && r.Validate() == nil {
+			records = append(records, r)
+		}
+	}
+	s.Records = sortRecords(records)
+	s.mods++
+	return removed
+}
+
+// RepairReport describes what Repair salvaged from a damaged store and what was lost
+type RepairReport struct {
+	Salvaged int            // count of records of the repaired store
+	Lost     []error        // problems of reading, like *TruncatedError and nodes which can't be decoded
+	Dropped  []*RecordError // records removed by Store.Normalize
+	Warnings []Warning      // recoverable anomalies found while reading
+}
+
+// Repair reads damaged .DS_Store data in best-effort mode and normalizes read records, so the store
+// can be written again. The error is returned only when no records can be read, like for a broken header.
+func Repair(data []byte, opts ReadOptions) (*Store, RepairReport, error) {
+	var report RepairReport
+	onWarning := opts.OnWarning
+	opts.OnWarning = func(w Warning) {
+		report.Warnings = append(report.Warnings, w)
+		if onWarning != nil {
+			onWarning(w)
+		}
+	}
+	opts.BestEffort = true
+	s := &Store{}
+	if err := s.ReadWithOptions(bytes.NewReader(data), opts); err != nil {
+		if len(s.Records) == 0 {
+			return nil, report, err
+		}
+		report.Lost = unwrapJoined(err)
+	}
+	s.Materialize()
+	report.Dropped = s.Normalize()
+	report.Salvaged = len(s.Records)
+	return s, report, nil
+}
@@ -0,0 +1,52 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	s := &Store{Records: []Record{
+		TextRecord("b", "cmmt", "old"),
+		{FileName: "a", Type: "blob"},
+		TextRecord("B", "cmmt", "new"),
+		TextRecord("a", "cmmt", "a"),
+	}}
+	removed := s.Normalize()
+	if len(removed) != 2 || removed[0].Index != 1 || removed[1].Index != 0 || !errors.Is(removed[1], ErrDuplicateRecord) {
+		t.Errorf("unexpected removed records %v", removed)
+	}
+	if len(s.Records) != 2 || s.Records[0].FileName != "a" || s.Records[1].FileName != "B" {
+		t.Errorf("unexpected records %+v", s.Records)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	s := &Store{}
+	for i := range 300 {
+		s.Records = append(s.Records, TextRecord(fmt.Sprintf("file %03d", i), "cmmt", strings.Repeat("x", 50)))
+	}
+	buf := new(bytes.Buffer)
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// corrupt a leaf node in the middle of the file
+	data := buf.Bytes()
+	copy(data[len(data)/2:], bytes.Repeat([]byte{0xff}, 64))
+	repaired, report, err := Repair(data, ReadOptions{})
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if report.Salvaged != len(repaired.Records) || report.Salvaged == 0 || report.Salvaged == 300 || len(report.Lost) == 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if err = repaired.Write(new(bytes.Buffer)); err != nil {
+		t.Errorf("repaired store can't be written: %v", err)
+	}
+	if _, _, err = Repair([]byte("broken"), ReadOptions{}); err == nil {
+		t.Error("expected error for broken header")
+	}
+}
@@ -0,0 +1,148 @@
+package dsstore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Kinds of anomalies of Report
+const (
+	AnomalyError   = "error"   // file can't be read or is read partially
+	AnomalyWarning = "warning" // recoverable anomaly reported while reading, see Warning
+	AnomalyInvalid = "invalid" // store doesn't pass Store.Validate
+)
+
+// Anomaly is a problem of one store of Report
+type Anomaly struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"` // AnomalyError, AnomalyWarning or AnomalyInvalid
+	Message string `json:"message"`
+}
+
+// Report aggregates many stores, like stores found by Walk or read by ProcessAll, into one summary.
+// Report is safe for concurrent use, so Add can be called by ProcessAll callbacks.
+type Report struct {
+	mu        sync.Mutex
+	Stores    int            `json:"stores"`            // count of read stores
+	Failed    int            `json:"failed"`            // count of files which can't be read
+	Records   int            `json:"records"`           // total count of records
+	Files     int            `json:"files"`             // count of file names referenced by records of every store
+	Codes     map[string]int `json:"codes"`             // count of records of every structure ID
+	Earliest  time.Time      `json:"earliest,omitzero"` // the earliest timestamp of records
+	Latest    time.Time      `json:"latest,omitzero"`   // the latest timestamp of records
+	Anomalies []Anomaly      `json:"anomalies,omitempty"`
+}
+
+// NewReport returns empty report
+func NewReport() *Report {
+	return &Report{Codes: make(map[string]int)}
+}
+
+// Add adds the store read from the path to the report, nil store is a file which can't be read.
+// Add has the signature of WalkFunc, so the report can be passed to Walk directly. It always returns nil.
+func (r *Report) Add(path string, s *Store, err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Codes == nil {
+		r.Codes = make(map[string]int)
+	}
+	if err != nil {
+		r.Anomalies = append(r.Anomalies, Anomaly{Path: path, Kind: AnomalyError, Message: err.Error()})
+	}
+	if s == nil {
+		r.Failed++
+		return nil
+	}
+	r.Stores++
+	r.Records += len(s.Records)
+	files := make(map[string]bool)
+	for _, record := range s.Records {
+		r.Codes[record.Code()]++
+		if record.FileName != "." && !files[nameKey(record.FileName)] {
+			files[nameKey(record.FileName)] = true
+			r.Files++
+		}
+		if t, ok := record.Time(); ok {
+			if r.Earliest.IsZero() || t.Before(r.Earliest) {
+				r.Earliest = t
+			}
+			if t.After(r.Latest) {
+				r.Latest = t
+			}
+		}
+	}
+	if err = s.Validate(); err != nil {
+		for _, e := range unwrapJoined(err) {
+			r.Anomalies = append(r.Anomalies, Anomaly{Path: path, Kind: AnomalyInvalid, Message: e.Error()})
+		}
+	}
+	return nil
+}
+
+// AddWarning adds warning of reading the file to the report
+func (r *Report) AddWarning(path string, w Warning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Anomalies = append(r.Anomalies, Anomaly{Path: path, Kind: AnomalyWarning, Message: w.String()})
+}
+
+// AddResults adds failures of ProcessAll results to the report,
+// successfully processed stores are added by the ProcessAll callback
+func (r *Report) AddResults(results []Result) {
+	for _, result := range results {
+		if result.Err != nil {
+			_ = r.Add(result.Path, nil, result.Err)
+		}
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WalkReport finds and reads .DS_Store files like WalkContext and returns report of them,
+// warnings of reading are anomalies of the report
+func WalkReport(ctx context.Context, root string, opts ReadOptions) (*Report, error) {
+	report := NewReport()
+	var warnings []Warning
+	onWarning := opts.OnWarning
+	opts.OnWarning = func(w Warning) {
+		warnings = append(warnings, w)
+		if onWarning != nil {
+			onWarning(w)
+		}
+	}
+	err := WalkContext(ctx, root, opts, func(path string, s *Store, err error) error {
+		for _, w := range warnings {
+			report.AddWarning(path, w)
+		}
+		warnings = warnings[:0]
+		return report.Add(path, s, err)
+	})
+	return report, err
+}
+
+// ProcessAllReport reads files like ProcessAllContext and returns report of them
+func ProcessAllReport(ctx context.Context, paths []string, workers int, opts ReadOptions) *Report {
+	report := NewReport()
+	report.AddResults(ProcessAllContext(ctx, paths, workers, opts, func(path string, s *Store) error {
+		return report.Add(path, s, nil)
+	}))
+	return report
+}
+
+// unwrapJoined returns errors joined by errors.Join or the error itself
+func unwrapJoined(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWalkReport(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, walkTestFiles(t))
+	report, err := WalkReport(context.Background(), dir, ReadOptions{BestEffort: true})
+	if err != nil {
+		t.Fatalf("WalkReport failed: %v", err)
+	}
+	if report.Stores != 3 || report.Failed != 1 || report.Records != 12 || report.Files != 4 || report.Codes["Iloc"] != 4 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	var errs int
+	for _, a := range report.Anomalies {
+		if a.Kind == AnomalyError {
+			errs++
+		}
+	}
+	if errs != 2 {
+		t.Errorf("expected 2 errors, got %+v", report.Anomalies)
+	}
+
+	var buf bytes.Buffer
+	if err = report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Report
+	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Records != 12 || decoded.Codes["Iloc"] != 4 {
+		t.Errorf("unexpected decoded report %s: %v", buf.String(), err)
+	}
+}
+
+func TestProcessAllReport(t *testing.T) {
+	testdata := filepath.Join(".", "testdata", "00.DS_Store")
+	report := ProcessAllReport(context.Background(), []string{testdata, "missing", testdata}, 2, ReadOptions{})
+	if report.Stores != 2 || report.Failed != 1 || report.Records != 12 || len(report.Anomalies) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	earliest := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	invalid := Record{FileName: "", Type: "long", Data: make([]byte, 4)}
+	s := &Store{Records: []Record{dutcRecord("a", latest), dutcRecord("b", earliest), invalid}}
+	report = NewReport()
+	_ = report.Add("x/.DS_Store", s, nil)
+	if !report.Earliest.Truncate(time.Second).Equal(earliest) || !report.Latest.Truncate(time.Second).Equal(latest) {
+		t.Errorf("unexpected time range %v - %v", report.Earliest, report.Latest)
+	}
+	if len(report.Anomalies) == 0 || report.Anomalies[0].Kind != AnomalyInvalid {
+		t.Errorf("expected invalid store anomaly, got %+v", report.Anomalies)
+	}
+}
@@ -0,0 +1,147 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is ordered rules which allow or deny records, like organization rules
+// "no cmmt records, no pBBk blobs, Iloc allowed only in DMG build output":
+//
+//	rules:
+//	  - {action: deny, codes: [cmmt], reason: Finder comments are private}
+//	  - {action: deny, codes: [pBBk], types: [blob]}
+//	  - {action: deny, codes: [Iloc]}
+//	  - {action: allow, codes: [Iloc], stores: [dmg/.DS_Store]}
+//
+// The last matching rule decides, records matched by no rule are allowed.
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule allows or denies records matched by all its patterns, empty patterns match everything.
+// Patterns are globs of path.Match.
+type Rule struct {
+	Action string   `json:"action" yaml:"action"`                     // RuleAllow or RuleDeny
+	Codes  []string `json:"codes,omitempty" yaml:"codes,omitempty"`   // patterns of structure IDs, like "ic*"
+	Types  []string `json:"types,omitempty" yaml:"types,omitempty"`   // data types, like "blob"
+	Files  []string `json:"files,omitempty" yaml:"files,omitempty"`   // patterns of file names, like "*.app"
+	Stores []string `json:"stores,omitempty" yaml:"stores,omitempty"` // patterns of store paths matched like CleanOptions.Include
+	Reason string   `json:"reason,omitempty" yaml:"reason,omitempty"` // reported for denied records
+}
+
+// Actions of rules
+const (
+	RuleAllow = "allow"
+	RuleDeny  = "deny"
+)
+
+// PolicyViolation is the error of the record denied by the rule, it is wrapped into *RecordError
+type PolicyViolation struct {
+	Rule Rule
+}
+
+// Error returns the reason of the rule or its patterns
+func (e *PolicyViolation) Error() string {
+	if e.Rule.Reason != "" {
+		return "denied by policy: " + e.Rule.Reason
+	}
+	return fmt.Sprintf("denied by policy rule codes %q files %q", e.Rule.Codes, e.Rule.Files)
+}
+
+// ParsePolicy parses YAML or JSON policy and checks its rules
+func ParsePolicy(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LoadPolicy reads YAML or JSON policy from the file
+func LoadPolicy(name string) (*Policy, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// Check checks actions and patterns of the rules. All found problems are joined into one error.
+func (p *Policy) Check() error {
+	var errs []error
+	for i, rule := range p.Rules {
+		if rule.Action != RuleAllow && rule.Action != RuleDeny {
+			errs = append(errs, fmt.Errorf("rule %d: unknown action %q", i, rule.Action))
+		}
+		for _, pattern := range slices.Concat(rule.Codes, rule.Files, rule.Stores) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("rule %d: invalid pattern %q: %w", i, pattern, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Decide returns the last rule matching the record of the store at the slash-separated path
+// and whether the record is allowed. Nil rule means that no rule matches.
+// Rules with store patterns don't match empty path.
+func (p *Policy) Decide(storePath string, r Record) (*Rule, bool) {
+	for i := len(p.Rules) - 1; i >= 0; i-- {
+		if rule := &p.Rules[i]; rule.matches(storePath, r) {
+			return rule, rule.Action != RuleDeny
+		}
+	}
+	return nil, true
+}
+
+// Allowed reports whether the record of the store at the slash-separated path is allowed
+func (p *Policy) Allowed(storePath string, r Record) bool {
+	_, allowed := p.Decide(storePath, r)
+	return allowed
+}
+
+func (rule *Rule) matches(storePath string, r Record) bool {
+	if len(rule.Stores) != 0 && (storePath == "" || !matchAny(rule.Stores, storePath)) {
+		return false
+	}
+	return matchPattern(rule.Codes, r.Code()) && matchPattern(rule.Files, r.FileName) &&
+		(len(rule.Types) == 0 || slices.Contains(rule.Types, r.Type))
+}
+
+// matchPattern reports whether any of patterns matches the name, empty patterns match everything
+func matchPattern(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePolicy checks records of the store at the slash-separated path against the policy.
+// Denied records are reported as *RecordError wrapping *PolicyViolation, they are joined into one error.
+func (s *Store) ValidatePolicy(p *Policy, storePath string) error {
+	var errs []error
+	for i, r := range s.Records {
+		if rule, allowed := p.Decide(storePath, r); !allowed {
+			errs = append(errs, &RecordError{Index: i, FileName: r.FileName, Code: r.Code(), Err: &PolicyViolation{Rule: *rule}})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package dsstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+  - {action: deny, codes: [cmmt], reason: Finder comments are private}
+  - {action: deny, codes: [pBBk], types: [blob]}
+  - {action: deny, codes: [Iloc]}
+  - {action: allow, codes: [Iloc], stores: [dmg/.DS_Store]}
+`
+
+func TestPolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy failed: %v", err)
+	}
+	iloc := Record{FileName: "App.app", Type: "blob", DataLen: 16, Data: make([]byte, 16)}
+	iloc.SetCode("Iloc")
+	for _, test := range []struct {
+		path    string
+		r       Record
+		allowed bool
+	}{
+		{"", TextRecord("a", "cmmt", "secret"), false},
+		{"", TextRecord("a", "pBBk", "ustr is not matched"), true},
+		{"", TextRecord("a", "vSrn", "x"), true},
+		{"", iloc, false},
+		{"build/dmg/.DS_Store", iloc, true},
+		{"dmg/.DS_Store", iloc, true},
+		{"src/.DS_Store", iloc, false},
+	} {
+		if allowed := p.Allowed(test.path, test.r); allowed != test.allowed {
+			t.Errorf("Allowed(%q, %s) = %v", test.path, test.r.Code(), allowed)
+		}
+	}
+
+	s := &Store{Records: []Record{TextRecord(".", "vSrn", "x"), TextRecord("a", "cmmt", "secret"), iloc}}
+	err = s.ValidatePolicy(p, "src/.DS_Store")
+	var recordErr *RecordError
+	var violation *PolicyViolation
+	if !errors.As(err, &recordErr) || recordErr.Index != 1 || !errors.As(err, &violation) ||
+		violation.Error() != "denied by policy: Finder comments are private" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = s.ValidatePolicy(p, "dmg/.DS_Store"); err == nil {
+		t.Errorf("expected the comment to be denied, got %v", err)
+	}
+
+	scrubbed := Scrub(s, ScrubPolicy{Rules: p, Path: "src/.DS_Store"})
+	if len(scrubbed) != 2 || len(s.Records) != 1 || scrubbed[1].Code != "Iloc" || scrubbed[1].Action != ScrubDropped {
+		t.Errorf("unexpected scrubbed %+v", scrubbed)
+	}
+
+	for _, invalid := range []string{"rules: [{action: block}]", "rules: [{action: deny, files: ['[']}]", "rules: 1"} {
+		if _, err = ParsePolicy([]byte(invalid)); err == nil {
+			t.Errorf("invalid policy %q is accepted", invalid)
+		}
+	}
+}
+
+func TestCleanPolicy(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"dmg", "src"} {
+		s := &Store{Records: []Record{TextRecord("a", "cmmt", "secret"), TextRecord("a", "vSrn", "x")}}
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.WriteFile(filepath.Join(dir, name, StoreFileName), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &Policy{Rules: []Rule{{Action: RuleDeny, Codes: []string{"cmmt"}, Stores: []string{"src/*"}}}}
+	summary, err := Clean(dir, CleanOptions{Policy: p})
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if summary.Files != 1 || summary.Paths[0] != filepath.Join(dir, "src", StoreFileName) {
+		t.Errorf("expected src/.DS_Store to be scrubbed, got %+v", summary)
+	}
+	for name, records := range map[string]int{"dmg": 2, "src": 1} {
+		var s Store
+		if err = s.ReadFile(filepath.Join(dir, name, StoreFileName)); err != nil || len(s.Records) != records {
+			t.Errorf("expected %d records in %s, got %d (%v)", records, name, len(s.Records), err)
+		}
+	}
+}
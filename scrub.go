@@ -0,0 +1,152 @@
+package dsstore
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+)
+
+// ScrubPolicy selects records and data removed by Scrub
+type ScrubPolicy struct {
+	Drop  []string // structure IDs of removed records
+	Blank []string // structure IDs of records which data is replaced by zero bytes of the same length
+	// PlistKeys are keys removed from property lists of blob records at any depth, like "backgroundImageAlias"
+	PlistKeys []string
+	// Rename returns replacement of the file name, nil keeps names. Records of the folder itself are not renamed.
+	Rename func(name string) string
+	// Rules drop records denied by the policy, Path is the slash-separated path of the store matched by their store patterns
+	Rules *Policy
+	Path  string
+}
+
+// DefaultScrubPolicy drops comments, modification dates, bookmarks and aliases
+// and removes path-bearing keys of property lists
+var DefaultScrubPolicy = ScrubPolicy{
+	Drop:      []string{"cmmt", "moDD", "modD", "pBBk", "pict"},
+	PlistKeys: []string{"backgroundImageAlias", "backgroundImageBookmark"},
+}
+
+// ScrubAction is what Scrub did to a record
+type ScrubAction int
+
+// Actions of Scrub
+const (
+	ScrubDropped   ScrubAction = iota // record is removed
+	ScrubBlanked                      // data is replaced by zero bytes
+	ScrubRewritten                    // keys are removed from property list
+	ScrubRenamed                      // file name is replaced
+)
+
+// String returns description of the action
+func (a ScrubAction) String() string {
+	switch a {
+	case ScrubDropped:
+		return "dropped"
+	case ScrubBlanked:
+		return "blanked"
+	case ScrubRewritten:
+		return "rewritten"
+	case ScrubRenamed:
+		return "renamed"
+	default:
+		return "unknown"
+	}
+}
+
+// Scrubbed is a record changed by Scrub
+type Scrubbed struct {
+	Name   string // original file name
+	Code   string
+	Action ScrubAction
+	Keys   []string // removed keys of property list for ScrubRewritten
+}
+
+// Scrub removes sensitive records and data of the store by the policy and returns what was removed,
+// records are kept in their order
+func Scrub(s *Store, policy ScrubPolicy) []Scrubbed {
+	return scrub(s, policy, nil)
+}
+
+// scrub is Scrub adding changed records to the manifest when it isn't nil
+func scrub(s *Store, policy ScrubPolicy, manifest *RedactionManifest) []Scrubbed {
+	drop, blank, keys := stringSet(policy.Drop), stringSet(policy.Blank), stringSet(policy.PlistKeys)
+	var scrubbed []Scrubbed
+	records := make([]Record, 0, len(s.Records))
+	for _, r := range s.Records {
+		original, changes := r, len(scrubbed)
+		code := r.Code()
+		if drop[code] || policy.Rules != nil && !policy.Rules.Allowed(policy.Path, r) {
+			scrubbed = append(scrubbed, Scrubbed{Name: r.FileName, Code: code, Action: ScrubDropped})
+			manifest.add(original, nil, scrubbed[changes:])
+			continue
+		}
+		if blank[code] {
+			r.Data = make([]byte, len(r.Data))
+			scrubbed = append(scrubbed, Scrubbed{Name: r.FileName, Code: code, Action: ScrubBlanked})
+		} else if len(keys) != 0 && r.Type == "blob" && bytes.HasPrefix(r.Data, bplistHeader) {
+			if data, removed := removePlistKeys(r.Data, keys); len(removed) != 0 {
+				r.Data, r.DataLen = data, uint32(len(data))
+				scrubbed = append(scrubbed, Scrubbed{Name: r.FileName, Code: code, Action: ScrubRewritten, Keys: removed})
+			}
+		}
+		if policy.Rename != nil && r.FileName != "." {
+			if name := policy.Rename(r.FileName); name != r.FileName {
+				scrubbed = append(scrubbed, Scrubbed{Name: r.FileName, Code: code, Action: ScrubRenamed})
+				r.FileName = name
+			}
+		}
+		if len(scrubbed) != changes {
+			manifest.add(original, &r, scrubbed[changes:])
+		}
+		records = append(records, r)
+	}
+	s.Records = records
+	s.mods++
+	return scrubbed
+}
+
+// stringSet returns set of the strings
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// removePlistKeys removes the keys from dictionaries of the property list at any depth
+// and returns encoded property list with sorted removed keys, nil keys when nothing is removed
+func removePlistKeys(data []byte, keys map[string]bool) ([]byte, []string) {
+	v, err := decodePlist(data)
+	if err != nil {
+		return data, nil
+	}
+	removed := make(map[string]bool)
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			for k, item := range v {
+				if keys[k] {
+					delete(v, k)
+					removed[k] = true
+					continue
+				}
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	if len(removed) == 0 {
+		return data, nil
+	}
+	encoded, err := encodePlist(v)
+	if err != nil {
+		return data, nil
+	}
+	return encoded, slices.Sorted(maps.Keys(removed))
+}
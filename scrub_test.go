@@ -0,0 +1,53 @@
+package dsstore
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScrub(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	s.Records = append(s.Records, TextRecord("Applications", "cmmt", "secret"))
+	policy := DefaultScrubPolicy
+	policy.Blank = []string{"vSrn"}
+	policy.Rename = strings.ToUpper
+	scrubbed := Scrub(s, policy)
+	var actions []string
+	for _, sc := range scrubbed {
+		actions = append(actions, sc.Action.String()+" "+sc.Name+" "+sc.Code+" "+strings.Join(sc.Keys, ","))
+	}
+	want := []string{
+		"rewritten . icvp backgroundImageAlias",
+		"dropped . pBBk ",
+		"blanked . vSrn ",
+		"renamed Applications Iloc ",
+		"renamed Getscreen.me.app Iloc ",
+		"dropped Applications cmmt ",
+	}
+	if !slices.Equal(actions, want) {
+		t.Errorf("want %q, got %q", want, actions)
+	}
+	if len(s.Records) != 5 || s.Records[3].FileName != "APPLICATIONS" {
+		t.Fatalf("unexpected records %+v", s.Records)
+	}
+	for _, r := range s.Records {
+		if err := r.Validate(); err != nil {
+			t.Errorf("%s %s is invalid: %v", r.FileName, r.Code(), err)
+		}
+		switch r.Code() {
+		case "vSrn":
+			if !bytes.Equal(r.Data, make([]byte, 4)) {
+				t.Errorf("vSrn is not blanked: %x", r.Data)
+			}
+		case "icvp":
+			if v, err := r.Value(); err != nil || v.(map[string]any)["backgroundImageAlias"] != nil || v.(map[string]any)["iconSize"] == nil {
+				t.Errorf("unexpected icvp %v: %v", v, err)
+			}
+		}
+	}
+}
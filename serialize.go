@@ -0,0 +1,196 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// recordJSON is JSON of a record, data is authoritative and value of other types than blob is informational
+type recordJSON struct {
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+	Type  string `json:"type"`
+	Value any    `json:"value,omitempty"`
+	Data  string `json:"data,omitempty"` // data in hex
+}
+
+// storeJSON is JSON of a store, unknown extra data of blocks is in hex
+type storeJSON struct {
+	HeaderExtra string   `json:"headerExtra,omitempty"`
+	RootExtra   string   `json:"rootExtra,omitempty"`
+	DSDBExtra   string   `json:"dsdbExtra,omitempty"`
+	Records     []Record `json:"records"`
+}
+
+// MarshalJSON encodes the record as JSON object with name, code, type, data in hex
+// and decoded value of types other than blob and of structure IDs with registered codecs
+func (r Record) MarshalJSON() ([]byte, error) {
+	v := recordJSON{Name: r.FileName, Code: r.Code(), Type: r.Type, Data: hex.EncodeToString(r.Data)}
+	if _, ok := LookupCodec(v.Code); ok || r.Type != "blob" {
+		v.Value, _ = r.Value()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the record encoded by MarshalJSON. Records written by hand may have value instead
+// of data: bool, integer, RFC 3339 time of "dutc" and string of "type" and "ustr" records
+// or value of registered codec of the structure ID, which also sets the type.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var v struct {
+		recordJSON
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Code) != 4 {
+		return fmt.Errorf("code %q must have 4 characters", v.Code)
+	}
+	record := Record{FileName: v.Name, Type: v.Type}
+	record.SetCode(v.Code)
+	var err error
+	if v.Data != "" {
+		if record.Data, err = hex.DecodeString(v.Data); err != nil {
+			return fmt.Errorf("data of %q %s: %w", v.Name, v.Code, err)
+		}
+	} else if codec, ok := LookupCodec(v.Code); ok && v.Value != nil {
+		var value any
+		if err = json.Unmarshal(v.Value, &value); err != nil {
+			return fmt.Errorf("value of %q %s: %w", v.Name, v.Code, err)
+		}
+		if record.Type, record.Data, err = codec.Encode(value); err != nil {
+			return fmt.Errorf("value of %q %s: %w", v.Name, v.Code, err)
+		}
+	} else if record.Data, err = valueData(v.Type, v.Value); err != nil {
+		return fmt.Errorf("value of %q %s: %w", v.Name, v.Code, err)
+	}
+	record.setDataLen()
+	*r = record
+	return nil
+}
+
+// setDataLen sets DataLen of blob and ustr records by their data
+func (r *Record) setDataLen() {
+	switch r.Type {
+	case "blob":
+		r.DataLen = uint32(len(r.Data))
+	case "ustr":
+		r.DataLen = uint32(len(r.Data) / 2)
+	}
+}
+
+// valueData encodes JSON value of the type as record data
+func valueData(typ string, value json.RawMessage) ([]byte, error) {
+	if value == nil {
+		return nil, errors.New("neither data nor value is set")
+	}
+	switch typ {
+	case "bool":
+		var v bool
+		err := json.Unmarshal(value, &v)
+		if v {
+			return []byte{1}, err
+		}
+		return []byte{0}, err
+	case "long", "shor":
+		var v int32
+		err := json.Unmarshal(value, &v)
+		return binary.BigEndian.AppendUint32(nil, uint32(v)), err
+	case "comp":
+		var v int64
+		err := json.Unmarshal(value, &v)
+		return binary.BigEndian.AppendUint64(nil, uint64(v)), err
+	case "dutc":
+		var v time.Time
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		return TimeRecord("", "", v).Data, nil
+	case "type", "ustr":
+		var v string
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		if typ == "ustr" {
+			return TextRecord("", "", v).Data, nil
+		}
+		if len(v) != 4 {
+			return nil, fmt.Errorf("value of type must have 4 characters, got %q", v)
+		}
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("value of type %q is not supported, set data", typ)
+	}
+}
+
+// MarshalJSON encodes records of the store and unknown extra data of its blocks
+func (s *Store) MarshalJSON() ([]byte, error) {
+	return json.Marshal(storeJSON{
+		HeaderExtra: hex.EncodeToString(s.HeaderExtra),
+		RootExtra:   hex.EncodeToString(s.RootExtra),
+		DSDBExtra:   hex.EncodeToString(s.DSDBExtra),
+		Records:     s.Records,
+	})
+}
+
+// UnmarshalJSON decodes the store encoded by MarshalJSON
+func (s *Store) UnmarshalJSON(data []byte) error {
+	var v storeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	extras := make([][]byte, 3)
+	for i, extra := range []string{v.HeaderExtra, v.RootExtra, v.DSDBExtra} {
+		var err error
+		if extra != "" {
+			if extras[i], err = hex.DecodeString(extra); err != nil {
+				return fmt.Errorf("extra data: %w", err)
+			}
+		}
+	}
+	*s = Store{HeaderExtra: extras[0], RootExtra: extras[1], DSDBExtra: extras[2], Records: v.Records}
+	return nil
+}
+
+// MarshalPlist encodes records of the store as binary property list: dictionary with "records" array
+// of dictionaries with name, code, type and data
+func (s *Store) MarshalPlist() ([]byte, error) {
+	records := make([]any, len(s.Records))
+	for i, r := range s.Records {
+		records[i] = map[string]any{"name": r.FileName, "code": r.Code(), "type": r.Type, "data": r.Data}
+	}
+	return encodePlist(map[string]any{"records": records})
+}
+
+// UnmarshalPlist decodes records of the store encoded by MarshalPlist
+func (s *Store) UnmarshalPlist(data []byte) error {
+	v, err := decodePlist(data)
+	if err != nil {
+		return err
+	}
+	dict, _ := v.(map[string]any)
+	items, ok := dict["records"].([]any)
+	if !ok {
+		return errors.New("property list has no records array")
+	}
+	records := make([]Record, len(items))
+	for i, item := range items {
+		fields, _ := item.(map[string]any)
+		name, _ := fields["name"].(string)
+		code, _ := fields["code"].(string)
+		typ, _ := fields["type"].(string)
+		recordData, ok := fields["data"].([]byte)
+		if !ok || len(code) != 4 {
+			return fmt.Errorf("record %d of property list is invalid", i)
+		}
+		records[i] = Record{FileName: name, Type: typ, Data: recordData}
+		records[i].SetCode(code)
+		records[i].setDataLen()
+	}
+	*s = Store{Records: records}
+	return nil
+}
@@ -0,0 +1,87 @@
+package dsstore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStoreJSON(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	s.Records = append(s.Records, TextRecord("a", "cmmt", "comment"), TimeRecord("a", "moDD", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	var decoded Store
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if len(decoded.Records) != len(s.Records) {
+		t.Fatalf("want %d records, got %d", len(s.Records), len(decoded.Records))
+	}
+	for i, r := range decoded.Records {
+		if !r.Equal(s.Records[i]) {
+			t.Errorf("record %d: want %+v, got %+v", i, s.Records[i], r)
+		}
+	}
+}
+
+func TestRecordJSONValue(t *testing.T) {
+	for _, test := range []struct {
+		json string
+		want Record
+	}{
+		{`{"name":"a","code":"cmmt","type":"ustr","value":"comment"}`, TextRecord("a", "cmmt", "comment")},
+		{`{"name":"a","code":"moDD","type":"dutc","value":"2020-01-02T03:04:05Z"}`,
+			TimeRecord("a", "moDD", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))},
+		{`{"name":"a","code":"ICVO","type":"long","value":-7}`,
+			Record{FileName: "a", Extra: code("ICVO"), Type: "long", Data: []byte{0xff, 0xff, 0xff, 0xf9}}},
+		{`{"name":".","code":"vstl","type":"type","value":"icnv"}`,
+			Record{FileName: ".", Extra: code("vstl"), Type: "type", Data: []byte("icnv")}},
+	} {
+		var r Record
+		if err := json.Unmarshal([]byte(test.json), &r); err != nil {
+			t.Errorf("%s: %v", test.json, err)
+		} else if !r.Equal(test.want) {
+			t.Errorf("%s: want %+v, got %+v", test.json, test.want, r)
+		}
+	}
+	for _, invalid := range []string{
+		`{"name":"a","code":"Iloc","type":"blob","value":"x"}`,
+		`{"name":"a","code":"cmt","type":"ustr","value":"x"}`,
+		`{"name":"a","code":"cmmt","type":"ustr"}`,
+		`{"name":"a","code":"cmmt","type":"ustr","data":"zz"}`,
+	} {
+		var r Record
+		if err := json.Unmarshal([]byte(invalid), &r); err == nil {
+			t.Errorf("%s: want error", invalid)
+		}
+	}
+}
+
+func TestStorePlist(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.MarshalPlist()
+	if err != nil {
+		t.Fatalf("MarshalPlist failed: %v", err)
+	}
+	var decoded Store
+	if err = decoded.UnmarshalPlist(data); err != nil {
+		t.Fatalf("UnmarshalPlist failed: %v", err)
+	}
+	if len(decoded.Records) != len(s.Records) {
+		t.Fatalf("want %d records, got %d", len(s.Records), len(decoded.Records))
+	}
+	for i, r := range decoded.Records {
+		if !r.Equal(s.Records[i]) {
+			t.Errorf("record %d: want %+v, got %+v", i, s.Records[i], r)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package dsstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// viewStyles are view styles of "vstl" and "fwi0" records
+var viewStyles = map[string]string{
+	"icnv": "icon view",
+	"Nlsv": "list view",
+	"clmv": "column view",
+	"Flwv": "flow view",
+	"glyv": "gallery view",
+}
+
+// FolderSettings are window and icon view settings of a folder from records of the folder itself (file name ".")
+// and icon locations of its files
+type FolderSettings struct {
+	View      string                 // view style, like "icon view", empty when unknown
+	Window    *WindowSettings        // settings of "bwsp" or legacy "fwi0" record
+	IconView  *IconViewSettings      // settings of "icvp" record
+	Positions map[string]image.Point // icon locations of files from "Iloc" records
+}
+
+// WindowSettings are settings of Finder window of a folder
+type WindowSettings struct {
+	Bounds        image.Rectangle // window bounds as stored by Finder
+	ShowToolbar   bool
+	ShowStatusBar bool
+	ShowSidebar   bool
+	ShowPathbar   bool
+}
+
+// IconViewSettings are icon view options of a folder
+type IconViewSettings struct {
+	IconSize        float64
+	TextSize        float64
+	GridSpacing     float64
+	ArrangeBy       string // "none", "grid", "name", "kind", "dateModified" etc
+	LabelOnBottom   bool
+	ShowItemInfo    bool
+	ShowIconPreview bool
+	BackgroundType  int64      // 0 is default, 1 is color, 2 is picture
+	BackgroundColor [3]float64 // red, green and blue components from 0 to 1
+	BackgroundImage []byte     // alias record of the background picture
+}
+
+// FolderSettings returns window and icon view settings of the folder, settings without records are nil
+func (s *Store) FolderSettings() (FolderSettings, error) {
+	settings := FolderSettings{Positions: make(map[string]image.Point)}
+	for _, r := range s.Records {
+		code := r.Code()
+		if r.FileName != "." {
+			if code == "Iloc" && r.Type == "blob" && len(r.Data) >= 8 {
+				settings.Positions[r.FileName] = image.Pt(int(int32(binary.BigEndian.Uint32(r.Data))),
+					int(int32(binary.BigEndian.Uint32(r.Data[4:]))))
+			}
+			continue
+		}
+		var err error
+		switch code {
+		case "vstl":
+			settings.View = viewStyles[string(r.Data)]
+		case "bwsp":
+			settings.Window, err = windowSettings(r)
+		case "fwi0":
+			if settings.Window == nil && r.Type == "blob" && len(r.Data) >= 12 {
+				// top, left, bottom and right of the window followed by the view style
+				d := r.Data
+				settings.Window = &WindowSettings{Bounds: image.Rect(int(binary.BigEndian.Uint16(d[2:])),
+					int(binary.BigEndian.Uint16(d)), int(binary.BigEndian.Uint16(d[6:])), int(binary.BigEndian.Uint16(d[4:])))}
+				if settings.View == "" {
+					settings.View = viewStyles[string(d[8:12])]
+				}
+			}
+		case "icvp":
+			settings.IconView, err = iconViewSettings(r)
+		}
+		if err != nil {
+			return settings, fmt.Errorf("%s record: %w", code, err)
+		}
+	}
+	return settings, nil
+}
+
+// plistDict decodes property list dictionary of the blob record
+func plistDict(r Record) (map[string]any, error) {
+	if r.Type != "blob" {
+		return nil, fmt.Errorf("unexpected type %q", r.Type)
+	}
+	v, err := decodePlist(r.Data)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("property list is %T, not a dictionary", v)
+	}
+	return dict, nil
+}
+
+func windowSettings(r Record) (*WindowSettings, error) {
+	dict, err := plistDict(r)
+	if err != nil {
+		return nil, err
+	}
+	w := &WindowSettings{}
+	w.ShowToolbar, _ = dict["ShowToolbar"].(bool)
+	w.ShowStatusBar, _ = dict["ShowStatusBar"].(bool)
+	w.ShowSidebar, _ = dict["ShowSidebar"].(bool)
+	w.ShowPathbar, _ = dict["ShowPathbar"].(bool)
+	if bounds, ok := dict["WindowBounds"].(string); ok {
+		// "{{x, y}, {width, height}}"
+		var x, y, width, height int
+		if _, err = fmt.Sscanf(strings.ReplaceAll(bounds, " ", ""), "{{%d,%d},{%d,%d}}", &x, &y, &width, &height); err != nil {
+			return nil, fmt.Errorf("invalid WindowBounds %q", bounds)
+		}
+		w.Bounds = image.Rect(x, y, x+width, y+height)
+	}
+	return w, nil
+}
+
+func iconViewSettings(r Record) (*IconViewSettings, error) {
+	dict, err := plistDict(r)
+	if err != nil {
+		return nil, err
+	}
+	v := &IconViewSettings{
+		IconSize:    plistNumber(dict["iconSize"]),
+		TextSize:    plistNumber(dict["textSize"]),
+		GridSpacing: plistNumber(dict["gridSpacing"]),
+		BackgroundColor: [3]float64{plistNumber(dict["backgroundColorRed"]),
+			plistNumber(dict["backgroundColorGreen"]), plistNumber(dict["backgroundColorBlue"])},
+	}
+	v.ArrangeBy, _ = dict["arrangeBy"].(string)
+	v.LabelOnBottom, _ = dict["labelOnBottom"].(bool)
+	v.ShowItemInfo, _ = dict["showItemInfo"].(bool)
+	v.ShowIconPreview, _ = dict["showIconPreview"].(bool)
+	v.BackgroundType = int64(plistNumber(dict["backgroundType"]))
+	v.BackgroundImage, _ = dict["backgroundImageAlias"].([]byte)
+	return v, nil
+}
+
+// plistNumber returns number of integer or real property list value
+func plistNumber(v any) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// SetBackground sets background of the icon view to color "#rrggbb" or "#rgb", or to the picture with the path
+// inside of the volume, like ".background/bg.png", empty background keeps the current one
+func (v *IconViewSettings) SetBackground(volume, background string) error {
+	switch {
+	case background == "":
+	case strings.HasPrefix(background, "#"):
+		hex := background[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return fmt.Errorf("invalid background color %q", background)
+		}
+		v.BackgroundType = 1
+		v.BackgroundColor = [3]float64{float64(rgb>>16) / 255, float64(rgb>>8&0xff) / 255, float64(rgb&0xff) / 255}
+		v.BackgroundImage = nil
+	default:
+		if volume == "" {
+			return errors.New("volume name is required for background picture")
+		}
+		v.BackgroundType = 2
+		v.BackgroundImage = encodeAlias(volume, background)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/strongo/dsstore/buddy"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// readSmall reads records of B-tree consisting of one leaf node, which is the common case
+// of a folder with a handful of records. Records are decoded directly from the file data
+// into one preallocated slice without the tree walker. It returns false when B-tree
+// has more nodes or the node can't be decoded by the fast path, so the tree walker
+// reads it and reports errors.
+func (s *Store) readSmall(src blockSource, offsets []uint32, node uint32) (bool, error) {
+	data, ok := src.(bytesSource)
+	if !ok || uint64(node) >= uint64(len(offsets)) || len(s.Records) > 0 {
+		return false, nil
+	}
+	start, end, err := blockRange(data.size(), buddy.Offset(offsets[node]), buddy.Size(offsets[node]))
+	if err != nil {
+		return false, nil
+	}
+	block := data[start:end]
+	if len(block) < 8 || binary.BigEndian.Uint32(block) != 0 {
+		return false, nil
+	}
+	if err = s.ctxErr(); err != nil {
+		return true, err
+	}
+	// each record takes at least 13 bytes
+	count := binary.BigEndian.Uint32(block[4:])
+	if uint64(count) > uint64(len(block)/13) {
+		return false, nil
+	}
+	opts := s.opts.withDefaults()
+	records := make([]Record, count)
+	pos := 8
+	for i := range records {
+		n, ok := s.decodeRecord(block[pos:], &records[i], opts.MaxBlobLen)
+		if !ok {
+			return false, nil
+		}
+		pos += n
+	}
+	s.progress.NodesParsed++
+	s.debug("visit node", "node", node, "depth", 1, "offset", buddy.Offset(offsets[node]), "size", buddy.Size(offsets[node]), "records", count)
+	// filter records by structure IDs in place, records before an error are kept like the tree walker does
+	s.Records = records[:0]
+	for _, r := range records {
+		keep, err := s.acceptCode(r)
+		if err != nil {
+			return true, err
+		}
+		if !keep {
+			continue
+		}
+		if len(s.Records) >= opts.MaxRecords {
+			return true, fmt.Errorf("%w: more than %d records", ErrLimitExceeded, opts.MaxRecords)
+		}
+		s.Records = append(s.Records, r)
+	}
+	s.reportProgress()
+	s.checkPadding(bytes.NewBuffer(block[pos:]), node)
+	return true, nil
+}
+
+// decodeRecord decodes record from the start of b, it returns size of the record.
+// It returns false when the record can't be decoded.
+func (s *Store) decodeRecord(b []byte, r *Record, maxBlobLen int) (int, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	nameLen := 2 * uint64(binary.BigEndian.Uint32(b))
+	if nameLen+12 > uint64(len(b)) {
+		return 0, false
+	}
+	name16 := b[4 : 4+nameLen]
+	pos := 4 + int(nameLen)
+	r.Extra = binary.BigEndian.Uint32(b[pos:])
+	stype := b[pos+4 : pos+8]
+	pos += 8
+	var size uint64
+	switch string(stype) {
+	case "bool":
+		r.Type, size = "bool", 1
+	case "type":
+		r.Type, size = "type", 4
+	case "long":
+		r.Type, size = "long", 4
+	case "shor":
+		r.Type, size = "shor", 4
+	case "comp":
+		r.Type, size = "comp", 8
+	case "dutc":
+		r.Type, size = "dutc", 8
+	case "blob", "ustr":
+		if pos+4 > len(b) {
+			return 0, false
+		}
+		r.Type = string(stype)
+		r.DataLen = binary.BigEndian.Uint32(b[pos:])
+		pos += 4
+		size = uint64(r.DataLen)
+		if r.Type == "ustr" {
+			size *= 2
+		}
+	default:
+		return 0, false
+	}
+	if size == 0 || size > uint64(maxBlobLen) || size > uint64(len(b)-pos) {
+		return 0, false
+	}
+	if s.opts.ZeroCopy {
+		r.Data = b[pos : pos+int(size) : pos+int(size)]
+	} else {
+		r.Data = bytes.Clone(b[pos : pos+int(size)])
+	}
+	var ok bool
+	if r.FileName, ok = decodeName(name16); !ok {
+		return 0, false
+	}
+	return pos + int(size), true
+}
+
+// decodeName decodes UTF-16 file name, ASCII names are converted without the decoder
+func decodeName(name16 []byte) (string, bool) {
+	for i := 0; i < len(name16); i += 2 {
+		if name16[i] != 0 || name16[i+1] >= 0x80 {
+			name, _, err := transform.Bytes(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder(), name16)
+			return string(name), err == nil
+		}
+	}
+	var name strings.Builder
+	name.Grow(len(name16) / 2)
+	for i := 1; i < len(name16); i += 2 {
+		name.WriteByte(name16[i])
+	}
+	return name.String(), true
+}
+
+// sizeHint returns size of data left in the reader if it is known
+func sizeHint(r io.Reader) int64 {
+	switch r := r.(type) {
+	case ctxReader:
+		return sizeHint(r.r)
+	case progressReader:
+		return sizeHint(r.r)
+	case *countingReader:
+		return sizeHint(r.r)
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case fs.File:
+		// files of file systems are read from the start
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		return info.Size()
+	}
+	return -1
+}
+
+// readAll reads all data of the reader, but not more than limit bytes.
+// Data of the known size is read into one allocated buffer.
+func readAll(r io.Reader, hint, limit int64) ([]byte, error) {
+	r = io.LimitReader(r, limit)
+	if hint < 0 || hint >= limit {
+		return io.ReadAll(r)
+	}
+	// bytes.Buffer needs bytes.MinRead free bytes to detect the end of data without growing
+	buf := bytes.NewBuffer(make([]byte, 0, hint+bytes.MinRead))
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), err
+}
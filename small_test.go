@@ -0,0 +1,124 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// walkerSource hides bytesSource from the fast path, so records are read by the tree walker
+type walkerSource struct {
+	bytesSource
+}
+
+// readRecords reads records of the file data from the given source
+func readRecords(t testing.TB, fileData []byte, src func(bytesSource) blockSource, opts ReadOptions) (*Store, error) {
+	s := &Store{}
+	s.reset(opts)
+	rootOffset, rootSize, err := s.readHeader(fileData)
+	if err != nil {
+		t.Fatalf("readHeader failed: %v", err)
+	}
+	offsets, dataRoot, err := s.readRoot(bytesSource(fileData), rootOffset, rootSize)
+	if err != nil {
+		t.Fatalf("readRoot failed: %v", err)
+	}
+	return s, s.readParseDataFrom(src(bytesSource(fileData)), offsets, dataRoot)
+}
+
+func code(c string) uint32 {
+	var r Record
+	r.SetCode(c)
+	return r.Extra
+}
+
+func fastSource(b bytesSource) blockSource {
+	return b
+}
+
+func slowSource(b bytesSource) blockSource {
+	return walkerSource{b}
+}
+
+func TestReadSmall(t *testing.T) {
+	var s Store
+	s.Records = []Record{
+		{FileName: ".", Extra: code("vSrn"), Type: "long", Data: []byte{0, 0, 0, 1}},
+		{FileName: "Applications", Extra: code("Iloc"), Type: "blob", DataLen: 4, Data: []byte{1, 2, 3, 4}},
+		{FileName: "Приложение", Extra: code("cmmt"), Type: "ustr", DataLen: 1, Data: []byte{0, 'x'}},
+		{FileName: "flag", Extra: code("dscl"), Type: "bool", Data: []byte{1}},
+		{FileName: "unknown", Extra: code("zzzz"), Type: "long", Data: []byte{0, 0, 0, 2}},
+		{FileName: "zz", Extra: code("modD"), Type: "dutc", Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+	}
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, opts := range []ReadOptions{{}, {ZeroCopy: true}, {UnknownCodes: UnknownCodeDrop}} {
+		fast, err := readRecords(t, buf.Bytes(), fastSource, opts)
+		if err != nil {
+			t.Fatalf("fast path failed: %v", err)
+		}
+		slow, err := readRecords(t, buf.Bytes(), slowSource, opts)
+		if err != nil {
+			t.Fatalf("tree walker failed: %v", err)
+		}
+		if len(fast.Records) != len(slow.Records) {
+			t.Fatalf("expected %d records, got %d", len(slow.Records), len(fast.Records))
+		}
+		for i := range fast.Records {
+			if !fast.Records[i].Equal(slow.Records[i]) {
+				t.Errorf("record %d: expected %+v, got %+v", i, slow.Records[i], fast.Records[i])
+			}
+		}
+		if fast.progress.NodesParsed != 1 {
+			t.Errorf("expected 1 parsed node, got %d", fast.progress.NodesParsed)
+		}
+	}
+	// limits are checked like the tree walker does
+	_, err := readRecords(t, buf.Bytes(), fastSource, ReadOptions{MaxBlobLen: 2})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected limit error, got %v", err)
+	}
+}
+
+func TestReadAllSized(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 1000)
+	got, err := readAll(bytes.NewReader(data), sizeHint(bytes.NewReader(data)), 2000)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("readAll failed: %v", err)
+	}
+	if cap(got) != len(data)+bytes.MinRead {
+		t.Errorf("expected one allocation of %d bytes, got capacity %d", len(data)+bytes.MinRead, cap(got))
+	}
+	// wrong hint doesn't break reading
+	if got, err = readAll(bytes.NewReader(data), 10, 2000); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("readAll failed: %v", err)
+	}
+	if got, err = readAll(bytes.NewReader(data), -1, 500); err != nil || len(got) != 500 {
+		t.Fatalf("readAll failed: %v", err)
+	}
+}
+
+func benchmarkReadSmall(b *testing.B, src func(bytesSource) blockSource) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		b.Fatalf("ReadFile failed: %v", err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		if _, err = readRecords(b, fileData, src, ReadOptions{}); err != nil {
+			b.Fatalf("read failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkReadSmallFastPath(b *testing.B) {
+	benchmarkReadSmall(b, fastSource)
+}
+
+func BenchmarkReadSmallTreeWalker(b *testing.B) {
+	benchmarkReadSmall(b, slowSource)
+}
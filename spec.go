@@ -0,0 +1,186 @@
+package dsstore
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"image"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Spec is a specification of a folder layout, like a window of a DMG installer,
+// decoded from JSON by ParseSpec. It is built into .DS_Store by Spec.Build.
+type Spec struct {
+	Volume string `json:"volume,omitempty"` // volume name, required for the background picture
+	View   string `json:"view,omitempty"`   // view style, "icon view" by default
+	Window struct {
+		X             int  `json:"x"`
+		Y             int  `json:"y"`
+		Width         int  `json:"width,omitempty"`  // 640 by default
+		Height        int  `json:"height,omitempty"` // 480 by default
+		ShowToolbar   bool `json:"showToolbar,omitempty"`
+		ShowStatusBar bool `json:"showStatusBar,omitempty"`
+		ShowSidebar   bool `json:"showSidebar,omitempty"`
+		ShowPathbar   bool `json:"showPathbar,omitempty"`
+	} `json:"window"`
+	IconSize        float64 `json:"iconSize,omitempty"`    // 80 by default
+	TextSize        float64 `json:"textSize,omitempty"`    // 12 by default
+	GridSpacing     float64 `json:"gridSpacing,omitempty"` // 100 by default
+	ArrangeBy       string  `json:"arrangeBy,omitempty"`   // "none" by default
+	LabelOnRight    bool    `json:"labelOnRight,omitempty"`
+	ShowItemInfo    bool    `json:"showItemInfo,omitempty"`
+	ShowIconPreview bool    `json:"showIconPreview,omitempty"`
+	// Background is color "#rrggbb" or path of the picture inside of the volume, like ".background/bg.png"
+	Background string     `json:"background,omitempty"`
+	Icons      []SpecIcon `json:"icons,omitempty"`
+}
+
+// SpecIcon is position of the icon of a file of Spec
+type SpecIcon struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// appdmgSpec is specification of appdmg tool
+type appdmgSpec struct {
+	Title           string  `json:"title"`
+	Background      string  `json:"background"`
+	BackgroundColor string  `json:"background-color"`
+	IconSize        float64 `json:"icon-size"`
+	TextSize        float64 `json:"text-size"`
+	Window          struct {
+		Position struct{ X, Y int } `json:"position"`
+		Size     struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"size"`
+	} `json:"window"`
+	Contents []struct {
+		X    int    `json:"x"`
+		Y    int    `json:"y"`
+		Path string `json:"path"`
+		Name string `json:"name"`
+	} `json:"contents"`
+}
+
+// ParseSpec decodes JSON specification of Spec or of appdmg tool, which has "contents" instead of "icons".
+// Background picture of appdmg is copied to .background folder of the volume, so Spec references it there.
+func ParseSpec(data []byte) (Spec, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return Spec{}, err
+	}
+	var spec Spec
+	if _, ok := keys["contents"]; !ok {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&spec)
+		return spec, err
+	}
+	var appdmg appdmgSpec
+	if err := json.Unmarshal(data, &appdmg); err != nil {
+		return spec, err
+	}
+	spec.Volume = appdmg.Title
+	spec.Window.X, spec.Window.Y = appdmg.Window.Position.X, appdmg.Window.Position.Y
+	spec.Window.Width, spec.Window.Height = appdmg.Window.Size.Width, appdmg.Window.Size.Height
+	spec.IconSize, spec.TextSize = appdmg.IconSize, appdmg.TextSize
+	spec.Background = appdmg.BackgroundColor
+	if appdmg.Background != "" {
+		spec.Background = path.Join(".background", path.Base(appdmg.Background))
+	}
+	for _, entry := range appdmg.Contents {
+		name := entry.Name
+		if name == "" {
+			name = path.Base(entry.Path)
+		}
+		spec.Icons = append(spec.Icons, SpecIcon{Name: name, X: entry.X, Y: entry.Y})
+	}
+	return spec, nil
+}
+
+// Expand returns the specification with template variables like {{.Version}} or {{.AppName}} of the volume name,
+// view, arrangement, background and icon names resolved, so one specification serves all releases whose
+// file names embed versions. Variables are text/template fields of vars, undefined variables are errors.
+func (spec Spec) Expand(vars map[string]string) (Spec, error) {
+	expanded := spec
+	expanded.Icons = append([]SpecIcon(nil), spec.Icons...)
+	fields := []*string{&expanded.Volume, &expanded.View, &expanded.ArrangeBy, &expanded.Background}
+	for i := range expanded.Icons {
+		fields = append(fields, &expanded.Icons[i].Name)
+	}
+	for _, field := range fields {
+		if !strings.Contains(*field, "{{") {
+			continue
+		}
+		t, err := template.New("spec").Option("missingkey=error").Parse(*field)
+		if err != nil {
+			return spec, err
+		}
+		var b strings.Builder
+		if err = t.Execute(&b, vars); err != nil {
+			return spec, fmt.Errorf("template %q: %w", *field, err)
+		}
+		*field = b.String()
+	}
+	return expanded, nil
+}
+
+// Settings returns folder settings of the specification with defaults of omitted values
+func (spec Spec) Settings() (FolderSettings, error) {
+	width, height := cmp.Or(spec.Window.Width, 640), cmp.Or(spec.Window.Height, 480)
+	settings := FolderSettings{
+		View: cmp.Or(spec.View, "icon view"),
+		Window: &WindowSettings{
+			Bounds:        image.Rect(spec.Window.X, spec.Window.Y, spec.Window.X+width, spec.Window.Y+height),
+			ShowToolbar:   spec.Window.ShowToolbar,
+			ShowStatusBar: spec.Window.ShowStatusBar,
+			ShowSidebar:   spec.Window.ShowSidebar,
+			ShowPathbar:   spec.Window.ShowPathbar,
+		},
+		IconView: &IconViewSettings{
+			IconSize:        cmp.Or(spec.IconSize, 80),
+			TextSize:        cmp.Or(spec.TextSize, 12),
+			GridSpacing:     cmp.Or(spec.GridSpacing, 100),
+			ArrangeBy:       cmp.Or(spec.ArrangeBy, "none"),
+			LabelOnBottom:   !spec.LabelOnRight,
+			ShowItemInfo:    spec.ShowItemInfo,
+			ShowIconPreview: spec.ShowIconPreview,
+			BackgroundColor: [3]float64{1, 1, 1},
+		},
+		Positions: make(map[string]image.Point, len(spec.Icons)),
+	}
+	if _, ok := arrangements[settings.IconView.ArrangeBy]; !ok {
+		return settings, fmt.Errorf("unknown arrangement %q", settings.IconView.ArrangeBy)
+	}
+	if err := settings.IconView.SetBackground(spec.Volume, spec.Background); err != nil {
+		return settings, err
+	}
+	for _, icon := range spec.Icons {
+		if icon.Name == "" || strings.Contains(icon.Name, "/") {
+			return settings, fmt.Errorf("invalid icon name %q", icon.Name)
+		}
+		if _, ok := settings.Positions[icon.Name]; ok {
+			return settings, fmt.Errorf("duplicate icon %q", icon.Name)
+		}
+		settings.Positions[icon.Name] = image.Pt(icon.X, icon.Y)
+	}
+	return settings, nil
+}
+
+// Build returns a new store with records of the specification
+func (spec Spec) Build() (*Store, error) {
+	settings, err := spec.Settings()
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{}
+	if err = s.SetFolderSettings(settings); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
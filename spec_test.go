@@ -0,0 +1,112 @@
+package dsstore
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{
+		"volume": "App",
+		"window": {"x": 100, "y": 50, "width": 500, "height": 300},
+		"iconSize": 96,
+		"background": "#ff8000",
+		"icons": [{"name": "App.app", "x": 120, "y": 150}, {"name": "Applications", "x": 380, "y": 150}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	s, err := spec.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	settings, err := s.FolderSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.View != "icon view" || settings.Window.Bounds != image.Rect(100, 50, 600, 350) {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if v := settings.IconView; v.IconSize != 96 || v.TextSize != 12 || v.BackgroundType != 1 ||
+		v.BackgroundColor != [3]float64{1, 128.0 / 255, 0} {
+		t.Errorf("unexpected icon view settings %+v", v)
+	}
+	if settings.Positions["Applications"] != image.Pt(380, 150) || len(settings.Positions) != 2 {
+		t.Errorf("unexpected positions %v", settings.Positions)
+	}
+
+	for _, data := range []string{
+		`{"icons": [{"name": "a"}, {"name": "a"}]}`,
+		`{"background": "#12"}`,
+		`{"background": "bg.png"}`,
+		`{"arrangeBy": "color"}`,
+		`{"window": {"w": 1}}`,
+	} {
+		if spec, err = ParseSpec([]byte(data)); err == nil {
+			_, err = spec.Build()
+		}
+		if err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
+
+func TestParseSpecAppdmg(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{
+		"title": "Test Title",
+		"icon": "TestIcon.icns",
+		"background": "assets/TestBkg.png",
+		"icon-size": 80,
+		"window": {"position": {"x": 10, "y": 20}, "size": {"width": 640, "height": 480}},
+		"contents": [
+			{"x": 448, "y": 344, "type": "link", "path": "/Applications"},
+			{"x": 192, "y": 344, "type": "file", "path": "TestApp.app"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	if spec.Volume != "Test Title" || spec.Background != ".background/TestBkg.png" || len(spec.Icons) != 2 ||
+		spec.Icons[0] != (SpecIcon{Name: "Applications", X: 448, Y: 344}) || spec.Window.X != 10 {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+	settings, err := spec.Settings()
+	if err != nil {
+		t.Fatalf("Settings failed: %v", err)
+	}
+	alias := settings.IconView.BackgroundImage
+	if settings.IconView.BackgroundType != 2 || len(alias) < 150 ||
+		int(alias[4])<<8|int(alias[5]) != len(alias) || !bytes.Contains(alias, []byte("/.background/TestBkg.png")) {
+		t.Errorf("unexpected background alias %q", alias)
+	}
+}
+
+func TestSpecExpand(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"volume": "{{.AppName}} {{.Version}}", "background": ".background/{{.Version}}.png",
+		"icons": [{"name": "{{.AppName}}.app", "x": 140, "y": 120}, {"name": "Applications", "x": 400, "y": 120}]}`))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	expanded, err := spec.Expand(map[string]string{"AppName": "App", "Version": "2.0"})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if expanded.Volume != "App 2.0" || expanded.Background != ".background/2.0.png" ||
+		expanded.Icons[0].Name != "App.app" || expanded.Icons[1].Name != "Applications" {
+		t.Errorf("unexpected expanded spec %+v", expanded)
+	}
+	if spec.Icons[0].Name != "{{.AppName}}.app" {
+		t.Error("expected the specification not to be changed")
+	}
+	if _, err = expanded.Build(); err != nil {
+		t.Errorf("Build failed: %v", err)
+	}
+	if _, err = spec.Expand(map[string]string{"AppName": "App"}); err == nil || !strings.Contains(err.Error(), "Version") {
+		t.Errorf("expected error for undefined variable, got %v", err)
+	}
+	if _, err = spec.Expand(nil); err == nil {
+		t.Error("expected error without variables")
+	}
+}
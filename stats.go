@@ -0,0 +1,40 @@
+package dsstore
+
+// RecordSize is the size of data of a record
+type RecordSize struct {
+	FileName string `json:"fileName"`
+	Code     string `json:"code"`
+	Size     int    `json:"size"` // data size in bytes
+}
+
+// Stats are statistics of a store for quick health checks
+type Stats struct {
+	Records   int            `json:"records"`
+	Codes     map[string]int `json:"codes"`     // count of records by structure IDs
+	Types     map[string]int `json:"types"`     // count of records by types
+	DataBytes int64          `json:"dataBytes"` // total size of data of records in bytes
+	Largest   RecordSize     `json:"largest"`   // record with the largest data, zero for empty store
+	// Depth and FreeRatio are known for read stores, see Geometry
+	Depth     int     `json:"depth"`     // levels of data B-tree counting from 1 at the root
+	FreeRatio float64 `json:"freeRatio"` // part of the allocated region which is free, from 0 to 1
+}
+
+// Stats returns counts of records by structure IDs and types, size of their data, the largest record
+// and for read stores depth of B-tree and free space ratio
+func (s *Store) Stats() Stats {
+	st := Stats{Records: len(s.Records), Codes: make(map[string]int), Types: make(map[string]int)}
+	for _, r := range s.Records {
+		code := r.Code()
+		st.Codes[code]++
+		st.Types[r.Type]++
+		st.DataBytes += int64(len(r.Data))
+		if len(r.Data) > st.Largest.Size {
+			st.Largest = RecordSize{FileName: r.FileName, Code: code, Size: len(r.Data)}
+		}
+	}
+	if g, ok := s.Geometry(); ok {
+		st.Depth = g.Depth
+		st.FreeRatio = g.FreeRatio()
+	}
+	return st
+}
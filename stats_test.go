@@ -0,0 +1,34 @@
+package dsstore
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	st := s.Stats()
+	if st.Records != len(s.Records) || st.Codes["Iloc"] != 2 || st.Types["blob"] != 5 || st.Depth != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	var total int64
+	largest := 0
+	for _, r := range s.Records {
+		total += int64(len(r.Data))
+		largest = max(largest, len(r.Data))
+	}
+	if st.DataBytes != total || st.Largest.Size != largest || st.Largest.Code != "pBBk" {
+		t.Errorf("expected %d bytes and largest record of %d bytes, got %+v", total, largest, st)
+	}
+	if g, _ := s.Geometry(); st.FreeRatio != g.FreeRatio() || st.FreeRatio <= 0 {
+		t.Errorf("expected free ratio %v, got %v", g.FreeRatio(), st.FreeRatio)
+	}
+
+	empty := (&Store{}).Stats()
+	if empty.Records != 0 || empty.Largest != (RecordSize{}) || empty.Depth != 0 {
+		t.Errorf("unexpected stats of empty store %+v", empty)
+	}
+}
@@ -0,0 +1,137 @@
+package dsstore
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SyncStore is Store safe for concurrent use.
+//
+// Store itself is not safe for concurrent use: methods which only read the store
+// (Write, Validate, EncodedSize, ...) may be called concurrently only while nobody
+// modifies it. SyncStore guards the store by RWMutex, so lookups run in parallel
+// and modifications are exclusive.
+type SyncStore struct {
+	mu    sync.RWMutex
+	s     *Store
+	index map[syncKey]int // positions of records by keys, nil until the first lookup
+}
+
+// syncKey is key of record compared the way Finder compares keys
+type syncKey struct {
+	name  string // lower case file name
+	extra uint32 // structure ID
+}
+
+func newSyncKey(filename, code string) syncKey {
+	r := Record{}
+	r.SetCode(code)
+	return syncKey{strings.ToLower(filename), r.Extra}
+}
+
+// NewSyncStore creates SyncStore of the store. The store must not be used directly after that.
+func NewSyncStore(s *Store) *SyncStore {
+	return &SyncStore{s: s}
+}
+
+// Lookup returns record by file name (case-insensitively) and structure ID
+func (ss *SyncStore) Lookup(filename, code string) (Record, bool) {
+	key := newSyncKey(filename, code)
+	ss.mu.RLock()
+	if ss.index != nil {
+		defer ss.mu.RUnlock()
+		i, ok := ss.index[key]
+		if !ok {
+			return Record{}, false
+		}
+		return ss.s.Records[i], true
+	}
+	ss.mu.RUnlock()
+	// build index for the next lookups
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.index == nil {
+		ss.index = make(map[syncKey]int, len(ss.s.Records))
+		for i, r := range ss.s.Records {
+			k := syncKey{strings.ToLower(r.FileName), r.Extra}
+			if _, ok := ss.index[k]; !ok {
+				ss.index[k] = i
+			}
+		}
+	}
+	i, ok := ss.index[key]
+	if !ok {
+		return Record{}, false
+	}
+	return ss.s.Records[i], true
+}
+
+// Records returns copy of the slice of records
+func (ss *SyncStore) Records() []Record {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return slices.Clone(ss.s.Records)
+}
+
+// Len returns count of records
+func (ss *SyncStore) Len() int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return len(ss.s.Records)
+}
+
+// Set replaces record with the same file name and structure ID or adds the record
+func (ss *SyncStore) Set(r Record) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	key := syncKey{strings.ToLower(r.FileName), r.Extra}
+	i := slices.IndexFunc(ss.s.Records, func(o Record) bool {
+		return o.Extra == key.extra && strings.ToLower(o.FileName) == key.name
+	})
+	if i >= 0 {
+		ss.s.Records[i] = r
+		return
+	}
+	ss.s.Records = append(ss.s.Records, r)
+	ss.index = nil
+}
+
+// Delete removes records with the file name and structure ID, it reports whether records are removed
+func (ss *SyncStore) Delete(filename, code string) bool {
+	key := newSyncKey(filename, code)
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	n := len(ss.s.Records)
+	ss.s.Records = slices.DeleteFunc(ss.s.Records, func(r Record) bool {
+		return r.Extra == key.extra && strings.ToLower(r.FileName) == key.name
+	})
+	if len(ss.s.Records) == n {
+		return false
+	}
+	ss.index = nil
+	return true
+}
+
+// View calls fn with the store locked for reading. The store must not be modified by fn.
+func (ss *SyncStore) View(fn func(s *Store) error) error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return fn(ss.s)
+}
+
+// Update calls fn with the store locked for writing
+func (ss *SyncStore) Update(fn func(s *Store) error) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.index = nil
+	return fn(ss.s)
+}
+
+// Write writes .DS_Store to io.Writer
+func (ss *SyncStore) Write(w io.Writer) error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.s.Write(w)
+}
@@ -0,0 +1,69 @@
+package dsstore
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSyncStore(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	ss := NewSyncStore(&s)
+	if r, ok := ss.Lookup("applications", "Iloc"); !ok || r.FileName != "Applications" {
+		t.Errorf("expected record of Applications, got %v %v", r, ok)
+	}
+	if _, ok := ss.Lookup("Applications", "bwsp"); ok {
+		t.Error("expected missing record")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := fmt.Sprintf("file%d-%d", i, j)
+				r := Record{FileName: name, Type: "bool", Data: []byte{1}}
+				r.SetCode("dilc")
+				ss.Set(r)
+				if _, ok := ss.Lookup(name, "dilc"); !ok {
+					t.Errorf("record %q is not found", name)
+				}
+				if j%10 == 0 {
+					if err := ss.Write(io.Discard); err != nil {
+						t.Errorf("Write failed: %v", err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := ss.Len(); n != 6+800 {
+		t.Errorf("expected %d records, got %d", 6+800, n)
+	}
+
+	if !ss.Delete("FILE0-0", "dilc") || ss.Delete("file0-0", "dilc") {
+		t.Error("expected record to be deleted once")
+	}
+	if _, ok := ss.Lookup("file0-0", "dilc"); ok {
+		t.Error("expected deleted record to be missing")
+	}
+	err := ss.Update(func(s *Store) error {
+		s.Records = s.Records[:1]
+		return nil
+	})
+	if err != nil || len(ss.Records()) != 1 {
+		t.Errorf("expected 1 record after update, got %d (%v)", len(ss.Records()), err)
+	}
+	_ = ss.View(func(s *Store) error {
+		if len(s.Records) != 1 {
+			t.Errorf("expected 1 record, got %d", len(s.Records))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package dsstore
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Kinds of TamperFinding
+const (
+	TamperMissing   = "missing"   // the store has records of the file, but it is not on disk
+	TamperModified  = "modified"  // the file was modified after the recorded modification date, the store is stale
+	TamperBackdated = "backdated" // the file on disk is older than the recorded modification date
+)
+
+// TamperOptions are options of CheckTampering
+type TamperOptions struct {
+	// Tolerance is the maximal ignored difference of modification times,
+	// file systems keep them with different precision. Default is 2 seconds.
+	Tolerance time.Duration
+}
+
+// TamperFinding is a mismatch of the store and files of its directory
+type TamperFinding struct {
+	Name      string        `json:"name"`
+	Kind      string        `json:"kind"`               // TamperMissing, TamperModified or TamperBackdated
+	StoreTime time.Time     `json:"storeTime,omitzero"` // recorded modification date, zero when unknown
+	DiskTime  time.Time     `json:"diskTime,omitzero"`  // modification time on disk, zero for missing file
+	Delta     time.Duration `json:"delta,omitempty"`    // DiskTime minus StoreTime
+}
+
+// CheckTampering compares records of the store with files of its directory of the file system:
+// files of records which are missing on disk and files which modification times differ from
+// recorded "modD" and "moDD" dates. It helps to find tampered files in forensics
+// and stale stores of backups. Findings are sorted by file names like Leakage entries.
+func CheckTampering(s *Store, fsys fs.FS, dir string, opts TamperOptions) ([]TamperFinding, error) {
+	if opts.Tolerance == 0 {
+		opts.Tolerance = 2 * time.Second
+	}
+	report, err := Leakage(s, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var findings []TamperFinding
+	for _, e := range report.Entries {
+		finding := TamperFinding{Name: e.Name, StoreTime: e.StoreTime, DiskTime: e.DiskTime}
+		switch {
+		case e.Presence == InStoreOnly:
+			finding.Kind = TamperMissing
+		case e.Presence == OnDiskOnly || e.StoreTime.IsZero() || e.DiskTime.IsZero():
+			continue
+		default:
+			finding.Delta = e.DiskTime.Sub(e.StoreTime)
+			if finding.Delta > opts.Tolerance {
+				finding.Kind = TamperModified
+			} else if finding.Delta < -opts.Tolerance {
+				finding.Kind = TamperBackdated
+			} else {
+				continue
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
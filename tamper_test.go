@@ -0,0 +1,51 @@
+package dsstore
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCheckTampering(t *testing.T) {
+	recorded := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"dir/same.txt":      {ModTime: recorded.Add(time.Second)},
+		"dir/newer.txt":     {ModTime: recorded.Add(time.Hour)},
+		"dir/older.txt":     {ModTime: recorded.Add(-24 * time.Hour)},
+		"dir/undated.txt":   {ModTime: recorded},
+		"dir/unrecorded.md": {ModTime: recorded},
+	}
+	s := &Store{Records: []Record{
+		TimeRecord(".", "modD", recorded),
+		TimeRecord("same.txt", "modD", recorded),
+		TimeRecord("newer.txt", "moDD", recorded),
+		TimeRecord("older.txt", "modD", recorded),
+		TextRecord("undated.txt", "cmmt", "x"),
+		TimeRecord("deleted.txt", "modD", recorded),
+	}}
+	findings, err := CheckTampering(s, fsys, "dir", TamperOptions{})
+	if err != nil {
+		t.Fatalf("CheckTampering failed: %v", err)
+	}
+	expected := []TamperFinding{
+		{Name: "deleted.txt", Kind: TamperMissing, StoreTime: recorded},
+		{Name: "newer.txt", Kind: TamperModified, StoreTime: recorded, DiskTime: recorded.Add(time.Hour), Delta: time.Hour},
+		{Name: "older.txt", Kind: TamperBackdated, StoreTime: recorded, DiskTime: recorded.Add(-24 * time.Hour), Delta: -24 * time.Hour},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %+v", len(expected), findings)
+	}
+	for i, f := range findings {
+		e := expected[i]
+		if f.Name != e.Name || f.Kind != e.Kind || !f.StoreTime.Equal(e.StoreTime) || !f.DiskTime.Equal(e.DiskTime) || f.Delta != e.Delta {
+			t.Errorf("finding %d: expected %+v, got %+v", i, e, f)
+		}
+	}
+
+	if findings, _ = CheckTampering(s, fsys, "dir", TamperOptions{Tolerance: 48 * time.Hour}); len(findings) != 1 {
+		t.Errorf("expected only missing file with large tolerance, got %+v", findings)
+	}
+	if _, err = CheckTampering(s, fsys, "absent", TamperOptions{}); err == nil {
+		t.Error("expected error of absent directory")
+	}
+}
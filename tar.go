@@ -0,0 +1,102 @@
+package dsstore
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+)
+
+// TarFilterMode selects .DS_Store entries written by FilterTar
+type TarFilterMode int
+
+// Modes of FilterTar
+const (
+	TarDrop    TarFilterMode = iota // write archive without .DS_Store entries
+	TarExtract                      // write archive of .DS_Store entries only
+)
+
+// TarFilterOptions are options of FilterTar
+type TarFilterOptions struct {
+	Mode TarFilterMode
+	// OnStore is called for every .DS_Store entry with the store read in best-effort mode,
+	// the store is nil when nothing of the entry can be read
+	OnStore func(name string, s *Store, err error)
+	// ReadOptions are options of reading stores for OnStore
+	ReadOptions ReadOptions
+}
+
+// isTarStore reports whether the tar entry is .DS_Store file or its AppleDouble file "._.DS_Store"
+func isTarStore(hdr *tar.Header) bool {
+	name := path.Base(hdr.Name)
+	return (name == StoreFileName || name == "._"+StoreFileName) && hdr.FileInfo().Mode().IsRegular()
+}
+
+// FilterTar returns tar stream read from r with .DS_Store entries dropped or extracted.
+// Archive is filtered on the fly entry by entry, only .DS_Store entries passed to OnStore are kept in memory.
+// Gzip compressed archive is detected and the result is compressed too.
+// The returned reader must be closed to stop filtering when it is not read until EOF.
+func FilterTar(r io.Reader, opts TarFilterOptions) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(filterTar(pw, r, opts))
+	}()
+	return pr
+}
+
+func filterTar(w io.Writer, r io.Reader, opts TarFilterOptions) error {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		zw := gzip.NewWriter(w)
+		if err = filterTarStream(zw, zr, opts); err != nil {
+			return err
+		}
+		return zw.Close()
+	}
+	return filterTarStream(w, br, opts)
+}
+
+func filterTarStream(w io.Writer, r io.Reader, opts TarFilterOptions) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		isStore := isTarStore(hdr)
+		var data []byte
+		if isStore && path.Base(hdr.Name) == StoreFileName && opts.OnStore != nil {
+			// the store is read from memory, so it is written from the same data
+			if data, err = io.ReadAll(io.LimitReader(tr, opts.ReadOptions.withDefaults().MaxFileSize+1)); err != nil {
+				return err
+			}
+			readOpts := opts.ReadOptions
+			readOpts.BestEffort = true
+			s := &Store{}
+			if err = s.ReadWithOptions(bytes.NewReader(data), readOpts); err != nil && !s.readable() {
+				s = nil
+			}
+			opts.OnStore(hdr.Name, s, err)
+		}
+		if isStore != (opts.Mode == TarExtract) {
+			continue
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		// data of the read store is written first
+		if _, err = io.Copy(tw, io.MultiReader(bytes.NewReader(data), tr)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package dsstore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type tarFile struct {
+	name string
+	data []byte
+}
+
+func testTar(t *testing.T, compress bool) ([]byte, []tarFile) {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	files := []tarFile{
+		{"App/.DS_Store", fileData},
+		{"App/._.DS_Store", []byte("apple double")},
+		{"App/readme.txt", []byte("readme")},
+		{"App/lib/.DS_Store", []byte("garbage")},
+		{"App/lib/lib.so", bytes.Repeat([]byte{1, 2}, 1000)},
+	}
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+	tw := tar.NewWriter(w)
+	for _, f := range files {
+		if err = tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("WriteHeader failed: %v", err)
+		}
+		if _, err = tw.Write(f.data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if zw != nil {
+		if err = zw.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+	return buf.Bytes(), files
+}
+
+func readTar(t *testing.T, r io.Reader, compressed bool) []tarFile {
+	if compressed {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("expected gzip output: %v", err)
+		}
+		r = zr
+	}
+	tr := tar.NewReader(r)
+	var files []tarFile
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("ReadAll failed: %v", err)
+		}
+		files = append(files, tarFile{hdr.Name, data})
+	}
+}
+
+func TestFilterTar(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		data, files := testTar(t, compress)
+		for _, mode := range []TarFilterMode{TarDrop, TarExtract} {
+			var stores []string
+			r := FilterTar(bytes.NewReader(data), TarFilterOptions{Mode: mode, OnStore: func(name string, s *Store, err error) {
+				if name == "App/.DS_Store" && (s == nil || len(s.Records) != 6 || err != nil) {
+					t.Errorf("unexpected store of %s: %v", name, err)
+				}
+				if name == "App/lib/.DS_Store" && (s != nil || err == nil) {
+					t.Errorf("expected error of %s", name)
+				}
+				stores = append(stores, name)
+			}})
+			got := readTar(t, r, compress)
+			if err := r.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+			expected := slices.DeleteFunc(slices.Clone(files), func(f tarFile) bool {
+				isStore := filepath.Base(f.name) == ".DS_Store" || filepath.Base(f.name) == "._.DS_Store"
+				return isStore != (mode == TarExtract)
+			})
+			if !slices.EqualFunc(got, expected, func(a, b tarFile) bool {
+				return a.name == b.name && bytes.Equal(a.data, b.data)
+			}) {
+				t.Errorf("compress %v, mode %d: unexpected entries", compress, mode)
+			}
+			if !slices.Equal(stores, []string{"App/.DS_Store", "App/lib/.DS_Store"}) {
+				t.Errorf("unexpected stores %v", stores)
+			}
+		}
+	}
+}
+
+func TestFilterTarErrors(t *testing.T) {
+	data, _ := testTar(t, false)
+	r := FilterTar(bytes.NewReader(data[:1000]), TarFilterOptions{})
+	if _, err := io.ReadAll(r); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF, got %v", err)
+	}
+	// closing stops filtering of unread stream
+	r = FilterTar(bytes.NewReader(data), TarFilterOptions{})
+	if err := r.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package dsstore
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// Target is macOS versions which records of written stores are styled for
+type Target int
+
+// Targets of writing. Finder of different versions reads different variants of the same settings:
+// background of legacy "BKGD" with "pict" alias or of "icvp", list view properties of "lsvp" or "lsvP".
+const (
+	TargetAsIs  Target = iota // records are written as they are
+	MacOSLegacy               // legacy "BKGD" with "pict" and "lsvp" are derived, "lsvP" is dropped
+	MacOSModern               // background of "icvp" and "lsvP" are derived, "BKGD", "pict" and "lsvp" are dropped
+	MacOSBoth                 // missing variants of both are derived, so the store is styled for any macOS
+)
+
+// bkgdSize is size of data of "BKGD" records
+const bkgdSize = 12
+
+// targetKey is a record of a file name with structure ID
+type targetKey struct {
+	name, code string
+}
+
+// apply returns records with variants of the target, the records are not changed.
+// Existing variants are kept, missing ones are derived from their counterparts.
+func (t Target) apply(records []Record) ([]Record, error) {
+	if t == TargetAsIs {
+		return records, nil
+	}
+	if t < TargetAsIs || t > MacOSBoth {
+		return nil, fmt.Errorf("%w: unknown target %d", ErrInvalidOptions, t)
+	}
+	legacy, modern := t == MacOSLegacy || t == MacOSBoth, t == MacOSModern || t == MacOSBoth
+	byKey := make(map[targetKey]Record)
+	var names []string
+	for _, r := range records {
+		if !slices.Contains(names, r.FileName) {
+			names = append(names, r.FileName)
+		}
+		byKey[targetKey{r.FileName, r.Code()}] = r
+	}
+	drop := make(map[targetKey]bool)
+	var derived []Record
+	for _, name := range names {
+		icvp, hasIcvp := byKey[targetKey{name, "icvp"}]
+		bkgd, hasBkgd := byKey[targetKey{name, "BKGD"}]
+		pict, hasPict := byKey[targetKey{name, "pict"}]
+		if legacy && hasIcvp && !hasBkgd {
+			background, err := legacyBackground(icvp)
+			if err != nil {
+				return nil, err
+			}
+			derived = append(derived, background...)
+		}
+		if modern && hasBkgd {
+			if !hasIcvp {
+				background, err := modernBackground(bkgd, pict, hasPict)
+				if err != nil {
+					return nil, err
+				}
+				derived = append(derived, background...)
+			}
+			if !legacy {
+				drop[targetKey{name, "BKGD"}], drop[targetKey{name, "pict"}] = true, true
+			}
+		}
+
+		lsvp, hasLsvp := byKey[targetKey{name, "lsvp"}]
+		lsvP, hasLsvP := byKey[targetKey{name, "lsvP"}]
+		if legacy && hasLsvP && !hasLsvp {
+			r, err := convertListView(lsvP, "lsvp")
+			if err != nil {
+				return nil, err
+			}
+			derived = append(derived, r)
+		}
+		if modern && hasLsvp && !hasLsvP {
+			r, err := convertListView(lsvp, "lsvP")
+			if err != nil {
+				return nil, err
+			}
+			derived = append(derived, r)
+		}
+		if !modern {
+			drop[targetKey{name, "lsvP"}] = true
+		}
+		if !legacy {
+			drop[targetKey{name, "lsvp"}] = true
+		}
+	}
+	result := make([]Record, 0, len(records)+len(derived))
+	for _, r := range records {
+		if !drop[targetKey{r.FileName, r.Code()}] {
+			result = append(result, r)
+		}
+	}
+	return append(result, derived...), nil
+}
+
+// legacyBackground returns "BKGD" record with "pict" record of picture background of "icvp" record
+func legacyBackground(icvp Record) ([]Record, error) {
+	v, err := iconViewSettings(icvp)
+	if err != nil {
+		return nil, fmt.Errorf("icvp record of %q: %w", icvp.FileName, err)
+	}
+	bkgd := Record{FileName: icvp.FileName, Type: "blob", DataLen: bkgdSize, Data: make([]byte, bkgdSize)}
+	bkgd.SetCode("BKGD")
+	switch {
+	case v.BackgroundType == 1:
+		copy(bkgd.Data, "ClrB")
+		for i, c := range v.BackgroundColor {
+			binary.BigEndian.PutUint16(bkgd.Data[4+2*i:], uint16(math.Round(min(max(c, 0), 1)*0xffff)))
+		}
+	case v.BackgroundType == 2 && len(v.BackgroundImage) > 0:
+		copy(bkgd.Data, "PctB")
+		binary.BigEndian.PutUint32(bkgd.Data[4:], uint32(len(v.BackgroundImage)))
+		pict := Record{FileName: icvp.FileName, Type: "blob", DataLen: uint32(len(v.BackgroundImage)), Data: v.BackgroundImage}
+		pict.SetCode("pict")
+		return []Record{bkgd, pict}, nil
+	default:
+		copy(bkgd.Data, "DefB")
+	}
+	return []Record{bkgd}, nil
+}
+
+// modernBackground returns "icvp" record with background of "BKGD" record and its "pict" alias,
+// default background has no record
+func modernBackground(bkgd, pict Record, hasPict bool) ([]Record, error) {
+	if bkgd.Type != "blob" || len(bkgd.Data) != bkgdSize {
+		return nil, fmt.Errorf("BKGD record of %q must be blob of %d bytes", bkgd.FileName, bkgdSize)
+	}
+	dict := map[string]any{"viewOptionsVersion": 1}
+	switch kind := string(bkgd.Data[:4]); kind {
+	case "DefB":
+		return nil, nil
+	case "ClrB":
+		dict["backgroundType"] = 1
+		for i, key := range []string{"backgroundColorRed", "backgroundColorGreen", "backgroundColorBlue"} {
+			dict[key] = float64(binary.BigEndian.Uint16(bkgd.Data[4+2*i:])) / 0xffff
+		}
+	case "PctB":
+		if !hasPict {
+			return nil, fmt.Errorf("BKGD record of %q has picture background without pict record", bkgd.FileName)
+		}
+		dict["backgroundType"] = 2
+		dict["backgroundImageAlias"] = pict.Data
+	default:
+		return nil, fmt.Errorf("BKGD record of %q has unknown background %q", bkgd.FileName, kind)
+	}
+	r, err := plistRecord("icvp", dict)
+	r.FileName = bkgd.FileName
+	return []Record{r}, err
+}
+
+// convertListView returns list view properties of "lsvp" record as "lsvP" record or vice versa:
+// "lsvp" has columns in dictionary by identifiers with their indexes, "lsvP" has array of columns in order
+func convertListView(r Record, code string) (Record, error) {
+	dict, err := plistDict(r)
+	if err != nil {
+		return Record{}, fmt.Errorf("%s record of %q: %w", r.Code(), r.FileName, err)
+	}
+	dict = maps.Clone(dict)
+	switch columns := dict["columns"].(type) {
+	case map[string]any:
+		if code != "lsvP" {
+			break
+		}
+		ids := slices.SortedFunc(maps.Keys(columns), func(a, b string) int {
+			ca, _ := columns[a].(map[string]any)
+			cb, _ := columns[b].(map[string]any)
+			return cmp.Or(cmp.Compare(plistNumber(ca["index"]), plistNumber(cb["index"])), cmp.Compare(a, b))
+		})
+		list := make([]any, 0, len(ids))
+		for _, id := range ids {
+			column, ok := columns[id].(map[string]any)
+			if !ok {
+				return Record{}, fmt.Errorf("%s record of %q: column %q is not a dictionary", r.Code(), r.FileName, id)
+			}
+			column = maps.Clone(column)
+			delete(column, "index")
+			column["identifier"] = id
+			list = append(list, column)
+		}
+		dict["columns"] = list
+	case []any:
+		if code != "lsvp" {
+			break
+		}
+		byID := make(map[string]any, len(columns))
+		for i, c := range columns {
+			column, ok := c.(map[string]any)
+			id, _ := column["identifier"].(string)
+			if !ok || id == "" {
+				return Record{}, fmt.Errorf("%s record of %q: column %d has no identifier", r.Code(), r.FileName, i)
+			}
+			column = maps.Clone(column)
+			column["index"] = i
+			byID[id] = column
+		}
+		dict["columns"] = byID
+	}
+	converted, err := plistRecord(code, dict)
+	converted.FileName = r.FileName
+	return converted, err
+}
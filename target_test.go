@@ -0,0 +1,112 @@
+package dsstore
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// writeTarget writes the store for the target and returns records of the written store by structure IDs
+func writeTarget(t *testing.T, s *Store, target Target) map[string]Record {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	if err := s.WriteWithOptions(buf, WriteOptions{Target: target, Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var written Store
+	if err := written.Read(buf); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	records := make(map[string]Record)
+	for _, r := range written.Records {
+		records[r.Code()] = r
+	}
+	return records
+}
+
+func TestWriteTarget(t *testing.T) {
+	v := &IconViewSettings{IconSize: 64, TextSize: 12, ArrangeBy: "none"}
+	if err := v.SetBackground("", "#f00"); err != nil {
+		t.Fatalf("SetBackground failed: %v", err)
+	}
+	records, err := FolderSettings{IconView: v}.Records()
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	lsvp, err := plistRecord("lsvp", map[string]any{"textSize": 12.0, "columns": map[string]any{
+		"name": map[string]any{"index": 0, "width": 300, "visible": true},
+		"size": map[string]any{"index": 2, "width": 97, "visible": true},
+		"kind": map[string]any{"index": 1, "width": 115, "visible": false},
+	}})
+	if err != nil {
+		t.Fatalf("plistRecord failed: %v", err)
+	}
+	s := &Store{Records: append(records, lsvp)}
+	original := slices.Clone(s.Records)
+
+	both := writeTarget(t, s, MacOSBoth)
+	if bkgd := both["BKGD"]; !bytes.Equal(bkgd.Data, []byte("ClrB\xff\xff\x00\x00\x00\x00\x00\x00")) {
+		t.Errorf("expected BKGD of red color, got %q", bkgd.Data)
+	}
+	lsvP, err := plistDict(both["lsvP"])
+	if err != nil {
+		t.Fatalf("lsvP: %v", err)
+	}
+	var ids []any
+	for _, c := range lsvP["columns"].([]any) {
+		ids = append(ids, c.(map[string]any)["identifier"])
+	}
+	if !reflect.DeepEqual(ids, []any{"name", "kind", "size"}) || lsvP["textSize"] != 12.0 {
+		t.Errorf("expected columns in order of indexes, got %v", lsvP)
+	}
+	if _, ok := both["lsvp"]; !ok || !slices.EqualFunc(original, s.Records, Record.Equal) {
+		t.Error("expected records of the store to be kept")
+	}
+
+	// legacy store for modern macOS: icvp and lsvP replace legacy records
+	alias := encodeAlias("Volume", ".background/bg.png")
+	bkgd := Record{FileName: ".", Type: "blob", DataLen: bkgdSize, Data: []byte("PctB\x00\x00\x00\x00\x00\x00\x00\x00")}
+	bkgd.SetCode("BKGD")
+	pict := Record{FileName: ".", Type: "blob", DataLen: uint32(len(alias)), Data: alias}
+	pict.SetCode("pict")
+	modern := writeTarget(t, &Store{Records: []Record{bkgd, pict, lsvp}}, MacOSModern)
+	if len(modern) != 2 {
+		t.Errorf("expected icvp and lsvP, got %v", slices.Collect(maps.Keys(modern)))
+	}
+	icvp, err := iconViewSettings(modern["icvp"])
+	if err != nil || icvp.BackgroundType != 2 || !bytes.Equal(icvp.BackgroundImage, alias) {
+		t.Errorf("expected picture background of icvp, got %+v: %v", icvp, err)
+	}
+	if _, ok := modern["lsvP"]; !ok {
+		t.Error("expected lsvP")
+	}
+
+	// modern store for legacy macOS: BKGD with pict and lsvp are derived, lsvP is dropped
+	if err = v.SetBackground("Volume", ".background/bg.png"); err != nil {
+		t.Fatalf("SetBackground failed: %v", err)
+	}
+	if records, err = (FolderSettings{IconView: v}).Records(); err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	legacy := writeTarget(t, &Store{Records: append(records, both["lsvP"])}, MacOSLegacy)
+	if _, ok := legacy["lsvP"]; ok {
+		t.Error("expected lsvP to be dropped")
+	}
+	if !bytes.HasPrefix(legacy["BKGD"].Data, []byte("PctB")) || !bytes.Equal(legacy["pict"].Data, v.BackgroundImage) {
+		t.Errorf("expected picture background of BKGD and pict, got %q", legacy["BKGD"].Data)
+	}
+	lsvpDict, err := plistDict(legacy["lsvp"])
+	if err != nil {
+		t.Fatalf("lsvp: %v", err)
+	}
+	if kind, _ := lsvpDict["columns"].(map[string]any)["kind"].(map[string]any); kind["index"] != int64(1) {
+		t.Errorf("expected index of column, got %v", lsvpDict["columns"])
+	}
+
+	if err = s.WriteWithOptions(new(bytes.Buffer), WriteOptions{Target: MacOSBoth + 1}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected ErrInvalidOptions, got %v", err)
+	}
+}
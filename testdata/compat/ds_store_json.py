@@ -0,0 +1,50 @@
+"""Converts .DS_Store files to JSON records and back with the ds_store package for compatibility tests.
+
+Usage:
+    ds_store_json.py read <file>   prints JSON array of records of the store
+    ds_store_json.py write <file>  writes the store of JSON array of records read from stdin
+
+Records are objects with name, code, type and value: boolean, number, string of "ustr"
+and hex of data of "blob" and "type".
+"""
+import json
+import sys
+
+import ds_store.store
+from ds_store import DSStore, DSStoreEntry
+
+# raw data of blobs is compared, so codecs of known structure IDs are disabled
+getattr(ds_store.store, "codecs", {}).clear()
+
+
+def to_json(entry):
+    value = entry.value
+    if isinstance(value, bytes):
+        value = value.hex()
+    return {"name": entry.filename, "code": entry.code.decode("latin-1"),
+            "type": entry.type.decode("latin-1"), "value": value}
+
+
+def from_json(record):
+    value = record["value"]
+    if record["type"] in ("blob", "type"):
+        value = bytes.fromhex(value)
+    return DSStoreEntry(record["name"], record["code"].encode("latin-1"), record["type"].encode("latin-1"), value)
+
+
+def main():
+    command, path = sys.argv[1], sys.argv[2]
+    if command == "read":
+        with DSStore.open(path, "r") as store:
+            json.dump([to_json(entry) for entry in store], sys.stdout)
+    elif command == "write":
+        records = json.load(sys.stdin)
+        with DSStore.open(path, "w+") as store:
+            for record in records:
+                store.insert(from_json(record))
+    else:
+        sys.exit("unknown command " + command)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,61 @@
+package dsstore
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// TimelineEvent is a timestamp found in a store
+type TimelineEvent struct {
+	Source   string    `json:"source"` // path or URL of the store
+	FileName string    `json:"filename"`
+	Code     string    `json:"code"`
+	Kind     string    `json:"kind"` // "record", "bookmark", "bookmark volume", "alias" or "alias volume"
+	Time     time.Time `json:"time"`
+}
+
+// Events returns all timestamps of the store: times of "dutc" records, creation times of files
+// and volumes of bookmarks and aliases embedded in blob records. Events are in the order of records.
+func (s *Store) Events(source string) []TimelineEvent {
+	var events []TimelineEvent
+	add := func(r Record, kind string, t time.Time) {
+		if !t.IsZero() {
+			events = append(events, TimelineEvent{Source: source, FileName: r.FileName, Code: r.Code(), Kind: kind, Time: t})
+		}
+	}
+	for _, r := range s.Records {
+		if t, ok := r.Time(); ok {
+			add(r, "record", t)
+		}
+		if r.Type != "blob" {
+			continue
+		}
+		for _, data := range embeddedData(r) {
+			if b, err := DecodeBookmark(data); err == nil {
+				add(r, "bookmark", b.Created)
+				add(r, "bookmark volume", b.VolumeCreated)
+			} else if a, err := DecodeAlias(data); err == nil {
+				add(r, "alias", a.Created)
+				add(r, "alias volume", a.VolumeCreated)
+			}
+		}
+	}
+	return events
+}
+
+// Timeline is events of many stores
+type Timeline []TimelineEvent
+
+// Add adds events of the store of the source
+func (t *Timeline) Add(source string, s *Store) {
+	*t = append(*t, s.Events(source)...)
+}
+
+// Sort sorts events by time, events of the same time by source, file name, structure ID and kind
+func (t Timeline) Sort() {
+	slices.SortStableFunc(t, func(a, b TimelineEvent) int {
+		return cmp.Or(a.Time.Compare(b.Time), cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.FileName, b.FileName), cmp.Compare(a.Code, b.Code), cmp.Compare(a.Kind, b.Kind))
+	})
+}
@@ -0,0 +1,28 @@
+package dsstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	s := &Store{}
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatal(err)
+	}
+	events := s.Events("a/.DS_Store")
+	if len(events) != 2 || events[0].Kind != "bookmark" || events[0].Code != "pBBk" || events[0].Source != "a/.DS_Store" ||
+		events[1].Kind != "bookmark volume" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	modified := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	other := &Store{Records: []Record{TimeRecord("b.txt", "moDD", modified)}}
+	var timeline Timeline
+	timeline.Add("a/.DS_Store", s)
+	timeline.Add("b/.DS_Store", other)
+	timeline.Sort()
+	if len(timeline) != 3 || !timeline[0].Time.Equal(modified) || timeline[0].FileName != "b.txt" || timeline[0].Kind != "record" ||
+		timeline[1].Kind != "bookmark" {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+}
@@ -0,0 +1,108 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/strongo/dsstore/btree"
+)
+
+// treeWalker traverses B-tree of records with limits, progress reporting and recovery of best-effort reading.
+// Records are returned in the same order as they are sorted in B-tree.
+type treeWalker struct {
+	s     *Store
+	w     *btree.Walker[Record]
+	nodes int // count of loaded nodes
+}
+
+func (s *Store) newTreeWalker(root uint32, fetch func(node uint32) (*bytes.Buffer, error)) *treeWalker {
+	opts := s.opts.withDefaults()
+	tw := &treeWalker{s: s}
+	tw.w = btree.NewWalker(root, func(node uint32, depth int) (*bytes.Buffer, error) {
+		if err := s.ctxErr(); err != nil {
+			return nil, err
+		}
+		tw.nodes++
+		if tw.nodes > opts.MaxNodes {
+			return nil, fmt.Errorf("%w: more than %d nodes", ErrLimitExceeded, opts.MaxNodes)
+		}
+		s.debug("visit node", "node", node, "depth", depth)
+		block, err := fetch(node)
+		if err != nil || block == nil {
+			return nil, err
+		}
+		s.progress.NodesParsed++
+		s.reportProgress()
+		return block, nil
+	}, s.readParseFile)
+	tw.w.Done = func(node uint32, b *bytes.Buffer) {
+		s.checkPadding(b, node)
+	}
+	tw.w.MaxDepth = opts.MaxDepth
+	return tw
+}
+
+// next returns the next record in-order, false is returned when all records are read
+func (tw *treeWalker) next() (Record, bool, error) {
+	for tw.w.More() {
+		node := tw.w.Node()
+		r, ok, err := tw.w.Step()
+		if err = tw.s.recover(node, err); err != nil {
+			return Record{}, false, err
+		}
+		if ok {
+			return r, true, nil
+		}
+	}
+	return Record{}, false, nil
+}
+
+// lookup searches the record by key walking B-tree from the root using key comparisons,
+// so only nodes on the path to the record are read
+func (s *Store) lookup(root uint32, fetch func(node uint32) (*bytes.Buffer, error), name, code string) (Record, bool, error) {
+	return btree.Search(root, fetch, s.readParseFile, func(r Record) int {
+		return compareKeys(name, code, r.FileName, r.Code())
+	}, s.opts.withDefaults().MaxDepth)
+}
+
+// Page sizes of B-tree nodes, see WriteOptions.PageSize
+const (
+	DefaultPageSize = 0x1000 // size of B-tree node block used by Finder
+	MinPageSize     = 64
+	MaxPageSize     = 1 << 20
+)
+
+// validPageSize reports whether the page size is a power of 2 from MinPageSize to MaxPageSize
+func validPageSize(pageSize int) bool {
+	return pageSize >= MinPageSize && pageSize <= MaxPageSize && pageSize&(pageSize-1) == 0
+}
+
+// treeNode is B-tree node of encoded records prepared for writing
+type treeNode = btree.Node[[]byte]
+
+// buildTree splits sorted encoded records into B-tree nodes fitting into pageSize
+// with at most fanout records, see btree.Build
+func buildTree(records [][]byte, pageSize, fanout int, index func(n int) uint32) ([]treeNode, int) {
+	return btree.Build(records, func(r []byte) int { return len(r) }, pageSize, fanout, index)
+}
+
+// RecordCodec encodes and decodes records of B-tree nodes, with CompareRecords it lets btree.Tree
+// update the tree of records in place
+type RecordCodec struct{}
+
+// Append appends the record encoded like Write encodes it
+func (RecordCodec) Append(b []byte, r Record) []byte {
+	buf := bytes.NewBuffer(b)
+	_ = (&Store{}).writeRecord(buf, r)
+	return buf.Bytes()
+}
+
+// Decode decodes the record with default limits
+func (RecordCodec) Decode(b *bytes.Buffer) (Record, error) {
+	return (&Store{}).readParseFile(b)
+}
+
+// CompareRecords compares keys of records in the order Finder sorts them in B-tree
+func CompareRecords(a, b Record) int {
+	return compareRecords(a, b)
+}
@@ -0,0 +1,145 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/strongo/dsstore/btree"
+	"github.com/strongo/dsstore/buddy"
+)
+
+// chainTree creates B-tree where each internal node has only the rightmost child
+// and the last leaf node has one record
+func chainTree(nodes int) ([]byte, []uint32) {
+	offsets := []uint32{0}
+	fileData := make([]byte, 4+32*(nodes+1))
+	for node := 1; node <= nodes; node++ {
+		offsets = append(offsets, uint32(32*node+5))
+		if node < nodes {
+			binary.BigEndian.PutUint32(fileData[4+32*node:], uint32(node+1)) // rightmost
+			continue
+		}
+		binary.BigEndian.PutUint32(fileData[8+32*node:], 1)  // count
+		binary.BigEndian.PutUint32(fileData[12+32*node:], 1) // lenBytes
+		fileData[17+32*node] = 65                            // "A"
+		copy(fileData[22+32*node:], "bool")
+	}
+	return fileData, offsets
+}
+
+func TestTreeWalkerDeep(t *testing.T) {
+	fileData, offsets := chainTree(100000)
+	s := &Store{opts: ReadOptions{MaxDepth: 200000, MaxNodes: 200000}}
+	if err := s.readParseData(fileData, offsets, 1); err != nil {
+		t.Fatalf("readParseData failed: %v", err)
+	}
+	if len(s.Records) != 1 || s.Records[0].FileName != "A" {
+		t.Errorf("unexpected records %v", s.Records)
+	}
+}
+
+func TestTreeWalkerNodeBudget(t *testing.T) {
+	fileData, offsets := chainTree(100)
+	s := &Store{opts: ReadOptions{MaxNodes: 50}}
+	err := s.readParseData(fileData, offsets, 1)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+// threeNodeTree creates B-tree with root node 1 having record "C",
+// child node 2 having record "B" and rightmost child node 3 having record "D"
+func threeNodeTree() ([]byte, []uint32) {
+	offsets := []uint32{0, 32 + 5, 64 + 5, 96 + 5} // size 32
+	fileData := make([]byte, 256)
+	record := func(at int, name byte) {
+		binary.BigEndian.PutUint32(fileData[at:], 1) // lenBytes
+		fileData[at+5] = name
+		copy(fileData[at+6:], "dscl")
+		copy(fileData[at+10:], "bool")
+		fileData[at+14] = 1
+	}
+	binary.BigEndian.PutUint32(fileData[36:], 3) // rightmost
+	binary.BigEndian.PutUint32(fileData[40:], 1) // count
+	binary.BigEndian.PutUint32(fileData[44:], 2) // child
+	record(48, 'C')
+	binary.BigEndian.PutUint32(fileData[72:], 1) // count
+	record(76, 'B')
+	binary.BigEndian.PutUint32(fileData[104:], 1) // count
+	record(108, 'D')
+	return fileData, offsets
+}
+
+func TestLookup(t *testing.T) {
+	fileData, offsets := threeNodeTree()
+	s := &Store{}
+	tests := []struct {
+		name  string
+		code  string
+		found bool
+		nodes int
+	}{
+		{"C", "dscl", true, 1},
+		{"b", "dscl", true, 2},
+		{"D", "dscl", true, 2},
+		{"D", "Iloc", false, 2},
+		{"A", "dscl", false, 2},
+		{"E", "dscl", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+tt.code, func(t *testing.T) {
+			nodes := 0
+			r, ok, err := s.lookup(1, func(node uint32) (*bytes.Buffer, error) {
+				nodes++
+				return s.readNodeBlock(bytesSource(fileData), offsets, node)
+			}, tt.name, tt.code)
+			if err != nil {
+				t.Fatalf("lookup failed: %v", err)
+			}
+			if ok != tt.found {
+				t.Fatalf("expected found %v, got %v", tt.found, ok)
+			}
+			if ok && !strings.EqualFold(r.FileName, tt.name) {
+				t.Errorf("unexpected record %v", r)
+			}
+			if nodes != tt.nodes {
+				t.Errorf("expected %d nodes to be read, got %d", tt.nodes, nodes)
+			}
+		})
+	}
+}
+
+func TestRecordCodec(t *testing.T) {
+	var s Store
+	if err := s.ReadFile("testdata/00.DS_Store"); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	f := buddy.New()
+	tree, err := btree.Create(btree.FilePager{File: f}, btree.Codec[Record](RecordCodec{}), CompareRecords, DefaultPageSize)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, r := range slices.Backward(s.Records) {
+		if err = tree.Insert(r); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	var records []Record
+	if err = tree.Walk(func(r Record) error {
+		records = append(records, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if !slices.EqualFunc(records, s.sortedRecords(), Record.Equal) {
+		t.Errorf("expected sorted records, got %v", records)
+	}
+	r, ok, err := tree.Get(Record{FileName: "applications", Extra: s.Records[4].Extra})
+	if err != nil || !ok || !r.Equal(s.Records[4]) {
+		t.Errorf("expected record %v, got %v, %v, %v", s.Records[4], r, ok, err)
+	}
+}
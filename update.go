@@ -0,0 +1,242 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/strongo/dsstore/btree"
+	"github.com/strongo/dsstore/buddy"
+)
+
+// updateChunk is granularity of comparing of old and new file data.
+// It is the minimal block size of buddy allocator, so blocks are never split between chunks.
+const updateChunk = 32
+
+// Updater writes the store to existing .DS_Store file in place,
+// writing only blocks which differ from the current file content
+type Updater struct {
+	rws     io.ReadWriteSeeker
+	Written int64 // bytes written by the last update
+}
+
+// NewUpdater creates Updater of .DS_Store file
+func NewUpdater(rws io.ReadWriteSeeker) *Updater {
+	return &Updater{rws: rws}
+}
+
+// Update writes changed blocks of the store to the file. Records are deleted, inserted and replaced
+// in B-tree of the current file by btree.Tree, so only nodes on paths to changed records, their split or
+// merged siblings and blocks of the allocator are written, grown nodes are reallocated by buddy allocator.
+// File which can't be read or has records with the same keys is rewritten. The header is written the last,
+// after blocks it points to are synced, and the file is synced before returning when it supports Sync() error.
+// Blocks changed in place can still be torn by a crash.
+// If the file becomes smaller, it is truncated when the file supports Truncate(size int64) error.
+func (u *Updater) Update(s *Store) error {
+	u.Written = 0
+	if _, err := u.rws.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	oldData, err := io.ReadAll(u.rws)
+	if err != nil {
+		return err
+	}
+	fileData, err := encodeUpdate(s, oldData)
+	if err != nil {
+		return err
+	}
+	// write changed ranges of chunks, the header goes the last
+	var header []int
+	for start := 0; start < len(fileData); {
+		end := chunkEnd(start, len(fileData))
+		if end <= len(oldData) && bytes.Equal(fileData[start:end], oldData[start:end]) {
+			start = end
+			continue
+		}
+		// extend the range while chunks differ
+		for end < len(fileData) {
+			next := chunkEnd(end, len(fileData))
+			if next <= len(oldData) && bytes.Equal(fileData[end:next], oldData[end:next]) {
+				break
+			}
+			end = next
+		}
+		if start < buddy.HeaderSize {
+			header = []int{start, min(end, buddy.HeaderSize)}
+		}
+		if from := max(start, buddy.HeaderSize); from < end {
+			if err = u.writeAt(fileData[from:end], int64(from)); err != nil {
+				return err
+			}
+		}
+		start = end
+	}
+	if header != nil {
+		if err = u.sync(); err != nil {
+			return err
+		}
+		if err = u.writeAt(fileData[header[0]:header[1]], int64(header[0])); err != nil {
+			return err
+		}
+	}
+	if len(fileData) < len(oldData) {
+		if t, ok := u.rws.(interface{ Truncate(size int64) error }); ok {
+			if err = t.Truncate(int64(len(fileData))); err != nil {
+				return err
+			}
+		}
+	}
+	if u.Written == 0 {
+		return nil
+	}
+	return u.sync()
+}
+
+// encodeUpdate returns file data of the store updating B-tree of the current file data in place,
+// the store is encoded anew when the current file can't be read or its tree can't be updated
+func encodeUpdate(s *Store, oldData []byte) ([]byte, error) {
+	if err := s.validateRecords(); err != nil {
+		return nil, err
+	}
+	// records are read with checks of the tree, so updates only follow valid paths
+	var current Store
+	if err := current.ReadWithOptions(bytes.NewReader(oldData), ReadOptions{}); err != nil {
+		return s.encode(WriteOptions{})
+	}
+	f, tree, dsdb, err := openTree(oldData)
+	if err != nil || !strictlySorted(current.Records) {
+		return s.encode(WriteOptions{})
+	}
+	records := s.sortedRecords()
+	if !strictlySorted(records) {
+		// the tree can't have records with the same keys
+		return s.encode(WriteOptions{})
+	}
+	tree.Count = len(current.Records)
+	if err = updateTree(tree, current.Records, records); err != nil {
+		return nil, err
+	}
+	block := make([]byte, 0, 20+len(s.DSDBExtra))
+	for _, v := range []uint32{tree.Root, uint32(tree.Levels), uint32(tree.Count), uint32(tree.Nodes), uint32(tree.PageSize)} {
+		block = binary.BigEndian.AppendUint32(block, v)
+	}
+	if err = tree.Pager.WriteBlock(dsdb, append(block, s.DSDBExtra...)); err != nil {
+		return nil, err
+	}
+	f.HeaderExtra, f.RootExtra = s.HeaderExtra, s.RootExtra
+	buf := new(bytes.Buffer)
+	if err = f.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// openTree opens B-tree of records of the file data stored in its buddy container
+func openTree(data []byte) (*buddy.File, *btree.Tree[Record], uint32, error) {
+	f, err := buddy.Read(data)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	dsdb, ok := f.Directory["DSDB"]
+	if !ok {
+		return nil, nil, 0, errors.New("no DSDB block")
+	}
+	block, err := f.Block(dsdb)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(block) < 20 {
+		return nil, nil, 0, errors.New("invalid DSDB block")
+	}
+	pageSize := int(binary.BigEndian.Uint32(block[16:]))
+	if pageSize < MinPageSize || pageSize > MaxPageSize {
+		return nil, nil, 0, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	tree := &btree.Tree[Record]{
+		Root:     binary.BigEndian.Uint32(block),
+		Levels:   int(binary.BigEndian.Uint32(block[4:])),
+		Count:    int(binary.BigEndian.Uint32(block[8:])),
+		Nodes:    int(binary.BigEndian.Uint32(block[12:])),
+		PageSize: pageSize,
+		Compare:  CompareRecords,
+		Codec:    RecordCodec{},
+		Pager:    btree.FilePager{File: f},
+	}
+	return f, tree, dsdb, nil
+}
+
+// updateTree deletes, inserts and replaces records of the tree, so it has the new records.
+// Both slices are sorted by keys, unchanged records aren't touched.
+func updateTree(tree *btree.Tree[Record], old, records []Record) error {
+	i, j := 0, 0
+	for i < len(old) || j < len(records) {
+		c := -1
+		if i == len(old) {
+			c = 1
+		} else if j < len(records) {
+			c = compareRecords(old[i], records[j])
+		}
+		switch {
+		case c < 0:
+			if _, err := tree.Delete(old[i]); err != nil {
+				return err
+			}
+			i++
+		case c > 0:
+			if err := tree.Insert(records[j]); err != nil {
+				return err
+			}
+			j++
+		default:
+			if !old[i].Equal(records[j]) {
+				if err := tree.Insert(records[j]); err != nil {
+					return err
+				}
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
+// strictlySorted reports whether records are sorted by keys without records of the same keys
+func strictlySorted(records []Record) bool {
+	for i := 1; i < len(records); i++ {
+		if compareRecords(records[i-1], records[i]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sync commits written data to storage when the file supports it
+func (u *Updater) sync() error {
+	if f, ok := u.rws.(interface{ Sync() error }); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+// chunkEnd returns end of the chunk starting at start.
+// Blocks are placed after 4 bytes of file prefix, so the first chunk includes the prefix.
+func chunkEnd(start, size int) int {
+	return min((start+updateChunk-4)/updateChunk*updateChunk+4, size)
+}
+
+func (u *Updater) writeAt(data []byte, offset int64) error {
+	if _, err := u.rws.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := u.rws.Write(data)
+	u.Written += int64(n)
+	return err
+}
+
+// Update writes the store to existing .DS_Store file in place, writing only changed blocks
+func (s *Store) Update(f *os.File) error {
+	return NewUpdater(f).Update(s)
+}
@@ -0,0 +1,192 @@
+package dsstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	tempFile := filepath.Join(t.TempDir(), "test.DS_Store")
+	if err := s.WriteFile(tempFile, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	f, err := os.OpenFile(tempFile, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+
+	u := NewUpdater(f)
+	if err = u.Update(&s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if u.Written != 0 {
+		t.Errorf("expected nothing written for unchanged store, got %d bytes", u.Written)
+	}
+
+	s.Records[len(s.Records)-1].Data[0] ^= 0xff
+	if err = u.Update(&s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if u.Written == 0 || u.Written > updateChunk*2 {
+		t.Errorf("expected only changed block to be written, got %d bytes", u.Written)
+	}
+
+	expected, err := s.encode(WriteOptions{})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	actual, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Error("updated file differs from written store")
+	}
+}
+
+func TestUpdateGrow(t *testing.T) {
+	// layout of the file written by Finder differs from layout of new files
+	data, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	tempFile := filepath.Join(t.TempDir(), "test.DS_Store")
+	if err = os.WriteFile(tempFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	var s Store
+	if err = s.ReadFile(tempFile); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	f, err := os.OpenFile(tempFile, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	// the grown record reallocates its node, other blocks stay in place
+	for i, r := range s.Records {
+		if r.Code() == "pBBk" {
+			s.Records[i].Data = append(r.Data, make([]byte, 200)...)
+			s.Records[i].DataLen += 200
+		}
+	}
+	u := NewUpdater(f)
+	if err = u.Update(&s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if u.Written == 0 || u.Written > info.Size()/10 {
+		t.Errorf("expected only changed blocks of %d bytes file to be written, got %d bytes", info.Size(), u.Written)
+	}
+	var s2 Store
+	if err = s2.ReadFile(tempFile); err != nil {
+		t.Fatalf("ReadFile of updated file failed: %v", err)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Fatalf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	for i := range s.Records {
+		if !s.Records[i].Equal(s2.Records[i]) {
+			t.Errorf("record %d differs", i)
+		}
+	}
+	if err = s2.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+}
+
+func TestUpdateTree(t *testing.T) {
+	s := &Store{}
+	for i := range 1000 {
+		s.Records = append(s.Records, TextRecord(fmt.Sprintf("file%04d", i), "cmmt", "comment"))
+	}
+	tempFile := filepath.Join(t.TempDir(), "test.DS_Store")
+	if err := s.WriteFile(tempFile, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	f, err := os.OpenFile(tempFile, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	// a changed record rewrites its leaf node only
+	s.Records[500] = TextRecord("file0500", "cmmt", "changed")
+	u := NewUpdater(f)
+	if err = u.Update(s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if u.Written == 0 || u.Written > DefaultPageSize {
+		t.Errorf("expected only the leaf node of %d bytes file to be written, got %d bytes", info.Size(), u.Written)
+	}
+
+	// deleted and inserted records split and merge nodes
+	s.Records = slices.Delete(s.Records, 100, 700)
+	for i := range 300 {
+		s.Records = append(s.Records, TextRecord(fmt.Sprintf("new%04d", i), "cmmt", "new comment"))
+	}
+	if err = u.Update(s); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	var s2 Store
+	if err = s2.ReadFile(tempFile); err != nil {
+		t.Fatalf("ReadFile of updated file failed: %v", err)
+	}
+	if !slices.EqualFunc(s2.Records, s.sortedRecords(), Record.Equal) {
+		t.Errorf("expected %d records of the store, got %d", len(s.Records), len(s2.Records))
+	}
+	if err = s2.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+	if g, _ := s2.Geometry(); g.Depth != 2 {
+		t.Errorf("expected 2 levels of nodes, got %+v", g)
+	}
+}
+
+func TestUpdateShrink(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	tempFile := filepath.Join(t.TempDir(), "test.DS_Store")
+	if err := os.WriteFile(tempFile, bytes.Repeat([]byte{1}, 1<<15), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	f, err := os.OpenFile(tempFile, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+
+	if err = s.Update(f); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	var s2 Store
+	if err = s2.ReadFile(tempFile); err != nil {
+		t.Fatalf("ReadFile of updated file failed: %v", err)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	if len(s2.Trailing()) != 0 {
+		t.Errorf("expected file to be truncated, got %d trailing bytes", len(s2.Trailing()))
+	}
+}
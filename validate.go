@@ -0,0 +1,156 @@
+package dsstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxFileNameLen is the maximal length of file names of records in UTF-16 code units, the limit of HFS+ and APFS
+const MaxFileNameLen = 255
+
+// ErrInvalidFileName is returned for file names which can't be names of files on HFS+ and APFS volumes
+var ErrInvalidFileName = errors.New("invalid file name")
+
+// ErrInvalidRecord is returned by writing of records which can't be encoded, it wraps *RecordError
+var ErrInvalidRecord = errors.New("invalid record")
+
+// RecordError is an error related to a record of the store
+type RecordError struct {
+	Index    int    // index of the record in Store.Records
+	FileName string // file name of the record
+	Code     string // structure ID of the record
+	Err      error  // the error
+}
+
+// Error returns the error with identification of the record
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d (%q, %q): %v", e.Index, e.FileName, e.Code, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError is a problem of a field of a record
+type FieldError struct {
+	Field string // name of the field of Record, like "FileName" or "DataLen"
+	Err   error  // the problem
+}
+
+// Error returns the problem with the name of the field
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the problem
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError returns *FieldError of the field with formatted problem
+func fieldError(field, format string, args ...any) error {
+	return &FieldError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// typeSizes are data sizes of fixed-size record types
+var typeSizes = map[string]int{
+	"bool": 1,
+	"type": 4,
+	"long": 4,
+	"shor": 4,
+	"comp": 8,
+	"dutc": 8,
+}
+
+// Validate checks that the record can be written. All found problems are joined into one error,
+// each problem is *FieldError telling the invalid field.
+func (r Record) Validate() error {
+	var errs []error
+	if err := ValidateFileName(r.FileName); err != nil {
+		errs = append(errs, &FieldError{Field: "FileName", Err: err})
+	}
+	if size, ok := typeSizes[r.Type]; ok {
+		if r.DataLen != 0 {
+			errs = append(errs, fieldError("DataLen", "DataLen must be 0 for type %q", r.Type))
+		}
+		if len(r.Data) != size {
+			errs = append(errs, fieldError("Data", "data of type %q must have %d bytes, got %d", r.Type, size, len(r.Data)))
+		}
+	} else {
+		switch r.Type {
+		case "blob":
+			if r.DataLen == 0 {
+				errs = append(errs, fieldError("DataLen", "DataLen of blob must not be 0"))
+			}
+			if uint64(len(r.Data)) != uint64(r.DataLen) {
+				errs = append(errs, fieldError("Data", "blob has %d bytes of data, but DataLen is %d", len(r.Data), r.DataLen))
+			}
+		case "ustr":
+			if r.DataLen == 0 {
+				errs = append(errs, fieldError("DataLen", "DataLen of ustr must not be 0"))
+			}
+			if uint64(len(r.Data)) != 2*uint64(r.DataLen) {
+				errs = append(errs, fieldError("Data", "ustr has %d bytes of data, but DataLen is %d characters", len(r.Data), r.DataLen))
+			}
+		default:
+			errs = append(errs, fieldError("Type", "unknown type %q", r.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateFileName checks that the name can be a name of a file on HFS+ and APFS volumes: it is valid UTF-8
+// of at most MaxFileNameLen UTF-16 code units without NUL and "/" characters and it isn't "..".
+// Name "." is valid, it's the file name of records of the folder itself.
+func ValidateFileName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: file name is empty", ErrInvalidFileName)
+	case name == "..":
+		return fmt.Errorf("%w: file name %q is reserved", ErrInvalidFileName, name)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w: file name %q is not valid UTF-8", ErrInvalidFileName, name)
+	case strings.ContainsRune(name, 0):
+		return fmt.Errorf("%w: file name %q has NUL character", ErrInvalidFileName, name)
+	case strings.ContainsRune(name, '/'):
+		return fmt.Errorf("%w: file name %q has path separator", ErrInvalidFileName, name)
+	}
+	if n := utf16Len(name); n > MaxFileNameLen {
+		return fmt.Errorf("%w: file name of %d UTF-16 code units is longer than %d", ErrInvalidFileName, n, MaxFileNameLen)
+	}
+	return nil
+}
+
+// utf16Len returns length of the string in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, c := range s {
+		n += utf16.RuneLen(c)
+	}
+	return n
+}
+
+// Validate checks all records of the store, allocation and order of records of read .DS_Store.
+// All found problems are joined into one error, problems of records are reported as *RecordError
+// and records read out of canonical order are reported as ErrNonCanonicalOrder.
+func (s *Store) Validate() error {
+	var errs []error
+	for i, r := range s.Records {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, &RecordError{Index: i, FileName: r.FileName, Code: r.Code(), Err: err})
+		}
+	}
+	if s.alloc.read {
+		if err := s.ValidateFreeList(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.orderErr != nil {
+		errs = append(errs, s.orderErr)
+	}
+	return errors.Join(errs...)
+}
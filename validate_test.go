@@ -0,0 +1,83 @@
+package dsstore
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("expected valid store, got %v", err)
+	}
+
+	s.Records = append(s.Records,
+		Record{FileName: "", Type: "bool", Data: []byte{1}},
+		Record{FileName: "a", Type: "long", DataLen: 4, Data: []byte{1}},
+		Record{FileName: "b", Type: "blob", DataLen: 2, Data: []byte{1}},
+		Record{FileName: "c", Type: "ustr", DataLen: 0},
+		Record{FileName: "d", Type: "xxxx"},
+	)
+	s.alloc.freeLists[5] = nil
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T", err)
+	}
+	errs := joined.Unwrap()
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors, got %d: %v", len(errs), err)
+	}
+	var recordErr *RecordError
+	if !errors.As(errs[1], &recordErr) || recordErr.Index != 7 || recordErr.FileName != "a" {
+		t.Errorf("unexpected record error %v", errs[1])
+	}
+	if !strings.Contains(errs[1].Error(), "DataLen must be 0") || !strings.Contains(errs[1].Error(), "must have 4 bytes") {
+		t.Errorf("expected all problems of the record, got %v", errs[1])
+	}
+	if !strings.Contains(errs[5].Error(), "gap from 0x20 to 0x40") {
+		t.Errorf("expected free list error, got %v", errs[5])
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	s := &Store{Records: []Record{
+		{FileName: "b", Type: "bool", Data: []byte{1}},
+		{FileName: "a", Type: "bool", Data: []byte{1}},
+	}}
+	if err := s.Validate(); err != nil {
+		t.Errorf("expected order of records which aren't read not to be checked, got %v", err)
+	}
+	s.checkOrder()
+	err := s.Validate()
+	if !errors.Is(err, ErrNonCanonicalOrder) || !strings.Contains(err.Error(), `record 1 ("a", "\x00\x00\x00\x00")`) {
+		t.Errorf("expected ErrNonCanonicalOrder, got %v", err)
+	}
+	s.Records[0].FileName = "\U0001F600"
+	s.Records[1].FileName = "\uFF01"
+	if s.checkOrder(); s.Validate() != nil {
+		t.Errorf("expected order of Finder to be canonical, got %v", s.Validate())
+	}
+}
+
+func TestValidateFileName(t *testing.T) {
+	for _, name := range []string{".", "a", ".hidden", "a:b", strings.Repeat("\U0001F600", MaxFileNameLen/2) + "a"} {
+		if err := ValidateFileName(name); err != nil {
+			t.Errorf("expected valid name %q, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "..", "a/b", "a\x00b", "a\xffb", strings.Repeat("a", MaxFileNameLen+1),
+		strings.Repeat("\U0001F600", MaxFileNameLen/2+1)} {
+		if err := ValidateFileName(name); !errors.Is(err, ErrInvalidFileName) {
+			t.Errorf("expected ErrInvalidFileName for %q, got %v", name, err)
+		}
+	}
+}
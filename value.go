@@ -0,0 +1,56 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Value decodes data of the record by its type: "bool" is bool, "long" and "shor" are int32, "comp" is int64,
+// "dutc" is time.Time, "type" is string of 4 characters, "ustr" is string. "blob" is decoded binary property list
+// (map[string]any, []any, string, int64, float64, bool or []byte) or []byte when it isn't a property list.
+// Records of structure IDs with registered codecs are decoded by the codecs.
+func (r Record) Value() (any, error) {
+	if codec, ok := LookupCodec(r.Code()); ok {
+		return codec.Decode(r)
+	}
+	if size, ok := typeSizes[r.Type]; ok && len(r.Data) != size {
+		return nil, fmt.Errorf("data of type %q must have %d bytes, got %d", r.Type, size, len(r.Data))
+	}
+	switch r.Type {
+	case "bool":
+		return r.Data[0] != 0, nil
+	case "long", "shor":
+		return int32(binary.BigEndian.Uint32(r.Data)), nil
+	case "comp":
+		return int64(binary.BigEndian.Uint64(r.Data)), nil
+	case "dutc":
+		t, _ := r.Time()
+		return t, nil
+	case "type":
+		return string(r.Data), nil
+	case "ustr":
+		text, ok := r.Text()
+		if !ok {
+			return nil, fmt.Errorf("ustr has odd count of %d bytes", len(r.Data))
+		}
+		return text, nil
+	case "blob":
+		if bytes.HasPrefix(r.Data, bplistHeader) {
+			return decodePlist(r.Data)
+		}
+		return r.Data, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", r.Type)
+	}
+}
+
+// TimeRecord returns "dutc" record of the file with the structure ID and the time
+func TimeRecord(fileName, code string, t time.Time) Record {
+	d := t.Sub(epoch1904)
+	v := uint64(d/time.Second)<<16 | uint64(d%time.Second)<<16/uint64(time.Second)
+	r := Record{FileName: fileName, Type: "dutc", Data: binary.BigEndian.AppendUint64(nil, v)}
+	r.SetCode(code)
+	return r
+}
@@ -0,0 +1,59 @@
+package dsstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordValue(t *testing.T) {
+	date := time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)
+	for _, test := range []struct {
+		r        Record
+		expected any
+	}{
+		{Record{Type: "bool", Data: []byte{1}}, true},
+		{Record{Type: "long", Data: []byte{0xff, 0xff, 0xff, 0xfe}}, int32(-2)},
+		{Record{Type: "shor", Data: []byte{0, 0, 0, 7}}, int32(7)},
+		{Record{Type: "comp", Data: []byte{0, 0, 0, 0, 0, 0, 1, 0}}, int64(256)},
+		{dutcRecord("a", date), date.Add(time.Second / 2)},
+		{Record{Type: "type", Data: []byte("icnv")}, "icnv"},
+		{TextRecord("a", "cmmt", "comment"), "comment"},
+		{Record{Type: "blob", DataLen: 2, Data: []byte{1, 2}}, "\x01\x02"},
+	} {
+		v, err := test.r.Value()
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if err != nil || v != test.expected {
+			t.Errorf("%s: expected %v, got %v: %v", test.r.Type, test.expected, v, err)
+		}
+	}
+	for _, r := range []Record{{Type: "long", Data: []byte{1}}, {Type: "ustr", Data: []byte{1}}, {Type: "none"}} {
+		if _, err := r.Value(); err == nil {
+			t.Errorf("expected error for %+v", r)
+		}
+	}
+
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, r := range s.Records {
+		if r.Code() != "bwsp" {
+			continue
+		}
+		v, err := r.Value()
+		if dict, ok := v.(map[string]any); err != nil || !ok || dict["WindowBounds"] != "{{200, 458}, {360, 222}}" {
+			t.Errorf("unexpected bwsp value %v: %v", v, err)
+		}
+	}
+}
+
+func TestTimeRecord(t *testing.T) {
+	expected := time.Date(2024, time.February, 29, 23, 59, 58, int(time.Second/4), time.UTC)
+	r := TimeRecord("a", "modD", expected)
+	if got, ok := r.Time(); !ok || !got.Equal(expected) || r.Code() != "modD" || r.Validate() != nil {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
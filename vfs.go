@@ -0,0 +1,114 @@
+package dsstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+)
+
+// WriteFS is a file system with writable files. Write-capable virtual file systems,
+// like afero.Fs or billy.Filesystem, are adapted to it by modules aferofs and billyfs.
+// Names are slash-separated like names of fs.FS.
+type WriteFS interface {
+	fs.FS
+	OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error)
+	Remove(name string) error
+	Rename(oldName, newName string) error
+}
+
+// WriteFS writes .DS_Store to the file of the file system
+func (s *Store) WriteFS(fsys WriteFS, name string, perm fs.FileMode) error {
+	return s.WriteFSWithOptions(fsys, name, perm, WriteOptions{})
+}
+
+// WriteFSWithOptions writes .DS_Store to the file of the file system using options.
+// Locking and PreserveMetadata options are not supported by file systems and are ignored.
+func (s *Store) WriteFSWithOptions(fsys WriteFS, name string, perm fs.FileMode, opts WriteOptions) error {
+	e := getEncoder()
+	defer putEncoder(e)
+	fileData, err := e.EncodeWithOptions(s, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Atomic {
+		err = writeFSAtomic(fsys, name, fileData, perm)
+	} else {
+		err = writeFS(fsys, name, fileData, perm, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	}
+	if err != nil {
+		return err
+	}
+	e.progress.BytesWritten = int64(len(fileData))
+	e.reportProgress()
+	return nil
+}
+
+// writeFS writes data to the file opened with the flags
+func writeFS(fsys WriteFS, name string, data []byte, perm fs.FileMode, flag int) error {
+	f, err := fsys.OpenFile(name, flag, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFSAtomic writes data to a temporary file and renames it over the file
+func writeFSAtomic(fsys WriteFS, name string, data []byte, perm fs.FileMode) error {
+	dir, base := path.Split(name)
+	tmpName := fmt.Sprintf("%s.%s.%d.tmp", dir, base, rand.Uint32())
+	if err := writeFS(fsys, tmpName, data, perm, os.O_WRONLY|os.O_CREATE|os.O_EXCL); err != nil {
+		_ = fsys.Remove(tmpName)
+		return err
+	}
+	if err := fsys.Rename(tmpName, name); err != nil {
+		_ = fsys.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// CleanFS is Clean for the file tree rooted at root of the file system
+func CleanFS(fsys WriteFS, root string, opts CleanOptions) (CleanSummary, error) {
+	return CleanFSContext(context.Background(), fsys, root, opts)
+}
+
+// CleanFSContext is CleanFS stopping when the context is done
+func CleanFSContext(ctx context.Context, fsys WriteFS, root string, opts CleanOptions) (CleanSummary, error) {
+	return clean(opts, cleanTarget{
+		walk: func(fn WalkFunc) error {
+			return WalkFSContext(ctx, fsys, root, ReadOptions{BestEffort: true}, fn)
+		},
+		stat: func(name string) (fs.FileInfo, error) {
+			return fs.Stat(fsys, name)
+		},
+		rel: func(name string) (string, error) {
+			return relPath(root, name)
+		},
+		remove: fsys.Remove,
+		write: func(name string, s *Store, perm fs.FileMode) error {
+			return s.WriteFSWithOptions(fsys, name, perm, WriteOptions{Atomic: true})
+		},
+	})
+}
+
+// relPath returns slash-separated path of the name relative to the root of fs.FS
+func relPath(root, name string) (string, error) {
+	if root == "." {
+		return name, nil
+	}
+	if name == root {
+		return ".", nil
+	}
+	if len(name) > len(root) && name[:len(root)] == root && name[len(root)] == '/' {
+		return name[len(root)+1:], nil
+	}
+	return "", fmt.Errorf("%s is not in %s", name, root)
+}
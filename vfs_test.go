@@ -0,0 +1,98 @@
+package dsstore
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// dirFS is WriteFS of OS directory
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func newDirFS(dir string) dirFS {
+	return dirFS{os.DirFS(dir), dir}
+}
+
+func (d dirFS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(filepath.Join(d.dir, name), flag, perm)
+}
+
+func (d dirFS) Remove(name string) error {
+	return os.Remove(filepath.Join(d.dir, name))
+}
+
+func (d dirFS) Rename(oldName, newName string) error {
+	return os.Rename(filepath.Join(d.dir, oldName), filepath.Join(d.dir, newName))
+}
+
+func TestWriteFS(t *testing.T) {
+	dir := t.TempDir()
+	fsys := newDirFS(dir)
+	if err := os.Mkdir(filepath.Join(dir, "a"), 0o755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	r := Record{FileName: "file", Type: "long", Data: []byte{0, 0, 0, 1}}
+	r.SetCode("Iloc")
+	s := &Store{Records: []Record{r}}
+	for _, opts := range []WriteOptions{{}, {Atomic: true}} {
+		if err := s.WriteFSWithOptions(fsys, "a/.DS_Store", 0o644, opts); err != nil {
+			t.Fatalf("WriteFS failed: %v", err)
+		}
+		var read Store
+		if err := read.ReadFS(fsys, "a/.DS_Store"); err != nil {
+			t.Fatalf("ReadFS failed: %v", err)
+		}
+		if len(read.Records) != 1 || !read.Records[0].Equal(r) {
+			t.Errorf("unexpected records %v", read.Records)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "a"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected no temporary files, got %v: %v", entries, err)
+	}
+	if err = s.WriteFS(fsys, "missing/.DS_Store", 0o644); err == nil {
+		t.Error("expected error for missing directory")
+	}
+	if err = s.WriteFSWithOptions(fsys, "missing/.DS_Store", 0o644, WriteOptions{Atomic: true}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestCleanFS(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, walkTestFiles(t))
+	fsys := newDirFS(dir)
+	summary, err := CleanFS(fsys, "a", CleanOptions{Exclude: []string{"b/*"}})
+	if err != nil {
+		t.Fatalf("CleanFS failed: %v", err)
+	}
+	if !slices.Equal(summary.Paths, []string{"a/.DS_Store"}) {
+		t.Errorf("unexpected cleaned files %v", summary.Paths)
+	}
+	summary, err = CleanFS(fsys, ".", CleanOptions{Scrub: func(r Record) bool { return r.FileName != "." }})
+	if err == nil || !slices.Equal(summary.Paths, []string{".DS_Store"}) {
+		t.Errorf("unexpected scrubbed files %v: %v", summary.Paths, err)
+	}
+	if got := existing(t, dir); !slices.Equal(got, []string{".DS_Store", "a/b/.DS_Store", "c/.DS_Store"}) {
+		t.Errorf("unexpected files %v", got)
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	for _, test := range [][3]string{
+		{".", "a/b", "a/b"},
+		{"a", "a", "."},
+		{"a", "a/b", "b"},
+		{"a", "ab/c", ""},
+	} {
+		if rel, _ := relPath(test[0], test[1]); rel != test[2] {
+			t.Errorf("relPath(%q, %q): expected %q, got %q", test[0], test[1], test[2], rel)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package dsstore
+
+import "slices"
+
+// viewSettingsCodes are structure IDs of records of the folder itself (file name ".") which are its view settings:
+// window, view options of all view styles and background. Scroll positions depend on files of the folder, so
+// they are not view settings.
+var viewSettingsCodes = []string{
+	"bwsp", "fwi0", "fwsw", "fwvh", // window
+	"vstl", "icvp", "icvo", "ICVO", "icgo", "icvt", "lsvp", "lsvP", "lsvo", "LSVO", "lsvC", "lsvt", // view options
+	"BKGD", "pict", "pBBk", // background
+}
+
+// ViewSettings is a snapshot of view settings of a folder, records of the folder itself of viewSettingsCodes
+type ViewSettings struct {
+	Records []Record
+}
+
+// ExtractViewSettings returns view settings of the folder of the store: window, view options and background.
+// Records of the snapshot have own copies of data, so it outlives the store and can be applied to many stores.
+func ExtractViewSettings(s *Store) ViewSettings {
+	var settings ViewSettings
+	for _, r := range s.Records {
+		if r.FileName == "." && slices.Contains(viewSettingsCodes, r.Code()) {
+			settings.Records = append(settings.Records, r.Materialize())
+		}
+	}
+	return settings
+}
+
+// ApplyViewSettings replaces view settings of the folder of the store by the snapshot of ExtractViewSettings.
+// View settings of the store missing in the snapshot are removed, so stale variants like legacy "BKGD" don't
+// override applied ones. Records of files of the folder, like icon locations, are kept.
+func ApplyViewSettings(s *Store, settings ViewSettings) {
+	s.Records = slices.DeleteFunc(s.Records, func(r Record) bool {
+		return r.FileName == "." && slices.Contains(viewSettingsCodes, r.Code())
+	})
+	for _, r := range settings.Records {
+		s.Records = append(s.Records, r.Materialize())
+	}
+	s.mods++
+}
@@ -0,0 +1,44 @@
+package dsstore
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestViewSettings(t *testing.T) {
+	var src Store
+	if err := src.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	settings := ExtractViewSettings(&src)
+	var codes []string
+	for _, r := range settings.Records {
+		codes = append(codes, r.Code())
+	}
+	if len(codes) != 3 || codes[0] != "bwsp" || codes[1] != "icvp" || codes[2] != "pBBk" {
+		t.Errorf("expected bwsp, icvp and pBBk records, got %v", codes)
+	}
+
+	record := func(name, code string) Record {
+		r := Record{FileName: name, Type: "bool", Data: []byte{1}}
+		r.SetCode(code)
+		return r
+	}
+	dst := &Store{Records: []Record{record(".", "BKGD"), record(".", "vSrn"), record(".", "icsp"), record("a", "Iloc")}}
+	ApplyViewSettings(dst, settings)
+	if len(dst.Records) != 6 {
+		t.Fatalf("expected 6 records, got %v", dst.Records)
+	}
+	for _, r := range dst.Records {
+		if r.Code() == "BKGD" {
+			t.Error("expected view settings missing in the snapshot to be removed")
+		}
+	}
+	if got, err := dst.FolderSettings(); err != nil || got.IconView == nil || got.IconView.IconSize != 48 {
+		t.Errorf("expected icon view settings to be applied, got %v, %v", got.IconView, err)
+	}
+	settings.Records[0].Data[0] ^= 0xff
+	if dst.Records[3].Data[0] == settings.Records[0].Data[0] {
+		t.Error("expected applied records to have own data")
+	}
+}
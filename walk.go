@@ -0,0 +1,91 @@
+package dsstore
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// StoreFileName is the name of .DS_Store files
+const StoreFileName = ".DS_Store"
+
+// WalkFunc is called by Walk for every found .DS_Store file.
+// The store is nil when a directory or nothing of the file can be read, otherwise it has records
+// read leniently and err describes skipped parts of the file.
+// Returning fs.SkipDir skips the rest of the directory, fs.SkipAll stops walking without error,
+// any other error stops walking and is returned by Walk.
+type WalkFunc func(path string, s *Store, err error) error
+
+// Walk finds .DS_Store files in the file tree rooted at root and calls fn for each of them.
+// Files are read in best-effort mode, so damaged files give records of their readable nodes.
+// Symbolic links are not followed. ReadOptions.OnProgress of WalkWithOptions reports progress of every file.
+func Walk(root string, fn WalkFunc) error {
+	return WalkContext(context.Background(), root, ReadOptions{BestEffort: true}, fn)
+}
+
+// WalkWithOptions is Walk reading files using options
+func WalkWithOptions(root string, opts ReadOptions, fn WalkFunc) error {
+	return WalkContext(context.Background(), root, opts, fn)
+}
+
+// WalkContext is Walk reading files using options until the context is done.
+// It returns the context error when the context is done.
+func WalkContext(ctx context.Context, root string, opts ReadOptions, fn WalkFunc) error {
+	return walk(ctx, fn, func(walkFn fs.WalkDirFunc) error {
+		return filepath.WalkDir(root, walkFn)
+	}, func(path string, s *Store) error {
+		return s.readFile(ctx, path, opts)
+	})
+}
+
+// WalkFS is Walk for the file tree rooted at root of the file system
+func WalkFS(fsys fs.FS, root string, fn WalkFunc) error {
+	return WalkFSContext(context.Background(), fsys, root, ReadOptions{BestEffort: true}, fn)
+}
+
+// WalkFSContext is WalkFS reading files using options until the context is done
+func WalkFSContext(ctx context.Context, fsys fs.FS, root string, opts ReadOptions, fn WalkFunc) error {
+	return walk(ctx, fn, func(walkFn fs.WalkDirFunc) error {
+		return fs.WalkDir(fsys, root, walkFn)
+	}, func(path string, s *Store) error {
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		return s.ReadContextWithOptions(ctx, f, opts)
+	})
+}
+
+// walk calls fn for .DS_Store files found by walkDir, files are read by read
+func walk(ctx context.Context, fn WalkFunc, walkDir func(fs.WalkDirFunc) error,
+	read func(path string, s *Store) error) error {
+	return walkDir(func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// directory can't be read
+			return fn(path, nil, err)
+		}
+		if d.IsDir() || d.Name() != StoreFileName || !d.Type().IsRegular() {
+			return nil
+		}
+		s := &Store{}
+		err = read(path, s)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil && !s.readable() {
+			return fn(path, nil, err)
+		}
+		return fn(path, s, err)
+	})
+}
+
+// readable reports whether the store is read at least partially, so records of it can be used
+func (s *Store) readable() bool {
+	return len(s.Records) > 0 || s.truncErr != nil || len(s.readErrs) > 0
+}
@@ -0,0 +1,165 @@
+package dsstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// writeTree writes files of the map to the directory
+func writeTree(t *testing.T, dir string, files map[string][]byte) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+}
+
+func walkTestFiles(t *testing.T) map[string][]byte {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	s := &Store{}
+	s.Records = append(s.Records, Record{FileName: "a", Type: "blob", DataLen: 3000, Data: make([]byte, 3000)})
+	buf := new(bytes.Buffer)
+	if err = s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return map[string][]byte{
+		".DS_Store":           fileData,
+		"a/.DS_Store":         fileData,
+		"a/b/.DS_Store":       buf.Bytes()[:6000], // truncated
+		"a/b/file.txt":        []byte("text"),
+		"c/.DS_Store":         []byte("garbage"),
+		"c/d/.DS_Store.bak":   fileData,
+		"e/.DS_Store/ignored": fileData, // directory named like the store
+	}
+}
+
+type walked struct {
+	path    string
+	records int
+	store   bool
+	err     bool
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	files := walkTestFiles(t)
+	writeTree(t, dir, files)
+	var got []walked
+	err := Walk(dir, func(path string, s *Store, err error) error {
+		w := walked{path: filepath.ToSlash(path[len(dir):]), store: s != nil, err: err != nil}
+		if s != nil {
+			w.records = len(s.Records)
+		}
+		got = append(got, w)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	expected := []walked{
+		{path: "/.DS_Store", records: 6, store: true},
+		{path: "/a/.DS_Store", records: 6, store: true},
+		{path: "/a/b/.DS_Store", store: true, err: true},
+		{path: "/c/.DS_Store", err: true},
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, walkTestFiles(t))
+	var paths []string
+	err := Walk(dir, func(path string, s *Store, err error) error {
+		paths = append(paths, filepath.ToSlash(path[len(dir):]))
+		if path == filepath.Join(dir, "a", ".DS_Store") {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if !slices.Equal(paths, []string{"/.DS_Store", "/a/.DS_Store", "/c/.DS_Store"}) {
+		t.Errorf("unexpected paths %v", paths)
+	}
+	paths = nil
+	if err = Walk(dir, func(path string, s *Store, err error) error {
+		paths = append(paths, path)
+		return fs.SkipAll
+	}); err != nil || len(paths) != 1 {
+		t.Errorf("expected 1 path without error, got %v: %v", paths, err)
+	}
+	stop := errors.New("stop")
+	if err = Walk(dir, func(path string, s *Store, err error) error {
+		return stop
+	}); err != stop {
+		t.Errorf("expected stop error, got %v", err)
+	}
+	// missing root is reported to fn
+	missing := filepath.Join(dir, "missing")
+	if err = Walk(missing, func(path string, s *Store, err error) error {
+		if path != missing || s != nil || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("unexpected call for %s: %v", path, err)
+		}
+		return err
+	}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestWalkContext(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, walkTestFiles(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := WalkContext(ctx, dir, ReadOptions{}, func(path string, s *Store, err error) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected cancellation after 1 call, got %d calls: %v", calls, err)
+	}
+}
+
+func TestWalkFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, data := range walkTestFiles(t) {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	var got []walked
+	err := WalkFS(fsys, "a", func(path string, s *Store, err error) error {
+		w := walked{path: path, store: s != nil, err: err != nil}
+		if s != nil {
+			w.records = len(s.Records)
+		}
+		got = append(got, w)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkFS failed: %v", err)
+	}
+	expected := []walked{
+		{path: "a/.DS_Store", records: 6, store: true},
+		{path: "a/b/.DS_Store", store: true, err: true},
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
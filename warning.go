@@ -0,0 +1,59 @@
+package dsstore
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// WarningKind is a kind of non-fatal anomaly found in .DS_Store
+type WarningKind int
+
+// Kinds of warnings
+const (
+	WarningZeroOffset   WarningKind = iota // zero block offset is skipped
+	WarningFreeList                        // free list is inconsistent
+	WarningPadding                         // padding of block is not zeroed
+	WarningUnusedTopic                     // directory entry is not used
+	WarningTrailingData                    // data after the allocated region
+)
+
+var warningKindNames = map[WarningKind]string{
+	WarningZeroOffset:   "zero offset",
+	WarningFreeList:     "free list",
+	WarningPadding:      "padding",
+	WarningUnusedTopic:  "unused topic",
+	WarningTrailingData: "trailing data",
+}
+
+// String returns the name of the warning kind
+func (k WarningKind) String() string {
+	if name, ok := warningKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("warning(%d)", int(k))
+}
+
+// Warning about recoverable anomaly found while reading
+type Warning struct {
+	Kind    WarningKind // kind of anomaly
+	Message string      // human-readable description
+}
+
+// String returns the warning as text
+func (w Warning) String() string {
+	return w.Kind.String() + ": " + w.Message
+}
+
+// warn reports the warning to ReadOptions.OnWarning callback and ReadOptions.Logger if they are set
+func (s *Store) warn(kind WarningKind, format string, args ...any) {
+	if s.opts.OnWarning == nil && s.opts.Logger == nil {
+		return
+	}
+	w := Warning{Kind: kind, Message: fmt.Sprintf(format, args...)}
+	if s.opts.Logger != nil {
+		s.opts.Logger.Log(s.logContext(), slog.LevelWarn, w.Message, "kind", kind.String())
+	}
+	if s.opts.OnWarning != nil {
+		s.opts.OnWarning(w)
+	}
+}
@@ -0,0 +1,93 @@
+package dsstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+func TestWarningKindString(t *testing.T) {
+	if WarningPadding.String() != "padding" {
+		t.Errorf("unexpected name %q", WarningPadding.String())
+	}
+	if WarningKind(100).String() != "warning(100)" {
+		t.Errorf("unexpected name %q", WarningKind(100).String())
+	}
+	w := Warning{Kind: WarningFreeList, Message: "test"}
+	if w.String() != "free list: test" {
+		t.Errorf("unexpected warning text %q", w.String())
+	}
+}
+
+func TestReadNoWarnings(t *testing.T) {
+	var warnings []Warning
+	var s Store
+	err := s.ReadFileWithOptions(filepath.Join(".", "testdata", "00.DS_Store"), ReadOptions{
+		OnWarning: func(w Warning) { warnings = append(warnings, w) },
+	})
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestReadWarnings(t *testing.T) {
+	var warnings []Warning
+	s := &Store{opts: ReadOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }}}
+
+	// zero offset within count
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.BigEndian, uint32(2))
+	_ = binary.Write(buf, binary.BigEndian, uint32(0)) // dummy
+	_ = binary.Write(buf, binary.BigEndian, uint32(0x100b))
+	buf.Write(make([]byte, 4*255))
+	if _, err := s.readOffsets(buf); err != nil {
+		t.Fatalf("readOffsets failed: %v", err)
+	}
+
+	// unused topic
+	buf.Reset()
+	_ = binary.Write(buf, binary.BigEndian, uint32(1))
+	buf.WriteByte(4)
+	buf.WriteString("XXXX")
+	_ = binary.Write(buf, binary.BigEndian, uint32(1))
+	if _, err := s.readTopics(buf); err != nil {
+		t.Fatalf("readTopics failed: %v", err)
+	}
+
+	// not aligned free block
+	buf.Reset()
+	for i := 0; i < 32; i++ {
+		if i == 5 {
+			_ = binary.Write(buf, binary.BigEndian, uint32(1))
+			_ = binary.Write(buf, binary.BigEndian, uint32(0x21))
+		} else {
+			_ = binary.Write(buf, binary.BigEndian, uint32(0))
+		}
+	}
+	if err := s.readFreeBlocks(buf); err != nil {
+		t.Fatalf("readFreeBlocks failed: %v", err)
+	}
+
+	// not zeroed padding
+	fileData := make([]byte, 128)
+	binary.BigEndian.PutUint32(fileData[36:], 0) // nextNode
+	binary.BigEndian.PutUint32(fileData[40:], 0) // count
+	fileData[60] = 1
+	if err := s.readParseData(fileData, []uint32{0, 32 + 5}, 1); err != nil {
+		t.Fatalf("readParseData failed: %v", err)
+	}
+
+	expected := []WarningKind{WarningZeroOffset, WarningUnusedTopic, WarningFreeList, WarningPadding}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, kind := range expected {
+		if warnings[i].Kind != kind {
+			t.Errorf("expected warning %v, got %v", kind, warnings[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dsstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// EventKind is kind of change of watched .DS_Store
+type EventKind int
+
+// Kinds of watched .DS_Store changes
+const (
+	EventCreated  EventKind = iota // store is created
+	EventModified                  // records of the store are changed
+	EventRemoved                   // store is removed
+	EventError                     // store can't be read, like while it is written
+)
+
+var eventKindNames = [...]string{"created", "modified", "removed", "error"}
+
+// String returns name of the event kind
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+	return eventKindNames[k]
+}
+
+// Event is change of watched .DS_Store
+type Event struct {
+	Kind    EventKind
+	Path    string   // path of .DS_Store
+	Changes []Change // changes of records from the previous state of the store
+	Err     error    // error of reading for EventError
+}
+
+// Watch monitors .DS_Store of the directory and calls fn with the read store when its records change.
+// Changes are reported against the previous state of the store, so rewrites of the file
+// without changes of records are not reported. The store is nil for EventRemoved and EventError.
+// Watch blocks until the context is done and returns the context error.
+func Watch(ctx context.Context, dir string, fn func(event Event, s *Store)) error {
+	return watch(ctx, dir, fn, nil)
+}
+
+// watch is Watch calling ready when the directory is watched
+func watch(ctx context.Context, dir string, fn func(event Event, s *Store), ready func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+	// directory is watched, because Finder replaces the file
+	if err = watcher.Add(dir); err != nil {
+		return err
+	}
+	filePath := filepath.Join(dir, StoreFileName)
+	w := &storeWatcher{path: filePath, fn: fn}
+	w.prev, _ = w.read()
+	if ready != nil {
+		ready()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			if filepath.Base(event.Name) == StoreFileName {
+				w.update()
+			}
+		}
+	}
+}
+
+// storeWatcher reports changes of the watched store
+type storeWatcher struct {
+	path string
+	prev *Store // previous state of the store, nil when the store doesn't exist
+	fn   func(event Event, s *Store)
+}
+
+// read reads the store, it returns nil when the store doesn't exist
+func (w *storeWatcher) read() (*Store, error) {
+	s := &Store{}
+	if err := s.ReadFile(w.path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// update reads the store and reports its change
+func (w *storeWatcher) update() {
+	s, err := w.read()
+	switch {
+	case err != nil:
+		w.fn(Event{Kind: EventError, Path: w.path, Err: err}, nil)
+	case s == nil && w.prev == nil:
+	case s == nil:
+		w.fn(Event{Kind: EventRemoved, Path: w.path, Changes: Diff(w.prev, &Store{})}, nil)
+		w.prev = nil
+	case w.prev == nil:
+		w.fn(Event{Kind: EventCreated, Path: w.path, Changes: Diff(&Store{}, s)}, s)
+		w.prev = s
+	default:
+		if changes := Diff(w.prev, s); len(changes) > 0 {
+			w.fn(Event{Kind: EventModified, Path: w.path, Changes: changes}, s)
+		}
+		w.prev = s
+	}
+}
@@ -0,0 +1,86 @@
+package dsstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type watchEvent struct {
+	event Event
+	store *Store
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, StoreFileName)
+	write := func(records ...Record) {
+		if err := (&Store{Records: records}).WriteFileWithOptions(filePath, 0o644, WriteOptions{Atomic: true}); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	r1 := Record{FileName: "a", Type: "long", Data: []byte{0, 0, 0, 1}}
+	r1.SetCode("Iloc")
+	r2 := r1
+	r2.FileName = "b"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan watchEvent, 100)
+	ready := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- watch(ctx, dir, func(event Event, s *Store) {
+			events <- watchEvent{event, s}
+		}, func() {
+			close(ready)
+		})
+	}()
+	<-ready
+	next := func() watchEvent {
+		for {
+			select {
+			case e := <-events:
+				// partially written file can be reported, it is reported with error
+				if e.event.Kind != EventError {
+					return e
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for event")
+			}
+		}
+	}
+
+	write(r1)
+	if e := next(); e.event.Kind != EventCreated || len(e.event.Changes) != 1 || len(e.store.Records) != 1 {
+		t.Errorf("expected created event, got %+v", e.event)
+	}
+	// rewriting without changes is not reported
+	write(r1)
+	write(r1, r2)
+	if e := next(); e.event.Kind != EventModified || len(e.event.Changes) != 1 || e.event.Changes[0].Kind != ChangeAdded ||
+		e.event.Changes[0].New.FileName != "b" {
+		t.Errorf("expected modified event, got %+v", e.event)
+	}
+	if err := os.Remove(filePath); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if e := next(); e.event.Kind != EventRemoved || len(e.event.Changes) != 2 || e.store != nil {
+		t.Errorf("expected removed event, got %+v", e.event)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context error, got %v", err)
+	}
+	if err := Watch(context.Background(), filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestEventKindString(t *testing.T) {
+	if EventModified.String() != "modified" || EventKind(9).String() != "EventKind(9)" {
+		t.Errorf("unexpected names %s, %s", EventModified, EventKind(9))
+	}
+}
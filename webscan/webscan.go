@@ -0,0 +1,199 @@
+// Package webscan reconstructs directory trees of web servers exposing .DS_Store files.
+// Every found .DS_Store lists names of files of its directory, subdirectories are probed
+// for their own .DS_Store files recursively.
+package webscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// Default limits of scanning
+const (
+	DefaultMaxDepth    = 8
+	DefaultMaxRequests = 1000
+)
+
+// ErrTooManyRequests is returned when scanning stops because of Options.MaxRequests
+var ErrTooManyRequests = errors.New("too many requests")
+
+// Options are options of scanning
+type Options struct {
+	Client    *http.Client // HTTP client, http.DefaultClient by default
+	UserAgent string       // User-Agent header of requests
+	// Recursive probes entries of found stores as subdirectories with their own .DS_Store files
+	Recursive bool
+	// MaxDepth limits depth of recursion, DefaultMaxDepth by default
+	MaxDepth int
+	// MaxRequests limits count of requests, DefaultMaxRequests by default
+	MaxRequests int
+	// Interval is minimal interval between requests for rate limiting
+	Interval time.Duration
+	// ReadOptions are options of reading fetched stores, they limit size of responses too
+	ReadOptions dsstore.ReadOptions
+}
+
+// Node is a file or a directory of the reconstructed tree
+type Node struct {
+	Name     string         // file name, empty for the root
+	URL      string         // URL of the file or the directory
+	Store    *dsstore.Store // .DS_Store of the directory, nil for files and directories without it
+	Children []*Node        // entries of the directory listed by the store, sorted by name
+	Err      error          // error of fetching or reading the store
+}
+
+// IsDir reports whether the node is directory found by its .DS_Store
+func (n *Node) IsDir() bool {
+	return n.Store != nil
+}
+
+// Walk calls fn for the node and its descendants in depth-first order with slash-separated paths
+func (n *Node) Walk(fn func(p string, n *Node)) {
+	n.walk("", fn)
+}
+
+func (n *Node) walk(p string, fn func(p string, n *Node)) {
+	fn(p, n)
+	for _, child := range n.Children {
+		child.walk(path.Join(p, child.Name), fn)
+	}
+}
+
+type scanner struct {
+	opts     Options
+	requests int
+	last     time.Time
+}
+
+// Scan fetches .DS_Store of the directory URL, like "https://example.com/static/",
+// and returns tree of files listed by it. With Options.Recursive the entries are probed
+// as subdirectories. Root node has no store when the directory has no .DS_Store.
+// Errors of subdirectories are stored in their nodes, the returned error
+// is an error of the root directory, of the context or ErrTooManyRequests.
+func Scan(ctx context.Context, rawURL string, opts Options) (*Node, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", base.Scheme)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	base.RawPath, base.RawQuery, base.Fragment = "", "", ""
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	if opts.MaxRequests <= 0 {
+		opts.MaxRequests = DefaultMaxRequests
+	}
+	sc := &scanner{opts: opts}
+	root := &Node{URL: base.String()}
+	if root.Store, err = sc.fetch(ctx, base); err != nil || root.Store == nil {
+		return root, err
+	}
+	err = sc.scan(ctx, root, base, 1)
+	return root, err
+}
+
+// scan adds children of the directory node and probes them recursively
+func (sc *scanner) scan(ctx context.Context, node *Node, dir *url.URL, depth int) error {
+	names := make(map[string]bool)
+	for _, r := range node.Store.Records {
+		// names can't escape the directory
+		if r.FileName == "." || r.FileName == ".." || r.FileName == "" || strings.Contains(r.FileName, "/") {
+			continue
+		}
+		names[r.FileName] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		child := &Node{Name: name, URL: dir.JoinPath(name).String()}
+		node.Children = append(node.Children, child)
+		if !sc.opts.Recursive || depth >= sc.opts.MaxDepth {
+			continue
+		}
+		childDir := dir.JoinPath(name + "/")
+		var err error
+		if child.Store, err = sc.fetch(ctx, childDir); err != nil {
+			if ctx.Err() != nil || errors.Is(err, ErrTooManyRequests) {
+				return err
+			}
+			child.Err = err
+			continue
+		}
+		if child.Store == nil {
+			continue
+		}
+		child.URL = childDir.String()
+		if err = sc.scan(ctx, child, childDir, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch fetches and reads .DS_Store of the directory, it returns nil when the store is not found
+func (sc *scanner) fetch(ctx context.Context, dir *url.URL) (*dsstore.Store, error) {
+	if sc.requests >= sc.opts.MaxRequests {
+		return nil, ErrTooManyRequests
+	}
+	sc.requests++
+	if err := sc.wait(ctx); err != nil {
+		return nil, err
+	}
+	storeURL := dir.JoinPath(dsstore.StoreFileName).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, storeURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sc.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", sc.opts.UserAgent)
+	}
+	resp, err := sc.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", storeURL, resp.Status)
+	}
+	s := &dsstore.Store{}
+	if err = s.ReadWithOptions(resp.Body, sc.opts.ReadOptions); err != nil {
+		return nil, fmt.Errorf("%s: %w", storeURL, err)
+	}
+	return s, nil
+}
+
+// wait waits for the interval after the previous request
+func (sc *scanner) wait(ctx context.Context) error {
+	if delay := time.Until(sc.last.Add(sc.opts.Interval)); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	sc.last = time.Now()
+	return ctx.Err()
+}
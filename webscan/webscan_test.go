@@ -0,0 +1,119 @@
+package webscan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongo/dsstore"
+)
+
+// storeData returns the store with records of the names. Hostile names with "/" can't be written,
+// so they are written with U+2215 DIVISION SLASH which is replaced by "/" in UTF-16 data.
+func storeData(t *testing.T, names ...string) []byte {
+	var s dsstore.Store
+	for _, name := range names {
+		r := dsstore.Record{FileName: strings.ReplaceAll(name, "/", "\u2215"), Type: "long", Data: []byte{0, 0, 0, 1}}
+		r.SetCode("Iloc")
+		s.Records = append(s.Records, r)
+	}
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return bytes.ReplaceAll(buf.Bytes(), []byte{0x22, 0x15}, []byte{0, '/'})
+}
+
+func testServer(t *testing.T, requests *[]string) *httptest.Server {
+	files := map[string][]byte{
+		"/site/.DS_Store":            storeData(t, ".", "index.html", "static", "../etc", "a/b"),
+		"/site/static/.DS_Store":     storeData(t, "app.js", "img"),
+		"/site/static/img/.DS_Store": []byte("broken"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+		if r.UserAgent() != "test" {
+			t.Errorf("unexpected user agent %q", r.UserAgent())
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func paths(root *Node) []string {
+	var result []string
+	root.Walk(func(p string, n *Node) {
+		if n.IsDir() {
+			p += "/"
+		}
+		if n.Err != nil {
+			p += " error"
+		}
+		result = append(result, p)
+	})
+	return result
+}
+
+func TestScan(t *testing.T) {
+	var requests []string
+	server := testServer(t, &requests)
+	root, err := Scan(context.Background(), server.URL+"/site", Options{UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if got := strings.Join(paths(root), ","); got != "/,index.html,static" {
+		t.Errorf("unexpected tree %s", got)
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected 1 request, got %v", requests)
+	}
+
+	requests = nil
+	root, err = Scan(context.Background(), server.URL+"/site/", Options{UserAgent: "test", Recursive: true, Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if got := strings.Join(paths(root), ","); got != "/,index.html,static/,static/app.js,static/img error" {
+		t.Errorf("unexpected tree %s", got)
+	}
+	if root.Children[1].URL != server.URL+"/site/static/" {
+		t.Errorf("unexpected URL %s", root.Children[1].URL)
+	}
+	if len(requests) != 5 {
+		t.Errorf("expected 5 requests, got %v", requests)
+	}
+}
+
+func TestScanLimits(t *testing.T) {
+	var requests []string
+	server := testServer(t, &requests)
+	root, err := Scan(context.Background(), server.URL+"/site", Options{UserAgent: "test", Recursive: true, MaxDepth: 1})
+	if err != nil || len(requests) != 1 || len(root.Children) != 2 {
+		t.Errorf("expected 1 request without recursion, got %v: %v", requests, err)
+	}
+	if _, err = Scan(context.Background(), server.URL+"/site", Options{UserAgent: "test", Recursive: true, MaxRequests: 2}); !errors.Is(err, ErrTooManyRequests) {
+		t.Errorf("expected too many requests error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = Scan(ctx, server.URL+"/site", Options{UserAgent: "test"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context error, got %v", err)
+	}
+	if root, err = Scan(context.Background(), server.URL+"/missing", Options{UserAgent: "test"}); err != nil || root.IsDir() {
+		t.Errorf("expected empty tree, got %v", err)
+	}
+	if _, err = Scan(context.Background(), "file:///etc", Options{}); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
@@ -2,88 +2,23 @@ package dsstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
 	"os"
-	"sort"
+	"path/filepath"
+	"slices"
+	"unicode"
+	"unicode/utf16"
 
-	"golang.org/x/text/encoding/unicode"
-	"golang.org/x/text/transform"
+	"github.com/strongo/dsstore/buddy"
 )
 
-type freeBlock struct {
-	offset uint32
-	size   uint32
-}
-
-func (s *Store) writeFreeMapCreate() []freeBlock {
-	freeBlocks := make([]freeBlock, 0)
-	for i := 5; i < 31; i++ {
-		pow := uint32(1 << i)
-		freeBlocks = append(freeBlocks, freeBlock{pow, pow})
-	}
-	return freeBlocks
-}
-
-func (s *Store) writeFreeMapSort(freeBlocks []freeBlock) {
-	sort.SliceStable(freeBlocks, func(i, j int) bool {
-		if freeBlocks[i].size < freeBlocks[j].size {
-			return true
-		}
-		if freeBlocks[i].size < freeBlocks[j].size {
-			return freeBlocks[i].offset < freeBlocks[j].offset
-		}
-		return false
-	})
-}
-
-func (s *Store) writeFreeMapAlloc(freeBlocks []freeBlock, size uint32, capacity uint32) (uint32, []freeBlock) {
-	// check capacity
-	if capacity < size {
-		capacity = size
-	}
-	// calculate size needed size that powered by 2
-	var powCapacity uint32 = 0
-	var powSize uint32 = 0
-	var powIndex uint32 = 32
-	for i := uint32(5); i < 32; i++ {
-		powCapacity = uint32(1) << i
-		if powCapacity >= size && powIndex >= 32 {
-			powIndex = i
-			powSize = powCapacity
-		}
-		if powCapacity >= capacity {
-			if powIndex < 32 {
-				break
-			}
-		}
-	}
-	// sort map
-	s.writeFreeMapSort(freeBlocks)
-	// find block in partly used blocks
-	for i, block := range freeBlocks {
-		if block.size != block.offset && block.size == powCapacity {
-			freeBlocks = append(freeBlocks[:i], freeBlocks[i+1:]...)
-			if block.size > powSize {
-				freeBlocks = append(freeBlocks, freeBlock{block.offset + powSize, block.size - powSize})
-			}
-			return block.offset | powIndex, freeBlocks
-		}
-	}
-	// find block in fully cleared blocks
-	for i, block := range freeBlocks {
-		if block.size >= powCapacity {
-			freeBlocks = append(freeBlocks[:i], freeBlocks[i+1:]...)
-			if block.size > powSize {
-				freeBlocks = append(freeBlocks, freeBlock{block.offset + powSize, block.size - powSize})
-			}
-			return block.offset | powIndex, freeBlocks
-		}
-	}
-	return 0, freeBlocks
-}
-
 func (s *Store) writeAlignBlock(b *bytes.Buffer, minSize uint32) error {
 	var lenBytes = uint32(b.Len())
 	for i := 0; i < 32; i++ {
@@ -99,72 +34,69 @@ func (s *Store) writeAlignBlock(b *bytes.Buffer, minSize uint32) error {
 	return nil
 }
 
-func (s *Store) writeBlockData(b *bytes.Buffer, records []Record) error {
-	// nextBlock is always 0. Storing all records in the one block
-	if err := binary.Write(b, binary.BigEndian, uint32(0)); err != nil {
-		return err
+func (s *Store) writeRecord(b *bytes.Buffer, r Record) error {
+	var scratch [4]byte
+	// r.FileName in UTF-16, length is count of UTF-16 code units
+	var length uint32
+	for _, c := range r.FileName {
+		length += uint32(utf16.RuneLen(c))
+	}
+	binary.BigEndian.PutUint32(scratch[:], length)
+	b.Write(scratch[:])
+	for _, c := range r.FileName {
+		if c1, c2 := utf16.EncodeRune(c); c1 != unicode.ReplacementChar {
+			b.Write(binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(scratch[:0], uint16(c1)), uint16(c2)))
+		} else {
+			b.Write(binary.BigEndian.AppendUint16(scratch[:0], uint16(c)))
+		}
 	}
-	// count of records
-	if err := binary.Write(b, binary.BigEndian, uint32(len(records))); err != nil {
-		return err
+	// unknown extra 4 bytes
+	binary.BigEndian.PutUint32(scratch[:], r.Extra)
+	b.Write(scratch[:])
+	// r.Type (4-bytes string)
+	clear(scratch[:])
+	copy(scratch[:], r.Type)
+	b.Write(scratch[:])
+	// r.DataLen for blob, ustr etc
+	if r.DataLen > 0 {
+		binary.BigEndian.PutUint32(scratch[:], r.DataLen)
+		b.Write(scratch[:])
 	}
-	// records
-	for _, r := range records {
-		// r.FileName
-		n, _, err := transform.Bytes(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder(), []byte(r.FileName))
-		if err != nil {
-			return err
-		}
-		if err := binary.Write(b, binary.BigEndian, uint32(len(n)/2)); err != nil {
-			return err
-		}
-		if _, err := b.Write(n); err != nil {
-			return err
-		}
-		// unknown extra 4 bytes
-		if err := binary.Write(b, binary.BigEndian, uint32(r.Extra)); err != nil {
-			return err
-		}
-		// r.Type (4-bytes string)
-		t := make([]byte, 4)
-		copy(t, []byte(r.Type))
-		if _, err := b.Write(t); err != nil {
-			return err
-		}
-		// r.DataLen for blob, ustr etc
-		if r.DataLen > 0 {
-			if err := binary.Write(b, binary.BigEndian, uint32(r.DataLen)); err != nil {
-				return err
-			}
-		}
-		// r.Data
-		if _, err := b.Write(r.Data); err != nil {
-			return err
-		}
+	// r.Data
+	if _, err := b.Write(r.Data); err != nil {
+		return err
 	}
 	return nil
 }
 
-func (s *Store) writeBlockDSDB(b *bytes.Buffer, index uint32) error {
-	// write data block index
+// writeBlockNode writes B-tree node of encoded records
+func (s *Store) writeBlockNode(b *bytes.Buffer, node treeNode) error {
+	_, err := b.Write(node.Append(b.AvailableBuffer(), func(b, r []byte) []byte {
+		return append(b, r...)
+	}))
+	return err
+}
+
+func (s *Store) writeBlockDSDB(b *bytes.Buffer, index, levels, nodes, pageSize uint32) error {
+	// write index of B-tree root node
 	err := binary.Write(b, binary.BigEndian, index)
 	if err != nil {
 		return err
 	}
-	// levels. always is 0
-	if err = binary.Write(b, binary.BigEndian, uint32(0)); err != nil {
+	// levels of internal nodes. 0 when all records are in one node
+	if err = binary.Write(b, binary.BigEndian, levels); err != nil {
 		return err
 	}
 	// records
 	if err = binary.Write(b, binary.BigEndian, uint32(len(s.Records))); err != nil {
 		return err
 	}
-	// nodes. always is 1 (storing all data in one data block)
-	if err = binary.Write(b, binary.BigEndian, uint32(1)); err != nil {
+	// nodes
+	if err = binary.Write(b, binary.BigEndian, nodes); err != nil {
 		return err
 	}
-	// dummy 0x1000 value
-	if err = binary.Write(b, binary.BigEndian, uint32(0x1000)); err != nil {
+	// page size
+	if err = binary.Write(b, binary.BigEndian, pageSize); err != nil {
 		return err
 	}
 	// other unknown data
@@ -174,236 +106,459 @@ func (s *Store) writeBlockDSDB(b *bytes.Buffer, index uint32) error {
 	return nil
 }
 
-func (s *Store) writeOffsets(b *bytes.Buffer, offsetRoot, offsetDBDS, offsetData uint32) error {
-	// count of offset. always 3 blocks only
-	if err := binary.Write(b, binary.BigEndian, uint32(3)); err != nil {
-		return err
+// writeBlockRoot writes root (bookkeeping) block of the container
+func (s *Store) writeBlockRoot(b *bytes.Buffer, offsets []uint32, topics map[string]uint32, freeLists *[32][]uint32, extra []byte) error {
+	root := buddy.Root{Offsets: offsets, Directory: topics, FreeLists: *freeLists, Extra: extra}
+	_, err := b.Write(root.Append(b.AvailableBuffer()))
+	return err
+}
+
+// writeHeader writes header of the container with HeaderExtra
+func (s *Store) writeHeader(b *bytes.Buffer, offsetRoot, size uint32) error {
+	header := buddy.Header{RootOffset: offsetRoot, RootSize: size, Extra: s.HeaderExtra}
+	_, err := b.Write(header.Append(b.AvailableBuffer()))
+	return err
+}
+
+// WriteOptions of .DS_Store writing
+type WriteOptions struct {
+	PreserveTrailing bool // write trailing data found on reading after the allocated region
+	Verify           bool // re-read written data and compare records with the store before writing
+	// Atomic makes WriteFile write to a temporary file in the destination directory, fsync it
+	// and rename it over the target, so the target is never left partially written
+	Atomic bool
+	// Locking takes exclusive advisory lock of the target file while WriteFile writes it,
+	// with Atomic the lock of the file at the path is taken, see LockOptions
+	Locking LockOptions
+	// PreserveMetadata keeps permissions, access and modification times of the existing target file
+	// and on macOS its flags (like hidden) and extended attributes
+	PreserveMetadata bool
+	// OnProgress is called while writing with records encoded and bytes written so far
+	OnProgress func(Progress)
+	// PageSize is size of B-tree node blocks, a power of 2 from MinPageSize to MaxPageSize.
+	// Default is DefaultPageSize used by Finder. Bigger pages make trees of very large stores shallower.
+	PageSize int
+	// Fanout limits count of records of B-tree nodes, so geometry of files written by specific
+	// macOS versions can be matched. Default 0 limits nodes only by PageSize like Finder does.
+	Fanout int
+	// Target selects variants of records for macOS versions: legacy "BKGD" with "pict" and "lsvp" or modern
+	// background of "icvp" and "lsvP". Missing variants are derived from existing ones and variants of
+	// other versions are dropped, records of the store aren't changed. Default TargetAsIs writes records as they are
+	Target Target
+	// Logger receives debug events of writing: built B-tree, allocated, kept and reallocated blocks.
+	// Nil logger logs nothing
+	Logger *slog.Logger
+	// PreserveOrder writes records in order of Store.Records, like the order read from the file, instead of sorting
+	// them when the order is canonical, so the exact order of Finder is reproduced where it differs from sorting
+	// of this library, like for names with characters out of BMP. Records out of canonical order are sorted.
+	PreserveOrder bool
+}
+
+// ErrInvalidOptions is returned for WriteOptions which can't be used
+var ErrInvalidOptions = errors.New("invalid options")
+
+// tree returns page size and fanout of B-tree with defaults
+func (o WriteOptions) tree() (pageSize, fanout int, err error) {
+	pageSize = o.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
 	}
-	// dummy 4 bytes
-	if err := binary.Write(b, binary.BigEndian, uint32(0)); err != nil {
-		return err
+	if !validPageSize(pageSize) {
+		return 0, 0, fmt.Errorf("%w: page size %d is not a power of 2 from %d to %d", ErrInvalidOptions, o.PageSize, MinPageSize, MaxPageSize)
 	}
-	// offsets
-	if err := binary.Write(b, binary.BigEndian, offsetRoot); err != nil {
-		return err
+	if o.Fanout < 0 || o.Fanout == 1 {
+		return 0, 0, fmt.Errorf("%w: fanout %d is less than 2", ErrInvalidOptions, o.Fanout)
 	}
-	if err := binary.Write(b, binary.BigEndian, offsetDBDS); err != nil {
-		return err
+	return pageSize, o.Fanout, nil
+}
+
+// ErrVerifyFailed is returned when written data doesn't match the store on WriteOptions.Verify
+var ErrVerifyFailed = errors.New("verification of written data failed")
+
+// verify re-reads written file data and compares it with records of the store in the written order
+func (s *Store) verify(fileData []byte, records []Record) error {
+	var written Store
+	if err := written.Read(bytes.NewReader(fileData)); err != nil {
+		return fmt.Errorf("%w: %w", ErrVerifyFailed, err)
+	}
+	if len(written.Records) != len(s.Records) {
+		return fmt.Errorf("%w: %d records are written instead of %d", ErrVerifyFailed, len(written.Records), len(s.Records))
+	}
+	for i, r := range records {
+		if !r.Equal(written.Records[i]) {
+			return fmt.Errorf("%w: record %d (%q, %q) is different", ErrVerifyFailed, i, r.FileName, r.Code())
+		}
 	}
-	if err := binary.Write(b, binary.BigEndian, offsetData); err != nil {
-		return err
+	return nil
+}
+
+// Write writes .DS_Store to io.Writer
+func (s *Store) Write(w io.Writer) error {
+	return s.WriteWithOptions(w, WriteOptions{})
+}
+
+// WriteWithOptions writes .DS_Store to io.Writer using options
+func (s *Store) WriteWithOptions(w io.Writer, opts WriteOptions) error {
+	return s.WriteContextWithOptions(context.Background(), w, opts)
+}
+
+// WriteTo writes .DS_Store to io.Writer, it implements io.WriterTo
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	e := getEncoder()
+	defer putEncoder(e)
+	fileData, err := e.Encode(s)
+	if err != nil {
+		return 0, err
 	}
-	// dummy 253 zero offsets
-	for i := 0; i < 253; i++ {
-		if err := binary.Write(b, binary.BigEndian, uint32(0)); err != nil {
-			return err
+	n, err := w.Write(fileData)
+	return int64(n), err
+}
+
+// sortedRecords returns records of the store in the order of B-tree keys
+func (s *Store) sortedRecords() []Record {
+	return sortRecords(slices.Clone(s.Records))
+}
+
+// sortRecords sorts records in the order of B-tree keys
+func sortRecords(records []Record) []Record {
+	slices.SortStableFunc(records, compareRecords)
+	return records
+}
+
+// validateRecords checks that all records can be encoded
+func (s *Store) validateRecords() error {
+	for i, r := range s.Records {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRecord, &RecordError{Index: i, FileName: r.FileName, Code: r.Code(), Err: err})
 		}
 	}
 	return nil
 }
 
-func (s *Store) writeTopicDBDS(b *bytes.Buffer, index uint32) error {
-	// always one topic (DBDS)
-	if err := binary.Write(b, binary.BigEndian, uint32(1)); err != nil {
-		return err
+// encodeRecords returns encoded records sorted by B-tree keys or in the order of the store when it is preserved.
+// Records are encoded into the buffer of the encoder.
+func (s *Store) encodeRecords(e *Encoder) ([][]byte, error) {
+	// records which can't be encoded fail before anything is written
+	if err := s.validateRecords(); err != nil {
+		return nil, err
+	}
+	e.sorted = append(e.sorted[:0], s.Records...)
+	if !e.preserveOrder || misordered(e.sorted) >= 0 {
+		sortRecords(e.sorted)
+	}
+	e.records.Reset()
+	ends := e.ends[:0]
+	for _, r := range e.sorted {
+		if err := s.writeRecord(&e.records, r); err != nil {
+			return nil, err
+		}
+		ends = append(ends, e.records.Len())
 	}
-	// topic name len. Always is 4
-	if err := b.WriteByte(4); err != nil {
-		return err
+	e.ends = ends
+	records := make([][]byte, len(ends))
+	data := e.records.Bytes()
+	start := 0
+	for i, end := range ends {
+		records[i] = data[start:end:end]
+		start = end
 	}
-	// topic name
-	if _, err := b.Write([]byte("DSDB")); err != nil {
-		return err
+	return records, nil
+}
+
+// encode returns full .DS_Store file data.
+// Records are written into B-tree sorted by file names and structure IDs.
+// Layout of the file read in fidelity mode is kept when it is possible.
+func (s *Store) encode(opts WriteOptions) ([]byte, error) {
+	return NewEncoder().EncodeWithOptions(s, opts)
+}
+
+// encodeTree returns file data with newly allocated blocks
+func (s *Store) encodeTree(e *Encoder) ([]byte, error) {
+	records, err := s.encodeRecords(e)
+	if err != nil {
+		return nil, err
+	}
+	// prepare B-tree nodes. block 0 is the root block, block 1 is DSDB block
+	nodes, levels := buildTree(records, e.pageSize, e.fanout, func(n int) uint32 { return uint32(n + 2) })
+	e.debug("build tree", "records", len(records), "nodes", len(nodes), "levels", levels, "pageSize", e.pageSize, "fanout", e.fanout)
+	e.nodes.Reset()
+	nodeEnds := e.nodeEnds[:0]
+	for _, node := range nodes {
+		if err := e.ctxErr(); err != nil {
+			return nil, err
+		}
+		if err := s.writeBlockNode(&e.nodes, node); err != nil {
+			return nil, err
+		}
+		nodeEnds = append(nodeEnds, e.nodes.Len())
+		e.progress.RecordsWritten += len(node.Records)
+		e.reportProgress()
 	}
-	// topic offset
-	if err := binary.Write(b, binary.BigEndian, uint32(index)); err != nil {
-		return err
+	e.nodeEnds = nodeEnds
+	// prepare DSDB block, the root node is the last one
+	blockDSDB := getBuffer()
+	defer putBuffer(blockDSDB)
+	if err := s.writeBlockDSDB(blockDSDB, uint32(len(nodes)+1), uint32(levels), uint32(len(nodes)), uint32(e.pageSize)); err != nil {
+		return nil, err
 	}
-	return nil
+	if err := s.writeAlignBlock(blockDSDB, 32); err != nil {
+		return nil, err
+	}
+	// allocate blocks
+	nodeSizes := make([]int, len(nodeEnds))
+	start := 0
+	for i, end := range nodeEnds {
+		nodeSizes[i] = end - start
+		start = end
+	}
+	blockRoot := getBuffer()
+	defer putBuffer(blockRoot)
+	offsets, err := allocateBlocks(nodeSizes, e.pageSize, blockDSDB.Len(), func(offsets []uint32, freeLists *[32][]uint32) (int, error) {
+		blockRoot.Reset()
+		err := s.writeBlockRoot(blockRoot, offsets, map[string]uint32{"DSDB": 1}, freeLists, s.RootExtra)
+		return blockRoot.Len(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for index, offset := range offsets {
+		e.debug("allocate block", "index", index, "offset", buddy.Offset(offset), "size", buddy.Size(offset))
+	}
+	// write header
+	blockHeader := getBuffer()
+	defer putBuffer(blockHeader)
+	if err := s.writeHeader(blockHeader, buddy.Offset(offsets[0]), uint32(blockRoot.Len())); err != nil {
+		return nil, err
+	}
+	// create full file
+	fileData := e.fileData(fileSize(offsets))
+	copy(fileData[0:], blockHeader.Bytes())
+	copy(fileData[4+buddy.Offset(offsets[0]):], blockRoot.Bytes())
+	copy(fileData[4+buddy.Offset(offsets[1]):], blockDSDB.Bytes())
+	start = 0
+	for i, end := range nodeEnds {
+		copy(fileData[4+buddy.Offset(offsets[i+2]):], e.nodes.Bytes()[start:end])
+		start = end
+	}
+	return fileData, nil
 }
 
-func (s *Store) writeFreeBlocks(b *bytes.Buffer, freeBlocks []freeBlock) error {
-	// sort blocks by size
-	s.writeFreeMapSort(freeBlocks)
-	// it is magic
-	for i := 0; i < 32; i++ {
-		// current block size (iterating block sizes 1, 2, 4, 8, 16, ..., 1024, 2048, 4096, ...)
-		var blockSize = uint32(1) << i
-		var blockCount uint32 = 0
-		for j := 0; j < len(freeBlocks); j++ {
-			power := (freeBlocks[j].size & (freeBlocks[j].size - 1)) == 0
-			// we need take blocks with size == blockSize
-			// but also we take all blocks bigger than blockSize that are not round by 2 power
-			//
-			// For example we have the following blocks allocation (globally 2 blocks - 2048-4096, 4096-8092):
-			// |             |####1024####                 |
-			// 2048          4096                          8092
-			//
-			// Used space is 4096-5120. Free space are 2048-4095 and 5121-8091 (sub part of block 4096-8092).
-			// Free blocks for blockSize == 2048 are 2048-4096 and 5120-8092 (because it can't be assinged to blocks with size >= 4096)
-			// Free blocks for blockSize == 4096 are none
-			// here we just calculate count of free blocks with needed size
-			if freeBlocks[j].size == blockSize || (freeBlocks[j].size > blockSize && !power) {
-				blockCount++
-				continue
-			}
-			if freeBlocks[j].size >= blockSize {
-				break
-			}
+// allocateBlocks allocates blocks of B-tree nodes, DSDB block and root block.
+// Block 0 is the root block, block 1 is DSDB block, B-tree nodes follow them in blocks of at least pageSize.
+// Root block contains offsets and free lists, so rootSize is called with the current
+// allocation until the root block fits its block. The last call is for the final allocation.
+func allocateBlocks(nodeSizes []int, pageSize, dsdbSize int, rootSize func(offsets []uint32, freeLists *[32][]uint32) (int, error)) ([]uint32, error) {
+	allocator := buddy.NewAllocator()
+	offsets := make([]uint32, 2, len(nodeSizes)+2)
+	for _, size := range nodeSizes {
+		offset, err := allocator.Alloc(uint32(max(size, pageSize)))
+		if err != nil {
+			return nil, err
 		}
-		if err := binary.Write(b, binary.BigEndian, uint32(blockCount)); err != nil {
-			return err
+		offsets = append(offsets, offset)
+	}
+	offset, err := allocator.Alloc(uint32(dsdbSize))
+	if err != nil {
+		return nil, err
+	}
+	offsets[1] = offset
+	for {
+		size, err := rootSize(offsets, &allocator.FreeLists)
+		if err != nil {
+			return nil, err
 		}
-		if blockCount > 0 {
-			// writing blocks offsets
-			for j := 0; j < len(freeBlocks); j++ {
-				power := (freeBlocks[j].size & (freeBlocks[j].size - 1)) == 0
-				if freeBlocks[j].size == blockSize || (freeBlocks[j].size > blockSize && !power) {
-					if err := binary.Write(b, binary.BigEndian, uint32(freeBlocks[j].offset)); err != nil {
-						return err
-					}
-					continue
-				}
-				if freeBlocks[j].size >= blockSize {
-					break
-				}
-			}
+		if offsets[0] != 0 && uint32(size) <= buddy.Size(offsets[0]) {
+			return offsets, nil
+		}
+		if offsets[0] != 0 {
+			allocator.Free(offsets[0])
+		}
+		if offsets[0], err = allocator.Alloc(uint32(size)); err != nil {
+			return nil, err
 		}
 	}
-	return nil
 }
 
-func (s *Store) writeBlockRoot(b *bytes.Buffer, offsetRoot, offsetDBDS, offsetData uint32, freeBlocks []freeBlock) error {
-	// offsets
-	if err := s.writeOffsets(b, offsetRoot, offsetDBDS, offsetData); err != nil {
-		return err
+// fileSize returns size of file with allocated blocks
+func fileSize(offsets []uint32) int {
+	var size uint32 = 32
+	for _, offset := range offsets {
+		size = max(size, buddy.Offset(offset)+buddy.Size(offset))
 	}
-	// topic. index is always 1
-	if err := s.writeTopicDBDS(b, 1); err != nil {
-		return err
+	// blocks are addressed after 4 bytes of file prefix
+	return int(size) + 4
+}
+
+// EncodedSize returns exact size of data written by Write including allocator data and padding,
+// or -1 when the store can't be written. Records are not encoded, only their sizes are calculated.
+func (s *Store) EncodedSize() int {
+	if s.layout != nil {
+		// size of file with kept layout depends on the read blocks
+		e := getEncoder()
+		defer putEncoder(e)
+		fileData, err := e.Encode(s)
+		if err != nil {
+			return -1
+		}
+		return len(fileData)
+	}
+	if s.validateRecords() != nil {
+		return -1
+	}
+	// B-tree is built like by Write from sorted records of the right sizes sharing one zeroed buffer
+	sorted := s.sortedRecords()
+	sizes := make([]int, len(sorted))
+	maxSize := 0
+	for i, r := range sorted {
+		sizes[i] = recordSize(r)
+		maxSize = max(maxSize, sizes[i])
+	}
+	shared := make([]byte, maxSize)
+	records := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		records[i] = shared[:size]
+	}
+	nodes, _ := buildTree(records, DefaultPageSize, 0, func(n int) uint32 { return uint32(n + 2) })
+	nodeSizes := make([]int, len(nodes))
+	for i := range nodes {
+		nodeSizes[i] = nodes[i].Size(func(r []byte) int { return len(r) })
+	}
+	// DSDB block has 5 values and extra data
+	dsdbSize := 5*4 + len(s.DSDBExtra)
+	offsets, err := allocateBlocks(nodeSizes, DefaultPageSize, dsdbSize, func(offsets []uint32, freeLists *[32][]uint32) (int, error) {
+		// offsets padded to multiple of 256, DSDB topic, free lists and extra data
+		size := 8 + 4*((len(offsets)+255)/256*256) + 4 + 1 + len("DSDB") + 4 + len(s.RootExtra)
+		for _, list := range freeLists {
+			size += 4 + 4*len(list)
+		}
+		return size, nil
+	})
+	if err != nil {
+		return -1
 	}
-	// free blocks
-	if err := s.writeFreeBlocks(b, freeBlocks); err != nil {
-		return err
+	return fileSize(offsets)
+}
+
+// recordSize returns size of encoded record
+func recordSize(r Record) int {
+	size := 4 + 4 + 4 + len(r.Data)
+	for _, c := range r.FileName {
+		size += 2 * utf16.RuneLen(c)
 	}
-	// write extra (unknown data)
-	if _, err := b.Write(s.RootExtra); err != nil {
-		return err
+	if r.DataLen > 0 {
+		size += 4
 	}
-	return nil
+	return size
 }
 
-func (s *Store) writeHeader(b *bytes.Buffer, offsetRoot, size uint32) error {
-	// magic1
-	if err := binary.Write(b, binary.BigEndian, headerMagic1); err != nil {
-		return err
-	}
-	// magic2
-	if err := binary.Write(b, binary.BigEndian, headerMagic2); err != nil {
-		return err
+// WriteFile writes .DS_Store to the file
+func (s *Store) WriteFile(filename string, perm os.FileMode) error {
+	return s.WriteFileWithOptions(filename, perm, WriteOptions{})
+}
+
+// WriteFileWithOptions writes .DS_Store to the file using options
+func (s *Store) WriteFileWithOptions(filename string, perm os.FileMode, opts WriteOptions) error {
+	if !opts.PreserveMetadata {
+		return s.writeFile(filename, perm, opts)
 	}
-	// offset of root block
-	if err := binary.Write(b, binary.BigEndian, offsetRoot); err != nil {
+	m, err := readMetadata(filename)
+	if err != nil {
 		return err
 	}
-	// size of root block
-	if err := binary.Write(b, binary.BigEndian, size); err != nil {
+	if err = s.writeFile(filename, perm, opts); err != nil || m == nil {
 		return err
 	}
-	// offset of root block
-	if err := binary.Write(b, binary.BigEndian, offsetRoot); err != nil {
+	return m.apply(filename)
+}
+
+func (s *Store) writeFile(filename string, perm os.FileMode, opts WriteOptions) error {
+	e := getEncoder()
+	defer putEncoder(e)
+	fileData, err := e.EncodeWithOptions(s, opts)
+	if err != nil {
 		return err
 	}
-	// write extra
-	headerExtra := make([]byte, 16)
-	copy(headerExtra, s.HeaderExtra)
-	if _, err := b.Write(headerExtra); err != nil {
+	if err = writeFileData(filename, fileData, perm, opts); err != nil {
 		return err
 	}
-	// check size
-	if b.Len() != 36 {
-		return errors.New("invalid header size")
-	}
+	e.progress.BytesWritten = int64(len(fileData))
+	e.reportProgress()
 	return nil
 }
 
-// WriteStore writes .DS_Store to io.Writer
-func (s *Store) Write(w io.Writer) error {
-	// prepare data block
-	blockData := new(bytes.Buffer)
-	if err := s.writeBlockData(blockData, s.Records); err != nil {
-		return err
+// writeFileData writes encoded data to the file
+func writeFileData(filename string, fileData []byte, perm os.FileMode, opts WriteOptions) error {
+	if !opts.Locking.Enabled {
+		if opts.Atomic {
+			return writeFileAtomic(filename, fileData, perm)
+		}
+		return os.WriteFile(filename, fileData, perm)
 	}
-	// prepare DSDB block (always 2 index)
-	blockDSDB := new(bytes.Buffer)
-	if err := s.writeBlockDSDB(blockDSDB, 2); err != nil {
+	// the target is locked while it is written or replaced
+	f, err := openLocked(filename, perm, opts.Locking)
+	if err != nil {
 		return err
 	}
-	// prepare Root block
-	blockList := s.writeFreeMapCreate()
-	blockRoot := new(bytes.Buffer)
-	if err := s.writeBlockRoot(blockRoot, 0, 0, 0, blockList); err != nil {
-		return err
+	defer func() {
+		_ = f.Close()
+	}()
+	defer func() {
+		_ = unlockFile(f)
+	}()
+	if opts.Atomic {
+		return writeFileAtomic(filename, fileData, perm)
 	}
-	// align blocks
-	if err := s.writeAlignBlock(blockData, 32); err != nil {
+	if err = f.Truncate(0); err != nil {
 		return err
 	}
-	if err := s.writeAlignBlock(blockDSDB, 32); err != nil {
+	if _, err = f.WriteAt(fileData, 0); err != nil {
 		return err
 	}
-	if err := s.writeAlignBlock(blockRoot, 32); err != nil {
+	return f.Sync()
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over the file.
+// Mode of the existing file is kept, new file gets perm with umask applied like by os.WriteFile.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	info, err := os.Stat(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
-	// create blocks map
-	blockDataOffset, blockList := s.writeFreeMapAlloc(blockList, uint32(blockData.Len()), 0)
-	blockDSDBOffset, blockList := s.writeFreeMapAlloc(blockList, uint32(blockDSDB.Len()), 0)
-	blockRootOffset, blockList := s.writeFreeMapAlloc(blockList, uint32(blockRoot.Len()), 0)
-	// calc real offset
-	blockDataOffsetReal := blockOffset(blockDataOffset)
-	blockDSDBOffsetReal := blockOffset(blockDSDBOffset)
-	blockRootOffsetReal := blockOffset(blockRootOffset)
-	// calc end of blocks
-	blockDataEnd := blockDataOffsetReal + blockSize(blockDataOffset)
-	blockDSDBEnd := blockDSDBOffsetReal + blockSize(blockDSDBOffset)
-	blockRootEnd := blockRootOffsetReal + blockSize(blockRootOffset)
-	// re-create root block with correct offsets
-	blockRoot.Reset()
-	if err := s.writeBlockRoot(blockRoot, blockRootOffset, blockDSDBOffset, blockDataOffset, blockList); err != nil {
+	f, err := createTemp(filename, perm)
+	if err != nil {
 		return err
 	}
-	// write header
-	blockHeader := new(bytes.Buffer)
-	if err := s.writeHeader(blockHeader, blockRootOffsetReal, uint32(blockRoot.Len())); err != nil {
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
 		return err
 	}
-	// calculate file size
-	var size uint32 = 32
-	if blockRootEnd > size {
-		size = blockRootEnd
+	if info != nil {
+		if err = f.Chmod(info.Mode().Perm()); err != nil {
+			return err
+		}
 	}
-	if blockDSDBEnd > size {
-		size = blockDSDBEnd
+	if err = f.Sync(); err != nil {
+		return err
 	}
-	if blockDataEnd > size {
-		size = blockDataEnd
+	if err = f.Close(); err != nil {
+		return err
 	}
-	// create full file
-	fileData := make([]byte, size+4)
-	copy(fileData[0:], blockHeader.Bytes())
-	copy(fileData[4+blockRootOffsetReal:], blockRoot.Bytes())
-	copy(fileData[4+blockDSDBOffsetReal:], blockDSDB.Bytes())
-	copy(fileData[4+blockDataOffsetReal:], blockData.Bytes())
-	// write it
-	_, err := w.Write(fileData)
-	return err
+	return os.Rename(f.Name(), filename)
 }
 
-// WriteFile writes .DS_Store to the file
-func (s *Store) WriteFile(filename string, perm os.FileMode) error {
-	buffer := new(bytes.Buffer)
-	if err := s.Write(buffer); err != nil {
-		return err
+// createTemp creates new temporary file in the directory of the file, the umask is applied to perm
+func createTemp(filename string, perm os.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(filename)
+	for {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
 	}
-	return os.WriteFile(filename, buffer.Bytes(), perm)
 }
@@ -2,9 +2,16 @@ package dsstore
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestWriteFile(t *testing.T) {
@@ -103,6 +110,7 @@ func TestManyRecords(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Write failed: %v", err)
 	}
+	fileData := buf.Bytes()
 
 	var s2 Store
 	err = s2.Read(buf)
@@ -112,18 +120,84 @@ func TestManyRecords(t *testing.T) {
 	if len(s2.Records) != 2000 {
 		t.Errorf("expected 2000 records, got %d", len(s2.Records))
 	}
+	if err = s2.ValidateFreeList(); err != nil {
+		t.Errorf("expected valid free list, got %v", err)
+	}
+	if len(s2.alloc.offsets) < 4 {
+		t.Errorf("expected records to be split into several nodes, got %d blocks", len(s2.alloc.offsets))
+	}
+
+	// records are found by B-tree search
+	r, err := OpenReaderAt(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+	for _, record := range s.Records {
+		if _, found, err := r.Lookup(record.FileName, record.Code()); err != nil || !found {
+			t.Errorf("record %q is not found: %v", record.FileName, err)
+		}
+	}
 }
 
-func TestWriteFreeBlocksMultiple(t *testing.T) {
+func TestWriteSorted(t *testing.T) {
 	s := &Store{}
+	for _, name := range []string{"b", "C", "a"} {
+		s.Records = append(s.Records, Record{FileName: name, Type: "bool", Data: []byte{1}})
+	}
 	buf := new(bytes.Buffer)
-	freeBlocks := make([]freeBlock, 0)
-	for i := uint32(0); i < 40; i++ {
-		freeBlocks = append(freeBlocks, freeBlock{offset: i * 1024, size: 1024})
+	if err := s.WriteWithOptions(buf, WriteOptions{Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
 	}
-	err := s.writeFreeBlocks(buf, freeBlocks)
-	if err != nil {
-		t.Fatalf("writeFreeBlocks failed: %v", err)
+	var s2 Store
+	if err := s2.Read(buf); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	var names []string
+	for _, r := range s2.Records {
+		names = append(names, r.FileName)
+	}
+	if strings.Join(names, ",") != "a,b,C" {
+		t.Errorf("expected records sorted as a,b,C, got %v", names)
+	}
+	if s.Records[0].FileName != "b" {
+		t.Error("expected records of the store not to be modified")
+	}
+}
+
+func TestWritePreserveOrder(t *testing.T) {
+	// Finder compares UTF-16 code units, so the emoji sorts before the fullwidth exclamation mark
+	write := func(names []string, opts WriteOptions) string {
+		s := &Store{}
+		for _, name := range names {
+			s.Records = append(s.Records, Record{FileName: name, Type: "bool", Data: []byte{1}})
+		}
+		buf := new(bytes.Buffer)
+		opts.Verify = true
+		if err := s.WriteWithOptions(buf, opts); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var written Store
+		if err := written.Read(buf); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if err := written.Validate(); err != nil {
+			t.Errorf("expected canonical order, got %v", err)
+		}
+		var order []string
+		for _, r := range written.Records {
+			order = append(order, r.FileName)
+		}
+		return strings.Join(order, ",")
+	}
+	finder := []string{"a", "\U0001F600", "\uFF01"}
+	if got := write(finder, WriteOptions{PreserveOrder: true}); got != "a,\U0001F600,\uFF01" {
+		t.Errorf("expected order of Finder to be preserved, got %q", got)
+	}
+	if got := write(finder, WriteOptions{}); got != "a,\uFF01,\U0001F600" {
+		t.Errorf("expected sorted records, got %q", got)
+	}
+	if got := write([]string{"b", "a"}, WriteOptions{PreserveOrder: true}); got != "a,b" {
+		t.Errorf("expected records out of canonical order to be sorted, got %q", got)
 	}
 }
 
@@ -139,3 +213,331 @@ func TestWriteAlignBlock(t *testing.T) {
 		t.Errorf("expected length 8, got %d", buf.Len())
 	}
 }
+
+func TestWritePreserveTrailing(t *testing.T) {
+	s := &Store{trailing: []byte("trailing")}
+	buf := new(bytes.Buffer)
+	if err := s.WriteWithOptions(buf, WriteOptions{PreserveTrailing: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("trailing")) {
+		t.Error("expected trailing data to be preserved")
+	}
+
+	var s2 Store
+	if err := s2.Read(buf); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if string(s2.Trailing()) != "trailing" {
+		t.Errorf("expected trailing data, got %q", s2.Trailing())
+	}
+
+	buf.Reset()
+	if err := s.Write(buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if bytes.HasSuffix(buf.Bytes(), []byte("trailing")) {
+		t.Error("expected trailing data to be dropped")
+	}
+}
+
+func TestWriteVerify(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := s.WriteWithOptions(buf, WriteOptions{Verify: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// DataLen must not be written for bool records, such records fail before verification
+	s.Records = []Record{{FileName: "test", Type: "bool", DataLen: 1, Data: []byte{1}}}
+	buf.Reset()
+	err := s.WriteWithOptions(buf, WriteOptions{Verify: true})
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("expected ErrInvalidRecord, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Error("expected nothing to be written on failure")
+	}
+	// written data is compared with records of the store
+	s.Records[0].DataLen = 0
+	written, err := s.encode(WriteOptions{})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if err = s.verify(written, []Record{{FileName: "test", Type: "bool", Data: []byte{0}}}); !errors.Is(err, ErrVerifyFailed) {
+		t.Errorf("expected ErrVerifyFailed, got %v", err)
+	}
+}
+
+func TestWriteInvalidRecord(t *testing.T) {
+	valid := Record{FileName: "a", Type: "bool", Data: []byte{1}}
+	for _, test := range []struct {
+		record Record
+		field  string
+	}{
+		{Record{Type: "bool", Data: []byte{1}}, "FileName"},
+		{Record{FileName: "a\xffb", Type: "bool", Data: []byte{1}}, "FileName"},
+		{Record{FileName: strings.Repeat("\U0001F600", MaxFileNameLen/2+1), Type: "bool", Data: []byte{1}}, "FileName"},
+		{Record{FileName: "a", Type: "xxxx", Data: []byte{1}}, "Type"},
+		{Record{FileName: "a", Type: "long", Data: []byte{1}}, "Data"},
+		{Record{FileName: "a", Type: "blob"}, "DataLen"},
+	} {
+		s := &Store{Records: []Record{valid, test.record}}
+		buf := new(bytes.Buffer)
+		err := s.Write(buf)
+		var recordErr *RecordError
+		var fieldErr *FieldError
+		if !errors.Is(err, ErrInvalidRecord) || !errors.As(err, &recordErr) || recordErr.Index != 1 ||
+			!errors.As(err, &fieldErr) || fieldErr.Field != test.field {
+			t.Errorf("expected invalid %s of record 1, got %v", test.field, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %d bytes", buf.Len())
+		}
+	}
+}
+
+func TestWritePageSize(t *testing.T) {
+	s := &Store{}
+	for i := 0; i < 500; i++ {
+		s.Records = append(s.Records, Record{FileName: fmt.Sprintf("file%04d", i), Type: "long", Data: []byte{0, 0, 0, byte(i)}})
+	}
+	geometry := func(opts WriteOptions) Geometry {
+		t.Helper()
+		buf := new(bytes.Buffer)
+		if err := s.WriteWithOptions(buf, opts); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var s2 Store
+		if err := s2.Read(buf); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if len(s2.Records) != len(s.Records) {
+			t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+		}
+		if pageSize := cmp.Or(opts.PageSize, DefaultPageSize); int(s2.alloc.pageSize) != pageSize {
+			t.Errorf("expected page size %d, got %d", pageSize, s2.alloc.pageSize)
+		}
+		g, _ := s2.Geometry()
+		return g
+	}
+	finder := geometry(WriteOptions{})
+	small := geometry(WriteOptions{PageSize: 256})
+	if small.Nodes <= finder.Nodes || small.Depth <= finder.Depth {
+		t.Errorf("expected more nodes and levels with small pages, got %+v and %+v", finder, small)
+	}
+	fanout := geometry(WriteOptions{Fanout: 4})
+	if fanout.Nodes < len(s.Records)/4 {
+		t.Errorf("expected at least %d nodes with fanout 4, got %d", len(s.Records)/4, fanout.Nodes)
+	}
+
+	for _, opts := range []WriteOptions{{PageSize: 100}, {PageSize: 32}, {PageSize: MaxPageSize * 2}, {Fanout: 1}, {Fanout: -1}} {
+		if err := s.WriteWithOptions(io.Discard, opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("expected ErrInvalidOptions for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var _ io.WriterTo = &s
+	var _ io.ReaderFrom = &s
+
+	buf := new(bytes.Buffer)
+	n, err := s.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+
+	var s2 Store
+	size := int64(buf.Len())
+	n, err = s2.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if n != size {
+		t.Errorf("expected %d bytes read, got %d", size, n)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+
+	// options of the previous reading are kept
+	data, err := NewEncoder().Encode(&s)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if err = s2.ReadWithOptions(bytes.NewReader(data), ReadOptions{MaxRecords: 1}); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+	if _, err = s2.ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded of kept limits, got %v", err)
+	}
+}
+
+func TestEncodedSize(t *testing.T) {
+	var read Store
+	if err := read.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var fidelity Store
+	if err := fidelity.ReadFileWithOptions(filepath.Join(".", "testdata", "00.DS_Store"), ReadOptions{Fidelity: true}); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	many := &Store{}
+	for i := 0; i < 3000; i++ {
+		many.Records = append(many.Records, Record{FileName: fmt.Sprintf("файл%04d", i), Type: "ustr", DataLen: 3, Data: []byte("\x00a\x00b\x00c")})
+	}
+	big := &Store{Records: []Record{{FileName: "big", Type: "blob", DataLen: 100000, Data: make([]byte, 100000)}}}
+
+	for name, s := range map[string]*Store{"Empty": {}, "Read": &read, "Fidelity": &fidelity, "Many": many, "Big": big} {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := s.Write(buf); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if size := s.EncodedSize(); size != buf.Len() {
+				t.Errorf("expected size %d, got %d", buf.Len(), size)
+			}
+		})
+	}
+}
+
+func TestEncodedSizeUnsorted(t *testing.T) {
+	multiPage := 0
+	for seed := range int64(100) {
+		s := GenerateRandomStore(rand.New(rand.NewSource(seed)), GenerateOptions{MaxRecords: 500})
+		buf := new(bytes.Buffer)
+		if err := s.Write(buf); err != nil {
+			t.Fatalf("Write of store %d failed: %v", seed, err)
+		}
+		if buf.Len() > 3*DefaultPageSize {
+			multiPage++
+		}
+		if size := s.EncodedSize(); size != buf.Len() {
+			t.Errorf("expected size %d of store %d, got %d", buf.Len(), seed, size)
+		}
+	}
+	if multiPage == 0 {
+		t.Error("expected stores of many pages")
+	}
+}
+
+func TestEncodedSizeInvalid(t *testing.T) {
+	s := &Store{Records: []Record{{FileName: "file", Type: "????", Data: []byte{1}}}}
+	if err := s.Write(io.Discard); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+	if size := s.EncodedSize(); size != -1 {
+		t.Errorf("expected size -1, got %d", size)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, ".DS_Store")
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := s.WriteFileWithOptions(target, 0644, WriteOptions{Atomic: true}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	var s2 Store
+	if err := s2.ReadFile(target); err != nil {
+		t.Fatalf("ReadFile of written file failed: %v", err)
+	}
+	if len(s2.Records) != len(s.Records) {
+		t.Errorf("expected %d records, got %d", len(s.Records), len(s2.Records))
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions 0600 of the existing file, got %v (%v)", info.Mode().Perm(), err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected only the target file in directory, got %d entries (%v)", len(entries), err)
+	}
+
+	if err = s.WriteFileWithOptions(filepath.Join(dir, "missing", ".DS_Store"), 0644, WriteOptions{Atomic: true}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestWriteFileAtomicMode(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	mode := func(atomic bool, existing os.FileMode) os.FileMode {
+		t.Helper()
+		target := filepath.Join(t.TempDir(), ".DS_Store")
+		if existing != 0 {
+			if err := os.WriteFile(target, []byte("old"), existing); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+			if err := os.Chmod(target, existing); err != nil {
+				t.Fatalf("Chmod failed: %v", err)
+			}
+		}
+		if err := s.WriteFileWithOptions(target, 0666, WriteOptions{Atomic: atomic}); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		return info.Mode().Perm()
+	}
+	// new files get the mode with umask, existing files keep their mode
+	for _, existing := range []os.FileMode{0, 0600, 0640, 0666} {
+		if got, expected := mode(true, existing), mode(false, existing); got != expected {
+			t.Errorf("expected atomic write to give mode %v like non-atomic one for existing mode %v, got %v", expected, existing, got)
+		}
+	}
+}
+
+func TestWriteFilePreserveMetadata(t *testing.T) {
+	var s Store
+	if err := s.ReadFile(filepath.Join(".", "testdata", "00.DS_Store")); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	target := filepath.Join(t.TempDir(), ".DS_Store")
+	if err := s.WriteFileWithOptions(target, 0600, WriteOptions{PreserveMetadata: true}); err != nil {
+		t.Fatalf("WriteFile of new file failed: %v", err)
+	}
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	mtime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, atomic := range []bool{false, true} {
+		if err := os.Chmod(target, 0640); err != nil {
+			t.Fatalf("Chmod failed: %v", err)
+		}
+		if err := os.Chtimes(target, atime, mtime); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+		if err := s.WriteFileWithOptions(target, 0644, WriteOptions{PreserveMetadata: true, Atomic: atomic}); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Errorf("expected permissions 0640, got %v", info.Mode().Perm())
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("expected modification time %v, got %v", mtime, info.ModTime())
+		}
+	}
+}
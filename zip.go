@@ -0,0 +1,114 @@
+package dsstore
+
+import (
+	"archive/zip"
+	"io"
+	"path"
+	"strings"
+)
+
+// macOSXDir is the directory of AppleDouble files added by macOS archiver
+const macOSXDir = "__MACOSX/"
+
+// ZipEntry is .DS_Store found in zip archive
+type ZipEntry struct {
+	Name  string // name of the entry in the archive
+	Store *Store // read store, nil when the entry can't be read
+	Err   error  // error of reading the entry, the store can be partially read in best-effort mode
+	// Siblings are names of __MACOSX entries with AppleDouble data of the .DS_Store, like "__MACOSX/dir/._.DS_Store"
+	Siblings []string
+}
+
+// ZipStripOptions are options of StripZip
+type ZipStripOptions struct {
+	// MacOSX removes all entries of __MACOSX directory, not only siblings of .DS_Store files
+	MacOSX bool
+}
+
+// isStoreEntry reports whether the zip entry is .DS_Store file
+func isStoreEntry(name string) bool {
+	return path.Base(name) == StoreFileName && !strings.HasSuffix(name, "/") && !strings.HasPrefix(name, macOSXDir)
+}
+
+// appleDoubleName returns name of AppleDouble entry of the entry in __MACOSX directory
+func appleDoubleName(name string) string {
+	dir, file := path.Split(name)
+	return macOSXDir + dir + "._" + file
+}
+
+// ScanZip reads .DS_Store files embedded in zip archive of the given size.
+// Stores are read in best-effort mode using options, ReadOptions.MaxFileSize limits decompressed size of every store.
+func ScanZip(r io.ReaderAt, size int64, opts ReadOptions) ([]ZipEntry, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(zr.File))
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	opts.BestEffort = true
+	var entries []ZipEntry
+	for _, f := range zr.File {
+		if !isStoreEntry(f.Name) {
+			continue
+		}
+		entry := ZipEntry{Name: f.Name}
+		if sibling := appleDoubleName(f.Name); names[sibling] {
+			entry.Siblings = append(entry.Siblings, sibling)
+		}
+		entry.Store, entry.Err = readZipFile(f, opts)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readZipFile reads .DS_Store from the zip entry
+func readZipFile(f *zip.File, opts ReadOptions) (*Store, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	s := &Store{}
+	if err = s.ReadWithOptions(rc, opts); err != nil && !s.readable() {
+		return nil, err
+	}
+	return s, err
+}
+
+// StripZip writes zip archive of the given size without .DS_Store files and their AppleDouble siblings.
+// Other entries are copied without recompression, so their data is kept byte for byte.
+// It returns names of removed entries.
+func StripZip(w io.Writer, r io.ReaderAt, size int64, opts ZipStripOptions) ([]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	siblings := make(map[string]bool)
+	for _, f := range zr.File {
+		if isStoreEntry(f.Name) {
+			siblings[appleDoubleName(f.Name)] = true
+		}
+	}
+	zw := zip.NewWriter(w)
+	var removed []string
+	for _, f := range zr.File {
+		if isStoreEntry(f.Name) || siblings[f.Name] || opts.MacOSX && strings.HasPrefix(f.Name, macOSXDir) {
+			removed = append(removed, f.Name)
+			continue
+		}
+		if err = zw.Copy(f); err != nil {
+			return nil, err
+		}
+	}
+	if err = zw.SetComment(zr.Comment); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
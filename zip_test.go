@@ -0,0 +1,120 @@
+package dsstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type zipFile struct {
+	name   string
+	data   []byte
+	method uint16
+}
+
+func writeZip(t *testing.T, files []zipFile) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: file.method})
+		if err != nil {
+			t.Fatalf("CreateHeader failed: %v", err)
+		}
+		if _, err = w.Write(file.data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := zw.SetComment("release"); err != nil {
+		t.Fatalf("SetComment failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testZip(t *testing.T) []byte {
+	fileData, err := os.ReadFile(filepath.Join(".", "testdata", "00.DS_Store"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	return writeZip(t, []zipFile{
+		{name: "App/", method: zip.Store},
+		{name: "App/.DS_Store", data: fileData, method: zip.Deflate},
+		{name: "App/readme.txt", data: []byte("readme"), method: zip.Deflate},
+		{name: "App/lib/.DS_Store", data: []byte("garbage"), method: zip.Store},
+		{name: "App/lib/lib.so", data: bytes.Repeat([]byte{1, 2}, 100), method: zip.Deflate},
+		{name: "__MACOSX/App/._.DS_Store", data: []byte("apple double"), method: zip.Deflate},
+		{name: "__MACOSX/App/._readme.txt", data: []byte("apple double"), method: zip.Deflate},
+	})
+}
+
+func TestScanZip(t *testing.T) {
+	data := testZip(t)
+	entries, err := ScanZip(bytes.NewReader(data), int64(len(data)), ReadOptions{})
+	if err != nil {
+		t.Fatalf("ScanZip failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Name != "App/.DS_Store" || e.Err != nil || len(e.Store.Records) != 6 ||
+		!slices.Equal(e.Siblings, []string{"__MACOSX/App/._.DS_Store"}) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := entries[1]; e.Name != "App/lib/.DS_Store" || e.Err == nil || e.Store != nil || e.Siblings != nil {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if _, err = ScanZip(bytes.NewReader(data[:100]), 100, ReadOptions{}); err == nil {
+		t.Error("expected error for invalid archive")
+	}
+}
+
+func TestStripZip(t *testing.T) {
+	data := testZip(t)
+	for _, test := range []struct {
+		opts    ZipStripOptions
+		removed []string
+	}{
+		{ZipStripOptions{}, []string{"App/.DS_Store", "App/lib/.DS_Store", "__MACOSX/App/._.DS_Store"}},
+		{ZipStripOptions{MacOSX: true}, []string{"App/.DS_Store", "App/lib/.DS_Store", "__MACOSX/App/._.DS_Store", "__MACOSX/App/._readme.txt"}},
+	} {
+		var buf bytes.Buffer
+		removed, err := StripZip(&buf, bytes.NewReader(data), int64(len(data)), test.opts)
+		if err != nil {
+			t.Fatalf("StripZip failed: %v", err)
+		}
+		if !slices.Equal(removed, test.removed) {
+			t.Errorf("expected removed %v, got %v", test.removed, removed)
+		}
+		src, _ := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		dst, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+		if dst.Comment != "release" {
+			t.Errorf("expected comment to be kept, got %q", dst.Comment)
+		}
+		if len(dst.File)+len(removed) != len(src.File) {
+			t.Fatalf("expected %d entries, got %d", len(src.File)-len(removed), len(dst.File))
+		}
+		for _, f := range dst.File {
+			i := slices.IndexFunc(src.File, func(s *zip.File) bool { return s.Name == f.Name })
+			if i < 0 || src.File[i].CRC32 != f.CRC32 || src.File[i].Method != f.Method {
+				t.Errorf("entry %s is changed", f.Name)
+				continue
+			}
+			raw1, _ := src.File[i].OpenRaw()
+			raw2, _ := f.OpenRaw()
+			b1, _ := io.ReadAll(raw1)
+			b2, _ := io.ReadAll(raw2)
+			if !bytes.Equal(b1, b2) {
+				t.Errorf("data of entry %s is changed", f.Name)
+			}
+		}
+	}
+}